@@ -10,12 +10,13 @@ var Conf = new(Config)
 
 // Config 根节点配置，完全对齐你的 YAML
 type Config struct {
-	DB        DBConfig     `mapstructure:"db"`
-	Log       LogConfig    `mapstructure:"log"`
-	Whitelist []string     `mapstructure:"whitelist"` // 白名单路由
-	Auth      AuthConfig   `mapstructure:"auth"`
-	Server    ServerConfig `mapstructure:"server"`
-	Redis     RedisConfig  `mapstructure:"redis"`
+	DB        DBConfig       `mapstructure:"db"`
+	Log       LogConfig      `mapstructure:"log"`
+	Whitelist []string       `mapstructure:"whitelist"` // 白名单路由
+	Auth      AuthConfig     `mapstructure:"auth"`
+	Server    ServerConfig   `mapstructure:"server"`
+	Redis     RedisConfig    `mapstructure:"redis"`
+	Register  RegisterConfig `mapstructure:"register"`
 }
 
 // DBConfig 数据库配置 (注意：这里将 uri 拆分为 host 和 port 以适配 GORM)
@@ -54,6 +55,12 @@ type AuthConfig struct {
 	Secret        string `mapstructure:"secret"`
 }
 
+// RegisterConfig 注册配置
+type RegisterConfig struct {
+	Open            bool `mapstructure:"open"`                // 是否开放注册（默认关闭）
+	MaxPerIPPerHour int  `mapstructure:"max_per_ip_per_hour"` // 每个IP每小时最多注册尝试次数（0 表示 5）
+}
+
 // ServerConfig 服务器配置
 type ServerConfig struct {
 	Port int `mapstructure:"port"`
@@ -72,8 +72,8 @@ func HandlerFuncLogin(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
 			return
 		}
 
-		// 4. 检查状态
-		if u.Status == 0 {
+		// 4. 检查状态（只有正常状态可以登录）
+		if u.Status != 1 {
 			c.JSON(http.StatusOK, gin.H{
 				"code":    403,
 				"message": "账号已被禁用",
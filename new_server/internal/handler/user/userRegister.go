@@ -1,14 +1,18 @@
 package user
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"syc-file/config"
 	"syc-file/internal/model"
 	"syc-file/pkg/logger"
 	"syc-file/pkg/password"
@@ -16,6 +20,25 @@ import (
 
 func HandlerFuncRegister(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// 0. 注册开关与按IP限流
+		if !config.Conf.Register.Open {
+			c.JSON(http.StatusOK, gin.H{
+				"code":    403,
+				"message": "当前未开放注册",
+				"data":    nil,
+			})
+			return
+		}
+		if !allowRegisterAttempt(redisClient, c.ClientIP()) {
+			logger.Logger.Warn("注册尝试过于频繁", zap.String("ip", c.ClientIP()))
+			c.JSON(http.StatusOK, gin.H{
+				"code":    429,
+				"message": "注册尝试过于频繁，请稍后再试",
+				"data":    nil,
+			})
+			return
+		}
+
 		var req struct {
 			Username string `json:"username"`
 			Password string `json:"password" binding:"required"`
@@ -124,3 +147,23 @@ func HandlerFuncRegister(db *gorm.DB, redisClient *redis.Client) gin.HandlerFunc
 		})
 	}
 }
+
+// allowRegisterAttempt 记录一次注册尝试，超过每小时上限时返回 false；Redis 异常时放行
+func allowRegisterAttempt(redisClient *redis.Client, ip string) bool {
+	limit := config.Conf.Register.MaxPerIPPerHour
+	if limit <= 0 {
+		limit = 5
+	}
+
+	ctx := context.Background()
+	key := fmt.Sprintf("register_ip:%s", ip)
+	count, err := redisClient.Incr(ctx, key).Result()
+	if err != nil {
+		logger.Logger.Error("注册频率检查失败", zap.Error(err))
+		return true
+	}
+	if count == 1 {
+		redisClient.Expire(ctx, key, time.Hour)
+	}
+	return count <= int64(limit)
+}
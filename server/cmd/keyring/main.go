@@ -0,0 +1,241 @@
+// keyring 管理 token / 两步验证密钥使用的加密密钥环
+//
+// 轮换流程（不会让已登录用户掉线）：
+//
+//	go run ./cmd/keyring generate -id k2026q4   # 生成新密钥（保留状态）
+//	go run ./cmd/keyring activate k2026q4       # 启用，重启服务后新 token 使用新密钥
+//	go run ./cmd/keyring rewrap                 # 用新密钥重新加密数据库中的两步验证密钥
+//	go run ./cmd/keyring retire k2026q3         # 旧 token 全部过期后停用旧密钥
+//	go run ./cmd/keyring list
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/encryption"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/database"
+	_venv "github.com/sunyuanling/server/venv"
+)
+
+const usage = `用法: keyring [-config config/config.yaml] <命令> [参数]
+
+命令:
+  list                 列出密钥
+  generate [-id ID]    生成新密钥（保留状态，不会立即用于加密）
+  activate ID          启用密钥（需要重启服务）
+  retire [-force] ID   停用密钥，用它加密的 token 和数据将无法解密
+  rewrap               用当前密钥重新加密数据库中的两步验证密钥
+`
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "配置文件路径")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fail(err)
+	}
+	path := cfg.Security.KeyringFile
+
+	ring, err := encryption.LoadKeyring(path)
+	if errors.Is(err, os.ErrNotExist) {
+		ring = encryption.DefaultKeyring()
+	} else if err != nil {
+		fail(err)
+	}
+
+	args := flag.Args()[1:]
+	switch flag.Arg(0) {
+	case "list":
+		list(ring)
+		return
+	case "generate":
+		err = generate(ring, args)
+	case "activate":
+		err = activate(ring, args)
+	case "retire":
+		err = retire(cfg, ring, args)
+	case "rewrap":
+		err = rewrap(cfg, ring)
+		if err == nil {
+			return
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fail(err)
+	}
+
+	if err := ring.Save(path); err != nil {
+		fail(fmt.Errorf("保存密钥环失败: %w", err))
+	}
+	fmt.Println("已保存", path)
+	list(ring)
+}
+
+func list(ring *encryption.Keyring) {
+	fmt.Printf("%-20s %-9s %-17s %-17s %-17s %s\n", "ID", "STATUS", "CREATED", "ACTIVATED", "DEACTIVATED", "RETIRED")
+	for _, k := range ring.Keys {
+		fmt.Printf("%-20s %-9s %-17s %-17s %-17s %s\n",
+			k.ID, k.Status, formatTime(&k.CreatedAt), formatTime(k.ActivatedAt), formatTime(k.DeactivatedAt), formatTime(k.RetiredAt))
+	}
+}
+
+func generate(ring *encryption.Keyring, args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	id := fs.String("id", "k"+time.Now().Format("20060102"), "密钥ID")
+	_ = fs.Parse(args)
+
+	entry, err := ring.Generate(*id)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已生成密钥 %s，确认所有实例的密钥环都已更新后执行 activate %s\n", entry.ID, entry.ID)
+	return nil
+}
+
+func activate(ring *encryption.Keyring, args []string) error {
+	if len(args) != 1 {
+		return errors.New("用法: activate ID")
+	}
+	if err := ring.Activate(args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("已启用密钥 %s，重启服务后生效；旧密钥保留用于解密\n", args[0])
+	return nil
+}
+
+// retire 停用前检查：旧密钥签发的 token 是否都已过期、数据库中是否还有用它加密的数据
+func retire(cfg *config.Config, ring *encryption.Keyring, args []string) error {
+	fs := flag.NewFlagSet("retire", flag.ExitOnError)
+	force := fs.Bool("force", false, "跳过检查，强制停用")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("用法: retire [-force] ID")
+	}
+	id := fs.Arg(0)
+
+	entry, err := ring.Find(id)
+	if err != nil {
+		return err
+	}
+	if !*force && entry.Status == encryption.KeyStatusRetained {
+		if entry.DeactivatedAt != nil {
+			expiresAt := entry.DeactivatedAt.Add(time.Duration(cfg.Token.ValidityDate) * time.Minute)
+			if time.Now().Before(expiresAt) {
+				return fmt.Errorf("用密钥 %s 签发的 token 最晚在 %s 过期，提前停用会使这些用户需要重新登录（-force 跳过检查）",
+					id, expiresAt.Format("2006-01-02 15:04"))
+			}
+		}
+
+		db, err := openDB(cfg)
+		if err != nil {
+			return err
+		}
+		count, err := countSecrets(db, id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("还有 %d 个两步验证密钥使用密钥 %s 加密，请先执行 rewrap（-force 跳过检查）", count, id)
+		}
+	}
+
+	if err := ring.Retire(id); err != nil {
+		return err
+	}
+	fmt.Printf("已停用密钥 %s，重启服务后生效\n", id)
+	return nil
+}
+
+// rewrap 用当前密钥重新加密两步验证密钥
+func rewrap(cfg *config.Config, ring *encryption.Keyring) error {
+	enc, err := encryption.NewKeyringEncryptor(_venv.GetEncryptionKey(), ring)
+	if err != nil {
+		return err
+	}
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+
+	var rows []model.UserTwoFactor
+	if err := db.Find(&rows).Error; err != nil {
+		return err
+	}
+
+	updated, failed := 0, 0
+	for _, row := range rows {
+		if encryption.KeyID(row.Secret) == enc.ActiveKeyID() {
+			continue
+		}
+		secret, err := enc.Decrypt(row.Secret)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "用户 %d 的两步验证密钥解密失败: %v\n", row.UserID, err)
+			failed++
+			continue
+		}
+		sealed, err := enc.Encrypt(secret)
+		if err != nil {
+			return err
+		}
+		if err := db.Model(&model.UserTwoFactor{}).
+			Where("user_id = ? AND secret = ?", row.UserID, row.Secret).
+			Update("secret", sealed).Error; err != nil {
+			return err
+		}
+		updated++
+	}
+
+	fmt.Printf("已重新加密 %d 个两步验证密钥（当前密钥 %s，失败 %d）\n", updated, enc.ActiveKeyID(), failed)
+	return nil
+}
+
+// countSecrets 使用指定密钥加密的两步验证密钥数量
+func countSecrets(db *gorm.DB, id string) (int64, error) {
+	query := db.Model(&model.UserTwoFactor{})
+	if id == encryption.LegacyKeyID {
+		query = query.Where("secret NOT LIKE ?", "%.%")
+	} else {
+		query = query.Where("secret LIKE ?", id+".%")
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func openDB(cfg *config.Config) (*gorm.DB, error) {
+	db, err := database.NewPostgresDB(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	return db.Session(&gorm.Session{Logger: gormLogger.Default.LogMode(gormLogger.Warn)}), nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "错误:", err)
+	os.Exit(1)
+}
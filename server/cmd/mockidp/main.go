@@ -0,0 +1,261 @@
+// mockidp 本地测试用的 OpenID Connect 身份提供方（不要用于生产环境）
+//
+// 启动后访问授权地址会直接以命令行指定的用户身份回调，不需要输入密码；
+// 授权地址上带 login_hint=<sub> 时以该 sub 登录，方便测试多个账号。
+//
+//	go run ./cmd/mockidp -addr :9998 -client-id filesync -client-secret mock-secret \
+//	    -sub alice -email alice@example.com
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	keyID   = "mock-key-1"
+	codeTTL = time.Minute
+)
+
+type options struct {
+	issuer        string
+	clientID      string
+	clientSecret  string
+	sub           string
+	email         string
+	emailVerified bool
+	name          string
+}
+
+// grant 授权码对应的登录信息
+type grant struct {
+	sub           string
+	nonce         string
+	redirectURI   string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+type server struct {
+	opts options
+	key  *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+func main() {
+	addr := flag.String("addr", ":9998", "监听地址")
+	var opts options
+	flag.StringVar(&opts.issuer, "issuer", "http://localhost:9998", "issuer（需与服务端配置一致）")
+	flag.StringVar(&opts.clientID, "client-id", "filesync", "客户端ID")
+	flag.StringVar(&opts.clientSecret, "client-secret", "mock-secret", "客户端密钥，为空表示公共客户端")
+	flag.StringVar(&opts.sub, "sub", "mock-user", "默认登录用户的 sub")
+	flag.StringVar(&opts.email, "email", "mock-user@example.com", "邮箱")
+	flag.BoolVar(&opts.emailVerified, "email-verified", true, "邮箱是否已验证")
+	flag.StringVar(&opts.name, "name", "Mock User", "姓名")
+	flag.Parse()
+	opts.issuer = strings.TrimSuffix(opts.issuer, "/")
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("生成签名密钥失败: %v", err)
+	}
+	s := &server{opts: opts, key: key, grants: make(map[string]grant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+
+	fmt.Printf("Mock IdP 运行在 %s（issuer: %s）\n", *addr, opts.issuer)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (s *server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.opts.issuer,
+		"authorization_endpoint":                s.opts.issuer + "/authorize",
+		"token_endpoint":                        s.opts.issuer + "/token",
+		"jwks_uri":                              s.opts.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "none"},
+	})
+}
+
+// authorize 直接同意授权并带着 code 跳回 redirect_uri
+func (s *server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != s.opts.clientID {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "需要 PKCE（S256）", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "redirect_uri 无效", http.StatusBadRequest)
+		return
+	}
+
+	sub := q.Get("login_hint")
+	if sub == "" {
+		sub = s.opts.sub
+	}
+	code := randomString(24)
+	s.mu.Lock()
+	s.grants[code] = grant{
+		sub:           sub,
+		nonce:         q.Get("nonce"),
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+		expiresAt:     time.Now().Add(codeTTL),
+	}
+	s.mu.Unlock()
+
+	values := redirectURI.Query()
+	values.Set("code", code)
+	values.Set("state", q.Get("state"))
+	redirectURI.RawQuery = values.Encode()
+	log.Printf("授权: sub=%s -> %s", sub, redirectURI.String())
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token 校验客户端、授权码和 PKCE，签发 ID Token
+func (s *server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+	}
+	if clientID != s.opts.clientID || clientSecret != s.opts.clientSecret {
+		tokenError(w, http.StatusUnauthorized, "invalid_client", "客户端认证失败")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	g, found := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+	if !found || time.Now().After(g.expiresAt) || g.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "授权码无效或已过期")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != g.codeChallenge {
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "code_verifier 不匹配")
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":            s.opts.issuer,
+		"sub":            g.sub,
+		"aud":            s.opts.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          g.nonce,
+		"email":          s.opts.email,
+		"email_verified": s.opts.emailVerified,
+		"name":           s.opts.name,
+	}
+	if g.sub != s.opts.sub {
+		// login_hint 指定的其他账号：用 sub 生成邮箱和用户名
+		claims["email"] = g.sub + "@example.com"
+		claims["name"] = g.sub
+		claims["preferred_username"] = g.sub
+	}
+	idToken, err := s.sign(claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(32),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (s *server) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// sign RS256 签名
+func (s *server) sign(claims map[string]interface{}) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func tokenError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,35 @@
+// wsschema 生成 WebSocket 协议的 JSON Schema 文件
+//
+//	go run ./cmd/wsschema -o docs/websocket-protocol.schema.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sunyuanling/server/websocket"
+)
+
+func main() {
+	output := flag.String("o", "docs/websocket-protocol.schema.json", "输出文件路径，- 表示标准输出")
+	flag.Parse()
+
+	data, err := json.MarshalIndent(websocket.ProtocolSchema(), "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "生成Schema失败:", err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+
+	if *output == "-" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "写入Schema失败:", err)
+		os.Exit(1)
+	}
+	fmt.Println("已生成", *output)
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
 	"runtime"
 	"strings"
 
@@ -9,18 +12,28 @@ import (
 )
 
 type Config struct {
-	Server     ServerConfig   `mapstructure:"server"`
-	Database   DatabaseConfig `mapstructure:"database"`
-	Redis      RedisConfig    `mapstructure:"redis"`
-	JWT        JWTConfig      `mapstructure:"jwt"`
-	Security   SecurityConfig `mapstructure:"security"`
-	Sync       SyncConfig     `mapstructure:"sync"`
-	DebugLog   LogConfig      `mapstructure:"debugLog"`
-	DevLog     LogConfig      `mapstructure:"devLog"`
-	ProdLog    LogConfig      `mapstructure:"prodLog"`
-	Token      TokenConfig    `mapstructure:"token"`
-	File       FileConfig     `mapstructure:"file"`
-	UserConfig UserConfig     `mapstructure:"userConfig"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Redis      RedisConfig      `mapstructure:"redis"`
+	JWT        JWTConfig        `mapstructure:"jwt"`
+	Security   SecurityConfig   `mapstructure:"security"`
+	Sync       SyncConfig       `mapstructure:"sync"`
+	DebugLog   LogConfig        `mapstructure:"debugLog"`
+	DevLog     LogConfig        `mapstructure:"devLog"`
+	ProdLog    LogConfig        `mapstructure:"prodLog"`
+	Token      TokenConfig      `mapstructure:"token"`
+	File       FileConfig       `mapstructure:"file"`
+	UserConfig UserConfig       `mapstructure:"userConfig"`
+	Mail       MailConfig       `mapstructure:"mail"`
+	Register   RegisterConfig   `mapstructure:"register"`
+	OIDC       OIDCConfig       `mapstructure:"oidc"`
+	Audit      AuditConfig      `mapstructure:"audit"`
+	Account    AccountConfig    `mapstructure:"account"`
+	Monitor    MonitorConfig    `mapstructure:"monitor"`
+	Metrics    MetricsConfig    `mapstructure:"metrics"`
+	Alert      AlertConfig      `mapstructure:"alert"`
+	DiskHealth DiskHealthConfig `mapstructure:"diskHealth"`
+	Tracing    TracingConfig    `mapstructure:"tracing"`
 }
 
 type ServerConfig struct {
@@ -55,9 +68,17 @@ type JWTConfig struct {
 }
 
 type SecurityConfig struct {
-	BcryptCost             int `mapstructure:"bcrypt_cost"`
-	MaxLoginAttempts       int `mapstructure:"max_login_attempts"`
-	LockoutDurationMinutes int `mapstructure:"lockout_duration_minutes"`
+	BcryptCost             int    `mapstructure:"bcrypt_cost"`
+	MaxLoginAttempts       int    `mapstructure:"max_login_attempts"`
+	LockoutDurationMinutes int    `mapstructure:"lockout_duration_minutes"`
+	TwoFactorIssuer        string `mapstructure:"two_factor_issuer"`         // 认证器 App 中显示的发行方（默认：FileSync）
+	TrustedDeviceDays      int    `mapstructure:"trusted_device_days"`       // 两步验证记住设备的天数（默认：30）
+	PasswordResetMinutes   int    `mapstructure:"password_reset_minutes"`    // 找回密码验证码有效期（分钟，默认：15）
+	LoginWindowMinutes     int    `mapstructure:"login_window_minutes"`      // 登录失败计数的滑动窗口（分钟，默认：15）
+	MaxLoginAttemptsPerIP  int    `mapstructure:"max_login_attempts_per_ip"` // 单个IP窗口内允许的登录失败次数（默认：20）
+	MaxLockoutMinutes      int    `mapstructure:"max_lockout_minutes"`       // 连续锁定时逐级翻倍的上限（分钟，默认：1440）
+	MaxResetPerIPPerHour   int    `mapstructure:"max_reset_per_ip_per_hour"` // 单个IP每小时找回密码/邮箱验证请求次数（默认：10）
+	KeyringFile            string `mapstructure:"keyring_file"`              // 加密密钥环文件，不存在时只使用 key.yaml 中的密钥（默认：./venv/keyring.yaml）
 }
 
 type SyncConfig struct {
@@ -75,7 +96,13 @@ type LogConfig struct {
 
 // TokenConfig Token 配置
 type TokenConfig struct {
-	ValidityDate int `mapstructure:"validityDate"` // 有效期（分钟）
+	ValidityDate int      `mapstructure:"validityDate"` // 有效期（分钟）
+	Format       string   `mapstructure:"format"`       // 签发格式：encrypted / jwt-hs256 / jwt-eddsa / paseto-v4（默认：encrypted）
+	Accept       []string `mapstructure:"accept"`       // 迁移期内额外接受的格式（签发格式总是接受）
+	Issuer       string   `mapstructure:"issuer"`       // JWT / PASETO 中的 iss（默认：filesync）
+	// AcceptLegacyHS256 迁移期内接受以 key.yaml 原始密钥签名、没有 kid 的 HS256 token（new_server 签发的 token）
+	// 只用于验证，不会用原始密钥签发；需要 format 或 accept 中包含 jwt-hs256
+	AcceptLegacyHS256 bool `mapstructure:"acceptLegacyHS256"`
 }
 
 // FileConfig 文件存储配置
@@ -118,6 +145,121 @@ type UserConfig struct {
 	MaxSize           int64    `mapstructure:"maxSize"`           // 最大头像大小（字节，默认：10MB）
 }
 
+// MailConfig 邮件发送配置
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"`   // smtp / file（默认：file，写入本地目录便于测试）
+	Host     string `mapstructure:"host"`     // SMTP 服务器
+	Port     int    `mapstructure:"port"`     // SMTP 端口（465 使用 TLS，其余使用 STARTTLS）
+	Username string `mapstructure:"username"` // SMTP 用户名
+	Password string `mapstructure:"password"` // SMTP 密码/授权码
+	From     string `mapstructure:"from"`     // 发件人地址
+	FileDir  string `mapstructure:"fileDir"`  // file 模式的邮件保存目录（默认：./log/mail）
+}
+
+// RegisterConfig 注册策略配置
+type RegisterConfig struct {
+	Mode              string `mapstructure:"mode"`              // closed / invite / email_verify / admin_approve / open（默认：admin_approve）
+	InviteExpireHours int    `mapstructure:"inviteExpireHours"` // 邀请码默认有效期（小时，默认：72）
+	VerifyMinutes     int    `mapstructure:"verifyMinutes"`     // 邮箱验证码有效期（分钟，默认：30）
+	MaxPerIPPerHour   int    `mapstructure:"maxPerIPPerHour"`   // 每个IP每小时最多注册尝试次数（默认：5）
+}
+
+// OIDCConfig OpenID Connect 登录配置
+type OIDCConfig struct {
+	StateMinutes int                  `mapstructure:"stateMinutes"` // 授权请求（state）有效期（分钟，默认：10）
+	Providers    []OIDCProviderConfig `mapstructure:"providers"`    // 身份提供方列表，为空表示不启用
+}
+
+// OIDCProviderConfig 单个身份提供方
+type OIDCProviderConfig struct {
+	Name           string   `mapstructure:"name"`           // 标识，接口参数中使用（唯一）
+	DisplayName    string   `mapstructure:"displayName"`    // 登录页显示名称（默认同 name）
+	Issuer         string   `mapstructure:"issuer"`         // Issuer 地址，从 {issuer}/.well-known/openid-configuration 发现端点
+	ClientID       string   `mapstructure:"clientId"`       // 客户端ID
+	ClientSecret   string   `mapstructure:"clientSecret"`   // 客户端密钥（公共客户端可留空，仅使用 PKCE）
+	RedirectURL    string   `mapstructure:"redirectUrl"`    // 回调地址（前端页面，拿到 code 后调用 /api/auth/oidc/callback）
+	Scopes         []string `mapstructure:"scopes"`         // 申请的 scope（默认：openid profile email）
+	LinkByEmail    bool     `mapstructure:"linkByEmail"`    // 邮箱已验证且与本地账号一致时自动关联
+	AutoProvision  bool     `mapstructure:"autoProvision"`  // 没有对应账号时自动创建
+	AllowedDomains []string `mapstructure:"allowedDomains"` // 自动创建账号时允许的邮箱域名（为空不限制）
+}
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	RetentionDays int `mapstructure:"retentionDays"` // 保留天数，小于 0 表示永久保留（默认：180）
+	BufferSize    int `mapstructure:"bufferSize"`    // 异步写入队列长度，队列满时事件只写入日志文件（默认：4096）
+	BatchSize     int `mapstructure:"batchSize"`     // 单次批量写入条数（默认：100）
+	FlushSeconds  int `mapstructure:"flushSeconds"`  // 最长写入间隔（秒，默认：2）
+}
+
+// AccountConfig 账号数据导出和注销配置
+type AccountConfig struct {
+	DeletionGraceDays  int    `mapstructure:"deletionGraceDays"`  // 申请注销后的冷静期（天），期间可撤销（默认：14）
+	TakeoutDir         string `mapstructure:"takeoutDir"`         // 导出压缩包存放目录（默认：默认存储路径下的 temp/takeout）
+	TakeoutExpireHours int    `mapstructure:"takeoutExpireHours"` // 导出压缩包保留时间（小时，默认：72）
+}
+
+// MonitorConfig 系统监控配置
+type MonitorConfig struct {
+	IntervalSeconds int `mapstructure:"intervalSeconds"` // 采样间隔（秒，默认：5），小于 0 表示关闭采集
+	WindowMinutes   int `mapstructure:"windowMinutes"`   // Redis 中保留最近多久的采样（分钟，默认：60）
+}
+
+// MetricsConfig Prometheus 指标导出配置
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // 是否启用 /metrics
+	Listen  string `mapstructure:"listen"`  // 单独的监听地址（如 127.0.0.1:9464），为空时由主服务提供 /metrics
+	Token   string `mapstructure:"token"`   // 抓取时需携带 Authorization: Bearer <token>；为空时主服务上只允许本机直接访问，单独地址上不校验
+}
+
+// TracingConfig 链路追踪配置，通过 OTLP/HTTP 导出到 OpenTelemetry Collector
+type TracingConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`     // 是否启用
+	Endpoint    string            `mapstructure:"endpoint"`    // Collector 的 OTLP/HTTP 地址（默认：http://127.0.0.1:4318）
+	ServiceName string            `mapstructure:"serviceName"` // 上报的 service.name（默认：filesync-server）
+	SampleRatio float64           `mapstructure:"sampleRatio"` // 没有上游追踪信息的请求的采样比例，0~1（0 表示默认：1）
+	Headers     map[string]string `mapstructure:"headers"`     // 导出请求附带的请求头（如 Collector 的认证信息）
+}
+
+// DiskHealthConfig 磁盘健康（SMART）配置，仅 Linux 采集
+type DiskHealthConfig struct {
+	IntervalMinutes     int     `mapstructure:"intervalMinutes"`     // 采集间隔（分钟，默认：60），小于 0 表示关闭
+	RetentionDays       int     `mapstructure:"retentionDays"`       // 历史记录保留天数（默认：365）
+	TrendDays           int     `mapstructure:"trendDays"`           // 重映射扇区增长的比较窗口（天，默认：30）
+	Smartctl            string  `mapstructure:"smartctl"`            // smartctl 路径（默认从 PATH 查找），需要 root 或 CAP_SYS_RAWIO
+	TemperatureWarning  float64 `mapstructure:"temperatureWarning"`  // 温度警告阈值（℃，默认：55）
+	TemperatureCritical float64 `mapstructure:"temperatureCritical"` // 温度严重阈值（℃，默认：65）
+}
+
+// AlertConfig 告警规则和通知渠道配置
+// 各规则阈值为 0 时使用默认值，小于 0 表示关闭该规则
+type AlertConfig struct {
+	IntervalSeconds      int                 `mapstructure:"intervalSeconds"`      // 规则评估间隔（秒，默认：60），小于 0 表示关闭告警
+	RepeatMinutes        int                 `mapstructure:"repeatMinutes"`        // 告警持续未恢复时重复通知的间隔（分钟，默认：360），小于 0 表示不重复
+	DiskUsagePercent     float64             `mapstructure:"diskUsagePercent"`     // 存储磁盘使用率告警阈值（%，默认：90）
+	DiskCriticalPercent  float64             `mapstructure:"diskCriticalPercent"`  // 存储磁盘使用率严重阈值（%，默认：95）
+	FreeSpace            bool                `mapstructure:"freeSpace"`            // 存储磁盘剩余空间低于 file.storage.minFreeSpace 时告警
+	DiskHealth           bool                `mapstructure:"diskHealth"`           // 物理磁盘 SMART 状态为 warning/critical 时告警（需要启用 diskHealth 采集）
+	FailedUploadsPerHour int                 `mapstructure:"failedUploadsPerHour"` // 最近一小时上传失败次数阈值（默认：20）
+	LoginFailures        int                 `mapstructure:"loginFailures"`        // 同一账号或 IP 登录失败次数阈值（默认：10）
+	LoginWindowMinutes   int                 `mapstructure:"loginWindowMinutes"`   // 登录失败的统计窗口（分钟，默认：15）
+	DeviceOfflineDays    int                 `mapstructure:"deviceOfflineDays"`    // 设备连续离线天数阈值（默认：30）
+	Channels             AlertChannelsConfig `mapstructure:"channels"`             // 通知渠道
+}
+
+// AlertChannelsConfig 告警通知渠道
+type AlertChannelsConfig struct {
+	WebSocket bool                 `mapstructure:"websocket"` // 推送给在线的、有 system:monitor 权限的用户
+	Emails    []string             `mapstructure:"emails"`    // 邮件收件人（使用 mail 配置发送），为空时不发邮件
+	Webhooks  []AlertWebhookConfig `mapstructure:"webhooks"`  // Webhook 地址
+}
+
+// AlertWebhookConfig 告警 Webhook
+type AlertWebhookConfig struct {
+	URL    string `mapstructure:"url"`    // 接收地址，以 JSON POST
+	Secret string `mapstructure:"secret"` // 签名密钥，设置后请求头带 X-Alert-Signature: sha256=<HMAC-SHA256(body)>
+}
+
 //
 
 func Load(configPath string) (*Config, error) {
@@ -155,6 +297,163 @@ func (c *Config) setDefaults() {
 		}
 	}
 
+	// 两步验证默认值
+	if c.Security.TwoFactorIssuer == "" {
+		c.Security.TwoFactorIssuer = "FileSync"
+	}
+	if c.Security.TrustedDeviceDays == 0 {
+		c.Security.TrustedDeviceDays = 30
+	}
+	// 登录限流默认值
+	if c.Security.MaxLoginAttempts == 0 {
+		c.Security.MaxLoginAttempts = 5
+	}
+	if c.Security.LockoutDurationMinutes == 0 {
+		c.Security.LockoutDurationMinutes = 5
+	}
+	if c.Security.LoginWindowMinutes == 0 {
+		c.Security.LoginWindowMinutes = 15
+	}
+	if c.Security.MaxLoginAttemptsPerIP == 0 {
+		c.Security.MaxLoginAttemptsPerIP = 20
+	}
+	if c.Security.MaxLockoutMinutes == 0 {
+		c.Security.MaxLockoutMinutes = 1440
+	}
+	if c.Security.MaxResetPerIPPerHour == 0 {
+		c.Security.MaxResetPerIPPerHour = 10
+	}
+	if c.Security.PasswordResetMinutes == 0 {
+		c.Security.PasswordResetMinutes = 15
+	}
+	if c.Security.KeyringFile == "" {
+		c.Security.KeyringFile = "./venv/keyring.yaml"
+	}
+
+	// 注册策略默认值（私有云默认不开放注册，需要管理员审批）
+	if c.Register.Mode == "" {
+		c.Register.Mode = "admin_approve"
+	}
+	if c.Register.InviteExpireHours == 0 {
+		c.Register.InviteExpireHours = 72
+	}
+	if c.Register.VerifyMinutes == 0 {
+		c.Register.VerifyMinutes = 30
+	}
+	if c.Register.MaxPerIPPerHour == 0 {
+		c.Register.MaxPerIPPerHour = 5
+	}
+
+	// token 格式默认值
+	if c.Token.Format == "" {
+		c.Token.Format = "encrypted"
+	}
+	if c.Token.Issuer == "" {
+		c.Token.Issuer = "filesync"
+	}
+
+	// OIDC 默认值
+	if c.OIDC.StateMinutes == 0 {
+		c.OIDC.StateMinutes = 10
+	}
+	for i := range c.OIDC.Providers {
+		p := &c.OIDC.Providers[i]
+		if p.DisplayName == "" {
+			p.DisplayName = p.Name
+		}
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{"openid", "profile", "email"}
+		}
+		p.Issuer = strings.TrimSuffix(p.Issuer, "/")
+	}
+
+	// 审计日志默认值
+	if c.Audit.RetentionDays == 0 {
+		c.Audit.RetentionDays = 180
+	}
+	if c.Audit.BufferSize == 0 {
+		c.Audit.BufferSize = 4096
+	}
+	if c.Audit.BatchSize == 0 {
+		c.Audit.BatchSize = 100
+	}
+	if c.Audit.FlushSeconds == 0 {
+		c.Audit.FlushSeconds = 2
+	}
+
+	// 系统监控默认值
+	if c.Monitor.IntervalSeconds == 0 {
+		c.Monitor.IntervalSeconds = 5
+	}
+	if c.Monitor.WindowMinutes == 0 {
+		c.Monitor.WindowMinutes = 60
+	}
+
+	// 告警默认值
+	if c.Alert.IntervalSeconds == 0 {
+		c.Alert.IntervalSeconds = 60
+	}
+	if c.Alert.RepeatMinutes == 0 {
+		c.Alert.RepeatMinutes = 360
+	}
+	if c.Alert.DiskUsagePercent == 0 {
+		c.Alert.DiskUsagePercent = 90
+	}
+	if c.Alert.DiskCriticalPercent == 0 {
+		c.Alert.DiskCriticalPercent = 95
+	}
+	if c.Alert.FailedUploadsPerHour == 0 {
+		c.Alert.FailedUploadsPerHour = 20
+	}
+	if c.Alert.LoginFailures == 0 {
+		c.Alert.LoginFailures = 10
+	}
+	if c.Alert.LoginWindowMinutes == 0 {
+		c.Alert.LoginWindowMinutes = 15
+	}
+	if c.Alert.DeviceOfflineDays == 0 {
+		c.Alert.DeviceOfflineDays = 30
+	}
+
+	// 磁盘健康默认值
+	if c.DiskHealth.IntervalMinutes == 0 {
+		c.DiskHealth.IntervalMinutes = 60
+	}
+	if c.DiskHealth.RetentionDays == 0 {
+		c.DiskHealth.RetentionDays = 365
+	}
+	if c.DiskHealth.TrendDays == 0 {
+		c.DiskHealth.TrendDays = 30
+	}
+	if c.DiskHealth.TemperatureWarning == 0 {
+		c.DiskHealth.TemperatureWarning = 55
+	}
+	if c.DiskHealth.TemperatureCritical == 0 {
+		c.DiskHealth.TemperatureCritical = 65
+	}
+
+	// 链路追踪默认值
+	if c.Tracing.Endpoint == "" {
+		c.Tracing.Endpoint = "http://127.0.0.1:4318"
+	}
+	if c.Tracing.ServiceName == "" {
+		c.Tracing.ServiceName = "filesync-server"
+	}
+	if c.Tracing.SampleRatio == 0 {
+		c.Tracing.SampleRatio = 1
+	}
+
+	// 邮件默认值
+	if c.Mail.Driver == "" {
+		c.Mail.Driver = "file"
+	}
+	if c.Mail.FileDir == "" {
+		c.Mail.FileDir = "./log/mail"
+	}
+	if c.Mail.Port == 0 {
+		c.Mail.Port = 465
+	}
+
 	// 存储路径默认值
 	if c.File.Storage.BasePath == "" {
 		c.File.Storage.BasePath = "FileSync"
@@ -197,6 +496,17 @@ func (c *Config) setDefaults() {
 	if c.File.Upload.TempMaxAge == 0 {
 		c.File.Upload.TempMaxAge = 86400 // 24小时
 	}
+
+	// 账号导出和注销默认值（依赖存储路径默认值）
+	if c.Account.DeletionGraceDays == 0 {
+		c.Account.DeletionGraceDays = 14
+	}
+	if c.Account.TakeoutDir == "" {
+		c.Account.TakeoutDir = c.GetTempPath("") + "/takeout"
+	}
+	if c.Account.TakeoutExpireHours == 0 {
+		c.Account.TakeoutExpireHours = 72
+	}
 }
 
 // validateConfig 验证配置的有效性
@@ -232,6 +542,113 @@ func validateConfig(cfg *Config) error {
 	if cfg.Token.ValidityDate <= 0 {
 		return fmt.Errorf("token.validityDate 必须大于 0")
 	}
+	validTokenFormats := map[string]bool{"encrypted": true, "jwt-hs256": true, "jwt-eddsa": true, "paseto-v4": true}
+	for _, format := range append([]string{cfg.Token.Format}, cfg.Token.Accept...) {
+		if !validTokenFormats[format] {
+			return fmt.Errorf("无效的 token 格式: %s (可选: encrypted, jwt-hs256, jwt-eddsa, paseto-v4)", format)
+		}
+	}
+	if cfg.Token.AcceptLegacyHS256 {
+		hs256 := cfg.Token.Format == "jwt-hs256"
+		for _, format := range cfg.Token.Accept {
+			hs256 = hs256 || format == "jwt-hs256"
+		}
+		if !hs256 {
+			return fmt.Errorf("token.acceptLegacyHS256 需要在 token.format 或 token.accept 中包含 jwt-hs256")
+		}
+	}
+
+	// 验证邮件配置
+	switch cfg.Mail.Driver {
+	case "file":
+	case "smtp":
+		if cfg.Mail.Host == "" || cfg.Mail.From == "" {
+			return fmt.Errorf("mail.driver 为 smtp 时 mail.host 和 mail.from 不能为空")
+		}
+	default:
+		return fmt.Errorf("无效的邮件发送方式: %s (可选: smtp, file)", cfg.Mail.Driver)
+	}
+
+	// 验证注册策略
+	validRegisterModes := map[string]bool{"closed": true, "invite": true, "email_verify": true, "admin_approve": true, "open": true}
+	if !validRegisterModes[cfg.Register.Mode] {
+		return fmt.Errorf("无效的注册策略: %s (可选: closed, invite, email_verify, admin_approve, open)", cfg.Register.Mode)
+	}
+
+	// 验证 OIDC 身份提供方
+	providerNames := make(map[string]bool)
+	for i, p := range cfg.OIDC.Providers {
+		if p.Name == "" || p.Issuer == "" || p.ClientID == "" || p.RedirectURL == "" {
+			return fmt.Errorf("oidc.providers[%d] 的 name、issuer、clientId、redirectUrl 不能为空", i)
+		}
+		if providerNames[p.Name] {
+			return fmt.Errorf("oidc.providers 中 name 重复: %s", p.Name)
+		}
+		providerNames[p.Name] = true
+	}
+
+	// 验证审计日志配置
+	if cfg.Audit.BufferSize < 0 || cfg.Audit.BatchSize < 0 || cfg.Audit.FlushSeconds < 0 {
+		return fmt.Errorf("audit.bufferSize、batchSize、flushSeconds 不能为负数")
+	}
+
+	// 验证账号导出和注销配置
+	if cfg.Account.DeletionGraceDays < 0 || cfg.Account.TakeoutExpireHours < 0 {
+		return fmt.Errorf("account.deletionGraceDays、takeoutExpireHours 不能为负数")
+	}
+
+	// 验证系统监控配置
+	if cfg.Monitor.WindowMinutes < 0 {
+		return fmt.Errorf("monitor.windowMinutes 不能为负数")
+	}
+
+	// 验证 Prometheus 指标配置
+	if cfg.Metrics.Enabled && cfg.Metrics.Listen != "" {
+		if _, _, err := net.SplitHostPort(cfg.Metrics.Listen); err != nil {
+			return fmt.Errorf("metrics.listen 格式错误（应为 host:port）: %w", err)
+		}
+	}
+
+	// 验证告警配置
+	if cfg.Alert.DiskUsagePercent > 100 || cfg.Alert.DiskCriticalPercent > 100 {
+		return fmt.Errorf("alert.diskUsagePercent、diskCriticalPercent 不能大于 100")
+	}
+	if cfg.Alert.DiskCriticalPercent > 0 && cfg.Alert.DiskCriticalPercent < cfg.Alert.DiskUsagePercent {
+		return fmt.Errorf("alert.diskCriticalPercent 不能小于 diskUsagePercent")
+	}
+	if cfg.Alert.LoginWindowMinutes < 0 {
+		return fmt.Errorf("alert.loginWindowMinutes 不能为负数")
+	}
+	for _, w := range cfg.Alert.Channels.Webhooks {
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("alert.channels.webhooks 地址无效: %s", w.URL)
+		}
+	}
+	for _, addr := range cfg.Alert.Channels.Emails {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("alert.channels.emails 地址无效: %s", addr)
+		}
+	}
+
+	// 验证磁盘健康配置
+	if cfg.DiskHealth.RetentionDays < 0 || cfg.DiskHealth.TrendDays < 0 {
+		return fmt.Errorf("diskHealth.retentionDays、trendDays 不能为负数")
+	}
+	if cfg.DiskHealth.TemperatureCritical < cfg.DiskHealth.TemperatureWarning {
+		return fmt.Errorf("diskHealth.temperatureCritical 不能小于 temperatureWarning")
+	}
+
+	// 验证链路追踪配置
+	if cfg.Tracing.Enabled {
+		u, err := url.Parse(cfg.Tracing.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tracing.endpoint 无效（应为 http(s)://host:port）: %s", cfg.Tracing.Endpoint)
+		}
+		if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing.sampleRatio 应在 0~1 之间")
+		}
+	}
 
 	// 验证文件存储配置
 	if err := cfg.validateFileConfig(); err != nil {
@@ -5,6 +5,7 @@ import (
 	"crypto/cipher"
 	"encoding/base64"
 	"encoding/json"
+	"strings"
 )
 
 // Decrypt 解密数据（输入Base64编码的密文）
@@ -13,14 +14,25 @@ func (e *Encryptor) Decrypt(ciphertext string) (string, error) {
 		return "", ErrKeyNotLoaded
 	}
 
+	// 按密文前缀选择密钥，没有前缀的是 legacy 密钥加密的
+	keyID, encoded := LegacyKeyID, ciphertext
+	var aad []byte
+	if id, rest, ok := strings.Cut(ciphertext, "."); ok {
+		keyID, encoded, aad = id, rest, []byte(id)
+	}
+	key, ok := e.keys[keyID]
+	if !ok {
+		return "", ErrUnknownKeyID
+	}
+
 	// Base64解码
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	data, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
 		return "", ErrInvalidCiphertext
 	}
 
 	// 创建AES cipher
-	block, err := aes.NewCipher(e.key)
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
 	}
@@ -42,7 +54,7 @@ func (e *Encryptor) Decrypt(ciphertext string) (string, error) {
 	ciphertextBytes := data[nonceSize:] //  修正：不要重复转换
 
 	// 解密
-	plaintext, err := gcm.Open(nil, nonce, ciphertextBytes, nil)
+	plaintext, err := gcm.Open(nil, nonce, ciphertextBytes, aad)
 	if err != nil {
 		return "", ErrInvalidCiphertext
 	}
@@ -3,11 +3,16 @@ package encryption
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"os"
+	"strings"
 )
 
 var (
@@ -17,24 +22,101 @@ var (
 )
 
 // Encryptor 加密器
+// 使用密钥环时密文格式为 "<密钥ID>.<Base64>"，密钥ID同时作为 GCM 附加数据；
+// legacy 密钥加密的密文不带前缀，与轮换前签发的 token 相同
 type Encryptor struct {
-	key []byte
+	key   []byte            // 当前用于加密的密钥
+	keyID string            // 当前密钥ID
+	keys  map[string][]byte // 可用于解密的全部密钥
 }
 
 // NewEncryptor 创建加密器
 func NewEncryptor(key string) (*Encryptor, error) {
-	// Base64解码密钥
+	keyBytes, err := decodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Encryptor{
+		key:   keyBytes,
+		keyID: LegacyKeyID,
+		keys:  map[string][]byte{LegacyKeyID: keyBytes},
+	}, nil
+}
+
+// NewKeyringEncryptor 按密钥环创建加密器，legacyKey 为 key.yaml 中的密钥
+func NewKeyringEncryptor(legacyKey string, ring *Keyring) (*Encryptor, error) {
+	active, err := ring.Active()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Encryptor{keyID: active.ID, keys: make(map[string][]byte, len(ring.Keys))}
+	for _, entry := range ring.Keys {
+		if entry.Status == KeyStatusRetired {
+			continue
+		}
+		material := entry.Key
+		if entry.ID == LegacyKeyID {
+			material = legacyKey
+		}
+		keyBytes, err := decodeKey(material)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", entry.ID, err)
+		}
+		e.keys[entry.ID] = keyBytes
+	}
+	e.key = e.keys[active.ID]
+	return e, nil
+}
+
+// LoadEncryptor 密钥环文件存在时按密钥环创建加密器，否则只使用 legacyKey
+func LoadEncryptor(legacyKey, keyringPath string) (*Encryptor, error) {
+	ring, err := LoadKeyring(keyringPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewEncryptor(legacyKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyringEncryptor(legacyKey, ring)
+}
+
+// ActiveKeyID 当前用于加密的密钥ID
+func (e *Encryptor) ActiveKeyID() string {
+	return e.keyID
+}
+
+// DeriveKeys 从每把可用密钥派生用途为 info 的子密钥（HMAC-SHA256），
+// 返回当前密钥ID和 密钥ID -> 子密钥；轮换加密密钥时派生出的签名密钥随之轮换
+func (e *Encryptor) DeriveKeys(info string) (string, map[string][]byte) {
+	derived := make(map[string][]byte, len(e.keys))
+	for id, key := range e.keys {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(info))
+		derived[id] = mac.Sum(nil)
+	}
+	return e.keyID, derived
+}
+
+// KeyID 密文使用的密钥ID（不校验密文是否有效）
+func KeyID(ciphertext string) string {
+	if id, _, ok := strings.Cut(ciphertext, "."); ok {
+		return id
+	}
+	return LegacyKeyID
+}
+
+// decodeKey Base64解码并验证密钥长度（AES-256需要32字节）
+func decodeKey(key string) ([]byte, error) {
 	keyBytes, err := base64.StdEncoding.DecodeString(key)
 	if err != nil {
 		return nil, ErrInvalidKey
 	}
-
-	// 验证密钥长度（AES-256需要32字节）
 	if len(keyBytes) != 32 {
 		return nil, errors.New("key must be 32 bytes for AES-256")
 	}
-
-	return &Encryptor{key: keyBytes}, nil
+	return keyBytes, nil
 }
 
 // Encrypt 加密数据（返回Base64编码的密文）
@@ -61,11 +143,17 @@ func (e *Encryptor) Encrypt(plaintext string) (string, error) {
 		return "", err
 	}
 
-	// 加密（nonce会自动添加到密文前面）
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	// legacy 密钥保持原格式
+	if e.keyID == LegacyKeyID {
+		ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+		return base64.StdEncoding.EncodeToString(ciphertext), nil
+	}
+
+	// 加密（nonce会自动添加到密文前面，密钥ID作为附加数据防止替换前缀）
+	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(e.keyID))
 
-	// Base64编码返回
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	// Base64编码返回，带上密钥ID
+	return e.keyID + "." + base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
 // EncryptJSON 加密JSON数据
@@ -0,0 +1,175 @@
+package encryption
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LegacyKeyID key.yaml 中原有的密钥；用它加密的密文不带密钥ID（兼容轮换前签发的 token）
+const LegacyKeyID = "legacy"
+
+// 密钥状态
+const (
+	KeyStatusActive   = "active"   // 当前用于加密（只能有一把）
+	KeyStatusRetained = "retained" // 只用于解密（刚生成待启用，或已被新密钥替换）
+	KeyStatusRetired  = "retired"  // 已停用，用它加密的密文全部失效
+)
+
+var (
+	ErrKeyNotFound    = errors.New("key not found in keyring")
+	ErrInvalidKeyID   = errors.New("key id must be 1-32 letters, digits, '_' or '-'")
+	ErrKeyExists      = errors.New("key id already exists")
+	ErrRetireActive   = errors.New("cannot retire the active key, activate another key first")
+	ErrKeyRetired     = errors.New("key has been retired")
+	ErrNoActiveKey    = errors.New("no active key in keyring")
+	ErrMultipleActive = errors.New("more than one active key in keyring")
+	ErrUnknownKeyID   = errors.New("ciphertext encrypted with unknown or retired key")
+)
+
+// keyIDPattern 密钥ID会写在密文前缀中，不能包含 "."
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// KeyEntry 密钥环中的一把密钥
+type KeyEntry struct {
+	ID            string     `yaml:"id"`
+	Key           string     `yaml:"key,omitempty"` // Base64 编码的 AES-256 密钥（legacy 为空，使用 key.yaml 中的密钥）
+	Status        string     `yaml:"status"`
+	CreatedAt     time.Time  `yaml:"createdAt,omitempty"`
+	ActivatedAt   *time.Time `yaml:"activatedAt,omitempty"`   // 最近一次启用时间
+	DeactivatedAt *time.Time `yaml:"deactivatedAt,omitempty"` // 被新密钥替换的时间（此后不再用于加密）
+	RetiredAt     *time.Time `yaml:"retiredAt,omitempty"`
+}
+
+// Keyring 多版本密钥：用启用的密钥加密，用任一未停用的密钥解密
+type Keyring struct {
+	Keys []KeyEntry `yaml:"keys"`
+}
+
+// DefaultKeyring 没有密钥环文件时的默认状态：只有 key.yaml 中的密钥
+func DefaultKeyring() *Keyring {
+	return &Keyring{Keys: []KeyEntry{{ID: LegacyKeyID, Status: KeyStatusActive}}}
+}
+
+// LoadKeyring 从 YAML 文件读取密钥环，文件不存在时返回的错误满足 errors.Is(err, os.ErrNotExist)
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ring Keyring
+	if err := yaml.Unmarshal(data, &ring); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring file: %w", err)
+	}
+	if _, err := ring.Active(); err != nil {
+		return nil, err
+	}
+	return &ring, nil
+}
+
+// Save 写入密钥环文件（仅所有者可读写）
+func (r *Keyring) Save(path string) error {
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Find 按ID查找密钥
+func (r *Keyring) Find(id string) (*KeyEntry, error) {
+	for i := range r.Keys {
+		if r.Keys[i].ID == id {
+			return &r.Keys[i], nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// Active 当前启用的密钥
+func (r *Keyring) Active() (*KeyEntry, error) {
+	var active *KeyEntry
+	for i := range r.Keys {
+		if r.Keys[i].Status != KeyStatusActive {
+			continue
+		}
+		if active != nil {
+			return nil, ErrMultipleActive
+		}
+		active = &r.Keys[i]
+	}
+	if active == nil {
+		return nil, ErrNoActiveKey
+	}
+	return active, nil
+}
+
+// Generate 生成新密钥（保留状态，启用前可以先分发到所有实例）
+func (r *Keyring) Generate(id string) (*KeyEntry, error) {
+	if !keyIDPattern.MatchString(id) || id == LegacyKeyID {
+		return nil, ErrInvalidKeyID
+	}
+	if _, err := r.Find(id); err == nil {
+		return nil, ErrKeyExists
+	}
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	r.Keys = append(r.Keys, KeyEntry{
+		ID:        id,
+		Key:       key,
+		Status:    KeyStatusRetained,
+		CreatedAt: time.Now(),
+	})
+	return &r.Keys[len(r.Keys)-1], nil
+}
+
+// Activate 启用密钥：之后的加密都使用它，原来的密钥转为保留（仍可解密）
+func (r *Keyring) Activate(id string) error {
+	entry, err := r.Find(id)
+	if err != nil {
+		return err
+	}
+	if entry.Status == KeyStatusRetired {
+		return ErrKeyRetired
+	}
+	if entry.Status == KeyStatusActive {
+		return nil
+	}
+
+	now := time.Now()
+	for i := range r.Keys {
+		if r.Keys[i].Status == KeyStatusActive {
+			r.Keys[i].Status = KeyStatusRetained
+			r.Keys[i].DeactivatedAt = &now
+		}
+	}
+	entry.Status = KeyStatusActive
+	entry.ActivatedAt = &now
+	entry.DeactivatedAt = nil
+	return nil
+}
+
+// Retire 停用密钥：不再用于解密，密钥材料从文件中删除
+func (r *Keyring) Retire(id string) error {
+	entry, err := r.Find(id)
+	if err != nil {
+		return err
+	}
+	switch entry.Status {
+	case KeyStatusActive:
+		return ErrRetireActive
+	case KeyStatusRetired:
+		return nil
+	}
+	now := time.Now()
+	entry.Status = KeyStatusRetired
+	entry.RetiredAt = &now
+	entry.Key = ""
+	return nil
+}
@@ -1,16 +1,34 @@
 package gateway
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
 	"github.com/sunyuanling/server/config"
+	accountModule "github.com/sunyuanling/server/internal/handler/account"
+	adminModule "github.com/sunyuanling/server/internal/handler/admin"
+	alertModule "github.com/sunyuanling/server/internal/handler/alert"
+	apiKeyModule "github.com/sunyuanling/server/internal/handler/apikey"
+	auditModule "github.com/sunyuanling/server/internal/handler/audit"
 	"github.com/sunyuanling/server/internal/handler/auth"
+	"github.com/sunyuanling/server/internal/handler/device"
 	"github.com/sunyuanling/server/internal/handler/files"
+	"github.com/sunyuanling/server/internal/handler/group"
+	monitorModule "github.com/sunyuanling/server/internal/handler/monitor"
+	rbacModule "github.com/sunyuanling/server/internal/handler/rbac"
+	"github.com/sunyuanling/server/internal/handler/registration"
+	"github.com/sunyuanling/server/internal/handler/share"
 	"github.com/sunyuanling/server/internal/handler/test"
 	"github.com/sunyuanling/server/internal/handler/user"
+	"github.com/sunyuanling/server/internal/instrument"
+	"github.com/sunyuanling/server/internal/rbac"
 	"github.com/sunyuanling/server/middleware"
 	"github.com/sunyuanling/server/pkg/logger"
 	"github.com/sunyuanling/server/pkg/response"
@@ -26,17 +44,26 @@ type Gateway struct {
 }
 
 func NewGateway(db *gorm.DB, redis *redis.Client, cfg *config.Config) *Gateway {
-	router := gin.Default()
+	// 不使用 gin.Default()：访问日志和 panic 恢复都通过 zap 记录，不输出到标准输出
+	router := gin.New()
 	err := router.SetTrustedProxies([]string{"127.0.0.1", "192.168.0.0/16", "::1"})
 	if err != nil {
 		logger.Error("SetTrustedProxies失败", zap.Error(err))
 		return nil
 	}
+	if cfg.Tracing.Enabled {
+		router.Use(middleware.Tracing())
+	}
+	router.Use(middleware.RequestLog())
+	router.Use(middleware.Recovery())
+	if cfg.Metrics.Enabled {
+		router.Use(middleware.Metrics())
+	}
 	router.Use(middleware.CORS())
-	router.Use(middleware.AuthToken())
+	router.Use(middleware.AuthToken(db))
 
 	// 初始化WebSocket
-	wsHandler := websocket.InitWebSocket(db)
+	wsHandler := websocket.InitWebSocket(db, redis)
 
 	return &Gateway{
 		router:    router,
@@ -62,6 +89,14 @@ func (g *Gateway) SetupRoutes() {
 		})
 	})
 
+	// ========== Prometheus 指标（未配置单独监听地址时） ==========
+	if g.cfg.Metrics.Enabled && g.cfg.Metrics.Listen == "" {
+		g.router.GET("/metrics", instrument.GinHandler(g.cfg.Metrics.Token))
+	}
+
+	// ========== token验签公钥 ==========
+	g.router.GET("/.well-known/jwks.json", auth.NewJWKSHandler(g.db, g.redis).HandleGET)
+
 	// ========== API网关 ==========
 	api := g.router.Group("/api")
 	{
@@ -71,14 +106,60 @@ func (g *Gateway) SetupRoutes() {
 
 		// 注册auth模块路由
 		authGroup := api.Group("/auth")
-		auth.NewRouter().RegisterRoutes(authGroup, g.db, g.redis)
+		auth.NewRouter(g.cfg).RegisterRoutes(authGroup, g.db, g.redis)
 
 		// 注册user模块
 		userGroup := api.Group("/user")
 		user.NewUserRouter(g.cfg).RegisterRoutes(userGroup, g.db, g.redis)
 
+		// 注册device模块
+		deviceGroup := api.Group("/device")
+		device.NewRouter().RegisterRoutes(deviceGroup, g.db, g.redis)
+
+		// 注册group模块（共享空间分组，创建和管理需要分组管理权限）
+		groupGroup := api.Group("/group")
+		group.NewRouter(g.cfg).RegisterRoutes(groupGroup, g.db, g.redis)
+
+		// 注册rbac模块（角色权限管理）
+		rbacGroup := api.Group("/rbac")
+		rbacModule.NewRouter(g.cfg).RegisterRoutes(rbacGroup, g.db, g.redis)
+
+		// 注册registration模块（邀请码、注册审批，需要用户管理权限）
+		registrationGroup := api.Group("/registration", middleware.RequirePermission(g.db, rbac.PermUserManage))
+		registration.NewRouter(g.cfg).RegisterRoutes(registrationGroup, g.db, g.redis)
+
+		// 注册apikey模块（个人API Key）
+		apiKeyGroup := api.Group("/apikey")
+		apiKeyModule.NewRouter(g.cfg).RegisterRoutes(apiKeyGroup, g.db, g.redis)
+
+		// 注册audit模块（我的账号活动；查询和导出需要审计权限）
+		auditGroup := api.Group("/audit")
+		auditModule.NewRouter().RegisterRoutes(auditGroup, g.db, g.redis)
+
+		// 注册account模块（数据导出、申请/撤销注销）
+		accountGroup := api.Group("/account")
+		accountModule.NewRouter(g.cfg).RegisterRoutes(accountGroup, g.db, g.redis)
+
+		// 注册admin模块（管理后台：用户管理，需要管理员角色）
+		adminGroup := api.Group("/admin", middleware.RequireRole(g.db, rbac.RoleAdmin))
+		adminModule.NewRouter(g.cfg).RegisterRoutes(adminGroup, g.db, g.redis)
+
+		// 注册monitor模块（系统监控，需要查看系统监控权限）
+		monitorGroup := api.Group("/monitor", middleware.RequirePermission(g.db, rbac.PermSystemMonitor))
+		monitorModule.NewRouter().RegisterRoutes(monitorGroup, g.db, g.redis)
+
+		// 注册alert模块（告警记录和测试通知，需要查看系统监控权限）
+		alertGroup := api.Group("/alert", middleware.RequirePermission(g.db, rbac.PermSystemMonitor))
+		alertModule.NewRouter().RegisterRoutes(alertGroup, g.db, g.redis)
+
+		// 注册share模块（文件夹共享，按文件夹校验权限）
+		shareGroup := api.Group("/share")
+		share.NewRouter(g.cfg).RegisterRoutes(shareGroup, g.db, g.redis)
+
 		// 注册WebSocket路由
-		g.wsHandler.RegisterRoutes(api)
+		g.wsHandler.RegisterRoutes(api, func(perms ...string) gin.HandlerFunc {
+			return middleware.RequirePermission(g.db, perms...)
+		})
 
 		// 注册files模块（传递配置）
 		filesGroup := api.Group("/files")
@@ -105,7 +186,11 @@ func (g *Gateway) GetRouter() *gin.Engine {
 	return g.router
 }
 
-func (g *Gateway) Run(addr string) error {
+// shutdownTimeout 关闭时等待进行中请求完成的最长时间
+const shutdownTimeout = 15 * time.Second
+
+// Run 启动 HTTP 服务，ctx 取消后停止接收新请求并等待进行中的请求完成
+func (g *Gateway) Run(ctx context.Context, addr string) error {
 	// 启动时输出配置信息
 	logger.Info("WebSocket服务已启用",
 		zap.String("endpoint", "/api/ws/connect"),
@@ -114,7 +199,35 @@ func (g *Gateway) Run(addr string) error {
 		zap.String("mode", g.cfg.File.Mode),
 		zap.Strings("allowed_paths", g.cfg.GetAllowedPaths()),
 	)
-	return g.router.Run(addr)
+
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: g.router,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("收到退出信号，停止接收新请求")
+	// WebSocket 连接已被接管，http.Server 不会等待它们，先主动关闭
+	g.Shutdown()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 // Shutdown 优雅关闭
@@ -0,0 +1,109 @@
+// Package access 文件路径访问判断：rbac 权限（全局或目录范围）加上文件夹共享，API Key 访问时再受 Key 范围限制
+package access
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/apikey"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+)
+
+// shareLevelFor 各文件权限需要的共享等级
+var shareLevelFor = map[string]int{
+	rbac.PermFilesRead:   model.SharePermissionLevel(model.SharePermRead),
+	rbac.PermFilesWrite:  model.SharePermissionLevel(model.SharePermWrite),
+	rbac.PermFilesDelete: model.SharePermissionLevel(model.SharePermWrite),
+}
+
+// CanAccess 用户是否可以对路径执行 perm（files:read/files:write/files:delete）
+func CanAccess(c *gin.Context, db *gorm.DB, userID uint, perm, path string) (bool, error) {
+	// 使用 API Key 时，先受 Key 的权限范围和文件夹限制
+	if key := apikey.FromContext(c); key != nil && !apikey.Allows(key, perm, path) {
+		return false, nil
+	}
+
+	grants, err := rbac.FromContext(c, db, userID)
+	if err != nil {
+		return false, err
+	}
+	return canAccess(db, grants, userID, perm, path)
+}
+
+// UserCanAccess 不在请求中（如 WebSocket 推送）判断用户是否可以对路径执行 perm，不受 API Key 限制
+func UserCanAccess(db *gorm.DB, userID uint, perm, path string) (bool, error) {
+	grants, err := rbac.Load(db, userID)
+	if err != nil {
+		return false, err
+	}
+	return canAccess(db, grants, userID, perm, path)
+}
+
+func canAccess(db *gorm.DB, grants *rbac.Grants, userID uint, perm, path string) (bool, error) {
+	if grants.Can(perm, path) {
+		return true, nil
+	}
+
+	required, ok := shareLevelFor[perm]
+	if !ok {
+		return false, nil
+	}
+	level, err := model.ShareLevelForPath(db, userID, path)
+	if err != nil {
+		return false, err
+	}
+	return level >= required, nil
+}
+
+// CanShare 用户是否可以共享该文件夹：拥有写权限（rbac），或通过共享获得了 manage 权限
+func CanShare(c *gin.Context, db *gorm.DB, userID uint, path string) (bool, error) {
+	// API Key 不能共享文件夹
+	if apikey.FromContext(c) != nil {
+		return false, nil
+	}
+
+	grants, err := rbac.FromContext(c, db, userID)
+	if err != nil {
+		return false, err
+	}
+	return canShare(db, grants, userID, path)
+}
+
+// UserCanShare 不在请求中判断用户是否可以共享该文件夹（如校验分组创建者）
+func UserCanShare(db *gorm.DB, userID uint, path string) (bool, error) {
+	grants, err := rbac.Load(db, userID)
+	if err != nil {
+		return false, err
+	}
+	return canShare(db, grants, userID, path)
+}
+
+func canShare(db *gorm.DB, grants *rbac.Grants, userID uint, path string) (bool, error) {
+	if grants.Can(rbac.PermFilesWrite, path) {
+		return true, nil
+	}
+
+	level, err := model.ShareLevelForPath(db, userID, path)
+	if err != nil {
+		return false, err
+	}
+	return level >= model.SharePermissionLevel(model.SharePermManage), nil
+}
+
+// InAllowedPaths 路径是否为允许存储路径下的绝对路径（共享文件夹、分组文件夹、API Key 和角色的目录范围都要满足）
+func InAllowedPaths(cfg *config.Config, path string) bool {
+	if !filepath.IsAbs(path) || strings.Contains(path, "..") {
+		return false
+	}
+	for _, allowed := range cfg.GetAllowedPaths() {
+		if model.IsPathWithin(path, allowed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package access
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/sunyuanling/server/config"
+)
+
+func TestInAllowedPaths(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.File.Mode = "linux"
+	cfg.File.LinuxPath = []string{"/data", "/mnt/disk1/"}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/data", true},
+		{"/data/team/docs", true},
+		{"/mnt/disk1/photos", true},
+		{"/DATA/team", runtime.GOOS == "windows"}, // 只有 Windows 下不区分大小写
+
+		{"/database", false}, // 前缀相同但不在目录下
+		{"/etc/passwd", false},
+		{"/data/../etc", false},
+		{"data/team", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := InAllowedPaths(cfg, tt.path); got != tt.want {
+			t.Errorf("InAllowedPaths(%q) = %v, 期望 %v", tt.path, got, tt.want)
+		}
+	}
+}
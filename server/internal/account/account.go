@@ -0,0 +1,415 @@
+// Package account 管理员对用户账号的操作：禁用、启用、强制重置密码、删除和用量统计
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/passwordreset"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/internal/session"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/mailer"
+	"github.com/sunyuanling/server/pkg/password"
+	token "github.com/sunyuanling/server/pkg/tokn"
+	"github.com/sunyuanling/server/websocket"
+)
+
+var (
+	ErrTransferTarget   = errors.New("接收数据的用户不存在或未启用")
+	ErrConflictingFiles = errors.New("转移数据时不能同时删除文件")
+)
+
+// Disable 禁用账号：注销全部会话并断开 WebSocket 连接，返回注销的会话数
+// 不允许禁用最后一个全局管理员
+func Disable(db *gorm.DB, userID uint) (int, error) {
+	last, err := rbac.IsLastAdmin(db, userID)
+	if err != nil {
+		return 0, err
+	}
+	if last {
+		return 0, rbac.ErrLastAdmin
+	}
+
+	if err := setStatus(db, userID, model.StatusInactive); err != nil {
+		return 0, err
+	}
+	return signOut(db, userID)
+}
+
+// Enable 启用账号（待验证、待审批的账号同样直接启用；注销冷静期内的账号同时撤销注销申请）
+func Enable(db *gorm.DB, userID uint) error {
+	pending, err := PendingDeletion(db, userID)
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		if err := CancelDeletion(db, userID); err != nil {
+			return err
+		}
+	}
+	return setStatus(db, userID, model.StatusActive)
+}
+
+// ForceReset 强制重置密码：原密码立即失效，注销全部会话；用户有邮箱时发送找回密码验证码
+// 返回是否已发送验证码
+func ForceReset(ctx context.Context, db *gorm.DB, rdb *redis.Client, m mailer.Mailer, userID uint, ttl time.Duration) (bool, error) {
+	var user model.User
+	if err := db.Select("id", "email", "status").First(&user, userID).Error; err != nil {
+		return false, err
+	}
+
+	// 换成随机密码的哈希，原密码不能再登录，只能通过验证码重新设置
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return false, err
+	}
+	hashed, err := password.HashPassword(hex.EncodeToString(buf))
+	if err != nil {
+		return false, err
+	}
+	if err := db.Model(&model.User{}).Where("id = ?", userID).Update("password", hashed).Error; err != nil {
+		return false, err
+	}
+	if _, err := signOut(db, userID); err != nil {
+		return false, err
+	}
+
+	if user.Email == "" || user.Status != model.StatusActive {
+		return false, nil
+	}
+	err = passwordreset.Request(ctx, db, rdb, m, user.Email, ttl)
+	if errors.Is(err, passwordreset.ErrTooFrequent) {
+		// 一分钟内已经发过验证码，用户仍可使用那一封
+		return true, nil
+	}
+	return err == nil, err
+}
+
+// DeleteOptions 删除账号时的数据处理方式
+type DeleteOptions struct {
+	TransferTo   uint     // 非 0 时把共享、分组、文件记录转给该用户
+	DeleteFiles  bool     // 删除该用户上传的文件（仅限允许存储路径内，不含他人共享的文件夹中的文件）
+	AllowedPaths []string // 允许删除文件的存储路径
+}
+
+// DeleteResult 删除结果
+type DeleteResult struct {
+	SharesTransferred int64 `json:"shares_transferred"`
+	GroupsTransferred int64 `json:"groups_transferred"`
+	FilesTransferred  int64 `json:"files_transferred"`
+	FilesHandedOver   int64 `json:"files_handed_over"` // 他人共享的文件夹中的文件，记录转给文件夹所有者，文件保留
+	FilesRemoved      int   `json:"files_removed"`
+	FilesFailed       int   `json:"files_failed"`
+}
+
+// Delete 删除账号。会话、设备、API Key、角色绑定等随用户级联删除；审计日志保留
+// 不转移数据时，上传到他人共享的文件夹中的文件不删除，其记录转给文件夹所有者
+// 不允许删除最后一个全局管理员
+func Delete(db *gorm.DB, userID uint, opts DeleteOptions) (*DeleteResult, error) {
+	if opts.TransferTo > 0 && opts.DeleteFiles {
+		return nil, ErrConflictingFiles
+	}
+	if opts.TransferTo == userID {
+		return nil, ErrTransferTarget
+	}
+	last, err := rbac.IsLastAdmin(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if last {
+		return nil, rbac.ErrLastAdmin
+	}
+	if opts.TransferTo > 0 {
+		var target model.User
+		err := db.Select("id", "status").First(&target, opts.TransferTo).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && target.Status != model.StatusActive) {
+			return nil, ErrTransferTarget
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 会话随用户级联删除，先注销让访问 token 立即失效并断开连接
+	if _, err := signOut(db, userID); err != nil {
+		return nil, err
+	}
+
+	result := &DeleteResult{}
+	var paths []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		if opts.TransferTo > 0 {
+			if err := transfer(tx, userID, opts.TransferTo, result); err != nil {
+				return err
+			}
+		} else {
+			uploaded, err := uploadedPaths(tx, userID)
+			if err != nil {
+				return err
+			}
+			own, foreign, err := splitByFolderOwner(tx, userID, uploaded)
+			if err != nil {
+				return err
+			}
+			if err := handOver(tx, userID, foreign, result); err != nil {
+				return err
+			}
+			if opts.DeleteFiles {
+				paths = own
+			}
+			// upload_history 没有外键，需要单独删除
+			if err := tx.Where("user_id = ?", userID).Delete(&model.UploadHistory{}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&model.User{}, userID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rbac.Invalidate(userID)
+
+	// 文件在事务提交后删除，删除失败只记录日志
+	for _, p := range paths {
+		if !withinAny(p, opts.AllowedPaths) {
+			result.FilesFailed++
+			logger.Warn("上传记录中的文件不在允许的存储路径内，跳过删除", zap.Uint("user_id", userID), zap.String("path", p))
+			continue
+		}
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			result.FilesFailed++
+			logger.Warn("删除用户文件失败", zap.Uint("user_id", userID), zap.String("path", p), zap.Error(err))
+			continue
+		}
+		result.FilesRemoved++
+	}
+	return result, nil
+}
+
+// transfer 把用户的共享、分组和文件记录转给另一个用户
+func transfer(tx *gorm.DB, from, to uint, result *DeleteResult) error {
+	res := tx.Model(&model.FolderShare{}).Where("owner_id = ?", from).Update("owner_id", to)
+	if res.Error != nil {
+		return res.Error
+	}
+	result.SharesTransferred = res.RowsAffected
+
+	var groupIDs []uint
+	if err := tx.Model(&model.WsGroup{}).Where("owner_id = ?", from).Pluck("id", &groupIDs).Error; err != nil {
+		return err
+	}
+	if len(groupIDs) > 0 {
+		if err := tx.Model(&model.WsGroup{}).Where("id IN ?", groupIDs).Update("owner_id", to).Error; err != nil {
+			return err
+		}
+		members := make([]model.WsGroupMember, 0, len(groupIDs))
+		for _, id := range groupIDs {
+			members = append(members, model.WsGroupMember{GroupID: id, UserID: to, Role: model.GroupRoleOwner, Status: model.GroupMemberActive})
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "group_id"}, {Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"role": model.GroupRoleOwner, "status": model.GroupMemberActive}),
+		}).Create(&members).Error
+		if err != nil {
+			return err
+		}
+	}
+	result.GroupsTransferred = int64(len(groupIDs))
+
+	res = tx.Model(&model.File{}).Where("user_id = ?", from).Update("user_id", to)
+	if res.Error != nil {
+		return res.Error
+	}
+	result.FilesTransferred = res.RowsAffected
+
+	return tx.Model(&model.UploadHistory{}).Where("user_id = ?", from).Update("user_id", to).Error
+}
+
+// Usage 用户的用量统计
+type Usage struct {
+	Uploads        int64 `json:"uploads"`         // 完成的上传次数
+	UploadBytes    int64 `json:"upload_bytes"`    // 上传总字节数
+	Downloads      int64 `json:"downloads"`       // 下载次数
+	DownloadBytes  int64 `json:"download_bytes"`  // 下载总字节数
+	Devices        int64 `json:"devices"`         // 设备数
+	ActiveSessions int64 `json:"active_sessions"` // 有效会话数
+	APIKeys        int64 `json:"api_keys"`        // 可用的 API Key 数
+	Shares         int64 `json:"shares"`          // 共享出去的文件夹数
+	OnlineConns    int   `json:"online_conns"`    // 当前 WebSocket 连接数
+}
+
+// GetUsage 统计用户的上传、下载、设备、会话等用量
+func GetUsage(db *gorm.DB, userID uint) (*Usage, error) {
+	u := &Usage{}
+	var sums struct {
+		Count int64
+		Bytes int64
+	}
+
+	if err := db.Model(&model.UploadHistory{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(file_size), 0) AS bytes").
+		Where("user_id = ? AND upload_status = ?", userID, model.UploadStatusCompleted).
+		Scan(&sums).Error; err != nil {
+		return nil, err
+	}
+	u.Uploads, u.UploadBytes = sums.Count, sums.Bytes
+
+	if err := db.Model(&model.DownloadHistory{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(file_size), 0) AS bytes").
+		Where("user_id = ?", userID).
+		Scan(&sums).Error; err != nil {
+		return nil, err
+	}
+	u.Downloads, u.DownloadBytes = sums.Count, sums.Bytes
+
+	now := time.Now()
+	counts := []struct {
+		dst   *int64
+		query *gorm.DB
+	}{
+		{&u.Devices, db.Model(&model.Device{}).Where("user_id = ?", userID)},
+		{&u.ActiveSessions, db.Model(&model.UserSession{}).Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now)},
+		{&u.APIKeys, db.Model(&model.APIKey{}).Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, now)},
+		{&u.Shares, db.Model(&model.FolderShare{}).Where("owner_id = ?", userID)},
+	}
+	for _, c := range counts {
+		if err := c.query.Count(c.dst).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	u.OnlineConns = len(websocket.GetHub().GetUserConnections(userID))
+	return u, nil
+}
+
+// setStatus 修改账号状态，用户不存在时返回 gorm.ErrRecordNotFound
+func setStatus(db *gorm.DB, userID uint, status int16) error {
+	res := db.Model(&model.User{}).Where("id = ?", userID).Update("status", status)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	rbac.Invalidate(userID)
+	return nil
+}
+
+// signOut 注销用户全部会话，吊销各设备签发的 token（含不属于会话的旧设备 token），并断开 WebSocket 连接
+func signOut(db *gorm.DB, userID uint) (int, error) {
+	n, err := session.RevokeAll(db, userID, "")
+	if err != nil {
+		return n, err
+	}
+
+	var deviceIDs []string
+	if err := db.Model(&model.Device{}).Where("user_id = ?", userID).Pluck("device_id", &deviceIDs).Error; err != nil {
+		return n, err
+	}
+	for _, deviceID := range deviceIDs {
+		if err := token.GetGlobalTokenManager().RevokeDevice(userID, deviceID); err != nil {
+			return n, err
+		}
+	}
+
+	websocket.DisconnectUser(userID)
+	return n, nil
+}
+
+// uploadedPaths 用户已完成上传的文件路径
+func uploadedPaths(tx *gorm.DB, userID uint) ([]string, error) {
+	var paths []string
+	err := tx.Model(&model.UploadHistory{}).
+		Where("user_id = ? AND upload_status = ? AND storage_path <> ''", userID, model.UploadStatusCompleted).
+		Pluck("storage_path", &paths).Error
+	return paths, err
+}
+
+// splitByFolderOwner 把文件分为用户自己的，和位于他人共享出来的文件夹（文件夹共享、分组共享文件夹）中的，
+// 后者按文件夹所有者分组。注销、删除账号时不能删除或移走这些文件
+func splitByFolderOwner(tx *gorm.DB, userID uint, paths []string) ([]string, map[uint][]string, error) {
+	if len(paths) == 0 {
+		return nil, nil, nil
+	}
+
+	type folder struct {
+		path  string
+		owner uint
+	}
+	var folders []folder
+
+	var shares []model.FolderShare
+	if err := tx.Where("owner_id <> ?", userID).Find(&shares).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, s := range shares {
+		folders = append(folders, folder{s.FolderPath, s.OwnerID})
+	}
+	var groups []model.WsGroup
+	if err := tx.Where("owner_id <> ? AND folder_path IS NOT NULL AND folder_path <> ''", userID).Find(&groups).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, g := range groups {
+		folders = append(folders, folder{g.FolderPath, g.OwnerID})
+	}
+
+	var own []string
+	foreign := make(map[uint][]string)
+	for _, p := range paths {
+		var owner uint
+		for _, f := range folders {
+			if model.IsPathWithin(p, f.path) {
+				owner = f.owner
+				break
+			}
+		}
+		if owner == 0 {
+			own = append(own, p)
+		} else {
+			foreign[owner] = append(foreign[owner], p)
+		}
+	}
+	return own, foreign, nil
+}
+
+// handOver 把位于他人共享文件夹中的文件记录（上传记录、文件记录）转给文件夹所有者
+func handOver(tx *gorm.DB, userID uint, foreign map[uint][]string, result *DeleteResult) error {
+	for owner, paths := range foreign {
+		res := tx.Model(&model.UploadHistory{}).Where("user_id = ? AND storage_path IN ?", userID, paths).Update("user_id", owner)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.FilesHandedOver += res.RowsAffected
+		if err := tx.Model(&model.File{}).Where("user_id = ? AND file_path IN ?", userID, paths).Update("user_id", owner).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func withinAny(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if model.IsPathWithin(path, dir) {
+			return true
+		}
+	}
+	return false
+}
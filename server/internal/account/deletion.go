@@ -0,0 +1,288 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+)
+
+var (
+	ErrDeletionPending  = errors.New("已经申请注销，无需重复申请")
+	ErrNoDeletion       = errors.New("账号没有待处理的注销申请")
+	ErrNotActiveAccount = errors.New("账号状态不允许申请注销")
+)
+
+// trashedFile 移入回收站的文件
+type trashedFile struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RequestDeletion 申请注销账号：进入冷静期并注销全部会话，文件移入回收站（他人共享的文件夹中的文件保留原位），
+// 上传下载记录中的 IP 和 User-Agent 清空（不可撤销）；冷静期结束后由 StartPurger 彻底删除
+func RequestDeletion(db *gorm.DB, cfg *config.Config, userID uint, reason string) (*model.AccountDeletion, error) {
+	last, err := rbac.IsLastAdmin(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if last {
+		return nil, rbac.ErrLastAdmin
+	}
+
+	now := time.Now()
+	deletion := &model.AccountDeletion{
+		UserID:      userID,
+		RequestedAt: now,
+		PurgeAt:     now.AddDate(0, 0, cfg.Account.DeletionGraceDays),
+		Reason:      reason,
+	}
+	var paths []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").First(&user, userID).Error; err != nil {
+			return err
+		}
+		if user.Status == model.StatusPendingDeletion {
+			return ErrDeletionPending
+		}
+		if user.Status != model.StatusActive {
+			return ErrNotActiveAccount
+		}
+		deletion.PreviousStatus = user.Status
+
+		if err := tx.Create(deletion).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Update("status", model.StatusPendingDeletion).Error; err != nil {
+			return err
+		}
+		if err := anonymise(tx, userID); err != nil {
+			return err
+		}
+		uploaded, err := uploadedPaths(tx, userID)
+		if err != nil {
+			return err
+		}
+		paths, _, err = splitByFolderOwner(tx, userID, uploaded)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rbac.Invalidate(userID)
+	if _, err := signOut(db, userID); err != nil {
+		logger.Error("注销会话失败", zap.Uint("user_id", userID), zap.Error(err))
+	}
+
+	// 文件移动在事务外进行，记录移动结果以便撤销时恢复
+	moved := moveToTrash(cfg, userID, paths)
+	if len(moved) > 0 {
+		data, _ := json.Marshal(moved)
+		deletion.TrashedFiles = string(data)
+		if err := db.Model(deletion).Update("trashed_files", deletion.TrashedFiles).Error; err != nil {
+			logger.Error("记录回收站文件失败", zap.Uint("user_id", userID), zap.Error(err))
+		}
+	}
+	return deletion, nil
+}
+
+// CancelDeletion 撤销注销申请：恢复账号状态，把回收站中的文件移回原位置（已清空的 IP 等信息无法恢复）
+func CancelDeletion(db *gorm.DB, userID uint) error {
+	var deletion model.AccountDeletion
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&deletion, "user_id = ?", userID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNoDeletion
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&deletion).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).Where("id = ?", userID).Update("status", deletion.PreviousStatus).Error
+	})
+	if err != nil {
+		return err
+	}
+	rbac.Invalidate(userID)
+
+	for _, f := range trashed(&deletion) {
+		if _, err := os.Stat(f.From); err == nil {
+			logger.Warn("原位置已有同名文件，保留在回收站", zap.Uint("user_id", userID), zap.String("path", f.From), zap.String("trash", f.To))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(f.From), 0o755); err != nil {
+			logger.Warn("恢复文件失败", zap.Uint("user_id", userID), zap.String("path", f.From), zap.Error(err))
+			continue
+		}
+		if err := os.Rename(f.To, f.From); err != nil {
+			logger.Warn("恢复文件失败", zap.Uint("user_id", userID), zap.String("path", f.From), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// PendingDeletion 用户的注销申请，没有时返回 nil, nil
+func PendingDeletion(db *gorm.DB, userID uint) (*model.AccountDeletion, error) {
+	var deletion model.AccountDeletion
+	err := db.First(&deletion, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+var purgerOnce sync.Once
+
+// StartPurger 每小时彻底删除冷静期已结束的账号（只在服务启动时调用）
+func StartPurger(db *gorm.DB, cfg *config.Config) {
+	purgerOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(time.Hour)
+			defer ticker.Stop()
+			for {
+				if n, err := PurgeDue(db, cfg); err != nil {
+					logger.Error("清理到期注销账号失败", zap.Error(err))
+				} else if n > 0 {
+					logger.Info("已彻底删除到期注销的账号", zap.Int("count", n))
+				}
+				<-ticker.C
+			}
+		}()
+	})
+}
+
+// PurgeDue 彻底删除冷静期已结束的账号：删除回收站中的文件，匿名化审计日志，再删除账号（关联数据级联删除）
+func PurgeDue(db *gorm.DB, cfg *config.Config) (int, error) {
+	var due []model.AccountDeletion
+	if err := db.Where("purge_at <= ?", time.Now()).Find(&due).Error; err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for i := range due {
+		d := &due[i]
+		// 处理期间用户可能已撤销
+		if current, err := PendingDeletion(db, d.UserID); err != nil || current == nil {
+			continue
+		}
+
+		files := trashed(d)
+		for _, root := range cfg.GetAllowedPaths() {
+			dir := userTrashDir(cfg, root, d.UserID)
+			if err := os.RemoveAll(dir); err != nil {
+				logger.Warn("删除回收站文件失败", zap.Uint("user_id", d.UserID), zap.String("path", dir), zap.Error(err))
+			}
+		}
+
+		// 审计日志保留事件，但清空操作人的可识别信息；失败时暂不删除账号，下次重试
+		var user model.User
+		if err := db.Select("id", "username", "email", "phone").First(&user, d.UserID).Error; err != nil {
+			logger.Error("查询到期注销账号失败", zap.Uint("user_id", d.UserID), zap.Error(err))
+			continue
+		}
+		if _, err := audit.Anonymise(db, d.UserID, user.Username, user.Email, user.Phone); err != nil {
+			logger.Error("匿名化审计日志失败", zap.Uint("user_id", d.UserID), zap.Error(err))
+			continue
+		}
+
+		if _, err := Delete(db, d.UserID, DeleteOptions{}); err != nil {
+			logger.Error("删除到期注销账号失败", zap.Uint("user_id", d.UserID), zap.Error(err))
+			continue
+		}
+		purged++
+
+		id := d.UserID
+		audit.Record(&model.AuditLog{
+			CreatedAt:  time.Now(),
+			ActorID:    &id,
+			Action:     audit.ActionAccountPurge,
+			TargetType: audit.TargetUser,
+			TargetID:   strconv.FormatUint(uint64(id), 10),
+			Result:     model.AuditSuccess,
+			Detail:     fmt.Sprintf(`{"requested_at":%q,"trashed_files":%d}`, d.RequestedAt.Format(time.RFC3339), len(files)),
+		})
+	}
+	return purged, nil
+}
+
+// anonymise 清空上传、下载记录中可识别个人的信息
+func anonymise(tx *gorm.DB, userID uint) error {
+	if err := tx.Model(&model.UploadHistory{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"ip_address": "", "user_agent": ""}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.DownloadHistory{}).Where("user_id = ?", userID).
+		Update("ip_address", "").Error
+}
+
+// moveToTrash 把文件移到所在存储路径的回收站（trash/deleted-users/<用户ID>/ 下保持相对路径）
+// paths 只能包含用户自己的文件，他人共享的文件夹中的文件由调用方排除（splitByFolderOwner）
+func moveToTrash(cfg *config.Config, userID uint, paths []string) []trashedFile {
+	var moved []trashedFile
+	for _, p := range paths {
+		root := ""
+		for _, dir := range cfg.GetAllowedPaths() {
+			if model.IsPathWithin(p, dir) {
+				root = dir
+				break
+			}
+		}
+		if root == "" {
+			logger.Warn("文件不在允许的存储路径内，跳过", zap.Uint("user_id", userID), zap.String("path", p))
+			continue
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			// 目标必须留在 deleted-users/<用户ID>/ 下
+			logger.Warn("文件相对存储路径无效，跳过", zap.Uint("user_id", userID), zap.String("path", p), zap.String("root", root))
+			continue
+		}
+		dst := filepath.Join(userTrashDir(cfg, root, userID), rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			logger.Warn("创建回收站目录失败", zap.String("path", dst), zap.Error(err))
+			continue
+		}
+		if err := os.Rename(p, dst); err != nil {
+			if !os.IsNotExist(err) {
+				logger.Warn("移动文件到回收站失败", zap.Uint("user_id", userID), zap.String("path", p), zap.Error(err))
+			}
+			continue
+		}
+		moved = append(moved, trashedFile{From: p, To: dst})
+	}
+	return moved
+}
+
+func userTrashDir(cfg *config.Config, root string, userID uint) string {
+	return filepath.Join(cfg.GetTrashPath(root), "deleted-users", strconv.FormatUint(uint64(userID), 10))
+}
+
+func trashed(d *model.AccountDeletion) []trashedFile {
+	var files []trashedFile
+	if d.TrashedFiles != "" {
+		_ = json.Unmarshal([]byte(d.TrashedFiles), &files)
+	}
+	return files
+}
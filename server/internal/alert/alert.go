@@ -0,0 +1,312 @@
+// Package alert 告警：定时评估规则（磁盘空间、上传失败、登录失败、设备离线），
+// 状态变化时通过 WebSocket、邮件、Webhook 通知
+//
+// 同一规则、同一对象（rule + key）同时只有一条触发中的告警：持续满足条件时只更新最近一次的值，
+// 按 repeatMinutes 重复提醒；条件不再满足时标记为已恢复并发送恢复通知。
+package alert
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+)
+
+// 单次发送通知的超时
+const sendTimeout = 30 * time.Second
+
+var ErrDisabled = errors.New("告警未启用")
+
+// engine 规则评估服务
+type engine struct {
+	db       *gorm.DB
+	rules    []rule
+	channels []Channel
+	interval time.Duration
+	repeat   time.Duration // 为 0 时不重复提醒
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+var std *engine
+
+// Init 启动规则评估协程，评估间隔小于 0 时不启动
+func Init(db *gorm.DB, cfg *config.Config) {
+	ac := cfg.Alert
+	if ac.IntervalSeconds < 0 {
+		logger.Info("告警已关闭")
+		return
+	}
+
+	channels, err := buildChannels(db, cfg)
+	if err != nil {
+		logger.Error("创建告警通知渠道失败，告警未启动", zap.Error(err))
+		return
+	}
+
+	e := &engine{
+		db:       db,
+		rules:    buildRules(db, cfg),
+		channels: channels,
+		interval: time.Duration(ac.IntervalSeconds) * time.Second,
+		done:     make(chan struct{}),
+	}
+	if ac.RepeatMinutes > 0 {
+		e.repeat = time.Duration(ac.RepeatMinutes) * time.Minute
+	}
+
+	e.wg.Add(1)
+	go e.run()
+	std = e
+
+	names := make([]string, 0, len(e.channels))
+	for _, ch := range e.channels {
+		names = append(names, ch.Name())
+	}
+	logger.Info("告警已启动",
+		zap.Duration("interval", e.interval),
+		zap.Int("rules", len(e.rules)),
+		zap.Strings("channels", names),
+	)
+}
+
+// Close 停止规则评估
+func Close() {
+	if std == nil {
+		return
+	}
+	close(std.done)
+	std.wg.Wait()
+}
+
+// run 评估主循环
+func (e *engine) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.evaluate()
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// evaluate 评估一轮规则，更新告警状态并发送通知
+func (e *engine) evaluate() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+	defer cancel()
+
+	var firing []model.Alert
+	if err := e.db.WithContext(ctx).Where("status = ?", model.AlertFiring).Find(&firing).Error; err != nil {
+		logger.Error("查询触发中的告警失败", zap.Error(err))
+		return
+	}
+	existing := make(map[string]*model.Alert, len(firing))
+	for i := range firing {
+		existing[alertID(firing[i].Rule, firing[i].AlertKey)] = &firing[i]
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	failed := make(map[string]bool)
+	var changed []model.Alert
+
+	for _, r := range e.rules {
+		findings, err := r.evaluate(ctx)
+		if err != nil {
+			// 评估失败时保持该规则已有告警的状态，避免误报恢复
+			failed[r.name] = true
+			logger.Warn("告警规则评估失败", zap.String("rule", r.name), zap.Error(err))
+			continue
+		}
+		for _, f := range findings {
+			f.Key = truncate(f.Key, 255)
+			id := alertID(r.name, f.Key)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			if a, ok := existing[id]; ok {
+				if e.refresh(ctx, a, f, now) {
+					changed = append(changed, *a)
+				}
+				continue
+			}
+			if a, ok := e.fire(ctx, r.name, f, now); ok {
+				changed = append(changed, *a)
+			}
+		}
+	}
+
+	// 不再满足条件（包括规则已关闭）的告警标记为已恢复
+	for id, a := range existing {
+		if seen[id] || failed[a.Rule] {
+			continue
+		}
+		if e.resolve(ctx, a, now) {
+			changed = append(changed, *a)
+		}
+	}
+
+	if len(changed) > 0 {
+		e.notify(changed)
+	}
+}
+
+// fire 新建触发中的告警，返回是否需要通知
+func (e *engine) fire(ctx context.Context, ruleName string, f finding, now time.Time) (*model.Alert, bool) {
+	a := &model.Alert{
+		Rule:        ruleName,
+		AlertKey:    f.Key,
+		Severity:    f.Severity,
+		Status:      model.AlertFiring,
+		Title:       f.Title,
+		Message:     f.Message,
+		Value:       f.Value,
+		Threshold:   f.Threshold,
+		FiredAt:     now,
+		LastSeenAt:  now,
+		NotifiedAt:  &now,
+		NotifyCount: 1,
+	}
+	if err := e.db.WithContext(ctx).Create(a).Error; err != nil {
+		logger.Error("保存告警失败", zap.String("rule", ruleName), zap.String("key", f.Key), zap.Error(err))
+		return nil, false
+	}
+	logger.Warn("告警触发", zap.String("rule", ruleName), zap.String("key", f.Key), zap.String("message", f.Message))
+	return a, true
+}
+
+// refresh 告警持续满足条件，更新最近一次的值；级别升高或到了重复提醒时间时返回 true
+func (e *engine) refresh(ctx context.Context, a *model.Alert, f finding, now time.Time) bool {
+	escalated := f.Severity == model.AlertCritical && a.Severity != model.AlertCritical
+	remind := e.repeat > 0 && a.NotifiedAt != nil && now.Sub(*a.NotifiedAt) >= e.repeat
+	notify := escalated || remind
+
+	updates := map[string]interface{}{
+		"severity":     f.Severity,
+		"title":        f.Title,
+		"message":      f.Message,
+		"value":        f.Value,
+		"threshold":    f.Threshold,
+		"last_seen_at": now,
+	}
+	if notify {
+		updates["notified_at"] = now
+		updates["notify_count"] = a.NotifyCount + 1
+	}
+	if err := e.db.WithContext(ctx).Model(a).Updates(updates).Error; err != nil {
+		logger.Error("更新告警失败", zap.Uint64("alert_id", a.ID), zap.Error(err))
+		return false
+	}
+
+	a.Severity, a.Title, a.Message = f.Severity, f.Title, f.Message
+	a.Value, a.Threshold, a.LastSeenAt = f.Value, f.Threshold, now
+	if notify {
+		a.NotifiedAt = &now
+		a.NotifyCount++
+	}
+	return notify
+}
+
+// resolve 标记为已恢复
+func (e *engine) resolve(ctx context.Context, a *model.Alert, now time.Time) bool {
+	err := e.db.WithContext(ctx).Model(a).Updates(map[string]interface{}{
+		"status":      model.AlertResolved,
+		"resolved_at": now,
+	}).Error
+	if err != nil {
+		logger.Error("更新告警失败", zap.Uint64("alert_id", a.ID), zap.Error(err))
+		return false
+	}
+	a.Status = model.AlertResolved
+	a.ResolvedAt = &now
+	logger.Info("告警恢复", zap.String("rule", a.Rule), zap.String("key", a.AlertKey))
+	return true
+}
+
+// notify 把本轮状态变化的告警发送到全部渠道，单个渠道失败不影响其他渠道
+func (e *engine) notify(alerts []model.Alert) {
+	for _, ch := range e.channels {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		if err := ch.Send(ctx, alerts); err != nil {
+			logger.Warn("发送告警通知失败", zap.String("channel", ch.Name()), zap.Int("alerts", len(alerts)), zap.Error(err))
+		}
+		cancel()
+	}
+}
+
+// ChannelResult 测试告警在单个渠道的发送结果，Error 为空表示成功
+type ChannelResult struct {
+	Channel string `json:"channel"`
+	Error   string `json:"error"`
+}
+
+// SendTest 向全部渠道发送一条测试告警
+func SendTest(ctx context.Context, operator string) ([]ChannelResult, error) {
+	if std == nil {
+		return nil, ErrDisabled
+	}
+	now := time.Now()
+	test := []model.Alert{{
+		Rule:       "test",
+		Severity:   model.AlertWarning,
+		Status:     model.AlertFiring,
+		Title:      "测试告警",
+		Message:    "这是 " + operator + " 发送的测试告警，收到说明通知渠道配置正确",
+		FiredAt:    now,
+		LastSeenAt: now,
+	}}
+
+	results := make([]ChannelResult, 0, len(std.channels))
+	for _, ch := range std.channels {
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := ch.Send(sendCtx, test)
+		cancel()
+		result := ChannelResult{Channel: ch.Name()}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Rules 当前启用的规则
+func Rules() []string {
+	if std == nil {
+		return nil
+	}
+	names := make([]string, 0, len(std.rules))
+	for _, r := range std.rules {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// alertID 去重标识
+func alertID(rule, key string) string {
+	return rule + "\x00" + key
+}
+
+// truncate 按字符截断，保证不超过数据库字段长度
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,216 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/mailer"
+	"github.com/sunyuanling/server/websocket"
+)
+
+// Channel 告警通知渠道，一轮评估中状态变化的告警合并为一次发送
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, alerts []model.Alert) error
+}
+
+// buildChannels 按配置创建通知渠道
+func buildChannels(db *gorm.DB, cfg *config.Config) ([]Channel, error) {
+	cc := cfg.Alert.Channels
+	var channels []Channel
+	if cc.WebSocket {
+		channels = append(channels, &wsChannel{db: db})
+	}
+	if len(cc.Emails) > 0 {
+		m, err := mailer.New(cfg.Mail)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, &emailChannel{mailer: m, to: cc.Emails})
+	}
+	client := &http.Client{Timeout: webhookTimeout}
+	for _, w := range cc.Webhooks {
+		// 日志中只记录主机名，地址中可能带有令牌
+		name := "webhook"
+		if u, err := url.Parse(w.URL); err == nil {
+			name += ":" + u.Host
+		}
+		channels = append(channels, &webhookChannel{name: name, client: client, url: w.URL, secret: w.Secret})
+	}
+	return channels, nil
+}
+
+// heading 通知标题，区分触发和恢复
+func heading(a *model.Alert) string {
+	if a.Status == model.AlertResolved {
+		return "[恢复] " + a.Title
+	}
+	if a.Severity == model.AlertCritical {
+		return "[严重] " + a.Title
+	}
+	return "[告警] " + a.Title
+}
+
+// ========== WebSocket ==========
+
+// maxWSNotifications 一轮超过该条数时只推送一条汇总
+const maxWSNotifications = 5
+
+// wsChannel 推送给在线的、有 system:monitor 权限的用户
+type wsChannel struct {
+	db *gorm.DB
+}
+
+func (c *wsChannel) Name() string { return "websocket" }
+
+func (c *wsChannel) Send(_ context.Context, alerts []model.Alert) error {
+	var recipients []uint
+	for _, uid := range websocket.GetOnlineUserIDs() {
+		grants, err := rbac.Load(c.db, uid)
+		if err == nil && grants.Has(rbac.PermSystemMonitor) {
+			recipients = append(recipients, uid)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	for _, uid := range recipients {
+		if len(alerts) > maxWSNotifications {
+			firing := 0
+			for i := range alerts {
+				if alerts[i].Status == model.AlertFiring {
+					firing++
+				}
+			}
+			msg := fmt.Sprintf("%d 条告警触发，%d 条恢复，请到告警列表查看", firing, len(alerts)-firing)
+			_ = websocket.NotifyUser(uid, "告警状态变化", msg, "warning")
+			continue
+		}
+		for i := range alerts {
+			_ = websocket.NotifyUser(uid, heading(&alerts[i]), alerts[i].Message, wsLevel(&alerts[i]))
+		}
+	}
+	return nil
+}
+
+// wsLevel 通知级别
+func wsLevel(a *model.Alert) string {
+	switch {
+	case a.Status == model.AlertResolved:
+		return "success"
+	case a.Severity == model.AlertCritical:
+		return "error"
+	}
+	return "warning"
+}
+
+// ========== 邮件 ==========
+
+// emailChannel 发送邮件给配置的收件人
+type emailChannel struct {
+	mailer mailer.Mailer
+	to     []string
+}
+
+func (c *emailChannel) Name() string { return "email" }
+
+func (c *emailChannel) Send(ctx context.Context, alerts []model.Alert) error {
+	subject := heading(&alerts[0])
+	if len(alerts) > 1 {
+		subject = fmt.Sprintf("[告警] %d 条告警状态变化", len(alerts))
+	}
+
+	var body strings.Builder
+	for i := range alerts {
+		a := &alerts[i]
+		fmt.Fprintf(&body, "%s\n%s\n", heading(a), a.Message)
+		fmt.Fprintf(&body, "规则：%s  对象：%s  级别：%s\n", a.Rule, a.AlertKey, a.Severity)
+		fmt.Fprintf(&body, "触发时间：%s\n", a.FiredAt.Format(time.DateTime))
+		if a.ResolvedAt != nil {
+			fmt.Fprintf(&body, "恢复时间：%s\n", a.ResolvedAt.Format(time.DateTime))
+		}
+		body.WriteString("\n")
+	}
+
+	var errs []error
+	for _, to := range c.to {
+		msg := mailer.Message{To: to, Subject: subject, Body: body.String()}
+		if err := c.mailer.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", to, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ========== Webhook ==========
+
+// webhookTimeout 单次 Webhook 请求超时
+const webhookTimeout = 10 * time.Second
+
+// webhookChannel 以 JSON POST 到配置的地址
+type webhookChannel struct {
+	name   string
+	client *http.Client
+	url    string
+	secret string
+}
+
+// webhookPayload Webhook 请求体
+type webhookPayload struct {
+	Source string        `json:"source"`
+	SentAt int64         `json:"sent_at"`
+	Alerts []model.Alert `json:"alerts"`
+}
+
+func (c *webhookChannel) Name() string { return c.name }
+
+func (c *webhookChannel) Send(ctx context.Context, alerts []model.Alert) error {
+	body, err := json.Marshal(webhookPayload{Source: "filesync", SentAt: time.Now().Unix(), Alerts: alerts})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.secret))
+		mac.Write(body)
+		req.Header.Set("X-Alert-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// 错误信息中不带完整地址
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			return ue.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package alert
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/model"
+)
+
+// Filter 告警查询条件，零值字段不参与过滤
+type Filter struct {
+	Status   string    `json:"status"` // firing/resolved
+	Rule     string    `json:"rule"`
+	Severity string    `json:"severity"`
+	Since    time.Time `json:"since"` // 按触发时间
+	Until    time.Time `json:"until"`
+}
+
+func (f Filter) apply(db *gorm.DB) *gorm.DB {
+	query := db.Model(&model.Alert{})
+	if f.Status != "" {
+		query = query.Where("status = ?", f.Status)
+	}
+	if f.Rule != "" {
+		query = query.Where("rule = ?", f.Rule)
+	}
+	if f.Severity != "" {
+		query = query.Where("severity = ?", f.Severity)
+	}
+	if !f.Since.IsZero() {
+		query = query.Where("fired_at >= ?", f.Since)
+	}
+	if !f.Until.IsZero() {
+		query = query.Where("fired_at < ?", f.Until)
+	}
+	return query
+}
+
+// Query 按条件分页查询，触发中的在前，再按触发时间倒序
+func Query(db *gorm.DB, f Filter, page, pageSize int) ([]model.Alert, int64, error) {
+	var total int64
+	if err := f.apply(db).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var alerts []model.Alert
+	err := f.apply(db).
+		Order("CASE WHEN status = 'firing' THEN 0 ELSE 1 END, fired_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&alerts).Error
+	return alerts, total, err
+}
@@ -0,0 +1,372 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/diskhealth"
+	"github.com/sunyuanling/server/internal/model"
+)
+
+// 规则名称
+const (
+	RuleDiskUsage     = "disk_usage"     // 存储磁盘使用率过高
+	RuleFreeSpace     = "free_space"     // 存储磁盘剩余空间低于 minFreeSpace
+	RuleDiskHealth    = "disk_health"    // 物理磁盘 SMART 异常、温度过高
+	RuleFailedUploads = "failed_uploads" // 最近一小时上传失败过多
+	RuleLoginFailures = "login_failures" // 同一账号或 IP 登录失败过多
+	RuleDeviceOffline = "device_offline" // 设备长时间未活跃
+)
+
+// 单条规则一次最多产生的告警数，避免大量设备离线时刷屏
+const (
+	maxLoginFindings  = 20
+	maxDeviceFindings = 100
+)
+
+// finding 规则评估出的一条满足条件的告警
+type finding struct {
+	Key       string
+	Severity  string
+	Title     string
+	Message   string
+	Value     float64
+	Threshold float64
+}
+
+// rule 告警规则，evaluate 返回当前满足条件的全部告警
+// 返回错误时本轮不改变该规则已有告警的状态
+type rule struct {
+	name     string
+	evaluate func(ctx context.Context) ([]finding, error)
+}
+
+// buildRules 按配置启用规则，阈值小于 0 的规则不启用
+func buildRules(db *gorm.DB, cfg *config.Config) []rule {
+	ac := cfg.Alert
+	var rules []rule
+	if ac.DiskUsagePercent > 0 {
+		rules = append(rules, rule{RuleDiskUsage, func(context.Context) ([]finding, error) {
+			return diskUsage(cfg.GetAllowedPaths(), ac.DiskUsagePercent, ac.DiskCriticalPercent)
+		}})
+	}
+	if ac.FreeSpace && cfg.File.Storage.MinFreeSpace > 0 {
+		rules = append(rules, rule{RuleFreeSpace, func(context.Context) ([]finding, error) {
+			return freeSpace(cfg.GetAllowedPaths(), uint64(cfg.File.Storage.MinFreeSpace))
+		}})
+	}
+	if ac.DiskHealth {
+		rules = append(rules, rule{RuleDiskHealth, func(context.Context) ([]finding, error) {
+			return diskHealth()
+		}})
+	}
+	if ac.FailedUploadsPerHour > 0 {
+		rules = append(rules, rule{RuleFailedUploads, func(ctx context.Context) ([]finding, error) {
+			return failedUploads(ctx, db, ac.FailedUploadsPerHour)
+		}})
+	}
+	if ac.LoginFailures > 0 {
+		window := time.Duration(ac.LoginWindowMinutes) * time.Minute
+		rules = append(rules, rule{RuleLoginFailures, func(ctx context.Context) ([]finding, error) {
+			return loginFailures(ctx, db, ac.LoginFailures, window)
+		}})
+	}
+	if ac.DeviceOfflineDays > 0 {
+		rules = append(rules, rule{RuleDeviceOffline, func(ctx context.Context) ([]finding, error) {
+			return deviceOffline(ctx, db, ac.DeviceOfflineDays)
+		}})
+	}
+	return rules
+}
+
+// ========== 存储空间 ==========
+
+// storageDisk 存储路径所在的磁盘，多个存储路径在同一挂载点时只算一次
+type storageDisk struct {
+	mountpoint string
+	paths      []string
+	usage      *disk.UsageStat
+}
+
+// storageDisks 读取存储路径所在磁盘的使用情况
+func storageDisks(paths []string) ([]storageDisk, error) {
+	partitions, _ := disk.Partitions(false)
+
+	var (
+		out     []storageDisk
+		index   = make(map[string]int)
+		lastErr error
+	)
+	for _, p := range paths {
+		usage, err := disk.Usage(p)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		mp := mountpointOf(p, partitions)
+		if i, ok := index[mp]; ok {
+			out[i].paths = append(out[i].paths, p)
+			continue
+		}
+		index[mp] = len(out)
+		out = append(out, storageDisk{mountpoint: mp, paths: []string{p}, usage: usage})
+	}
+	if len(out) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return out, nil
+}
+
+// mountpointOf 路径所在的挂载点（最长前缀匹配），找不到时返回路径本身
+func mountpointOf(path string, partitions []disk.PartitionStat) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	best := ""
+	for _, part := range partitions {
+		mp := part.Mountpoint
+		if !pathWithin(abs, mp) || len(mp) <= len(best) {
+			continue
+		}
+		best = mp
+	}
+	if best == "" {
+		return path
+	}
+	return best
+}
+
+// pathWithin path 是否在目录 dir 之下（含 dir 本身）
+func pathWithin(path, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	if filepath.Separator == '\\' {
+		path, dir = strings.ToLower(path), strings.ToLower(dir)
+	}
+	if path == dir {
+		return true
+	}
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, dir)
+}
+
+// diskUsage 存储磁盘使用率达到阈值
+func diskUsage(paths []string, warning, critical float64) ([]finding, error) {
+	disks, err := storageDisks(paths)
+	if err != nil {
+		return nil, err
+	}
+	var out []finding
+	for _, d := range disks {
+		pct := d.usage.UsedPercent
+		if pct < warning {
+			continue
+		}
+		f := finding{
+			Key:       d.mountpoint,
+			Severity:  model.AlertWarning,
+			Title:     "存储磁盘空间不足",
+			Value:     pct,
+			Threshold: warning,
+		}
+		if critical > 0 && pct >= critical {
+			f.Severity = model.AlertCritical
+			f.Title = "存储磁盘空间严重不足"
+			f.Threshold = critical
+		}
+		f.Message = fmt.Sprintf("%s 已使用 %.1f%%（%s / %s），存储路径：%s",
+			d.mountpoint, pct, formatBytes(d.usage.Used), formatBytes(d.usage.Total), strings.Join(d.paths, ", "))
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// freeSpace 存储磁盘剩余空间低于 file.storage.minFreeSpace
+func freeSpace(paths []string, minFree uint64) ([]finding, error) {
+	disks, err := storageDisks(paths)
+	if err != nil {
+		return nil, err
+	}
+	var out []finding
+	for _, d := range disks {
+		if d.usage.Free >= minFree {
+			continue
+		}
+		out = append(out, finding{
+			Key:       d.mountpoint,
+			Severity:  model.AlertCritical,
+			Title:     "存储磁盘剩余空间低于下限",
+			Message:   fmt.Sprintf("%s 剩余 %s，低于下限 %s，存储路径：%s", d.mountpoint, formatBytes(d.usage.Free), formatBytes(minFree), strings.Join(d.paths, ", ")),
+			Value:     float64(d.usage.Free),
+			Threshold: float64(minFree),
+		})
+	}
+	return out, nil
+}
+
+// diskHealth 物理磁盘健康状态为 warning/critical（数据来自 diskhealth 的定时采集）
+func diskHealth() ([]finding, error) {
+	disks, err := diskhealth.Latest()
+	if errors.Is(err, diskhealth.ErrDisabled) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []finding
+	for _, d := range disks {
+		if d.Status == diskhealth.StatusHealthy {
+			continue
+		}
+		f := finding{
+			Key:      d.DiskID(),
+			Severity: model.AlertWarning,
+			Title:    "磁盘健康异常",
+			Message:  fmt.Sprintf("%s：%s", d.Device, strings.Join(d.Problems, "；")),
+		}
+		if d.Smart != nil && d.Smart.Model != "" {
+			f.Message = fmt.Sprintf("%s（%s）：%s", d.Device, d.Smart.Model, strings.Join(d.Problems, "；"))
+		}
+		if d.Status == diskhealth.StatusCritical {
+			f.Severity = model.AlertCritical
+		}
+		if d.Temperature != nil {
+			f.Value = *d.Temperature
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// ========== 上传失败 ==========
+
+// failedUploads 最近一小时上传失败次数达到阈值
+func failedUploads(ctx context.Context, db *gorm.DB, threshold int) ([]finding, error) {
+	var count int64
+	err := db.WithContext(ctx).Model(&model.UploadHistory{}).
+		Where("upload_status = ? AND updated_at >= ?", model.UploadStatusFailed, time.Now().Add(-time.Hour)).
+		Count(&count).Error
+	if err != nil || count < int64(threshold) {
+		return nil, err
+	}
+	return []finding{{
+		Severity:  model.AlertWarning,
+		Title:     "上传失败次数过多",
+		Message:   fmt.Sprintf("最近一小时有 %d 次上传失败（阈值 %d）", count, threshold),
+		Value:     float64(count),
+		Threshold: float64(threshold),
+	}}, nil
+}
+
+// ========== 登录失败 ==========
+
+// loginFailures 统计窗口内同一账号、同一 IP 的登录失败次数达到阈值
+// 数据来自审计日志，审计日志异步写入，会有几秒延迟
+func loginFailures(ctx context.Context, db *gorm.DB, threshold int, window time.Duration) ([]finding, error) {
+	since := time.Now().Add(-window)
+	minutes := int(window / time.Minute)
+
+	var out []finding
+	for _, group := range []struct {
+		column string
+		prefix string
+		label  string
+	}{
+		{"actor", "user:", "账号"},
+		{"ip", "ip:", "IP"},
+	} {
+		var rows []struct {
+			Name  string
+			Count int64
+		}
+		err := db.WithContext(ctx).Model(&model.AuditLog{}).
+			Select(group.column+" AS name, COUNT(*) AS count").
+			Where("action = ? AND result = ? AND created_at >= ?", audit.ActionLogin, model.AuditFailure, since).
+			Where(group.column+" <> ''").
+			Group(group.column).
+			Having("COUNT(*) >= ?", threshold).
+			Order("count DESC").
+			Limit(maxLoginFindings).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			out = append(out, finding{
+				Key:       group.prefix + r.Name,
+				Severity:  model.AlertWarning,
+				Title:     "登录失败次数过多",
+				Message:   fmt.Sprintf("%s %s 在 %d 分钟内登录失败 %d 次（阈值 %d）", group.label, r.Name, minutes, r.Count, threshold),
+				Value:     float64(r.Count),
+				Threshold: float64(threshold),
+			})
+		}
+	}
+	return out, nil
+}
+
+// ========== 设备离线 ==========
+
+// deviceOffline 正常状态的设备连续 days 天没有活跃（从未活跃的按创建时间算）
+func deviceOffline(ctx context.Context, db *gorm.DB, days int) ([]finding, error) {
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	var rows []struct {
+		ID         uint
+		DeviceName string
+		Username   string
+		LastSeen   time.Time
+	}
+	err := db.WithContext(ctx).Table("device AS d").
+		Select(`d.id, d.device_name, u.username, COALESCE(d.last_active, d.created_at) AS last_seen`).
+		Joins(`JOIN "user" u ON u.id = d.user_id`).
+		Where("d.status = ? AND COALESCE(d.last_active, d.created_at) < ?", model.DeviceStatusActive, cutoff).
+		Order("last_seen").
+		Limit(maxDeviceFindings).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]finding, 0, len(rows))
+	for _, r := range rows {
+		offline := int(time.Since(r.LastSeen).Hours() / 24)
+		out = append(out, finding{
+			Key:       strconv.FormatUint(uint64(r.ID), 10),
+			Severity:  model.AlertWarning,
+			Title:     "设备长时间离线",
+			Message:   fmt.Sprintf("用户 %s 的设备 %s 已 %d 天未活跃（最后活跃 %s）", r.Username, r.DeviceName, offline, r.LastSeen.Format("2006-01-02 15:04")),
+			Value:     float64(offline),
+			Threshold: float64(days),
+		})
+	}
+	return out, nil
+}
+
+// formatBytes 格式化字节大小
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.2f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,193 @@
+// Package apikey 个人 API Key：生成（明文只返回一次）、校验、吊销，以及 Key 的权限范围
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+)
+
+const (
+	keyPrefix     = "fsk_"      // Key 明文前缀，便于识别和密钥扫描
+	touchInterval = time.Minute // 最后使用时间的最小更新间隔
+	contextKey    = "APIKey"    // 中间件写入 gin.Context 的 key
+	maxKeysOfUser = 50          // 每个用户最多的有效 Key 数
+)
+
+var (
+	ErrInvalidKey   = errors.New("API Key 无效")
+	ErrKeyInactive  = errors.New("API Key 已吊销或已过期")
+	ErrUserDisabled = errors.New("账号已被禁用")
+	ErrInvalidScope = errors.New("无效的权限范围（可选: read / upload / read_write）")
+	ErrTooManyKeys  = errors.New("API Key 数量已达上限")
+)
+
+// scopePermissions Key 权限范围对应的权限（与用户自身权限取交集）
+var scopePermissions = map[string][]string{
+	model.APIKeyScopeRead:      {rbac.PermFilesRead, rbac.PermDisksRead},
+	model.APIKeyScopeUpload:    {rbac.PermFilesWrite},
+	model.APIKeyScopeReadWrite: {rbac.PermFilesRead, rbac.PermFilesWrite, rbac.PermDisksRead},
+}
+
+// Permissions 权限范围允许的权限
+func Permissions(scope string) []string {
+	return scopePermissions[scope]
+}
+
+// IsValidScope 判断权限范围是否有效
+func IsValidScope(scope string) bool {
+	_, ok := scopePermissions[scope]
+	return ok
+}
+
+// LooksLikeKey 判断字符串是否是 API Key 格式（用于区分 Bearer 中的 token 和 Key）
+func LooksLikeKey(raw string) bool {
+	return strings.HasPrefix(raw, keyPrefix)
+}
+
+// Create 生成 API Key，返回明文（只在创建时返回一次）
+func Create(db *gorm.DB, key *model.APIKey) (string, error) {
+	if !IsValidScope(key.Scope) {
+		return "", ErrInvalidScope
+	}
+
+	var count int64
+	if err := db.Model(&model.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", key.UserID, time.Now()).
+		Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count >= maxKeysOfUser {
+		return "", ErrTooManyKeys
+	}
+
+	prefix, err := randomString(6)
+	if err != nil {
+		return "", err
+	}
+	secret, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	key.KeyPrefix = prefix
+	key.KeyHash = hashSecret(secret)
+	if err := db.Create(key).Error; err != nil {
+		return "", err
+	}
+	return keyPrefix + prefix + "_" + secret, nil
+}
+
+// Authenticate 校验 API Key，返回 Key 和所属用户，并记录最后使用时间
+func Authenticate(db *gorm.DB, raw, ip string) (*model.APIKey, *model.User, error) {
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(raw, keyPrefix), "_")
+	if !ok || !LooksLikeKey(raw) || prefix == "" || secret == "" {
+		return nil, nil, ErrInvalidKey
+	}
+
+	var key model.APIKey
+	if err := db.Where("key_prefix = ?", prefix).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidKey
+		}
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashSecret(secret))) != 1 {
+		return nil, nil, ErrInvalidKey
+	}
+	if !key.IsActive() {
+		return nil, nil, ErrKeyInactive
+	}
+
+	var user model.User
+	if err := db.First(&user, key.UserID).Error; err != nil {
+		return nil, nil, err
+	}
+	if user.Status != model.StatusActive {
+		return nil, nil, ErrUserDisabled
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > touchInterval || key.LastUsedIP != ip {
+		db.Model(&key).Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip})
+		key.LastUsedAt = &now
+		key.LastUsedIP = ip
+	}
+	return &key, &user, nil
+}
+
+// List 用户的 API Key（含已吊销和已过期的）
+func List(db *gorm.DB, userID uint) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := db.Where("user_id = ?", userID).Order("id desc").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke 吊销用户的 API Key
+func Revoke(db *gorm.DB, userID, keyID uint) error {
+	result := db.Model(&model.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", keyID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetContext 中间件记录本次请求使用的 API Key
+func SetContext(c *gin.Context, key *model.APIKey) {
+	c.Set(contextKey, key)
+}
+
+// FromContext 本次请求使用的 API Key，使用普通 token 登录时返回 nil
+func FromContext(c *gin.Context) *model.APIKey {
+	if v, ok := c.Get(contextKey); ok {
+		if key, ok := v.(*model.APIKey); ok {
+			return key
+		}
+	}
+	return nil
+}
+
+// Allows Key 的权限范围是否允许对路径执行 perm
+func Allows(key *model.APIKey, perm, path string) bool {
+	allowed := false
+	for _, p := range Permissions(key.Scope) {
+		if p == perm {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return false
+	}
+	return key.FolderPath == "" || path == "" || model.IsPathWithin(path, key.FolderPath)
+}
+
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	// 去掉 "_"，保证前缀与密文之间的分隔符唯一
+	return strings.ReplaceAll(base64.RawURLEncoding.EncodeToString(buf), "_", "-"), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,332 @@
+// Package audit 审计日志：登录、文件操作、账号变更等安全事件写入只追加的 audit_log 表
+//
+// 事件先进入内存队列，由后台协程批量写入，不阻塞请求；队列满或写库失败时
+// 事件改为写入 zap 日志文件，不会静默丢失。
+package audit
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	token "github.com/sunyuanling/server/pkg/tokn"
+)
+
+// 事件类型
+const (
+	ActionLogin          = "auth.login"               // 登录（密码、OIDC、两步验证第二步）
+	ActionLogout         = "auth.logout"              // 退出登录
+	ActionPasswordReset  = "auth.password_reset"      // 重置密码
+	ActionTwoFactorOn    = "auth.2fa_enable"          // 启用两步验证
+	ActionTwoFactorOff   = "auth.2fa_disable"         // 关闭两步验证
+	ActionSessionRevoke  = "auth.session_revoke"      // 注销会话
+	ActionIdentityUnlink = "auth.identity_unlink"     // 取消关联外部身份
+	ActionProfileUpdate  = "user.profile_update"      // 修改个人信息
+	ActionFileUpload     = "file.upload"              // 上传文件
+	ActionFileDownload   = "file.download"            // 下载文件
+	ActionFileDelete     = "file.delete"              // 删除文件
+	ActionFileAccess     = "file.access"              // 访问路径被拒绝
+	ActionAPIKeyCreate   = "apikey.create"            // 创建 API Key
+	ActionAPIKeyRevoke   = "apikey.revoke"            // 吊销 API Key
+	ActionRoleGrant      = "rbac.grant"               // 绑定角色
+	ActionRoleRevoke     = "rbac.revoke"              // 解除角色绑定
+	ActionWSDisconnect   = "ws.disconnect"            // 管理员断开 WebSocket 连接
+	ActionAuditExport    = "audit.export"             // 导出审计日志
+	ActionUserDisable    = "admin.user_disable"       // 管理员禁用账号
+	ActionUserEnable     = "admin.user_enable"        // 管理员启用账号
+	ActionUserRoles      = "admin.user_roles"         // 管理员修改账号角色
+	ActionUserForceReset = "admin.user_reset"         // 管理员强制重置密码
+	ActionUserDelete     = "admin.user_delete"        // 管理员删除账号
+	ActionTakeoutCreate  = "account.takeout"          // 发起数据导出
+	ActionTakeoutFetch   = "account.takeout_download" // 下载导出的数据
+	ActionDeleteRequest  = "account.delete_request"   // 申请注销账号
+	ActionDeleteCancel   = "account.delete_cancel"    // 撤销注销申请
+	ActionAccountPurge   = "account.purge"            // 冷静期结束，账号彻底删除
+)
+
+// 操作对象类型
+const (
+	TargetUser        = "user"
+	TargetFile        = "file"
+	TargetSession     = "session"
+	TargetDevice      = "device"
+	TargetConnection  = "connection"
+	TargetAPIKey      = "api_key"
+	TargetRoleBinding = "role_binding"
+)
+
+// Event 一条待记录的事件，Log 会补充当前用户、IP、User-Agent 和设备
+type Event struct {
+	Action     string
+	Result     string // 为空时为 success
+	ActorID    uint   // 为 0 时取当前登录用户
+	Actor      string // 用户名或登录标识，为空时取当前登录用户
+	TargetType string
+	TargetID   string
+	Detail     map[string]interface{}
+}
+
+// writer 异步批量写入
+type writer struct {
+	db       *gorm.DB
+	queue    chan *model.AuditLog
+	batch    int
+	interval time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+	dropped  atomic.Int64
+}
+
+var std atomic.Pointer[writer]
+
+// Init 启动后台写入和保留期清理，只在服务启动时调用一次
+func Init(db *gorm.DB, cfg config.AuditConfig) {
+	w := &writer{
+		db:       db,
+		queue:    make(chan *model.AuditLog, cfg.BufferSize),
+		batch:    cfg.BatchSize,
+		interval: time.Duration(cfg.FlushSeconds) * time.Second,
+		done:     make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.run()
+	if cfg.RetentionDays > 0 {
+		w.wg.Add(1)
+		go w.retain(cfg.RetentionDays)
+	}
+	std.Store(w)
+
+	logger.Info("审计日志已启用",
+		zap.Int("buffer_size", cfg.BufferSize),
+		zap.Int("retention_days", cfg.RetentionDays),
+	)
+}
+
+// Close 停止接收新事件并写完队列中剩余的事件
+func Close() {
+	w := std.Swap(nil)
+	if w == nil {
+		return
+	}
+	close(w.done)
+	w.wg.Wait()
+	if n := w.dropped.Load(); n > 0 {
+		logger.Warn("审计队列曾经溢出，部分事件只写入了日志文件", zap.Int64("count", n))
+	}
+}
+
+// Log 记录请求中发生的事件
+func Log(c *gin.Context, e Event) {
+	entry := &model.AuditLog{
+		CreatedAt:  time.Now(),
+		Actor:      e.Actor,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   truncate(e.TargetID, 1000),
+		Result:     e.Result,
+		IP:         c.ClientIP(),
+		UserAgent:  truncate(c.GetHeader("User-Agent"), 500),
+	}
+	if entry.Result == "" {
+		entry.Result = model.AuditSuccess
+	}
+	if e.ActorID > 0 {
+		id := e.ActorID
+		entry.ActorID = &id
+	}
+
+	detail := e.Detail
+	if payload := currentUser(c); payload != nil {
+		if entry.ActorID == nil {
+			id := uint(payload.UserID)
+			entry.ActorID = &id
+		}
+		if entry.Actor == "" {
+			entry.Actor = payload.Username
+		}
+		entry.DeviceID = payload.DeviceID
+		if keyID, ok := payload.ExtraData["api_key_id"]; ok {
+			if detail == nil {
+				detail = make(map[string]interface{}, 1)
+			}
+			detail["api_key_id"] = keyID
+		}
+	}
+	if entry.DeviceID == "" {
+		entry.DeviceID = c.GetHeader("X-Device-ID")
+	}
+	entry.DeviceID = truncate(entry.DeviceID, 100)
+	entry.Actor = truncate(entry.Actor, 100)
+
+	if len(detail) > 0 {
+		if data, err := json.Marshal(detail); err == nil {
+			entry.Detail = string(data)
+		}
+	}
+	Record(entry)
+}
+
+// Record 把事件放入写入队列（不阻塞）；未初始化或队列已满时写入日志文件
+func Record(entry *model.AuditLog) {
+	w := std.Load()
+	if w == nil {
+		fallback("审计日志未启用", entry)
+		return
+	}
+	select {
+	case w.queue <- entry:
+	default:
+		w.dropped.Add(1)
+		fallback("审计队列已满", entry)
+	}
+}
+
+// run 攒够一批或到达刷新间隔时写库
+func (w *writer) run() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	pending := make([]*model.AuditLog, 0, w.batch)
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := w.db.CreateInBatches(pending, w.batch).Error; err != nil {
+			logger.Error("写入审计日志失败", zap.Int("count", len(pending)), zap.Error(err))
+			for _, entry := range pending {
+				fallback("审计日志写库失败", entry)
+			}
+		}
+		pending = make([]*model.AuditLog, 0, w.batch)
+	}
+
+	for {
+		select {
+		case entry := <-w.queue:
+			pending = append(pending, entry)
+			if len(pending) >= w.batch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-w.done:
+			for {
+				select {
+				case entry := <-w.queue:
+					pending = append(pending, entry)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// retain 每小时删除超过保留期的记录（删除需要打开 audit.purge，见 sql/audit_log.sql）
+func (w *writer) retain(days int) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		cutoff := time.Now().AddDate(0, 0, -days)
+		if n, err := Purge(w.db, cutoff); err != nil {
+			logger.Error("清理过期审计日志失败", zap.Error(err))
+		} else if n > 0 {
+			logger.Info("已清理过期审计日志", zap.Int64("count", n), zap.Time("before", cutoff))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Purge 删除 before 之前的审计日志，返回删除条数
+func Purge(db *gorm.DB, before time.Time) (int64, error) {
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL audit.purge = 'on'").Error; err != nil {
+			return err
+		}
+		result := tx.Where("created_at < ?", before).Delete(&model.AuditLog{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// Anonymise 清空用户的审计日志中的可识别信息（操作人名称、IP、User-Agent、设备标识），保留事件本身和操作人ID；
+// loginKeys 为用户的用户名、邮箱等登录标识，用于匹配未识别身份（如登录失败）的记录。
+// 修改需要打开 audit.anonymise，见 sql/audit_log.sql
+func Anonymise(db *gorm.DB, userID uint, loginKeys ...string) (int64, error) {
+	keys := make([]string, 0, len(loginKeys))
+	for _, k := range loginKeys {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+
+	var updated int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL audit.anonymise = 'on'").Error; err != nil {
+			return err
+		}
+		query := tx.Model(&model.AuditLog{}).Where("actor_id = ?", userID)
+		if len(keys) > 0 {
+			query = query.Or("actor_id IS NULL AND actor IN ?", keys)
+		}
+		result := query.Updates(map[string]interface{}{"actor": "", "ip": "", "user_agent": "", "device_id": ""})
+		updated = result.RowsAffected
+		return result.Error
+	})
+	return updated, err
+}
+
+// fallback 无法写库的事件写入日志文件
+func fallback(reason string, entry *model.AuditLog) {
+	fields := []zap.Field{
+		zap.String("action", entry.Action),
+		zap.String("result", entry.Result),
+		zap.String("actor", entry.Actor),
+		zap.String("target_type", entry.TargetType),
+		zap.String("target_id", entry.TargetID),
+		zap.String("ip", entry.IP),
+		zap.String("device_id", entry.DeviceID),
+		zap.String("detail", entry.Detail),
+		zap.Time("time", entry.CreatedAt),
+	}
+	if entry.ActorID != nil {
+		fields = append(fields, zap.Uint("actor_id", *entry.ActorID))
+	}
+	logger.Warn(reason, fields...)
+}
+
+func currentUser(c *gin.Context) *token.TokenPayload {
+	if !c.GetBool("Auth") {
+		return nil
+	}
+	payload, _ := c.Get("UserInfo")
+	p, _ := payload.(*token.TokenPayload)
+	return p
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
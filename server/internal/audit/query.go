@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/model"
+)
+
+// Filter 审计日志查询条件，零值字段不参与过滤
+type Filter struct {
+	Subject    uint      `json:"-"` // 与该用户有关：本人操作或以该用户为对象（"我的账号活动"）
+	ActorID    uint      `json:"actor_id"`
+	Action     string    `json:"action"` // 精确匹配；以 "." 结尾时按前缀匹配（如 "file."）
+	Result     string    `json:"result"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	IP         string    `json:"ip"`
+	DeviceID   string    `json:"device_id"`
+	Since      time.Time `json:"since"`
+	Until      time.Time `json:"until"`
+}
+
+func (f Filter) apply(db *gorm.DB) *gorm.DB {
+	query := db.Model(&model.AuditLog{})
+	if f.Subject > 0 {
+		query = query.Where("(actor_id = ? OR (target_type = ? AND target_id = ?))",
+			f.Subject, TargetUser, strconv.FormatUint(uint64(f.Subject), 10))
+	}
+	if f.ActorID > 0 {
+		query = query.Where("actor_id = ?", f.ActorID)
+	}
+	if f.Action != "" {
+		if f.Action[len(f.Action)-1] == '.' {
+			query = query.Where("action LIKE ?", f.Action+"%")
+		} else {
+			query = query.Where("action = ?", f.Action)
+		}
+	}
+	if f.Result != "" {
+		query = query.Where("result = ?", f.Result)
+	}
+	if f.TargetType != "" {
+		query = query.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != "" {
+		query = query.Where("target_id = ?", f.TargetID)
+	}
+	if f.IP != "" {
+		query = query.Where("ip = ?", f.IP)
+	}
+	if f.DeviceID != "" {
+		query = query.Where("device_id = ?", f.DeviceID)
+	}
+	if !f.Since.IsZero() {
+		query = query.Where("created_at >= ?", f.Since)
+	}
+	if !f.Until.IsZero() {
+		query = query.Where("created_at < ?", f.Until)
+	}
+	return query
+}
+
+// Query 按条件分页查询，按时间倒序
+func Query(db *gorm.DB, f Filter, page, pageSize int) ([]model.AuditLog, int64, error) {
+	var total int64
+	if err := f.apply(db).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []model.AuditLog
+	err := f.apply(db).
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&logs).Error
+	return logs, total, err
+}
+
+// exportBatch 导出时每次从数据库读取的条数
+const exportBatch = 500
+
+// Export 按时间正序把符合条件的记录逐行写成 JSONL，返回写出的条数
+func Export(db *gorm.DB, f Filter, w io.Writer) (int, error) {
+	enc := json.NewEncoder(w)
+	count := 0
+	var batch []model.AuditLog
+	err := f.apply(db).Order("id").FindInBatches(&batch, exportBatch, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := enc.Encode(&batch[i]); err != nil {
+				return err
+			}
+		}
+		count += len(batch)
+		return nil
+	}).Error
+	return count, err
+}
@@ -2,6 +2,7 @@ package base
 
 import (
 	"github.com/sunyuanling/server/pkg/response"
+	tokenPkg "github.com/sunyuanling/server/pkg/tokn"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -56,3 +57,25 @@ func (h *BaseHandler) GetDB() *gorm.DB {
 func (h *BaseHandler) GetRedis() *redis.Client {
 	return h.Redis
 }
+
+// CurrentUser 获取当前登录用户的 token 载荷
+// 未登录或载荷异常时直接写入错误响应并返回 false
+func (h *BaseHandler) CurrentUser(c *gin.Context) (*tokenPkg.TokenPayload, bool) {
+	if !c.GetBool("Auth") {
+		response.Unauthorized(c, "请先登录")
+		return nil, false
+	}
+
+	payloadRaw, exists := c.Get("UserInfo")
+	if !exists || payloadRaw == nil {
+		response.InternalError(c, "用户信息获取失败")
+		return nil, false
+	}
+
+	payload, ok := payloadRaw.(*tokenPkg.TokenPayload)
+	if !ok {
+		response.InternalError(c, "用户信息类型错误")
+		return nil, false
+	}
+	return payload, true
+}
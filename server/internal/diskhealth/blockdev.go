@@ -0,0 +1,176 @@
+package diskhealth
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// sysfs 路径，仅 Linux 有效
+const (
+	sysClassBlock = "/sys/class/block"
+	sysFsExt4     = "/sys/fs/ext4"
+)
+
+// maxStackDepth 分区 -> LVM -> RAID 等层叠设备的最大解析深度
+const maxStackDepth = 8
+
+// BlockDevices 分区、LVM 逻辑卷、软 RAID 等所在的物理磁盘（内核名，如 sda、nvme0n1）
+// device 可以是 /dev/sda1、/dev/mapper/vg-root 或内核名；非 Linux 返回 nil
+func BlockDevices(device string) []string {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	name := kernelName(device)
+	if name == "" {
+		return nil
+	}
+
+	var out []string
+	seen := make(map[string]bool)
+	for _, d := range resolveDisks(name, 0) {
+		if !seen[d] && !virtualDevice(d) {
+			seen[d] = true
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// kernelName 设备路径对应的内核名（/dev/mapper/vg-root 为指向 dm-0 的软链接）
+func kernelName(device string) string {
+	if !strings.HasPrefix(device, "/dev/") {
+		return device
+	}
+	if real, err := filepath.EvalSymlinks(device); err == nil {
+		device = real
+	}
+	return filepath.Base(device)
+}
+
+// resolveDisks 沿 sysfs 找到物理磁盘：分区取上级目录，dm/md 设备取 slaves 下的成员
+func resolveDisks(name string, depth int) []string {
+	if depth > maxStackDepth {
+		return nil
+	}
+	sys := filepath.Join(sysClassBlock, name)
+	if _, err := os.Stat(sys); err != nil {
+		// 没有 sysfs（容器内等），按命名规则去掉分区号
+		return []string{TrimPartition(name)}
+	}
+
+	// 分区：/sys/class/block/sda1 -> .../block/sda/sda1
+	if _, err := os.Stat(filepath.Join(sys, "partition")); err == nil {
+		if real, err := filepath.EvalSymlinks(sys); err == nil {
+			return resolveDisks(filepath.Base(filepath.Dir(real)), depth+1)
+		}
+		return []string{TrimPartition(name)}
+	}
+
+	// LVM、dm-crypt、软 RAID：由 slaves 下的设备组成
+	slaves, _ := os.ReadDir(filepath.Join(sys, "slaves"))
+	if len(slaves) > 0 {
+		var out []string
+		for _, s := range slaves {
+			out = append(out, resolveDisks(s.Name(), depth+1)...)
+		}
+		return out
+	}
+	return []string{name}
+}
+
+// TrimPartition 按内核命名规则去掉分区号：sda1 -> sda，nvme0n1p2 -> nvme0n1，mmcblk0p1 -> mmcblk0
+func TrimPartition(name string) string {
+	trimmed := strings.TrimRight(name, "0123456789")
+	if trimmed == name || trimmed == "" {
+		return name
+	}
+	// nvme0n1、mmcblk0 这类以数字结尾的磁盘，分区号前有 p
+	if strings.HasPrefix(name, "nvme") || strings.HasPrefix(name, "mmcblk") || strings.HasPrefix(name, "loop") {
+		if strings.HasSuffix(trimmed, "p") {
+			base := strings.TrimSuffix(trimmed, "p")
+			if base != "" && base[len(base)-1] >= '0' && base[len(base)-1] <= '9' {
+				return base
+			}
+		}
+		return name
+	}
+	return trimmed
+}
+
+// virtualDevice 没有 SMART 数据的虚拟块设备
+func virtualDevice(name string) bool {
+	for _, prefix := range []string{"loop", "ram", "zram", "sr", "fd", "nbd", "dm-", "md"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rotational 是否为机械盘（/sys/class/block/<disk>/queue/rotational），读取失败时 ok 为 false
+func Rotational(disk string) (rotational bool, ok bool) {
+	data, err := os.ReadFile(filepath.Join(sysClassBlock, disk, "queue", "rotational"))
+	if err != nil {
+		return false, false
+	}
+	return strings.TrimSpace(string(data)) == "1", true
+}
+
+// IsSSD 分区所在的物理磁盘是否全部为固态盘
+func IsSSD(device string) bool {
+	disks := BlockDevices(device)
+	if len(disks) == 0 {
+		return false
+	}
+	for _, d := range disks {
+		rotational, ok := Rotational(d)
+		if !ok || rotational {
+			return false
+		}
+	}
+	return true
+}
+
+// sysTemperature 从 hwmon 读取磁盘温度（NVMe 和加载了 drivetemp 的 SATA 盘），没有时返回 nil
+func sysTemperature(disk string) *float64 {
+	patterns := []string{
+		filepath.Join(sysClassBlock, disk, "device", "hwmon", "hwmon*", "temp1_input"),
+		filepath.Join(sysClassBlock, disk, "device", "hwmon*", "temp1_input"),
+		// NVMe 命名空间的 device 指向控制器
+		filepath.Join(sysClassBlock, disk, "device", "device", "hwmon", "hwmon*", "temp1_input"),
+	}
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			if v, ok := readInt(m); ok {
+				t := float64(v) / 1000 // 毫摄氏度
+				return &t
+			}
+		}
+	}
+	return nil
+}
+
+// FilesystemErrors ext4 文件系统自挂载以来记录的错误数（/sys/fs/ext4/<分区>/errors_count）
+// 其他文件系统或读取失败时 ok 为 false
+func FilesystemErrors(device string) (count int64, ok bool) {
+	if runtime.GOOS != "linux" {
+		return 0, false
+	}
+	return readInt(filepath.Join(sysFsExt4, kernelName(device), "errors_count"))
+}
+
+func readInt(path string) (int64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
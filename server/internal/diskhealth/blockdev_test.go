@@ -0,0 +1,38 @@
+package diskhealth
+
+import "testing"
+
+func TestTrimPartition(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		// SATA/SAS/virtio：分区号直接跟在磁盘名后
+		{"sda", "sda"},
+		{"sda1", "sda"},
+		{"sdb12", "sdb"},
+		{"sdaa3", "sdaa"},
+		{"vda2", "vda"},
+		{"xvdb1", "xvdb"},
+		{"hdc5", "hdc"},
+
+		// 以数字结尾的磁盘名，分区号前有 p
+		{"nvme0n1", "nvme0n1"},
+		{"nvme0n1p1", "nvme0n1"},
+		{"nvme10n2p15", "nvme10n2"},
+		{"mmcblk0", "mmcblk0"},
+		{"mmcblk0p1", "mmcblk0"},
+		{"mmcblk1boot0", "mmcblk1boot0"},
+		{"loop0", "loop0"},
+		{"loop0p1", "loop0"},
+
+		// 不能识别的保持原样
+		{"", ""},
+		{"123", "123"},
+	}
+	for _, tt := range tests {
+		if got := TrimPartition(tt.name); got != tt.want {
+			t.Errorf("TrimPartition(%q) = %q, 期望 %q", tt.name, got, tt.want)
+		}
+	}
+}
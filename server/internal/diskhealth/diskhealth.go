@@ -0,0 +1,316 @@
+// Package diskhealth 磁盘健康：定时通过 smartctl 读取各物理磁盘的 SMART 数据，
+// 记录温度、通电时间、坏扇区等随时间的变化，并给出健康状态
+//
+// 仅 Linux 采集。没有 smartctl（或没有权限）时只读取 /sys 中的温度；
+// 分区、LVM、软 RAID 通过 sysfs 映射到所在的物理磁盘。
+package diskhealth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/smart"
+)
+
+// 健康状态，与磁盘信息接口的 health_status.status 一致
+const (
+	StatusHealthy  = "healthy"
+	StatusWarning  = "warning"
+	StatusCritical = "critical"
+)
+
+// 单块磁盘读取 SMART 的超时
+const readTimeout = time.Minute
+
+// maxHistory 单次查询最多返回的记录数
+const maxHistory = 5000
+
+var ErrDisabled = errors.New("磁盘健康采集未启用")
+
+// Health 一块物理磁盘的健康状况
+type Health struct {
+	Device            string        `json:"device"`             // 内核名（如 sda、nvme0n1）
+	SSD               bool          `json:"ssd"`                // 是否为固态盘
+	Smart             *smart.Report `json:"smart"`              // SMART 数据，没有 smartctl 或读取失败时为空
+	SmartError        string        `json:"smart_error"`        // 读取 SMART 失败的原因
+	Temperature       *float64      `json:"temperature"`        // 温度（℃），SMART 优先，其次 hwmon
+	ReallocatedGrowth *int64        `json:"reallocated_growth"` // trendDays 内新增的重映射扇区
+	Status            string        `json:"status"`             // healthy/warning/critical
+	Problems          []string      `json:"problems"`           // 发现的问题
+	CheckedAt         time.Time     `json:"checked_at"`         // 采集时间
+}
+
+// DiskID 磁盘标识：序列号，没有时为设备名
+func (h *Health) DiskID() string {
+	if h.Smart != nil && h.Smart.Serial != "" {
+		return h.Smart.Serial
+	}
+	return h.Device
+}
+
+// service 采集服务
+type service struct {
+	db       *gorm.DB
+	cfg      config.DiskHealthConfig
+	smartctl smart.Smartctl
+
+	mu     sync.RWMutex
+	latest map[string]*Health
+
+	warned map[string]bool // 只在采集协程中使用
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+var std *service
+
+// Init 启动采集协程，非 Linux 或采集间隔小于 0 时不启动
+func Init(db *gorm.DB, cfg config.DiskHealthConfig) {
+	if cfg.IntervalMinutes < 0 {
+		logger.Info("磁盘健康采集已关闭")
+		return
+	}
+	if runtime.GOOS != "linux" {
+		logger.Info("磁盘健康采集仅支持 Linux", zap.String("os", runtime.GOOS))
+		return
+	}
+
+	s := &service{
+		db:       db,
+		cfg:      cfg,
+		smartctl: smart.Smartctl{Path: cfg.Smartctl},
+		latest:   make(map[string]*Health),
+		warned:   make(map[string]bool),
+		done:     make(chan struct{}),
+	}
+	if path, err := s.smartctl.Available(); err != nil {
+		logger.Warn("未找到 smartctl，只采集温度（安装 smartmontools 后可读取 SMART 数据）", zap.Error(err))
+	} else {
+		logger.Info("磁盘健康采集已启动", zap.String("smartctl", path), zap.Int("interval_minutes", cfg.IntervalMinutes))
+	}
+
+	s.wg.Add(1)
+	go s.run()
+	std = s
+}
+
+// Close 停止采集
+func Close() {
+	if std == nil {
+		return
+	}
+	close(std.done)
+	std.wg.Wait()
+}
+
+// Latest 各物理磁盘最近一次的健康状况，按设备名排序
+func Latest() ([]Health, error) {
+	if std == nil {
+		return nil, ErrDisabled
+	}
+	std.mu.RLock()
+	defer std.mu.RUnlock()
+	out := make([]Health, 0, len(std.latest))
+	for _, h := range std.latest {
+		out = append(out, *h)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Device < out[j].Device })
+	return out, nil
+}
+
+// ForDevice 分区（如 /dev/sda1、/dev/mapper/vg-root）所在物理磁盘的健康状况，未启用或还没有采样时返回 nil
+func ForDevice(device string) []Health {
+	if std == nil {
+		return nil
+	}
+	disks := BlockDevices(device)
+	std.mu.RLock()
+	defer std.mu.RUnlock()
+	var out []Health
+	for _, d := range disks {
+		if h, ok := std.latest[d]; ok {
+			out = append(out, *h)
+		}
+	}
+	return out
+}
+
+// History 一块磁盘（序列号或设备名）since 之后的采样，按时间升序
+func History(ctx context.Context, diskID string, since time.Time) ([]model.DiskHealthSample, error) {
+	if std == nil {
+		return nil, ErrDisabled
+	}
+	var samples []model.DiskHealthSample
+	err := std.db.WithContext(ctx).
+		Where("(disk_id = ? OR device = ?) AND created_at >= ?", diskID, diskID, since).
+		Order("created_at").
+		Limit(maxHistory).
+		Find(&samples).Error
+	return samples, err
+}
+
+// run 采集主循环，启动后立即采集一次
+func (s *service) run() {
+	defer s.wg.Done()
+
+	s.collect()
+	ticker := time.NewTicker(time.Duration(s.cfg.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.collect()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// collect 采集全部物理磁盘，保存采样并清理过期记录
+func (s *service) collect() {
+	disks, err := physicalDisks()
+	if err != nil {
+		s.warn("list", err)
+		return
+	}
+
+	now := time.Now()
+	latest := make(map[string]*Health, len(disks))
+	for _, d := range disks {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+		h, ok := s.check(d, now)
+		if ok {
+			latest[d] = h
+		}
+	}
+
+	s.mu.Lock()
+	s.latest = latest
+	s.mu.Unlock()
+
+	if s.cfg.RetentionDays > 0 {
+		cutoff := now.AddDate(0, 0, -s.cfg.RetentionDays)
+		if err := s.db.Where("created_at < ?", cutoff).Delete(&model.DiskHealthSample{}).Error; err != nil {
+			logger.Warn("清理磁盘健康记录失败", zap.Error(err))
+		}
+	}
+}
+
+// check 采集一块磁盘；磁盘休眠时沿用上一次的结果，不写入采样
+func (s *service) check(disk string, now time.Time) (*Health, bool) {
+	h := &Health{Device: disk, CheckedAt: now}
+	if rotational, ok := Rotational(disk); ok {
+		h.SSD = !rotational
+	}
+
+	if _, err := s.smartctl.Available(); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), readTimeout)
+		report, err := s.smartctl.Read(ctx, "/dev/"+disk)
+		cancel()
+		switch {
+		case errors.Is(err, smart.ErrStandby):
+			s.mu.RLock()
+			prev, ok := s.latest[disk]
+			s.mu.RUnlock()
+			return prev, ok
+		case err != nil:
+			h.SmartError = err.Error()
+			s.warn("smart:"+disk, err)
+		default:
+			h.Smart = report
+			h.SSD = h.SSD || report.SSD()
+			h.Temperature = report.Temperature
+		}
+	}
+	if h.Temperature == nil {
+		h.Temperature = sysTemperature(disk)
+	}
+
+	if h.Smart != nil || h.Temperature != nil {
+		h.ReallocatedGrowth = s.reallocatedGrowth(h, now)
+		s.save(h, now)
+	}
+	evaluate(h, s.cfg)
+	return h, true
+}
+
+// reallocatedGrowth trendDays 内重映射扇区的增长（与窗口内最小值比较），没有历史时返回 nil
+func (s *service) reallocatedGrowth(h *Health, now time.Time) *int64 {
+	if h.Smart == nil || h.Smart.ReallocatedSectors == nil || s.cfg.TrendDays <= 0 {
+		return nil
+	}
+	var row struct {
+		Min *int64
+	}
+	err := s.db.Model(&model.DiskHealthSample{}).
+		Select("MIN(reallocated_sectors) AS min").
+		Where("disk_id = ? AND created_at >= ?", h.DiskID(), now.AddDate(0, 0, -s.cfg.TrendDays)).
+		Scan(&row).Error
+	if err != nil || row.Min == nil {
+		return nil
+	}
+	growth := *h.Smart.ReallocatedSectors - *row.Min
+	return &growth
+}
+
+// save 写入一条采样
+func (s *service) save(h *Health, now time.Time) {
+	sample := model.DiskHealthSample{
+		DiskID:      h.DiskID(),
+		Device:      h.Device,
+		Temperature: h.Temperature,
+		CreatedAt:   now,
+	}
+	if r := h.Smart; r != nil {
+		sample.Model = r.Model
+		sample.SmartPassed = r.Passed
+		sample.PowerOnHours = r.PowerOnHours
+		sample.ReallocatedSectors = r.ReallocatedSectors
+		sample.PendingSectors = r.PendingSectors
+		sample.UncorrectableSectors = r.UncorrectableSectors
+		sample.MediaErrors = r.MediaErrors
+		sample.PercentageUsed = r.PercentageUsed
+	}
+	if err := s.db.Create(&sample).Error; err != nil {
+		logger.Warn("保存磁盘健康记录失败", zap.String("device", h.Device), zap.Error(err))
+	}
+}
+
+// physicalDisks /sys/block 下的物理磁盘（跳过 loop、ram、dm、md 等虚拟设备）
+func physicalDisks() ([]string, error) {
+	entries, err := os.ReadDir("/sys/block")
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, e := range entries {
+		if !virtualDevice(e.Name()) {
+			out = append(out, e.Name())
+		}
+	}
+	return out, nil
+}
+
+// warn 同一问题只记录一次警告，避免每个采集周期刷日志
+func (s *service) warn(item string, err error) {
+	if s.warned[item] {
+		return
+	}
+	s.warned[item] = true
+	logger.Warn("磁盘健康采集失败", zap.String("item", item), zap.Error(err))
+}
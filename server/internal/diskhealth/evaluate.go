@@ -0,0 +1,79 @@
+package diskhealth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sunyuanling/server/config"
+)
+
+// evaluate 根据 SMART 数据、温度和坏扇区增长给出健康状态和问题列表
+func evaluate(h *Health, cfg config.DiskHealthConfig) {
+	h.Status = StatusHealthy
+	h.Problems = nil
+	add := func(status, format string, args ...interface{}) {
+		h.Status = Worse(h.Status, status)
+		h.Problems = append(h.Problems, fmt.Sprintf(format, args...))
+	}
+
+	if r := h.Smart; r != nil {
+		if r.Passed != nil && !*r.Passed {
+			add(StatusCritical, "SMART 自检未通过，磁盘可能即将损坏")
+		}
+		if len(r.FailingAttributes) > 0 {
+			add(StatusCritical, "SMART 属性低于阈值：%s", strings.Join(r.FailingAttributes, ", "))
+		}
+		if r.CriticalWarning != 0 {
+			add(StatusCritical, "NVMe 报告严重警告（0x%02x）", r.CriticalWarning)
+		}
+		if v := r.UncorrectableSectors; v != nil && *v > 0 {
+			add(StatusCritical, "%d 个无法校正的扇区", *v)
+		}
+		if v := r.MediaErrors; v != nil && *v > 0 {
+			add(StatusCritical, "%d 次介质错误", *v)
+		}
+		if v := r.PendingSectors; v != nil && *v > 0 {
+			add(StatusWarning, "%d 个扇区等待重映射", *v)
+		}
+		if v := r.ReallocatedSectors; v != nil && *v > 0 {
+			add(StatusWarning, "已重映射 %d 个扇区", *v)
+		}
+		if v := r.PercentageUsed; v != nil {
+			if *v >= 100 {
+				add(StatusCritical, "固态盘寿命已耗尽（%d%%）", *v)
+			} else if *v >= 90 {
+				add(StatusWarning, "固态盘寿命已用 %d%%", *v)
+			}
+		}
+	}
+
+	if v := h.ReallocatedGrowth; v != nil && *v > 0 {
+		add(StatusWarning, "%d 天内新增 %d 个重映射扇区", cfg.TrendDays, *v)
+	}
+
+	if t := h.Temperature; t != nil {
+		if *t >= cfg.TemperatureCritical {
+			add(StatusCritical, "温度过高（%.0f℃）", *t)
+		} else if *t >= cfg.TemperatureWarning {
+			add(StatusWarning, "温度偏高（%.0f℃）", *t)
+		}
+	}
+}
+
+// Worse 两个健康状态中较差的一个
+func Worse(a, b string) string {
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+func rank(status string) int {
+	switch status {
+	case StatusCritical:
+		return 2
+	case StatusWarning:
+		return 1
+	}
+	return 0
+}
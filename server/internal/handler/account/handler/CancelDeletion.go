@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/account"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/account/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/middleware"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/ratelimit"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type cancelDeletion struct {
+	*base.BaseHandler
+	accountLimiter *ratelimit.Limiter // 与登录共用按账号的失败计数，不能借此绕过登录锁定
+}
+
+func NewCancelDeletion(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.CancelDeletion {
+	return &cancelDeletion{
+		BaseHandler:    base.NewBaseHandler(db, redis),
+		accountLimiter: ratelimit.New(redis, ratelimit.LoginAccount(cfg.Security)),
+	}
+}
+
+// HandlerPOST 撤销注销申请（无需登录）：用户名或邮箱 + 密码（+ 两步验证）确认身份
+func (h *cancelDeletion) HandlerPOST(c *gin.Context) {
+	var req struct {
+		credentials
+		Username string `json:"username"`
+		Email    string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Username == "" && req.Email == "") {
+		response.BadRequest(c, "请提供用户名或邮箱和密码")
+		return
+	}
+	loginKey := strings.ToLower(req.Username)
+	if loginKey == "" {
+		loginKey = strings.ToLower(req.Email)
+	}
+
+	ctx := c.Request.Context()
+	if status, err := h.accountLimiter.Check(ctx, loginKey); err != nil {
+		logger.Error("限流检查失败", zap.Error(err))
+	} else if !status.Allowed {
+		middleware.RejectRateLimited(c, status, "失败次数过多，请稍后再试")
+		return
+	}
+
+	query := h.DB.Where("status = ?", model.StatusPendingDeletion)
+	if req.Username != "" {
+		query = query.Where("username = ?", req.Username)
+	} else {
+		query = query.Where("email = ?", req.Email)
+	}
+	var user model.User
+	err := query.First(&user).Error
+	if err == nil {
+		err = req.verify(h.DB, &user)
+	}
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) && !isCredentialError(err) {
+			logger.Error("撤销注销时验证身份失败", zap.String("login_key", loginKey), zap.Error(err))
+			response.InternalError(c, "撤销失败")
+			return
+		}
+		if _, herr := h.accountLimiter.Hit(ctx, loginKey); herr != nil {
+			logger.Error("记录失败次数失败", zap.Error(herr))
+		}
+		var detail map[string]interface{}
+		if user.ID > 0 {
+			detail = map[string]interface{}{"reason": err.Error()}
+		}
+		audit.Log(c, audit.Event{
+			Action:     audit.ActionDeleteCancel,
+			Result:     model.AuditFailure,
+			ActorID:    user.ID,
+			Actor:      loginKey,
+			TargetType: audit.TargetUser,
+			TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+			Detail:     detail,
+		})
+		if errors.Is(err, errNeedTwoFactor) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		// 账号不存在、不在冷静期和密码错误返回同样的结果
+		response.Unauthorized(c, "账号、密码错误或没有待撤销的注销申请")
+		return
+	}
+
+	if err := account.CancelDeletion(h.DB, user.ID); err != nil {
+		if errors.Is(err, account.ErrNoDeletion) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("撤销注销申请失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		response.InternalError(c, "撤销失败")
+		return
+	}
+	if err := h.accountLimiter.Reset(ctx, loginKey); err != nil {
+		logger.Error("清除失败记录失败", zap.Error(err))
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionDeleteCancel,
+		ActorID:    user.ID,
+		Actor:      user.Username,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+	})
+	logger.Info("用户撤销注销申请", zap.Uint("user_id", user.ID), zap.String("ip", c.ClientIP()))
+	response.SuccessWithMsg(c, "已撤销注销申请，请重新登录", nil)
+}
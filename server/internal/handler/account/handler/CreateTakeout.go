@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/account/interface"
+	"github.com/sunyuanling/server/internal/takeout"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type createTakeout struct {
+	*base.BaseHandler
+}
+
+func NewCreateTakeout(db *gorm.DB, redis *redis.Client) _interface.CreateTakeout {
+	return &createTakeout{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 发起数据导出，任务在后台执行，进度通过 WebSocket 推送（event=takeout_progress）
+func (h *createTakeout) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	job, err := takeout.Start(userID)
+	if err != nil {
+		if errors.Is(err, takeout.ErrJobRunning) {
+			response.TooManyRequests(c, err.Error())
+			return
+		}
+		logger.Error("创建导出任务失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "创建导出任务失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionTakeoutCreate,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(userID), 10),
+		Detail:     map[string]interface{}{"job_id": job.ID},
+	})
+	logger.Info("发起数据导出", zap.Uint("user_id", userID), zap.Uint("job_id", job.ID))
+	response.SuccessWithMsg(c, "导出任务已创建，完成后可下载", job)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/account/interface"
+	"github.com/sunyuanling/server/internal/takeout"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type downloadTakeout struct {
+	*base.BaseHandler
+}
+
+func NewDownloadTakeout(db *gorm.DB, redis *redis.Client) _interface.DownloadTakeout {
+	return &downloadTakeout{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 下载已完成的导出压缩包（只能下载自己的）
+func (h *downloadTakeout) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req struct {
+		ID uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	job, err := takeout.Get(h.DB, userID, req.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "导出任务不存在")
+			return
+		}
+		logger.Error("查询导出任务失败", zap.Uint("job_id", req.ID), zap.Error(err))
+		response.InternalError(c, "查询导出任务失败")
+		return
+	}
+	if !job.IsDownloadable() {
+		response.BadRequest(c, "导出尚未完成或压缩包已过期")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionTakeoutFetch,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(userID), 10),
+		Detail:     map[string]interface{}{"job_id": job.ID, "size": job.ArchiveSize},
+	})
+	c.FileAttachment(job.ArchivePath, fmt.Sprintf("takeout-%s-%d.zip", payload.Username, job.ID))
+}
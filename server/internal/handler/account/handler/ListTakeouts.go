@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/account/interface"
+	"github.com/sunyuanling/server/internal/takeout"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listTakeouts struct {
+	*base.BaseHandler
+}
+
+func NewListTakeouts(db *gorm.DB, redis *redis.Client) _interface.ListTakeouts {
+	return &listTakeouts{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 我最近的导出任务
+func (h *listTakeouts) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	jobs, err := takeout.List(h.DB, uint(payload.UserID))
+	if err != nil {
+		logger.Error("查询导出任务失败", zap.Int64("user_id", payload.UserID), zap.Error(err))
+		response.InternalError(c, "查询导出任务失败")
+		return
+	}
+	response.Success(c, gin.H{"list": jobs})
+}
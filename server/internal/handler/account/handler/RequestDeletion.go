@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/account"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/account/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type requestDeletion struct {
+	*base.BaseHandler
+	cfg *config.Config
+}
+
+func NewRequestDeletion(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.RequestDeletion {
+	return &requestDeletion{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+	}
+}
+
+// HandlerPOST 申请注销账号：再次验证密码（和两步验证）后进入冷静期，所有设备立即退出登录
+func (h *requestDeletion) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req struct {
+		credentials
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请输入密码")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len([]rune(req.Reason)) > 500 {
+		response.BadRequest(c, "注销原因不能超过500个字符")
+		return
+	}
+
+	var user model.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		logger.Error("查询用户失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "申请注销失败")
+		return
+	}
+	if err := req.verify(h.DB, &user); err != nil {
+		if isCredentialError(err) {
+			audit.Log(c, audit.Event{
+				Action:     audit.ActionDeleteRequest,
+				Result:     model.AuditFailure,
+				TargetType: audit.TargetUser,
+				TargetID:   strconv.FormatUint(uint64(userID), 10),
+				Detail:     map[string]interface{}{"reason": err.Error()},
+			})
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("验证身份失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "申请注销失败")
+		return
+	}
+
+	deletion, err := account.RequestDeletion(h.DB, h.cfg, userID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, account.ErrDeletionPending), errors.Is(err, account.ErrNotActiveAccount):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, rbac.ErrLastAdmin):
+			response.BadRequest(c, "系统中唯一的管理员不能注销账号")
+		default:
+			logger.Error("申请注销失败", zap.Uint("user_id", userID), zap.Error(err))
+			response.InternalError(c, "申请注销失败")
+		}
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionDeleteRequest,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(userID), 10),
+		Detail:     map[string]interface{}{"purge_at": deletion.PurgeAt},
+	})
+	logger.Info("用户申请注销账号",
+		zap.Uint("user_id", userID),
+		zap.Time("purge_at", deletion.PurgeAt),
+	)
+	response.SuccessWithMsg(c, "已申请注销，冷静期结束后账号和数据将被彻底删除", gin.H{
+		"requested_at": deletion.RequestedAt,
+		"purge_at":     deletion.PurgeAt,
+	})
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/twofactor"
+	"github.com/sunyuanling/server/pkg/password"
+)
+
+var (
+	errBadCredentials = errors.New("密码错误")
+	errNeedTwoFactor  = errors.New("请输入两步验证码或恢复码")
+)
+
+// credentials 注销、撤销注销时的身份确认
+type credentials struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`          // 两步验证码（已启用两步验证时必填其一）
+	RecoveryCode string `json:"recovery_code"` // 恢复码
+}
+
+// verify 校验密码，已启用两步验证时同时校验验证码或恢复码
+func (cr *credentials) verify(db *gorm.DB, user *model.User) error {
+	if !password.VerifyPassword(user.Password, cr.Password) {
+		return errBadCredentials
+	}
+
+	enabled, err := twofactor.IsEnabled(db, user.ID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+	if cr.Code == "" && cr.RecoveryCode == "" {
+		return errNeedTwoFactor
+	}
+	return twofactor.Verify(db, user.ID, cr.Code, cr.RecoveryCode)
+}
+
+// isCredentialError 是否为身份确认失败（而不是服务器错误）
+func isCredentialError(err error) bool {
+	return errors.Is(err, errBadCredentials) || errors.Is(err, errNeedTwoFactor) ||
+		errors.Is(err, twofactor.ErrInvalidCode)
+}
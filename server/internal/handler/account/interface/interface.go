@@ -0,0 +1,28 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// CreateTakeout 发起数据导出
+type CreateTakeout interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListTakeouts 我的导出任务
+type ListTakeouts interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// DownloadTakeout 下载导出的压缩包
+type DownloadTakeout interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// RequestDeletion 申请注销账号
+type RequestDeletion interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// CancelDeletion 撤销注销申请
+type CancelDeletion interface {
+	HandlerPOST(c *gin.Context)
+}
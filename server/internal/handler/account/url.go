@@ -0,0 +1,44 @@
+package account
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/handler"
+	accountHandler "github.com/sunyuanling/server/internal/handler/account/handler"
+	"github.com/sunyuanling/server/middleware"
+	"github.com/sunyuanling/server/pkg/ratelimit"
+)
+
+// Router 账号数据导出和注销路由
+type Router struct {
+	cfg *config.Config
+}
+
+// NewRouter 创建账号模块路由（冷静期、回收站路径需要配置）
+func NewRouter(cfg *config.Config) handler.ModuleRouter {
+	return &Router{
+		cfg: cfg,
+	}
+}
+
+// RegisterRoutes 注册账号数据导出和注销相关路由
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	createTakeout := accountHandler.NewCreateTakeout(db, redis)
+	listTakeouts := accountHandler.NewListTakeouts(db, redis)
+	downloadTakeout := accountHandler.NewDownloadTakeout(db, redis)
+	requestDeletion := accountHandler.NewRequestDeletion(db, redis, r.cfg)
+	cancelDeletion := accountHandler.NewCancelDeletion(db, redis, r.cfg)
+
+	group.POST("/takeout/create", createTakeout.HandlerPOST)     // 发起数据导出
+	group.POST("/takeout/list", listTakeouts.HandlerPOST)        // 我的导出任务
+	group.POST("/takeout/download", downloadTakeout.HandlerPOST) // 下载压缩包
+
+	group.POST("/deletion/request", requestDeletion.HandlerPOST) // 申请注销（需要再次验证密码）
+
+	// 注销后无法登录，撤销时用账号密码验证身份；按IP限流（与找回密码共用额度），按账号计失败次数
+	cancelLimit := middleware.RateLimitByIP(ratelimit.New(redis, ratelimit.PasswordReset(r.cfg.Security)))
+	group.POST("/deletion/cancel", cancelLimit, cancelDeletion.HandlerPOST) // 撤销注销申请
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/account"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/admin/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type deleteUser struct {
+	*base.BaseHandler
+	cfg *config.Config
+}
+
+func NewDeleteUser(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.DeleteUser {
+	return &deleteUser{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+	}
+}
+
+// HandlerPOST 删除账号：可把共享、分组和文件记录转给其他用户，或同时删除其上传的文件
+func (h *deleteUser) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		UserID      uint `json:"user_id" binding:"required"`
+		TransferTo  uint `json:"transfer_to"`  // 接收数据的用户
+		DeleteFiles bool `json:"delete_files"` // 删除上传的文件
+	}
+	if !bindTarget(c, payload, &req, &req.UserID) {
+		return
+	}
+
+	// 注销冷静期内的账号先把回收站中的文件移回原位，再按本次选项处理
+	if pending, err := account.PendingDeletion(h.DB, req.UserID); err == nil && pending != nil {
+		if err := account.CancelDeletion(h.DB, req.UserID); err != nil {
+			logger.Error("撤销注销申请失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+			response.InternalError(c, "删除账号失败")
+			return
+		}
+	}
+
+	result, err := account.Delete(h.DB, req.UserID, account.DeleteOptions{
+		TransferTo:   req.TransferTo,
+		DeleteFiles:  req.DeleteFiles,
+		AllowedPaths: h.cfg.GetAllowedPaths(),
+	})
+	if err != nil {
+		if errors.Is(err, account.ErrTransferTarget) || errors.Is(err, account.ErrConflictingFiles) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if writeAccountError(c, err) {
+			return
+		}
+		logger.Error("删除账号失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "删除账号失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionUserDelete,
+		TargetType: audit.TargetUser,
+		TargetID:   idString(req.UserID),
+		Detail: map[string]interface{}{
+			"transfer_to":   req.TransferTo,
+			"delete_files":  req.DeleteFiles,
+			"files_removed": result.FilesRemoved,
+			"files_failed":  result.FilesFailed,
+		},
+	})
+	logger.Info("账号已删除",
+		zap.Int64("operator", payload.UserID),
+		zap.Uint("user_id", req.UserID),
+		zap.Uint("transfer_to", req.TransferTo),
+		zap.Int("files_removed", result.FilesRemoved),
+	)
+	response.SuccessWithMsg(c, "账号已删除", result)
+}
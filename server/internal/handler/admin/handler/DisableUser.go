@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/account"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/admin/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type disableUser struct {
+	*base.BaseHandler
+}
+
+func NewDisableUser(db *gorm.DB, redis *redis.Client) _interface.DisableUser {
+	return &disableUser{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 禁用账号，立即注销其全部会话并断开 WebSocket 连接
+func (h *disableUser) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	var req userIDReq
+	if !bindTarget(c, payload, &req, &req.UserID) {
+		return
+	}
+
+	revoked, err := account.Disable(h.DB, req.UserID)
+	if err != nil {
+		if writeAccountError(c, err) {
+			return
+		}
+		logger.Error("禁用账号失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "禁用账号失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionUserDisable,
+		TargetType: audit.TargetUser,
+		TargetID:   idString(req.UserID),
+		Detail:     map[string]interface{}{"sessions_revoked": revoked},
+	})
+	logger.Info("账号已禁用",
+		zap.Int64("operator", payload.UserID),
+		zap.Uint("user_id", req.UserID),
+		zap.Int("sessions_revoked", revoked),
+	)
+	response.SuccessWithMsg(c, "账号已禁用", gin.H{"sessions_revoked": revoked})
+}
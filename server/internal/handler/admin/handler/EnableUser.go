@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/account"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/admin/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type enableUser struct {
+	*base.BaseHandler
+}
+
+func NewEnableUser(db *gorm.DB, redis *redis.Client) _interface.EnableUser {
+	return &enableUser{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 启用账号
+func (h *enableUser) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	var req userIDReq
+	if !bindTarget(c, payload, &req, &req.UserID) {
+		return
+	}
+
+	if err := account.Enable(h.DB, req.UserID); err != nil {
+		if writeAccountError(c, err) {
+			return
+		}
+		logger.Error("启用账号失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "启用账号失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionUserEnable,
+		TargetType: audit.TargetUser,
+		TargetID:   idString(req.UserID),
+	})
+	logger.Info("账号已启用",
+		zap.Int64("operator", payload.UserID),
+		zap.Uint("user_id", req.UserID),
+	)
+	response.SuccessWithMsg(c, "账号已启用", nil)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/account"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/admin/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/mailer"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type forceResetPassword struct {
+	*base.BaseHandler
+	mailer mailer.Mailer
+	ttl    time.Duration
+}
+
+func NewForceResetPassword(db *gorm.DB, redis *redis.Client, m mailer.Mailer, cfg *config.Config) _interface.ForceResetPassword {
+	return &forceResetPassword{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		mailer:      m,
+		ttl:         time.Duration(cfg.Security.PasswordResetMinutes) * time.Minute,
+	}
+}
+
+// HandlerPOST 强制重置密码：原密码立即失效并注销全部会话，有邮箱时发送找回密码验证码
+func (h *forceResetPassword) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	var req userIDReq
+	if !bindTarget(c, payload, &req, &req.UserID) {
+		return
+	}
+
+	mailed, err := account.ForceReset(c.Request.Context(), h.DB, h.Redis, h.mailer, req.UserID, h.ttl)
+	if err != nil {
+		if writeAccountError(c, err) {
+			return
+		}
+		logger.Error("强制重置密码失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "重置密码失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionUserForceReset,
+		TargetType: audit.TargetUser,
+		TargetID:   idString(req.UserID),
+		Detail:     map[string]interface{}{"mail_sent": mailed},
+	})
+	logger.Info("已强制重置密码",
+		zap.Int64("operator", payload.UserID),
+		zap.Uint("user_id", req.UserID),
+		zap.Bool("mail_sent", mailed),
+	)
+	msg := "密码已重置，验证码已发送到用户邮箱"
+	if !mailed {
+		msg = "密码已重置，用户没有可用邮箱，需要管理员另行告知重置方式"
+	}
+	response.SuccessWithMsg(c, msg, gin.H{"mail_sent": mailed})
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/admin/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listUsers struct {
+	*base.BaseHandler
+}
+
+func NewListUsers(db *gorm.DB, redis *redis.Client) _interface.ListUsers {
+	return &listUsers{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// UserItem 用户列表项
+type UserItem struct {
+	ID        uint       `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	Phone     string     `json:"phone"`
+	Status    int16      `json:"status"`
+	Roles     []string   `json:"roles"` // 全局角色
+	LastLogin *time.Time `json:"last_login"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// HandlerPOST 用户列表：按用户名、邮箱、手机号模糊搜索，可按状态和全局角色筛选
+func (h *listUsers) HandlerPOST(c *gin.Context) {
+	var req struct {
+		pageReq
+		Keyword string `json:"keyword"`
+		Status  *int16 `json:"status"`
+		Role    string `json:"role"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "参数错误")
+			return
+		}
+	}
+	req.normalize()
+
+	query := h.DB.Model(&model.User{})
+	if kw := strings.TrimSpace(req.Keyword); kw != "" {
+		like := "%" + escapeLike(kw) + "%"
+		query = query.Where("username ILIKE ? OR email ILIKE ? OR phone ILIKE ?", like, like, like)
+	}
+	if req.Status != nil {
+		query = query.Where("status = ?", *req.Status)
+	}
+	if req.Role != "" {
+		// 有全局绑定时按绑定判断，没有任何绑定的旧账号按 user.role 判断
+		query = query.Where(`EXISTS (SELECT 1 FROM user_role ur JOIN role r ON r.id = ur.role_id
+			WHERE ur.user_id = "user".id AND ur.scope_path = '' AND r.role_code = ?)
+			OR (NOT EXISTS (SELECT 1 FROM user_role ur WHERE ur.user_id = "user".id) AND "user".role = ?)`,
+			req.Role, req.Role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.Error("查询用户总数失败", zap.Error(err))
+		response.InternalError(c, "查询用户失败")
+		return
+	}
+
+	var users []model.User
+	err := query.Order("id").
+		Offset((req.PageNum - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&users).Error
+	if err != nil {
+		logger.Error("查询用户列表失败", zap.Error(err))
+		response.InternalError(c, "查询用户失败")
+		return
+	}
+
+	roles, err := globalRoles(h.DB, users)
+	if err != nil {
+		logger.Error("查询用户角色失败", zap.Error(err))
+		response.InternalError(c, "查询用户失败")
+		return
+	}
+
+	items := make([]UserItem, 0, len(users))
+	for _, u := range users {
+		items = append(items, UserItem{
+			ID:        u.ID,
+			Username:  u.Username,
+			Email:     u.Email,
+			Phone:     u.Phone,
+			Status:    u.Status,
+			Roles:     roles[u.ID],
+			LastLogin: u.LastLogin,
+			CreatedAt: u.CreatedAt,
+		})
+	}
+
+	response.Success(c, gin.H{
+		"list":     items,
+		"total":    total,
+		"pageNum":  req.PageNum,
+		"pageSize": req.PageSize,
+	})
+}
+
+// globalRoles 批量查询用户的全局角色；没有任何绑定的旧账号取 user.role
+func globalRoles(db *gorm.DB, users []model.User) (map[uint][]string, error) {
+	result := make(map[uint][]string, len(users))
+	if len(users) == 0 {
+		return result, nil
+	}
+	ids := make([]uint, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+
+	var rows []struct {
+		UserID    uint
+		RoleCode  string
+		ScopePath string
+	}
+	err := db.Table("user_role AS ur").
+		Select("ur.user_id, r.role_code, ur.scope_path").
+		Joins("JOIN role r ON r.id = ur.role_id").
+		Where("ur.user_id IN ?", ids).
+		Order("ur.user_id, r.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	bound := make(map[uint]bool, len(rows))
+	for _, row := range rows {
+		bound[row.UserID] = true
+		if row.ScopePath == "" {
+			result[row.UserID] = append(result[row.UserID], row.RoleCode)
+		}
+	}
+	for _, u := range users {
+		if !bound[u.ID] && u.Role != "" {
+			result[u.ID] = []string{u.Role}
+		}
+		if result[u.ID] == nil {
+			result[u.ID] = []string{}
+		}
+	}
+	return result, nil
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/admin/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type setUserRoles struct {
+	*base.BaseHandler
+}
+
+func NewSetUserRoles(db *gorm.DB, redis *redis.Client) _interface.SetUserRoles {
+	return &setUserRoles{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 覆盖用户的全局角色（目录范围的绑定不受影响，仍在 /api/rbac 中管理）
+func (h *setUserRoles) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID uint     `json:"user_id" binding:"required"`
+		Roles  []string `json:"roles" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	var count int64
+	if err := h.DB.Model(&model.User{}).Where("id = ?", req.UserID).Count(&count).Error; err != nil {
+		logger.Error("查询用户失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "修改角色失败")
+		return
+	}
+	if count == 0 {
+		response.NotFound(c, "用户不存在")
+		return
+	}
+
+	if err := rbac.SetGlobalRoles(h.DB, req.UserID, req.Roles, uint(payload.UserID)); err != nil {
+		if errors.Is(err, rbac.ErrRoleNotFound) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if writeAccountError(c, err) {
+			return
+		}
+		logger.Error("修改用户角色失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "修改角色失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionUserRoles,
+		TargetType: audit.TargetUser,
+		TargetID:   idString(req.UserID),
+		Detail:     map[string]interface{}{"roles": req.Roles},
+	})
+	logger.Info("用户角色已修改",
+		zap.Int64("operator", payload.UserID),
+		zap.Uint("user_id", req.UserID),
+		zap.Strings("roles", req.Roles),
+	)
+	response.SuccessWithMsg(c, "角色已修改", gin.H{"roles": req.Roles})
+}
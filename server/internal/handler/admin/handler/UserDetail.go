@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/account"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/admin/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type userDetail struct {
+	*base.BaseHandler
+}
+
+func NewUserDetail(db *gorm.DB, redis *redis.Client) _interface.UserDetail {
+	return &userDetail{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 用户详情：基本信息、全局角色、设备列表和用量
+func (h *userDetail) HandlerPOST(c *gin.Context) {
+	var req userIDReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	var user model.User
+	if err := h.DB.First(&user, req.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "用户不存在")
+			return
+		}
+		logger.Error("查询用户失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "查询用户失败")
+		return
+	}
+
+	roles, err := globalRoles(h.DB, []model.User{user})
+	if err != nil {
+		logger.Error("查询用户角色失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "查询用户失败")
+		return
+	}
+
+	var devices []model.Device
+	if err := h.DB.Where("user_id = ?", user.ID).Order("last_active DESC NULLS LAST, id").Find(&devices).Error; err != nil {
+		logger.Error("查询用户设备失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "查询用户失败")
+		return
+	}
+
+	usage, err := account.GetUsage(h.DB, user.ID)
+	if err != nil {
+		logger.Error("统计用户用量失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "查询用户失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"user":    user,
+		"roles":   roles[user.ID],
+		"devices": devices,
+		"usage":   usage,
+	})
+}
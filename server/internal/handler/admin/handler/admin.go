@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/response"
+	token "github.com/sunyuanling/server/pkg/tokn"
+)
+
+// 分页
+const (
+	defaultPageSize = 20
+	maxPageSize     = 200
+)
+
+// pageReq 分页参数
+type pageReq struct {
+	PageNum  int `json:"pageNum"`
+	PageSize int `json:"pageSize"`
+}
+
+// normalize 补齐默认值并限制单页条数
+func (p *pageReq) normalize() {
+	if p.PageNum <= 0 {
+		p.PageNum = 1
+	}
+	if p.PageSize <= 0 {
+		p.PageSize = defaultPageSize
+	}
+	if p.PageSize > maxPageSize {
+		p.PageSize = maxPageSize
+	}
+}
+
+// userIDReq 只需要用户ID的请求
+type userIDReq struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// bindTarget 解析目标用户；不允许管理员对自己执行禁用、删除等操作
+func bindTarget(c *gin.Context, payload *token.TokenPayload, req interface{}, target *uint) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return false
+	}
+	if *target == uint(payload.UserID) {
+		response.BadRequest(c, "不能对自己的账号执行该操作")
+		return false
+	}
+	return true
+}
+
+// writeAccountError 账号操作的常见错误响应，返回是否已处理
+func writeAccountError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.NotFound(c, "用户不存在")
+	case errors.Is(err, rbac.ErrLastAdmin):
+		response.BadRequest(c, "不能禁用、删除或降级最后一个管理员")
+	default:
+		return false
+	}
+	return true
+}
+
+func idString(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,38 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// ListUsers 用户列表（分页、搜索）
+type ListUsers interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// UserDetail 用户详情：角色、设备和用量
+type UserDetail interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// DisableUser 禁用账号
+type DisableUser interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// EnableUser 启用账号
+type EnableUser interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// SetUserRoles 修改账号的全局角色
+type SetUserRoles interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ForceResetPassword 强制重置密码
+type ForceResetPassword interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// DeleteUser 删除账号
+type DeleteUser interface {
+	HandlerPOST(c *gin.Context)
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/handler"
+	adminHandler "github.com/sunyuanling/server/internal/handler/admin/handler"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/mailer"
+)
+
+// Router 管理后台路由
+type Router struct {
+	cfg *config.Config
+}
+
+// NewRouter 创建管理后台模块路由（重置密码发信和删除文件需要配置）
+func NewRouter(cfg *config.Config) handler.ModuleRouter {
+	return &Router{
+		cfg: cfg,
+	}
+}
+
+// RegisterRoutes 注册管理后台相关路由，整组由网关限定管理员角色
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	m, err := mailer.New(r.cfg.Mail)
+	if err != nil {
+		logger.Error("初始化邮件发送失败，改为写入本地文件", zap.Error(err))
+		m = mailer.NewFileMailer(r.cfg.Mail.FileDir)
+	}
+
+	listUsers := adminHandler.NewListUsers(db, redis)
+	userDetail := adminHandler.NewUserDetail(db, redis)
+	disableUser := adminHandler.NewDisableUser(db, redis)
+	enableUser := adminHandler.NewEnableUser(db, redis)
+	setUserRoles := adminHandler.NewSetUserRoles(db, redis)
+	forceReset := adminHandler.NewForceResetPassword(db, redis, m, r.cfg)
+	deleteUser := adminHandler.NewDeleteUser(db, redis, r.cfg)
+
+	group.POST("/user/list", listUsers.HandlerPOST)           // 用户列表
+	group.POST("/user/detail", userDetail.HandlerPOST)        // 用户详情、设备和用量
+	group.POST("/user/disable", disableUser.HandlerPOST)      // 禁用账号
+	group.POST("/user/enable", enableUser.HandlerPOST)        // 启用账号
+	group.POST("/user/roles", setUserRoles.HandlerPOST)       // 修改全局角色
+	group.POST("/user/resetPassword", forceReset.HandlerPOST) // 强制重置密码
+	group.POST("/user/delete", deleteUser.HandlerPOST)        // 删除账号
+}
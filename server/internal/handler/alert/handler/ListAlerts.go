@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/alert"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/alert/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+// 分页
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+type listAlerts struct {
+	*base.BaseHandler
+}
+
+func NewListAlerts(db *gorm.DB, redis *redis.Client) _interface.ListAlerts {
+	return &listAlerts{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 按状态、规则、级别和触发时间查询告警，同时返回当前启用的规则
+func (h *listAlerts) HandlerPOST(c *gin.Context) {
+	var req struct {
+		PageNum  int `json:"pageNum"`
+		PageSize int `json:"pageSize"`
+		alert.Filter
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+	}
+	if req.PageNum <= 0 {
+		req.PageNum = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = defaultPageSize
+	}
+	if req.PageSize > maxPageSize {
+		req.PageSize = maxPageSize
+	}
+
+	alerts, total, err := alert.Query(h.DB, req.Filter, req.PageNum, req.PageSize)
+	if err != nil {
+		logger.Error("查询告警失败", zap.Error(err))
+		response.InternalError(c, "查询告警失败")
+		return
+	}
+	response.Success(c, gin.H{
+		"list":     alerts,
+		"total":    total,
+		"pageNum":  req.PageNum,
+		"pageSize": req.PageSize,
+		"rules":    alert.Rules(),
+	})
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/alert"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/alert/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type testAlert struct {
+	*base.BaseHandler
+}
+
+func NewTestAlert(db *gorm.DB, redis *redis.Client) _interface.TestAlert {
+	return &testAlert{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 向全部通知渠道发送一条测试告警，返回各渠道的发送结果（error 为空表示成功）
+func (h *testAlert) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	results, err := alert.SendTest(c.Request.Context(), payload.Username)
+	if err != nil {
+		if errors.Is(err, alert.ErrDisabled) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("发送测试告警失败", zap.Error(err))
+		response.InternalError(c, "发送测试告警失败")
+		return
+	}
+	response.Success(c, gin.H{"list": results})
+}
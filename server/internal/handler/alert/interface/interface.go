@@ -0,0 +1,13 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// ListAlerts 查询告警记录
+type ListAlerts interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// TestAlert 发送测试告警
+type TestAlert interface {
+	HandlerPOST(c *gin.Context)
+}
@@ -0,0 +1,27 @@
+package alert
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/handler"
+	alertHandler "github.com/sunyuanling/server/internal/handler/alert/handler"
+)
+
+// Router 告警路由
+type Router struct{}
+
+// NewRouter 创建告警模块路由
+func NewRouter() handler.ModuleRouter {
+	return &Router{}
+}
+
+// RegisterRoutes 注册告警相关路由（需要 system:monitor 权限，在网关统一拦截）
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	listAlerts := alertHandler.NewListAlerts(db, redis)
+	testAlert := alertHandler.NewTestAlert(db, redis)
+
+	group.POST("/list", listAlerts.HandlerPOST) // 告警记录，触发中的在前
+	group.POST("/test", testAlert.HandlerPOST)  // 向全部通知渠道发送测试告警
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/access"
+	"github.com/sunyuanling/server/internal/apikey"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/apikey/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type createKey struct {
+	*base.BaseHandler
+	cfg *config.Config
+}
+
+func NewCreateKey(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.CreateKey {
+	return &createKey{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+	}
+}
+
+// CreateKeyRequest 创建 API Key 请求参数
+type CreateKeyRequest struct {
+	Name       string `json:"name" binding:"required"`
+	Scope      string `json:"scope"`       // read/upload/read_write，默认 read
+	FolderPath string `json:"folder_path"` // 可选：只允许访问该文件夹
+	ExpireDays int    `json:"expire_days"` // 有效天数，0 表示不过期
+}
+
+// HandlerPOST 创建 API Key，明文只在本次响应中返回
+func (h *createKey) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req CreateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len([]rune(req.Name)) > 100 {
+		response.BadRequest(c, "名称不能为空且不超过100个字符")
+		return
+	}
+	if req.Scope == "" {
+		req.Scope = model.APIKeyScopeRead
+	}
+	if !apikey.IsValidScope(req.Scope) {
+		response.BadRequest(c, apikey.ErrInvalidScope.Error())
+		return
+	}
+	if req.ExpireDays < 0 || req.ExpireDays > 3650 {
+		response.BadRequest(c, "有效天数必须在0到3650之间")
+		return
+	}
+	if req.FolderPath != "" {
+		req.FolderPath = filepath.Clean(filepath.FromSlash(req.FolderPath))
+		if !access.InAllowedPaths(h.cfg, req.FolderPath) {
+			response.Forbidden(c, "无权访问该路径")
+			return
+		}
+	}
+
+	key := &model.APIKey{
+		UserID:     userID,
+		Name:       req.Name,
+		Scope:      req.Scope,
+		FolderPath: req.FolderPath,
+	}
+	if req.ExpireDays > 0 {
+		expires := time.Now().AddDate(0, 0, req.ExpireDays)
+		key.ExpiresAt = &expires
+	}
+
+	plaintext, err := apikey.Create(h.DB, key)
+	if errors.Is(err, apikey.ErrTooManyKeys) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		logger.Error("创建API Key失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "创建API Key失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionAPIKeyCreate,
+		TargetType: audit.TargetAPIKey,
+		TargetID:   strconv.FormatUint(uint64(key.ID), 10),
+		Detail:     map[string]interface{}{"name": key.Name, "scope": key.Scope, "folder_path": key.FolderPath},
+	})
+	logger.Info("API Key已创建",
+		zap.Uint("user_id", userID),
+		zap.Uint("api_key_id", key.ID),
+		zap.String("scope", key.Scope),
+		zap.String("folder_path", key.FolderPath),
+	)
+
+	response.Success(c, gin.H{
+		"key":     plaintext, // 只返回这一次，请妥善保存
+		"api_key": key,
+	})
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/apikey"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/apikey/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listKeys struct {
+	*base.BaseHandler
+}
+
+func NewListKeys(db *gorm.DB, redis *redis.Client) _interface.ListKeys {
+	return &listKeys{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// KeyItem API Key 列表项（不含明文和哈希）
+type KeyItem struct {
+	model.APIKey
+	Active bool `json:"active"` // 未吊销且未过期
+}
+
+// HandlerPOST 当前用户的 API Key
+func (h *listKeys) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	keys, err := apikey.List(h.DB, userID)
+	if err != nil {
+		logger.Error("查询API Key失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "查询API Key失败")
+		return
+	}
+
+	items := make([]KeyItem, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, KeyItem{APIKey: key, Active: key.IsActive()})
+	}
+	response.Success(c, gin.H{
+		"list":  items,
+		"total": len(items),
+	})
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/apikey"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/apikey/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type revokeKey struct {
+	*base.BaseHandler
+}
+
+func NewRevokeKey(db *gorm.DB, redis *redis.Client) _interface.RevokeKey {
+	return &revokeKey{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 吊销自己的 API Key，立即失效
+func (h *revokeKey) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req struct {
+		ID uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := apikey.Revoke(h.DB, userID, req.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "API Key不存在或已吊销")
+			return
+		}
+		logger.Error("吊销API Key失败", zap.Uint("api_key_id", req.ID), zap.Error(err))
+		response.InternalError(c, "吊销API Key失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionAPIKeyRevoke,
+		TargetType: audit.TargetAPIKey,
+		TargetID:   strconv.FormatUint(uint64(req.ID), 10),
+	})
+	logger.Info("API Key已吊销",
+		zap.Uint("user_id", userID),
+		zap.Uint("api_key_id", req.ID),
+	)
+	response.Success(c, nil)
+}
@@ -0,0 +1,18 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// CreateKey 创建个人 API Key
+type CreateKey interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListKeys 当前用户的 API Key
+type ListKeys interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// RevokeKey 吊销 API Key
+type RevokeKey interface {
+	HandlerPOST(c *gin.Context)
+}
@@ -0,0 +1,34 @@
+package apikey
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/handler"
+	apiKeyHandler "github.com/sunyuanling/server/internal/handler/apikey/handler"
+)
+
+// Router 个人 API Key 路由
+type Router struct {
+	cfg *config.Config
+}
+
+// NewRouter 创建 API Key 模块路由（限定文件夹校验需要配置）
+func NewRouter(cfg *config.Config) handler.ModuleRouter {
+	return &Router{
+		cfg: cfg,
+	}
+}
+
+// RegisterRoutes 注册 API Key 相关路由
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	createKey := apiKeyHandler.NewCreateKey(db, redis, r.cfg)
+	listKeys := apiKeyHandler.NewListKeys(db, redis)
+	revokeKey := apiKeyHandler.NewRevokeKey(db, redis)
+
+	group.POST("/create", createKey.HandlerPOST) // 创建（明文只返回一次）
+	group.POST("/list", listKeys.HandlerPOST)    // 我的 API Key
+	group.POST("/revoke", revokeKey.HandlerPOST) // 吊销
+}
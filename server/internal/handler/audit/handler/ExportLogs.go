@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/audit/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type exportLogs struct {
+	*base.BaseHandler
+}
+
+func NewExportLogs(db *gorm.DB, redis *redis.Client) _interface.ExportLogs {
+	return &exportLogs{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 导出符合条件的审计日志，每行一条 JSON（按时间正序，流式输出）
+func (h *exportLogs) HandlerPOST(c *gin.Context) {
+	var filter audit.Filter
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&filter); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+	}
+
+	// 导出本身也是审计事件
+	audit.Log(c, audit.Event{
+		Action: audit.ActionAuditExport,
+		Detail: map[string]interface{}{"filter": filter},
+	})
+
+	filename := fmt.Sprintf("audit-%s.jsonl", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	count, err := audit.Export(h.DB, filter, c.Writer)
+	if err != nil {
+		logger.Error("导出审计日志失败", zap.Int("exported", count), zap.Error(err))
+		// 已经开始输出时只能中断，客户端按行数不完整判断失败
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			response.InternalError(c, "导出审计日志失败")
+		}
+		return
+	}
+	logger.Info("审计日志已导出", zap.Int("count", count))
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/audit/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listLogs struct {
+	*base.BaseHandler
+}
+
+func NewListLogs(db *gorm.DB, redis *redis.Client) _interface.ListLogs {
+	return &listLogs{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 按操作人、事件类型、结果、对象、IP、设备和时间范围查询审计日志
+func (h *listLogs) HandlerPOST(c *gin.Context) {
+	var req struct {
+		pageReq
+		audit.Filter
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+	}
+	req.normalize()
+
+	logs, total, err := audit.Query(h.DB, req.Filter, req.PageNum, req.PageSize)
+	if err != nil {
+		logger.Error("查询审计日志失败", zap.Error(err))
+		response.InternalError(c, "查询审计日志失败")
+		return
+	}
+	response.Success(c, pageResp(logs, total, req.pageReq))
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/audit/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type myActivity struct {
+	*base.BaseHandler
+}
+
+func NewMyActivity(db *gorm.DB, redis *redis.Client) _interface.MyActivity {
+	return &myActivity{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 我的账号活动：本人的登录、文件操作，以及管理员对本账号的操作
+func (h *myActivity) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		pageReq
+		Action string    `json:"action"`
+		Result string    `json:"result"`
+		Since  time.Time `json:"since"`
+		Until  time.Time `json:"until"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+	}
+	req.normalize()
+
+	filter := audit.Filter{
+		Subject: uint(payload.UserID),
+		Action:  req.Action,
+		Result:  req.Result,
+		Since:   req.Since,
+		Until:   req.Until,
+	}
+	logs, total, err := audit.Query(h.DB, filter, req.PageNum, req.PageSize)
+	if err != nil {
+		logger.Error("查询账号活动失败", zap.Int64("user_id", payload.UserID), zap.Error(err))
+		response.InternalError(c, "查询账号活动失败")
+		return
+	}
+	response.Success(c, pageResp(logs, total, req.pageReq))
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunyuanling/server/internal/model"
+)
+
+// 分页
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// pageReq 分页参数
+type pageReq struct {
+	PageNum  int `json:"pageNum"`
+	PageSize int `json:"pageSize"`
+}
+
+// normalize 补齐默认值并限制单页条数
+func (p *pageReq) normalize() {
+	if p.PageNum <= 0 {
+		p.PageNum = 1
+	}
+	if p.PageSize <= 0 {
+		p.PageSize = defaultPageSize
+	}
+	if p.PageSize > maxPageSize {
+		p.PageSize = maxPageSize
+	}
+}
+
+// pageResp 分页结果
+func pageResp(logs []model.AuditLog, total int64, p pageReq) gin.H {
+	return gin.H{
+		"list":     logs,
+		"total":    total,
+		"pageNum":  p.PageNum,
+		"pageSize": p.PageSize,
+	}
+}
@@ -0,0 +1,18 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// MyActivity 当前用户的账号活动
+type MyActivity interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListLogs 查询审计日志（管理员）
+type ListLogs interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ExportLogs 导出审计日志为 JSONL（管理员）
+type ExportLogs interface {
+	HandlerPOST(c *gin.Context)
+}
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/handler"
+	auditHandler "github.com/sunyuanling/server/internal/handler/audit/handler"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/middleware"
+)
+
+// Router 审计日志路由
+type Router struct{}
+
+// NewRouter 创建审计日志模块路由
+func NewRouter() handler.ModuleRouter {
+	return &Router{}
+}
+
+// RegisterRoutes 注册审计日志相关路由
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	myActivity := auditHandler.NewMyActivity(db, redis)
+	listLogs := auditHandler.NewListLogs(db, redis)
+	exportLogs := auditHandler.NewExportLogs(db, redis)
+
+	group.POST("/my", myActivity.HandlerPOST) // 我的账号活动
+
+	// 以下接口需要查看审计日志权限
+	admin := group.Group("", middleware.RequirePermission(db, rbac.PermAuditRead))
+	admin.POST("/list", listLogs.HandlerPOST)     // 按条件查询
+	admin.POST("/export", exportLogs.HandlerPOST) // 导出 JSONL
+}
@@ -1,12 +1,18 @@
 package auth
 
 import (
-	"context"
 	"errors"
-	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/audit"
 	_interface "github.com/sunyuanling/server/internal/handler/auth/interface"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/internal/session"
+	"github.com/sunyuanling/server/internal/twofactor"
 	"github.com/sunyuanling/server/pkg/password"
-	token "github.com/sunyuanling/server/pkg/tokn"
+	"github.com/sunyuanling/server/pkg/ratelimit"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,27 +22,33 @@ import (
 
 	"github.com/sunyuanling/server/internal/base"
 	model "github.com/sunyuanling/server/internal/handler/auth/modle"
+	internalModel "github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/middleware"
 	"github.com/sunyuanling/server/pkg/logger"
 	"github.com/sunyuanling/server/pkg/response"
 )
 
 type authHandler struct { //  小写，私有
 	*base.BaseHandler
+	limiter *loginLimiter
 }
 
-func NewAuthHandler(db *gorm.DB, redis *redis.Client) _interface.AuthHandler {
+func NewAuthHandler(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.AuthHandler {
 	return &authHandler{
 		BaseHandler: base.NewBaseHandler(db, redis),
+		limiter:     newLoginLimiter(redis, cfg),
 	}
 }
 
 // HandlePOST Login 登录
 func (h *authHandler) HandlePOST(c *gin.Context) {
 	var req struct {
-		Username string `json:"username"`
-		Password string `json:"password" binding:"required"`
-		Email    string `json:"email"`
-		Phone    string `json:"phone"`
+		Username   string `json:"username"`
+		Password   string `json:"password" binding:"required"`
+		Email      string `json:"email"`
+		Phone      string `json:"phone"`
+		DeviceID   string `json:"device_id"`   // 可选：登录设备标识，显示在会话列表中
+		TrustToken string `json:"trust_token"` // 可选：记住设备时返回的信任凭证，有效时跳过两步验证
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -55,7 +67,7 @@ func (h *authHandler) HandlePOST(c *gin.Context) {
 		return
 	}
 
-	// 登录标识（用于Redis key）
+	// 登录标识（用于按账号限流）
 	loginKey := req.Username
 	if loginKey == "" {
 		loginKey = req.Email
@@ -63,32 +75,18 @@ func (h *authHandler) HandlePOST(c *gin.Context) {
 	if loginKey == "" {
 		loginKey = req.Phone
 	}
+	loginKey = strings.ToLower(loginKey)
 
-	// 检查登录失败次数
-	redisKey := fmt.Sprintf("login_fail:%s", loginKey)
-	ctx := context.Background()
-
-	failCount, err := h.Redis.Get(ctx, redisKey).Int()
-	if err != nil && !errors.Is(err, redis.Nil) {
-		logger.Error("Redis查询失败",
-			zap.String("key", redisKey),
-			zap.Error(err),
-		)
-	}
-
-	if failCount >= 5 {
-		logger.Warn("登录失败次数超限",
-			zap.String("login_key", loginKey),
-			zap.Int("fail_count", failCount),
-			zap.String("ip", c.ClientIP()),
-		)
-		response.TooManyRequests(c, "登录失败次数过多，请5分钟后再试")
+	// 检查IP和账号是否处于锁定期
+	if status, locked := h.limiter.locked(c, loginKey); locked {
+		auditLogin(c, nil, loginKey, internalModel.AuditDenied, "locked")
+		middleware.RejectRateLimited(c, status, "登录失败次数过多，请稍后再试")
 		return
 	}
 
 	// 查询用户
 	var user model.User
-	err = h.DB.Where("username = ? OR email = ? OR phone = ?",
+	err := h.DB.Where("username = ? OR email = ? OR phone = ?",
 		req.Username, req.Email, req.Phone).First(&user).Error
 
 	if err != nil {
@@ -104,7 +102,8 @@ func (h *authHandler) HandlePOST(c *gin.Context) {
 			)
 		}
 
-		h.incrLoginFail(redisKey)
+		h.limiter.recordFailure(c, loginKey)
+		auditLogin(c, nil, loginKey, internalModel.AuditFailure, "unknown_account")
 		response.Unauthorized(c, "用户名或密码错误")
 		return
 	}
@@ -117,36 +116,155 @@ func (h *authHandler) HandlePOST(c *gin.Context) {
 			zap.String("ip", c.ClientIP()),
 		)
 
-		h.incrLoginFail(redisKey)
+		h.limiter.recordFailure(c, loginKey)
+		auditLogin(c, &user, loginKey, internalModel.AuditFailure, "bad_password")
 		response.Unauthorized(c, "用户名或密码错误")
 		return
 	}
 
-	// 更新最后登录时间
+	// 账号状态：禁用、待验证邮箱、待审批、注销冷静期都不能登录
+	if user.Status != model.StatusActive {
+		logger.Warn("账号状态不允许登录",
+			zap.Uint("user_id", user.ID),
+			zap.Int16("status", user.Status),
+			zap.String("ip", c.ClientIP()),
+		)
+		auditLogin(c, &user, loginKey, internalModel.AuditDenied, "account_status")
+		switch user.Status {
+		case model.StatusPendingVerify:
+			response.Forbidden(c, "邮箱尚未验证，请先完成邮箱验证")
+		case model.StatusPendingApproval:
+			response.Forbidden(c, "账号正在等待管理员审批")
+		case model.StatusPendingDeletion:
+			response.Forbidden(c, "账号已申请注销，冷静期内可撤销注销申请后再登录")
+		default:
+			response.Forbidden(c, "账号已被禁用")
+		}
+		return
+	}
+
+	finishLogin(c, h.DB, h.Redis, h.limiter, &user, loginKey, req.DeviceID, req.TrustToken)
+}
+
+// finishLogin 第一步验证通过后：需要两步验证时返回票据，否则直接完成登录
+// loginKey 为按账号限流的登录标识，为空时取用户名；limiter 为空表示第一步不计登录失败（如 OIDC）
+// trustToken 为客户端保存的受信任设备凭证；extra 会合并到直接登录成功的响应中
+func finishLogin(c *gin.Context, db *gorm.DB, rdb *redis.Client, limiter *loginLimiter, user *model.User, loginKey, deviceID, trustToken string, extra ...gin.H) {
+	if loginKey == "" {
+		loginKey = strings.ToLower(user.Username)
+	}
+
+	// 两步验证：已启用（且不是受信任设备）或角色强制要求时，先进入第二步
+	challenge, err := twoFactorChallenge(db, user, loginKey, deviceID, trustToken, c.ClientIP())
+	if err != nil {
+		logger.Error("检查两步验证状态失败",
+			zap.Uint("user_id", user.ID),
+			zap.Error(err),
+		)
+		response.Error(c, 500, "服务器内部错误")
+		return
+	}
+	if challenge != nil {
+		ticket, err := twofactor.CreateChallenge(rdb, *challenge)
+		if err != nil {
+			logger.Error("创建两步验证票据失败", zap.Error(err))
+			response.Error(c, 500, "服务器内部错误")
+			return
+		}
+
+		logger.Info("登录等待两步验证",
+			zap.Uint("user_id", user.ID),
+			zap.Bool("enroll_required", challenge.Enroll),
+			zap.String("ip", c.ClientIP()),
+		)
+		response.Success(c, gin.H{
+			"two_factor_required": true,
+			"enroll_required":     challenge.Enroll,
+			"ticket":              ticket,
+			"expires_in":          int64(twofactor.ChallengeTTL.Seconds()),
+		})
+		return
+	}
+
+	// 不需要第二步时登录已完成，清除失败记录；需要时等第二步通过后再清除
+	limiter.reset(c, loginKey)
+	completeLogin(c, db, user, deviceID, extra...)
+}
+
+// twoFactorChallenge 判断登录是否需要第二步，不需要时返回 nil
+func twoFactorChallenge(db *gorm.DB, user *model.User, loginKey, deviceID, trustToken, ip string) (*twofactor.Challenge, error) {
+	enabled, err := twofactor.IsEnabled(db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		trusted, err := twofactor.IsTrustedDevice(db, user.ID, deviceID, trustToken)
+		if err != nil || trusted {
+			return nil, err
+		}
+		return &twofactor.Challenge{UserID: user.ID, DeviceID: deviceID, IP: ip, LoginKey: loginKey}, nil
+	}
+
+	required, err := twoFactorRequired(db, user.ID)
+	if err != nil || !required {
+		return nil, err
+	}
+	return &twofactor.Challenge{UserID: user.ID, DeviceID: deviceID, IP: ip, LoginKey: loginKey, Enroll: true}, nil
+}
+
+// completeLogin 登录成功：更新最后登录时间，创建会话并返回令牌
+// extra 会合并到响应中（例如两步验证启用时的恢复码）
+func completeLogin(c *gin.Context, db *gorm.DB, user *model.User, deviceID string, extra ...gin.H) {
 	now := time.Now()
-	h.DB.Model(&user).Update("last_login", now)
+	db.Model(user).Update("last_login", now)
 	user.LastLogin = &now
 
-	// 登录成功：清除失败记录
-	h.Redis.Del(ctx, redisKey)
-
 	logger.Info("用户登录成功",
 		zap.Uint("user_id", user.ID),
 		zap.String("username", user.Username),
 		zap.String("ip", c.ClientIP()),
 	)
 
-	// 生成token
-	generateToken, err := h.generateToken(&user)
+	// 全局角色写入 token（权限判断仍以 rbac 实时数据为准）
+	roles, err := rbac.GlobalRoles(db, user.ID)
+	if err != nil {
+		logger.Error("加载用户角色失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		response.Error(c, 500, "服务器内部错误")
+		return
+	}
+
+	// 创建会话并签发访问token和刷新令牌
+	tokens, err := session.Issue(db, session.Subject{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Roles:    roles,
+	}, session.Client{
+		DeviceID:  deviceID,
+		IP:        c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
+	})
 	if err != nil {
 		logger.Error("生成token失败", zap.Error(err))
 		response.Error(c, 500, "服务器内部错误")
 		return
 	}
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionLogin,
+		ActorID:    user.ID,
+		Actor:      user.Username,
+		TargetType: audit.TargetSession,
+		TargetID:   tokens.SessionID,
+		Detail:     map[string]interface{}{"device_id": deviceID},
+	})
 
 	// 返回时不要返回整个user（包含密码）
-	response.Success(c, gin.H{
-		"token": generateToken,
+	data := gin.H{
+		"token":              tokens.AccessToken,
+		"refresh_token":      tokens.RefreshToken,
+		"expires_in":         tokens.ExpiresIn,
+		"refresh_expires_in": tokens.RefreshExpiresIn,
+		"session_id":         tokens.SessionID,
 		"user": gin.H{
 			"id":         user.ID,
 			"username":   user.Username,
@@ -154,45 +272,103 @@ func (h *authHandler) HandlePOST(c *gin.Context) {
 			"phone":      user.Phone,
 			"avatar":     user.Avatar,
 			"role":       user.Role,
+			"roles":      roles,
 			"status":     user.Status,
 			"last_login": user.LastLogin,
 		},
-	})
+	}
+	for _, e := range extra {
+		for k, v := range e {
+			data[k] = v
+		}
+	}
+	response.Success(c, data)
 }
 
-// 在 incrLoginFail 函数里加日志排查
-func (h *authHandler) incrLoginFail(redisKey string) {
-	ctx := context.Background()
+// auditLogin 记录未完成的登录；user 为空表示账号不存在
+func auditLogin(c *gin.Context, user *model.User, loginKey, result, reason string) {
+	e := audit.Event{
+		Action: audit.ActionLogin,
+		Result: result,
+		Actor:  loginKey,
+		Detail: map[string]interface{}{"reason": reason},
+	}
+	if user != nil {
+		e.ActorID = user.ID
+		e.Actor = user.Username
+		e.TargetType = audit.TargetUser
+		e.TargetID = strconv.FormatUint(uint64(user.ID), 10)
+	}
+	audit.Log(c, e)
+}
 
-	// 增加计数
-	result := h.Redis.Incr(ctx, redisKey)
-	if result.Err() != nil {
-		logger.Error("Redis Incr失败", zap.Error(result.Err()))
-		return
+// loginLimiter 登录失败限流：按IP计数防止跨账号撞库，按账号计数防止针对单个账号猜测
+// 密码和两步验证码的错误都计入，登录完全成功后才清除账号计数
+type loginLimiter struct {
+	ip      *ratelimit.Limiter
+	account *ratelimit.Limiter
+}
+
+func newLoginLimiter(rdb *redis.Client, cfg *config.Config) *loginLimiter {
+	return &loginLimiter{
+		ip:      ratelimit.New(rdb, ratelimit.LoginIP(cfg.Security)),
+		account: ratelimit.New(rdb, ratelimit.LoginAccount(cfg.Security)),
 	}
+}
 
-	// 设置过期时间：5分钟
-	expireResult := h.Redis.Expire(ctx, redisKey, 5*time.Minute)
-	if expireResult.Err() != nil {
-		logger.Error("Redis Expire失败", zap.Error(expireResult.Err()))
-		return
+// locked IP 或账号是否处于锁定期
+func (l *loginLimiter) locked(c *gin.Context, loginKey string) (ratelimit.Status, bool) {
+	ctx := c.Request.Context()
+	for _, check := range []struct {
+		limiter *ratelimit.Limiter
+		key     string
+	}{{l.ip, c.ClientIP()}, {l.account, loginKey}} {
+		status, err := check.limiter.Check(ctx, check.key)
+		if err != nil {
+			logger.Error("登录限流检查失败", zap.Error(err))
+			continue
+		}
+		if !status.Allowed {
+			logger.Warn("登录被锁定",
+				zap.String("policy", check.limiter.Policy().Name),
+				zap.String("login_key", loginKey),
+				zap.String("ip", c.ClientIP()),
+				zap.Duration("retry_after", status.RetryAfter),
+			)
+			return status, true
+		}
 	}
+	return ratelimit.Status{Allowed: true}, false
+}
 
-	logger.Info("设置过期时间成功", zap.Duration("ttl", 5*time.Minute))
+// recordFailure 登录失败：IP 和账号各计一次，达到上限时锁定（锁定时长逐级翻倍）
+func (l *loginLimiter) recordFailure(c *gin.Context, loginKey string) {
+	ctx := c.Request.Context()
+	for _, hit := range []struct {
+		limiter *ratelimit.Limiter
+		key     string
+	}{{l.ip, c.ClientIP()}, {l.account, loginKey}} {
+		status, err := hit.limiter.Hit(ctx, hit.key)
+		if err != nil {
+			logger.Error("记录登录失败次数失败", zap.Error(err))
+			continue
+		}
+		if !status.Allowed {
+			logger.Warn("登录失败次数超限，已锁定",
+				zap.String("policy", hit.limiter.Policy().Name),
+				zap.String("key", hit.key),
+				zap.Duration("lockout", status.RetryAfter),
+			)
+		}
+	}
 }
 
-func (h *authHandler) generateToken(user *model.User) (string, error) {
-	payload := map[string]interface{}{
-		"user_id":   user.ID,
-		"username":  user.Username,
-		"email":     user.Email,
-		"role":      user.Role,
-		"status":    user.Status,
-		"phone":     user.Phone,
-		"lastLogin": user.LastLogin,
-		"createAt":  user.CreatedAt,
-		"updateAt":  user.UpdatedAt,
-	}
-	resToken, err := token.GetGlobalTokenManager().GenerateTokenFromMap(payload)
-	return resToken, err
+// reset 登录成功：清除该账号的失败记录（IP计数保留，避免用一个已知账号刷新撞库额度）
+func (l *loginLimiter) reset(c *gin.Context, loginKey string) {
+	if l == nil {
+		return
+	}
+	if err := l.account.Reset(c.Request.Context(), loginKey); err != nil {
+		logger.Error("清除登录失败记录失败", zap.Error(err))
+	}
 }
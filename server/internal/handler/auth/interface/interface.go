@@ -11,3 +11,113 @@ type AuthHandler interface {
 type TokenVerifyHandler interface {
 	HandlePOST(c *gin.Context)
 }
+
+// RefreshHandler 使用刷新令牌换取新的访问token
+type RefreshHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// LogoutHandler 注销当前会话
+type LogoutHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// SessionListHandler 当前用户的登录会话列表
+type SessionListHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// SessionRevokeHandler 注销指定会话
+type SessionRevokeHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// SessionRevokeAllHandler 注销全部会话
+type SessionRevokeAllHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// TwoFactorStatusHandler 两步验证状态
+type TwoFactorStatusHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// TwoFactorSetupHandler 生成 TOTP 密钥和绑定二维码地址
+type TwoFactorSetupHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// TwoFactorEnableHandler 验证首个验证码并启用两步验证
+type TwoFactorEnableHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// TwoFactorVerifyHandler 登录第二步：校验验证码或恢复码并签发token
+type TwoFactorVerifyHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// TwoFactorDisableHandler 关闭两步验证
+type TwoFactorDisableHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// TwoFactorRecoveryCodesHandler 重新生成恢复码
+type TwoFactorRecoveryCodesHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// TwoFactorPolicyListHandler 各角色两步验证策略（管理员）
+type TwoFactorPolicyListHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// TwoFactorPolicySetHandler 设置角色是否强制两步验证（管理员）
+type TwoFactorPolicySetHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// TwoFactorForgetDeviceHandler 取消受信任设备
+type TwoFactorForgetDeviceHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// LockoutListHandler 当前登录/注册/找回密码限流锁定列表（管理员）
+type LockoutListHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// LockoutUnlockHandler 解除锁定（管理员）
+type LockoutUnlockHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// OIDCProvidersHandler 可用的外部登录方式（OIDC 身份提供方）
+type OIDCProvidersHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// OIDCStartHandler 发起 OIDC 授权（授权码 + PKCE）
+type OIDCStartHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// OIDCCallbackHandler OIDC 回调：校验 ID Token 后登录或关联账号
+type OIDCCallbackHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// OIDCIdentitiesHandler 当前用户已关联的外部身份
+type OIDCIdentitiesHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// OIDCUnlinkHandler 取消关联外部身份
+type OIDCUnlinkHandler interface {
+	HandlePOST(c *gin.Context)
+}
+
+// JWKSHandler 发布 token 验签公钥（JWKS）
+type JWKSHandler interface {
+	HandleGET(c *gin.Context)
+}
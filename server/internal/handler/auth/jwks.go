@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/auth/interface"
+	token "github.com/sunyuanling/server/pkg/tokn"
+)
+
+type jwksHandler struct {
+	*base.BaseHandler
+}
+
+func NewJWKSHandler(db *gorm.DB, redis *redis.Client) _interface.JWKSHandler {
+	return &jwksHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandleGET 发布 token 验签公钥（标准 JWKS 格式，不包装统一响应结构）
+func (h *jwksHandler) HandleGET(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": token.GetGlobalTokenManager().JWKS()})
+}
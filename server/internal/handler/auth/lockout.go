@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/auth/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/ratelimit"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type lockoutListHandler struct {
+	*base.BaseHandler
+}
+
+func NewLockoutListHandler(db *gorm.DB, redis *redis.Client) _interface.LockoutListHandler {
+	return &lockoutListHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// LockoutItem 锁定列表项
+type LockoutItem struct {
+	ratelimit.Lockout
+	RetryAfterSeconds int64 `json:"retry_after_seconds"`
+}
+
+// HandlePOST 当前处于锁定期的IP和账号
+func (h *lockoutListHandler) HandlePOST(c *gin.Context) {
+	if _, ok := requireSecurityManager(c, h.BaseHandler); !ok {
+		return
+	}
+
+	lockouts, err := ratelimit.ListLockouts(c.Request.Context(), h.Redis)
+	if err != nil {
+		logger.Error("查询锁定列表失败", zap.Error(err))
+		response.InternalError(c, "查询锁定列表失败")
+		return
+	}
+
+	items := make([]LockoutItem, 0, len(lockouts))
+	for _, l := range lockouts {
+		items = append(items, LockoutItem{Lockout: l, RetryAfterSeconds: int64(l.RetryAfter.Seconds())})
+	}
+	response.Success(c, gin.H{
+		"list":  items,
+		"total": len(items),
+	})
+}
+
+type lockoutUnlockHandler struct {
+	*base.BaseHandler
+}
+
+func NewLockoutUnlockHandler(db *gorm.DB, redis *redis.Client) _interface.LockoutUnlockHandler {
+	return &lockoutUnlockHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 解除指定策略下某个IP或账号的锁定
+func (h *lockoutUnlockHandler) HandlePOST(c *gin.Context) {
+	admin, ok := requireSecurityManager(c, h.BaseHandler)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Policy string `json:"policy" binding:"required"`
+		Key    string `json:"key" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if err := ratelimit.Unlock(c.Request.Context(), h.Redis, req.Policy, req.Key); err != nil {
+		if errors.Is(err, ratelimit.ErrNotLocked) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		logger.Error("解除锁定失败", zap.Error(err))
+		response.InternalError(c, "解除锁定失败")
+		return
+	}
+
+	logger.Info("管理员解除锁定",
+		zap.Uint("admin_id", admin.ID),
+		zap.String("policy", req.Policy),
+		zap.String("key", req.Key),
+	)
+	response.Success(c, nil)
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/auth/interface"
+	"github.com/sunyuanling/server/internal/session"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type logoutHandler struct {
+	*base.BaseHandler
+}
+
+func NewLogoutHandler(db *gorm.DB, redis *redis.Client) _interface.LogoutHandler {
+	return &logoutHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 注销当前会话：刷新令牌作废，访问token立即失效
+func (h *logoutHandler) HandlePOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	if payload.SessionID == "" {
+		response.BadRequest(c, "当前token未绑定会话")
+		return
+	}
+
+	userID := uint(payload.UserID)
+	if err := session.Revoke(h.DB, userID, payload.SessionID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("注销会话失败",
+			zap.Uint("user_id", userID),
+			zap.String("session_id", payload.SessionID),
+			zap.Error(err),
+		)
+		response.InternalError(c, "注销失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionLogout,
+		TargetType: audit.TargetSession,
+		TargetID:   payload.SessionID,
+	})
+	logger.Info("用户退出登录",
+		zap.Uint("user_id", userID),
+		zap.String("session_id", payload.SessionID),
+	)
+	response.SuccessWithMsg(c, "已退出登录", nil)
+}
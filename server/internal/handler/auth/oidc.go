@@ -0,0 +1,285 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/auth/interface"
+	internalModel "github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/oidc"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+// ========== 身份提供方列表 ==========
+
+type oidcProvidersHandler struct {
+	*base.BaseHandler
+	registry *oidc.Registry
+}
+
+func NewOIDCProvidersHandler(db *gorm.DB, redis *redis.Client, registry *oidc.Registry) _interface.OIDCProvidersHandler {
+	return &oidcProvidersHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		registry:    registry,
+	}
+}
+
+// HandlePOST 登录页可用的外部登录方式
+func (h *oidcProvidersHandler) HandlePOST(c *gin.Context) {
+	providers := h.registry.List()
+	list := make([]gin.H, 0, len(providers))
+	for _, p := range providers {
+		cfg := p.Config()
+		list = append(list, gin.H{
+			"name":         cfg.Name,
+			"display_name": cfg.DisplayName,
+		})
+	}
+	response.Success(c, gin.H{"list": list})
+}
+
+// ========== 发起授权 ==========
+
+type oidcStartHandler struct {
+	*base.BaseHandler
+	registry *oidc.Registry
+}
+
+func NewOIDCStartHandler(db *gorm.DB, redis *redis.Client, registry *oidc.Registry) _interface.OIDCStartHandler {
+	return &oidcStartHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		registry:    registry,
+	}
+}
+
+// HandlePOST 生成授权地址，前端跳转过去；link 为 true 时把外部身份关联到当前登录的账号
+func (h *oidcStartHandler) HandlePOST(c *gin.Context) {
+	var req struct {
+		Provider   string `json:"provider" binding:"required"`
+		DeviceID   string `json:"device_id"`
+		TrustToken string `json:"trust_token"`
+		Link       bool   `json:"link"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	provider, err := h.registry.Get(req.Provider)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	authReq := oidc.AuthRequest{DeviceID: req.DeviceID, TrustToken: req.TrustToken}
+	if req.Link {
+		payload, ok := h.CurrentUser(c)
+		if !ok {
+			return
+		}
+		authReq.LinkUserID = uint(payload.UserID)
+	}
+
+	authURL, err := h.registry.Begin(c.Request.Context(), h.Redis, provider, authReq)
+	if err != nil {
+		logger.Error("发起OIDC授权失败",
+			zap.String("provider", req.Provider),
+			zap.Error(err),
+		)
+		if errors.Is(err, oidc.ErrDiscovery) {
+			response.Error(c, 502, "身份提供方暂时不可用")
+			return
+		}
+		response.InternalError(c, "服务器内部错误")
+		return
+	}
+
+	response.Success(c, gin.H{"authorization_url": authURL})
+}
+
+// ========== 回调 ==========
+
+type oidcCallbackHandler struct {
+	*base.BaseHandler
+	registry *oidc.Registry
+}
+
+func NewOIDCCallbackHandler(db *gorm.DB, redis *redis.Client, registry *oidc.Registry) _interface.OIDCCallbackHandler {
+	return &oidcCallbackHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		registry:    registry,
+	}
+}
+
+// HandlePOST 前端把回调地址上的 state 和 code 交给后端：换取并校验 ID Token，
+// 找到（或关联、自动创建）本地账号后按普通登录流程签发本系统的 token
+func (h *oidcCallbackHandler) HandlePOST(c *gin.Context) {
+	var req struct {
+		State string `json:"state" binding:"required"`
+		Code  string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	ctx := c.Request.Context()
+	authReq, claims, err := h.registry.Finish(ctx, h.Redis, req.State, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, oidc.ErrStateInvalid), errors.Is(err, oidc.ErrUnknownProvider):
+			response.BadRequest(c, oidc.ErrStateInvalid.Error())
+		case errors.Is(err, oidc.ErrInvalidIDToken), errors.Is(err, oidc.ErrTokenExchange), errors.Is(err, oidc.ErrDiscovery):
+			logger.Warn("OIDC登录校验失败", zap.String("ip", c.ClientIP()), zap.Error(err))
+			audit.Log(c, audit.Event{
+				Action: audit.ActionLogin,
+				Result: internalModel.AuditFailure,
+				Detail: map[string]interface{}{"method": "oidc", "reason": err.Error()},
+			})
+			response.Unauthorized(c, "外部登录失败，请重试")
+		default:
+			logger.Error("OIDC回调处理失败", zap.Error(err))
+			response.InternalError(c, "服务器内部错误")
+		}
+		return
+	}
+
+	provider, _ := h.registry.Get(authReq.Provider)
+	res, err := oidc.ResolveUser(h.DB, provider, claims, authReq.LinkUserID, c.ClientIP())
+	if err != nil {
+		logger.Warn("OIDC账号匹配失败",
+			zap.String("provider", authReq.Provider),
+			zap.String("subject", claims.Subject),
+			zap.Uint("link_user_id", authReq.LinkUserID),
+			zap.Error(err),
+		)
+		audit.Log(c, audit.Event{
+			Action:  audit.ActionLogin,
+			Result:  internalModel.AuditDenied,
+			ActorID: authReq.LinkUserID,
+			Actor:   claims.Email,
+			Detail: map[string]interface{}{
+				"method":   "oidc",
+				"provider": authReq.Provider,
+				"subject":  claims.Subject,
+				"reason":   err.Error(),
+			},
+		})
+		switch {
+		case errors.Is(err, oidc.ErrNoAccount), errors.Is(err, oidc.ErrDomainNotAllow), errors.Is(err, oidc.ErrUserDisabled):
+			response.Forbidden(c, err.Error())
+		case errors.Is(err, oidc.ErrIdentityInUse), errors.Is(err, oidc.ErrAlreadyLinked):
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalError(c, "服务器内部错误")
+		}
+		return
+	}
+
+	logger.Info("OIDC身份验证通过",
+		zap.String("provider", authReq.Provider),
+		zap.String("subject", claims.Subject),
+		zap.Uint("user_id", res.User.ID),
+		zap.Bool("linked", res.Linked),
+		zap.Bool("created", res.Created),
+		zap.String("ip", c.ClientIP()),
+	)
+
+	// 已登录用户主动关联：不签发新的 token
+	if authReq.LinkUserID != 0 {
+		response.SuccessWithMsg(c, "关联成功", gin.H{"provider": authReq.Provider, "linked": true})
+		return
+	}
+
+	user, ok := loadUser(c, h.DB, res.User.ID)
+	if !ok {
+		return
+	}
+	finishLogin(c, h.DB, h.Redis, nil, user, "", authReq.DeviceID, authReq.TrustToken, gin.H{
+		"oidc_provider":   authReq.Provider,
+		"account_created": res.Created,
+	})
+}
+
+// ========== 已关联的外部身份 ==========
+
+type oidcIdentitiesHandler struct {
+	*base.BaseHandler
+}
+
+func NewOIDCIdentitiesHandler(db *gorm.DB, redis *redis.Client) _interface.OIDCIdentitiesHandler {
+	return &oidcIdentitiesHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 当前用户已关联的外部身份
+func (h *oidcIdentitiesHandler) HandlePOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	list, err := oidc.Identities(h.DB, userID)
+	if err != nil {
+		logger.Error("查询外部身份失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "查询外部身份失败")
+		return
+	}
+	response.Success(c, gin.H{"list": list})
+}
+
+type oidcUnlinkHandler struct {
+	*base.BaseHandler
+}
+
+func NewOIDCUnlinkHandler(db *gorm.DB, redis *redis.Client) _interface.OIDCUnlinkHandler {
+	return &oidcUnlinkHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 取消关联外部身份（之后只能用密码登录）
+func (h *oidcUnlinkHandler) HandlePOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req struct {
+		ID uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if err := oidc.Unlink(h.DB, userID, req.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "外部身份不存在")
+			return
+		}
+		logger.Error("取消关联外部身份失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "取消关联失败")
+		return
+	}
+
+	logger.Info("已取消关联外部身份", zap.Uint("user_id", userID), zap.Uint("identity_id", req.ID))
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionIdentityUnlink,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(userID), 10),
+		Detail:     map[string]interface{}{"identity_id": req.ID},
+	})
+	response.Success(c, nil)
+}
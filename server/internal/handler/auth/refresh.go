@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/auth/interface"
+	"github.com/sunyuanling/server/internal/session"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type refreshHandler struct {
+	*base.BaseHandler
+}
+
+func NewRefreshHandler(db *gorm.DB, redis *redis.Client) _interface.RefreshHandler {
+	return &refreshHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 刷新令牌换新（每次刷新都会轮换刷新令牌，旧令牌立即作废）
+func (h *refreshHandler) HandlePOST(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	tokens, err := session.Refresh(h.DB, req.RefreshToken, session.Client{
+		IP:        c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, session.ErrInvalidRefreshToken),
+			errors.Is(err, session.ErrSessionExpired),
+			errors.Is(err, session.ErrRefreshTokenReused):
+			logger.Warn("刷新令牌校验失败",
+				zap.String("ip", c.ClientIP()),
+				zap.Error(err),
+			)
+			response.Unauthorized(c, err.Error())
+		case errors.Is(err, session.ErrUserDisabled):
+			response.Forbidden(c, err.Error())
+		default:
+			logger.Error("刷新令牌失败", zap.Error(err))
+			response.InternalError(c, "刷新令牌失败")
+		}
+		return
+	}
+
+	response.Success(c, tokens)
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/auth/interface"
+	"github.com/sunyuanling/server/internal/session"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type sessionListHandler struct {
+	*base.BaseHandler
+}
+
+func NewSessionListHandler(db *gorm.DB, redis *redis.Client) _interface.SessionListHandler {
+	return &sessionListHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 列出当前用户的有效会话，current 标记发起请求的会话
+func (h *sessionListHandler) HandlePOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := session.List(h.DB, uint(payload.UserID))
+	if err != nil {
+		logger.Error("查询会话列表失败",
+			zap.Int64("user_id", payload.UserID),
+			zap.Error(err),
+		)
+		response.InternalError(c, "查询会话列表失败")
+		return
+	}
+
+	list := make([]gin.H, 0, len(sessions))
+	for _, s := range sessions {
+		list = append(list, gin.H{
+			"id":           s.ID,
+			"device_id":    s.DeviceID,
+			"ip_address":   s.IPAddress,
+			"user_agent":   s.UserAgent,
+			"created_at":   s.CreatedAt,
+			"last_used_at": s.LastUsedAt,
+			"expires_at":   s.ExpiresAt,
+			"current":      s.ID == payload.SessionID,
+		})
+	}
+
+	response.Success(c, gin.H{
+		"total":    len(list),
+		"sessions": list,
+	})
+}
+
+type sessionRevokeHandler struct {
+	*base.BaseHandler
+}
+
+func NewSessionRevokeHandler(db *gorm.DB, redis *redis.Client) _interface.SessionRevokeHandler {
+	return &sessionRevokeHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 注销当前用户的指定会话
+func (h *sessionRevokeHandler) HandlePOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		SessionID string `json:"session_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	userID := uint(payload.UserID)
+	if err := session.Revoke(h.DB, userID, req.SessionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "会话不存在")
+			return
+		}
+		logger.Error("注销会话失败",
+			zap.Uint("user_id", userID),
+			zap.String("session_id", req.SessionID),
+			zap.Error(err),
+		)
+		response.InternalError(c, "注销会话失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionSessionRevoke,
+		TargetType: audit.TargetSession,
+		TargetID:   req.SessionID,
+	})
+	logger.Info("会话已注销",
+		zap.Uint("user_id", userID),
+		zap.String("session_id", req.SessionID),
+	)
+	response.SuccessWithMsg(c, "会话已注销", gin.H{"session_id": req.SessionID})
+}
+
+type sessionRevokeAllHandler struct {
+	*base.BaseHandler
+}
+
+func NewSessionRevokeAllHandler(db *gorm.DB, redis *redis.Client) _interface.SessionRevokeAllHandler {
+	return &sessionRevokeAllHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 注销当前用户的全部会话，keep_current 为 true 时保留发起请求的会话
+func (h *sessionRevokeAllHandler) HandlePOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		KeepCurrent bool `json:"keep_current"`
+	}
+	// 请求体可选
+	_ = c.ShouldBindJSON(&req)
+
+	exceptID := ""
+	if req.KeepCurrent {
+		exceptID = payload.SessionID
+	}
+
+	userID := uint(payload.UserID)
+	count, err := session.RevokeAll(h.DB, userID, exceptID)
+	if err != nil {
+		logger.Error("注销全部会话失败",
+			zap.Uint("user_id", userID),
+			zap.Int("revoked", count),
+			zap.Error(err),
+		)
+		response.InternalError(c, "注销全部会话失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionSessionRevoke,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(userID), 10),
+		Detail:     map[string]interface{}{"revoked": count, "keep_current": req.KeepCurrent},
+	})
+	logger.Info("已注销全部会话",
+		zap.Uint("user_id", userID),
+		zap.Int("revoked", count),
+		zap.Bool("keep_current", req.KeepCurrent),
+	)
+	response.SuccessWithMsg(c, "已注销全部会话", gin.H{"revoked": count})
+}
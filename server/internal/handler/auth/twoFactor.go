@@ -0,0 +1,692 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/auth/interface"
+	model "github.com/sunyuanling/server/internal/handler/auth/modle"
+	internalModel "github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/internal/twofactor"
+	"github.com/sunyuanling/server/middleware"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/password"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/pkg/totp"
+)
+
+// ========== 状态 ==========
+
+type twoFactorStatusHandler struct {
+	*base.BaseHandler
+}
+
+func NewTwoFactorStatusHandler(db *gorm.DB, redis *redis.Client) _interface.TwoFactorStatusHandler {
+	return &twoFactorStatusHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 当前用户的两步验证状态、剩余恢复码和受信任设备
+func (h *twoFactorStatusHandler) HandlePOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	user, ok := loadUser(c, h.DB, userID)
+	if !ok {
+		return
+	}
+
+	tf, err := internalModel.FindTwoFactor(h.DB, userID)
+	if err != nil {
+		logger.Error("查询两步验证配置失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "查询两步验证状态失败")
+		return
+	}
+	required, err := twoFactorRequired(h.DB, user.ID)
+	if err != nil {
+		logger.Error("查询两步验证策略失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		response.InternalError(c, "查询两步验证状态失败")
+		return
+	}
+	remaining, _ := internalModel.UnusedRecoveryCodeCount(h.DB, userID)
+
+	var devices []internalModel.Device
+	h.DB.Where("user_id = ? AND trusted_until > ?", userID, time.Now()).
+		Order("trusted_until desc").
+		Find(&devices)
+	trusted := make([]gin.H, 0, len(devices))
+	for _, d := range devices {
+		trusted = append(trusted, gin.H{
+			"device_id":     d.DeviceID,
+			"device_name":   d.DeviceName,
+			"trusted_until": d.TrustedUntil,
+		})
+	}
+
+	status := gin.H{
+		"enabled":                  tf != nil && tf.Enabled,
+		"pending":                  tf != nil && !tf.Enabled,
+		"required":                 required,
+		"recovery_codes_remaining": remaining,
+		"trusted_devices":          trusted,
+	}
+	if tf != nil {
+		status["enabled_at"] = tf.EnabledAt
+	}
+	response.Success(c, status)
+}
+
+// ========== 绑定 ==========
+
+type twoFactorSetupHandler struct {
+	*base.BaseHandler
+	cfg *config.Config
+}
+
+func NewTwoFactorSetupHandler(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.TwoFactorSetupHandler {
+	return &twoFactorSetupHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+	}
+}
+
+// HandlePOST 生成 TOTP 密钥，返回 otpauth:// 地址（前端生成二维码）
+// 已登录用户直接调用；角色强制要求但未绑定的用户在登录时凭 ticket 调用
+func (h *twoFactorSetupHandler) HandlePOST(c *gin.Context) {
+	var req struct {
+		Ticket string `json:"ticket"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	userID, _, ok := enrollSubject(c, h.BaseHandler, req.Ticket)
+	if !ok {
+		return
+	}
+	user, ok := loadUser(c, h.DB, userID)
+	if !ok {
+		return
+	}
+
+	secret, err := twofactor.Enroll(h.DB, userID)
+	if err != nil {
+		if errors.Is(err, twofactor.ErrAlreadyEnabled) {
+			response.BadRequest(c, "两步验证已启用，如需更换请先关闭")
+			return
+		}
+		logger.Error("生成两步验证密钥失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "生成两步验证密钥失败")
+		return
+	}
+
+	issuer := h.cfg.Security.TwoFactorIssuer
+	response.Success(c, gin.H{
+		"secret":      secret,
+		"otpauth_uri": totp.URI(issuer, user.Username, secret),
+		"issuer":      issuer,
+		"digits":      totp.Digits,
+		"period":      totp.Period,
+	})
+}
+
+type twoFactorEnableHandler struct {
+	*base.BaseHandler
+	cfg     *config.Config
+	limiter *loginLimiter
+}
+
+func NewTwoFactorEnableHandler(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.TwoFactorEnableHandler {
+	return &twoFactorEnableHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+		limiter:     newLoginLimiter(redis, cfg),
+	}
+}
+
+// HandlePOST 用认证器上的验证码确认绑定，返回恢复码（只显示这一次）
+// 凭 ticket 调用时同时完成登录并返回 token
+func (h *twoFactorEnableHandler) HandlePOST(c *gin.Context) {
+	var req struct {
+		Code           string `json:"code" binding:"required"`
+		Ticket         string `json:"ticket"`
+		RememberDevice bool   `json:"remember_device"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	userID, challenge, ok := enrollSubject(c, h.BaseHandler, req.Ticket)
+	if !ok {
+		return
+	}
+	if challenge != nil {
+		if status, locked := h.limiter.locked(c, challenge.LoginKey); locked {
+			middleware.RejectRateLimited(c, status, "登录失败次数过多，请稍后再试")
+			return
+		}
+	}
+
+	codes, err := twofactor.Enable(h.DB, userID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, twofactor.ErrInvalidCode):
+			if challenge != nil {
+				h.limiter.recordFailure(c, challenge.LoginKey)
+				if err := twofactor.RecordChallengeFailure(h.Redis, req.Ticket); errors.Is(err, twofactor.ErrTooManyAttempts) {
+					response.Unauthorized(c, err.Error())
+					return
+				}
+			}
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, twofactor.ErrNotEnrolled), errors.Is(err, twofactor.ErrAlreadyEnabled):
+			response.BadRequest(c, err.Error())
+		default:
+			logger.Error("启用两步验证失败", zap.Uint("user_id", userID), zap.Error(err))
+			response.InternalError(c, "启用两步验证失败")
+		}
+		return
+	}
+
+	logger.Info("两步验证已启用", zap.Uint("user_id", userID))
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionTwoFactorOn,
+		ActorID:    userID,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(userID), 10),
+	})
+
+	// 已登录用户：只返回恢复码
+	if challenge == nil {
+		response.SuccessWithMsg(c, "两步验证已启用", gin.H{"recovery_codes": codes})
+		return
+	}
+
+	// 登录中绑定：完成登录
+	if !twofactor.ConsumeChallenge(h.Redis, req.Ticket) {
+		response.Unauthorized(c, twofactor.ErrChallengeInvalid.Error())
+		return
+	}
+	user, ok := loginUser(c, h.DB, challenge)
+	if !ok {
+		return
+	}
+	h.limiter.reset(c, challenge.LoginKey)
+	extra := rememberDevice(h.DB, h.cfg, req.RememberDevice, challenge)
+	extra["recovery_codes"] = codes
+	completeLogin(c, h.DB, user, challenge.DeviceID, extra)
+}
+
+// ========== 登录第二步 ==========
+
+type twoFactorVerifyHandler struct {
+	*base.BaseHandler
+	cfg     *config.Config
+	limiter *loginLimiter
+}
+
+func NewTwoFactorVerifyHandler(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.TwoFactorVerifyHandler {
+	return &twoFactorVerifyHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+		limiter:     newLoginLimiter(redis, cfg),
+	}
+}
+
+// HandlePOST 登录第二步：ticket + 验证码（或恢复码），可选择记住设备
+func (h *twoFactorVerifyHandler) HandlePOST(c *gin.Context) {
+	var req struct {
+		Ticket         string `json:"ticket" binding:"required"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recovery_code"`
+		RememberDevice bool   `json:"remember_device"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		response.BadRequest(c, "请输入验证码或恢复码")
+		return
+	}
+
+	challenge, err := twofactor.GetChallenge(h.Redis, req.Ticket)
+	if err != nil {
+		if errors.Is(err, twofactor.ErrChallengeInvalid) {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		logger.Error("读取两步验证票据失败", zap.Error(err))
+		response.InternalError(c, "服务器内部错误")
+		return
+	}
+	if challenge.Enroll {
+		response.BadRequest(c, "请先绑定两步验证")
+		return
+	}
+	// 每张票据的错误次数有限，但凭密码可以反复取得新票据，所以同样受登录失败锁定限制
+	if status, locked := h.limiter.locked(c, challenge.LoginKey); locked {
+		middleware.RejectRateLimited(c, status, "登录失败次数过多，请稍后再试")
+		return
+	}
+
+	if err := twofactor.Verify(h.DB, challenge.UserID, req.Code, req.RecoveryCode); err != nil {
+		if errors.Is(err, twofactor.ErrInvalidCode) {
+			logger.Warn("两步验证失败",
+				zap.Uint("user_id", challenge.UserID),
+				zap.Bool("recovery_code", req.RecoveryCode != ""),
+				zap.String("ip", c.ClientIP()),
+			)
+			audit.Log(c, audit.Event{
+				Action:     audit.ActionLogin,
+				Result:     internalModel.AuditFailure,
+				ActorID:    challenge.UserID,
+				TargetType: audit.TargetUser,
+				TargetID:   strconv.FormatUint(uint64(challenge.UserID), 10),
+				Detail:     map[string]interface{}{"reason": "bad_two_factor_code"},
+			})
+			h.limiter.recordFailure(c, challenge.LoginKey)
+			if err := twofactor.RecordChallengeFailure(h.Redis, req.Ticket); errors.Is(err, twofactor.ErrTooManyAttempts) {
+				response.Unauthorized(c, err.Error())
+				return
+			}
+			response.Unauthorized(c, "验证码错误")
+			return
+		}
+		logger.Error("两步验证校验失败", zap.Uint("user_id", challenge.UserID), zap.Error(err))
+		response.InternalError(c, "服务器内部错误")
+		return
+	}
+
+	if !twofactor.ConsumeChallenge(h.Redis, req.Ticket) {
+		response.Unauthorized(c, twofactor.ErrChallengeInvalid.Error())
+		return
+	}
+
+	user, ok := loginUser(c, h.DB, challenge)
+	if !ok {
+		return
+	}
+	h.limiter.reset(c, challenge.LoginKey)
+
+	extra := rememberDevice(h.DB, h.cfg, req.RememberDevice, challenge)
+	if req.RecoveryCode != "" {
+		remaining, _ := internalModel.UnusedRecoveryCodeCount(h.DB, user.ID)
+		extra["recovery_codes_remaining"] = remaining
+		logger.Info("使用恢复码登录",
+			zap.Uint("user_id", user.ID),
+			zap.Int64("remaining", remaining),
+		)
+	}
+	completeLogin(c, h.DB, user, challenge.DeviceID, extra)
+}
+
+// ========== 关闭 / 恢复码 / 受信任设备 ==========
+
+type twoFactorDisableHandler struct {
+	*base.BaseHandler
+}
+
+func NewTwoFactorDisableHandler(db *gorm.DB, redis *redis.Client) _interface.TwoFactorDisableHandler {
+	return &twoFactorDisableHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 关闭两步验证（需要密码和验证码/恢复码；角色强制要求时不允许关闭）
+func (h *twoFactorDisableHandler) HandlePOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Password     string `json:"password" binding:"required"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	user, ok := loadUser(c, h.DB, uint(payload.UserID))
+	if !ok {
+		return
+	}
+
+	required, err := twoFactorRequired(h.DB, user.ID)
+	if err != nil {
+		logger.Error("查询两步验证策略失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		response.InternalError(c, "关闭两步验证失败")
+		return
+	}
+	if required {
+		response.Forbidden(c, "当前角色必须启用两步验证")
+		return
+	}
+
+	if !password.VerifyPassword(user.Password, req.Password) {
+		response.Unauthorized(c, "密码错误")
+		return
+	}
+	if err := twofactor.Verify(h.DB, user.ID, req.Code, req.RecoveryCode); err != nil {
+		if errors.Is(err, twofactor.ErrInvalidCode) || errors.Is(err, twofactor.ErrNotEnrolled) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("两步验证校验失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		response.InternalError(c, "关闭两步验证失败")
+		return
+	}
+
+	if err := twofactor.Disable(h.DB, user.ID); err != nil {
+		logger.Error("关闭两步验证失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		response.InternalError(c, "关闭两步验证失败")
+		return
+	}
+
+	logger.Info("两步验证已关闭", zap.Uint("user_id", user.ID))
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionTwoFactorOff,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+	})
+	response.SuccessWithMsg(c, "两步验证已关闭", nil)
+}
+
+type twoFactorRecoveryCodesHandler struct {
+	*base.BaseHandler
+}
+
+func NewTwoFactorRecoveryCodesHandler(db *gorm.DB, redis *redis.Client) _interface.TwoFactorRecoveryCodesHandler {
+	return &twoFactorRecoveryCodesHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 校验验证码后重新生成恢复码，旧恢复码全部作废
+func (h *twoFactorRecoveryCodesHandler) HandlePOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	userID := uint(payload.UserID)
+	if err := twofactor.Verify(h.DB, userID, req.Code, ""); err != nil {
+		if errors.Is(err, twofactor.ErrInvalidCode) || errors.Is(err, twofactor.ErrNotEnrolled) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("两步验证校验失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "生成恢复码失败")
+		return
+	}
+
+	codes, err := twofactor.RegenerateRecoveryCodes(h.DB, userID)
+	if err != nil {
+		logger.Error("生成恢复码失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "生成恢复码失败")
+		return
+	}
+
+	logger.Info("恢复码已重新生成", zap.Uint("user_id", userID))
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+type twoFactorForgetDeviceHandler struct {
+	*base.BaseHandler
+}
+
+func NewTwoFactorForgetDeviceHandler(db *gorm.DB, redis *redis.Client) _interface.TwoFactorForgetDeviceHandler {
+	return &twoFactorForgetDeviceHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 取消受信任设备，device_id 为空时取消全部
+func (h *twoFactorForgetDeviceHandler) HandlePOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		DeviceID string `json:"device_id"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	query := h.DB.Where("user_id = ? AND trusted_until IS NOT NULL", payload.UserID)
+	if req.DeviceID != "" {
+		query = query.Where("device_id = ?", req.DeviceID)
+	}
+	count, err := twofactor.UntrustDevices(query)
+	if err != nil {
+		logger.Error("取消受信任设备失败", zap.Int64("user_id", payload.UserID), zap.Error(err))
+		response.InternalError(c, "取消受信任设备失败")
+		return
+	}
+
+	response.SuccessWithMsg(c, "已取消受信任设备", gin.H{"count": count})
+}
+
+// ========== 角色策略（管理员） ==========
+
+type twoFactorPolicyListHandler struct {
+	*base.BaseHandler
+}
+
+func NewTwoFactorPolicyListHandler(db *gorm.DB, redis *redis.Client) _interface.TwoFactorPolicyListHandler {
+	return &twoFactorPolicyListHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 列出各角色的两步验证策略
+func (h *twoFactorPolicyListHandler) HandlePOST(c *gin.Context) {
+	if _, ok := requireSecurityManager(c, h.BaseHandler); !ok {
+		return
+	}
+
+	var policies []internalModel.TwoFactorPolicy
+	if err := h.DB.Order("role").Find(&policies).Error; err != nil {
+		logger.Error("查询两步验证策略失败", zap.Error(err))
+		response.InternalError(c, "查询两步验证策略失败")
+		return
+	}
+	response.Success(c, gin.H{"policies": policies})
+}
+
+type twoFactorPolicySetHandler struct {
+	*base.BaseHandler
+}
+
+func NewTwoFactorPolicySetHandler(db *gorm.DB, redis *redis.Client) _interface.TwoFactorPolicySetHandler {
+	return &twoFactorPolicySetHandler{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlePOST 设置角色是否必须启用两步验证
+// 开启后该角色未绑定的用户下次登录时必须先完成绑定
+func (h *twoFactorPolicySetHandler) HandlePOST(c *gin.Context) {
+	admin, ok := requireSecurityManager(c, h.BaseHandler)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Role     string `json:"role" binding:"required"`
+		Required bool   `json:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	var roleCount int64
+	h.DB.Model(&internalModel.Role{}).Where("role_code = ?", req.Role).Count(&roleCount)
+	if roleCount == 0 {
+		response.BadRequest(c, "角色不存在")
+		return
+	}
+
+	policy := internalModel.TwoFactorPolicy{
+		Role:      req.Role,
+		Required:  req.Required,
+		UpdatedBy: admin.ID,
+		UpdatedAt: time.Now(),
+	}
+	if err := h.DB.Save(&policy).Error; err != nil {
+		logger.Error("保存两步验证策略失败", zap.String("role", req.Role), zap.Error(err))
+		response.InternalError(c, "保存两步验证策略失败")
+		return
+	}
+
+	logger.Info("两步验证策略已更新",
+		zap.Uint("admin_id", admin.ID),
+		zap.String("role", req.Role),
+		zap.Bool("required", req.Required),
+	)
+	response.Success(c, policy)
+}
+
+// ========== 公共函数 ==========
+
+// loadUser 查询用户，失败时写入错误响应
+func loadUser(c *gin.Context, db *gorm.DB, userID uint) (*model.User, bool) {
+	var user model.User
+	if err := db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Unauthorized(c, "用户不存在")
+		} else {
+			logger.Error("查询用户失败", zap.Uint("user_id", userID), zap.Error(err))
+			response.InternalError(c, "查询用户失败")
+		}
+		return nil, false
+	}
+	return &user, true
+}
+
+// loginUser 第二步通过后加载登录用户，账号在验证期间被禁用、进入注销冷静期等状态时拒绝；失败时已写入响应
+func loginUser(c *gin.Context, db *gorm.DB, challenge *twofactor.Challenge) (*model.User, bool) {
+	user, ok := loadUser(c, db, challenge.UserID)
+	if !ok {
+		return nil, false
+	}
+	if user.Status != model.StatusActive {
+		logger.Warn("账号状态不允许登录",
+			zap.Uint("user_id", user.ID),
+			zap.Int16("status", user.Status),
+			zap.String("ip", c.ClientIP()),
+		)
+		auditLogin(c, user, challenge.LoginKey, internalModel.AuditDenied, "account_status")
+		response.Forbidden(c, "账号已被禁用")
+		return nil, false
+	}
+	return user, true
+}
+
+// requireSecurityManager 要求当前用户拥有安全策略管理权限
+func requireSecurityManager(c *gin.Context, h *base.BaseHandler) (*model.User, bool) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return nil, false
+	}
+	user, ok := loadUser(c, h.DB, uint(payload.UserID))
+	if !ok {
+		return nil, false
+	}
+
+	grants, err := rbac.FromContext(c, h.DB, user.ID)
+	if err != nil {
+		logger.Error("加载用户权限失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		response.InternalError(c, "加载用户权限失败")
+		return nil, false
+	}
+	if !grants.Has(rbac.PermSecurityManage) {
+		response.Forbidden(c, "权限不足")
+		return nil, false
+	}
+	return user, true
+}
+
+// twoFactorRequired 用户的任一全局角色是否被强制要求两步验证
+func twoFactorRequired(db *gorm.DB, userID uint) (bool, error) {
+	roles, err := rbac.GlobalRoles(db, userID)
+	if err != nil {
+		return false, err
+	}
+	return internalModel.IsTwoFactorRequired(db, roles)
+}
+
+// enrollSubject 绑定操作的用户：有 ticket 时取登录挑战中的用户（必须是待绑定状态），否则取当前登录用户
+func enrollSubject(c *gin.Context, h *base.BaseHandler, ticket string) (uint, *twofactor.Challenge, bool) {
+	if ticket == "" {
+		payload, ok := h.CurrentUser(c)
+		if !ok {
+			return 0, nil, false
+		}
+		return uint(payload.UserID), nil, true
+	}
+
+	challenge, err := twofactor.GetChallenge(h.Redis, ticket)
+	if err != nil {
+		if errors.Is(err, twofactor.ErrChallengeInvalid) {
+			response.Unauthorized(c, err.Error())
+		} else {
+			logger.Error("读取两步验证票据失败", zap.Error(err))
+			response.InternalError(c, "服务器内部错误")
+		}
+		return 0, nil, false
+	}
+	if !challenge.Enroll {
+		response.BadRequest(c, "该登录不需要绑定两步验证")
+		return 0, nil, false
+	}
+	return challenge.UserID, challenge, true
+}
+
+// rememberDevice 按需记住设备，返回合并到登录响应中的 device_trusted 和 trust_token
+// 客户端保存 trust_token，之后登录时随 device_id 一起提交才能跳过第二步
+func rememberDevice(db *gorm.DB, cfg *config.Config, remember bool, challenge *twofactor.Challenge) gin.H {
+	result := gin.H{"device_trusted": false}
+	if !remember || challenge.DeviceID == "" {
+		return result
+	}
+	trustToken, err := twofactor.TrustDevice(db, challenge.UserID, challenge.DeviceID, cfg.Security.TrustedDeviceDays)
+	if err != nil {
+		logger.Error("记住设备失败",
+			zap.Uint("user_id", challenge.UserID),
+			zap.String("device_id", challenge.DeviceID),
+			zap.Error(err),
+		)
+		return result
+	}
+	if trustToken != "" {
+		result["device_trusted"] = true
+		result["trust_token"] = trustToken
+	}
+	return result
+}
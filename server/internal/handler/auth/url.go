@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"github.com/sunyuanling/server/config"
 	"github.com/sunyuanling/server/internal/handler"
+	"github.com/sunyuanling/server/internal/oidc"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -9,19 +11,79 @@ import (
 )
 
 // Router 定义路由
-type Router struct{}
+type Router struct {
+	cfg *config.Config
+}
 
-func NewRouter() handler.ModuleRouter {
-	return &Router{}
+// NewRouter 创建认证模块路由（两步验证需要配置）
+func NewRouter(cfg *config.Config) handler.ModuleRouter {
+	return &Router{cfg: cfg}
 }
 
 func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
 	// 创建业务处理实例
-	authHandler := NewAuthHandler(db, redis)
+	authHandler := NewAuthHandler(db, redis, r.cfg)
 	tokenHandler := NewTokenVerifyHandler(db, redis)
+	refreshHandler := NewRefreshHandler(db, redis)
+	logoutHandler := NewLogoutHandler(db, redis)
+	sessionListHandler := NewSessionListHandler(db, redis)
+	sessionRevokeHandler := NewSessionRevokeHandler(db, redis)
+	sessionRevokeAllHandler := NewSessionRevokeAllHandler(db, redis)
+	twoFactorStatus := NewTwoFactorStatusHandler(db, redis)
+	twoFactorSetup := NewTwoFactorSetupHandler(db, redis, r.cfg)
+	twoFactorEnable := NewTwoFactorEnableHandler(db, redis, r.cfg)
+	twoFactorVerify := NewTwoFactorVerifyHandler(db, redis, r.cfg)
+	twoFactorDisable := NewTwoFactorDisableHandler(db, redis)
+	twoFactorRecovery := NewTwoFactorRecoveryCodesHandler(db, redis)
+	twoFactorForgetDevice := NewTwoFactorForgetDeviceHandler(db, redis)
+	twoFactorPolicyList := NewTwoFactorPolicyListHandler(db, redis)
+	twoFactorPolicySet := NewTwoFactorPolicySetHandler(db, redis)
+	lockoutList := NewLockoutListHandler(db, redis)
+	lockoutUnlock := NewLockoutUnlockHandler(db, redis)
+	oidcRegistry := oidc.NewRegistry(r.cfg.OIDC)
+	oidcProviders := NewOIDCProvidersHandler(db, redis, oidcRegistry)
+	oidcStart := NewOIDCStartHandler(db, redis, oidcRegistry)
+	oidcCallback := NewOIDCCallbackHandler(db, redis, oidcRegistry)
+	oidcIdentities := NewOIDCIdentitiesHandler(db, redis)
+	oidcUnlink := NewOIDCUnlinkHandler(db, redis)
+	jwksHandler := NewJWKSHandler(db, redis)
 
 	//用户登录
 	group.POST("/login", authHandler.HandlePOST)
 	//验证token
 	group.POST("/verify", tokenHandler.HandlePOST)
+	//token验签公钥（jwt-eddsa / paseto-v4）
+	group.GET("/jwks", jwksHandler.HandleGET)
+	//刷新token（轮换刷新令牌）
+	group.POST("/refresh", refreshHandler.HandlePOST)
+	//退出登录（注销当前会话）
+	group.POST("/logout", logoutHandler.HandlePOST)
+	//会话列表
+	group.POST("/sessions", sessionListHandler.HandlePOST)
+	//注销指定会话
+	group.POST("/sessions/revoke", sessionRevokeHandler.HandlePOST)
+	//注销全部会话
+	group.POST("/sessions/revoke-all", sessionRevokeAllHandler.HandlePOST)
+
+	// ========== 两步验证 ==========
+	group.POST("/2fa/status", twoFactorStatus.HandlePOST)                       // 两步验证状态
+	group.POST("/2fa/setup", twoFactorSetup.HandlePOST)                         // 生成密钥和绑定地址
+	group.POST("/2fa/enable", twoFactorEnable.HandlePOST)                       // 确认绑定并启用，返回恢复码
+	group.POST("/2fa/verify", twoFactorVerify.HandlePOST)                       // 登录第二步
+	group.POST("/2fa/disable", twoFactorDisable.HandlePOST)                     // 关闭两步验证
+	group.POST("/2fa/recovery-codes", twoFactorRecovery.HandlePOST)             // 重新生成恢复码
+	group.POST("/2fa/trusted-devices/forget", twoFactorForgetDevice.HandlePOST) // 取消受信任设备
+	group.POST("/2fa/policy", twoFactorPolicyList.HandlePOST)                   // 角色策略列表（管理员）
+	group.POST("/2fa/policy/set", twoFactorPolicySet.HandlePOST)                // 设置角色策略（管理员）
+
+	// ========== 登录限流 ==========
+	group.POST("/lockouts", lockoutList.HandlePOST)          // 当前锁定列表（管理员）
+	group.POST("/lockouts/unlock", lockoutUnlock.HandlePOST) // 解除锁定（管理员）
+
+	// ========== OIDC 登录 ==========
+	group.POST("/oidc/providers", oidcProviders.HandlePOST)   // 可用的身份提供方
+	group.POST("/oidc/start", oidcStart.HandlePOST)           // 发起授权，返回跳转地址
+	group.POST("/oidc/callback", oidcCallback.HandlePOST)     // 回调：登录或关联账号
+	group.POST("/oidc/identities", oidcIdentities.HandlePOST) // 已关联的外部身份
+	group.POST("/oidc/unlink", oidcUnlink.HandlePOST)         // 取消关联
 }
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/device/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type createPairing struct {
+	*base.BaseHandler
+}
+
+func NewCreatePairing(db *gorm.DB, redis *redis.Client) _interface.CreatePairing {
+	return &createPairing{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// CreatePairingRequest 生成配对码请求参数
+type CreatePairingRequest struct {
+	DeviceID string `json:"device_id"` // 发起配对的设备标识（可选，设备token可省略）
+}
+
+// HandlerPOST 生成一次性配对码，返回配对码和二维码内容
+func (h *createPairing) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req CreatePairingRequest
+	if c.Request.Body != nil && c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "参数错误")
+			return
+		}
+	}
+	if req.DeviceID == "" {
+		req.DeviceID = payload.DeviceID
+	}
+
+	session, err := json.Marshal(pairingSession{
+		UserID:         userID,
+		SourceDeviceID: req.DeviceID,
+		CreatedAt:      time.Now().Unix(),
+	})
+	if err != nil {
+		response.InternalError(c, "生成配对码失败")
+		return
+	}
+
+	// 生成配对码，碰撞时重试
+	ctx := context.Background()
+	var code string
+	for i := 0; i < 3; i++ {
+		code, err = generatePairingCode()
+		if err != nil {
+			break
+		}
+		var created bool
+		created, err = h.Redis.SetNX(ctx, pairingKey(code), session, pairingCodeTTL).Result()
+		if err == nil && !created {
+			err = errors.New("配对码冲突")
+			continue
+		}
+		break
+	}
+	if err != nil {
+		logger.Error("生成配对码失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "生成配对码失败")
+		return
+	}
+
+	logger.Info("已生成设备配对码",
+		zap.Uint("user_id", userID),
+		zap.String("source_device_id", req.DeviceID),
+	)
+
+	response.Success(c, gin.H{
+		"code":       code,
+		"expires_in": int(pairingCodeTTL.Seconds()),
+		"qr_payload": buildPairingURI(c, code),
+	})
+}
+
+// buildPairingURI 生成二维码内容：filesync://pair?code=XXXX&server=http://host:port
+func buildPairingURI(c *gin.Context, code string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	query := url.Values{}
+	query.Set("code", code)
+	query.Set("server", fmt.Sprintf("%s://%s", scheme, c.Request.Host))
+	return "filesync://pair?" + query.Encode()
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/device/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listCommands struct {
+	*base.BaseHandler
+}
+
+func NewListCommands(db *gorm.DB, redis *redis.Client) _interface.ListCommands {
+	return &listCommands{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// ListCommandsRequest 查询指令记录请求参数
+type ListCommandsRequest struct {
+	ID    uint `json:"id" binding:"required"` // device 表主键
+	Limit int  `json:"limit"`
+}
+
+// HandlerPOST 查询设备最近的指令及执行状态
+func (h *listCommands) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req ListCommandsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	var cmds []model.DeviceCommand
+	if err := h.DB.Where("user_id = ? AND device_record_id = ?", userID, req.ID).
+		Order("id desc").
+		Limit(req.Limit).
+		Find(&cmds).Error; err != nil {
+		logger.Error("查询设备指令失败", zap.Uint("device_record_id", req.ID), zap.Error(err))
+		response.InternalError(c, "查询设备指令失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  cmds,
+		"total": len(cmds),
+	})
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/device/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type listDevices struct {
+	*base.BaseHandler
+}
+
+func NewListDevices(db *gorm.DB, redis *redis.Client) _interface.ListDevices {
+	return &listDevices{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// DeviceItem 设备列表项
+type DeviceItem struct {
+	ID         uint       `json:"id"`
+	DeviceID   string     `json:"device_id"`
+	DeviceName string     `json:"device_name"`
+	DeviceType string     `json:"device_type"`
+	OSVersion  string     `json:"os_version"`
+	AppVersion string     `json:"app_version"`
+	IPAddress  string     `json:"ip_address"`
+	LastActive *time.Time `json:"last_active"`
+	Status     int16      `json:"status"`
+	Online     bool       `json:"online"` // 当前是否有 WebSocket 连接
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HandlerPOST 获取当前用户的所有设备
+func (h *listDevices) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var devices []model.Device
+	if err := h.DB.Where("user_id = ?", userID).
+		Order("last_active desc nulls last").
+		Find(&devices).Error; err != nil {
+		logger.Error("查询设备列表失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "查询设备列表失败")
+		return
+	}
+
+	items := make([]DeviceItem, 0, len(devices))
+	for _, d := range devices {
+		items = append(items, DeviceItem{
+			ID:         d.ID,
+			DeviceID:   d.DeviceID,
+			DeviceName: d.DeviceName,
+			DeviceType: d.DeviceType,
+			OSVersion:  d.OSVersion,
+			AppVersion: d.AppVersion,
+			IPAddress:  d.IPAddress,
+			LastActive: d.LastActive,
+			Status:     d.Status,
+			Online:     websocket.IsDeviceOnline(userID, d.DeviceID),
+			CreatedAt:  d.CreatedAt,
+		})
+	}
+
+	response.Success(c, gin.H{
+		"total":   len(items),
+		"devices": items,
+	})
+}
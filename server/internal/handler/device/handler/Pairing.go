@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	pairingCodeTTL      = 5 * time.Minute                    // 配对码有效期
+	pairingCodeLength   = 8                                  // 配对码长度
+	pairingCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // 去掉易混淆的 0/O/1/I
+	pairingMaxFailures  = 10                                 // 同一IP配对码错误次数上限
+	pairingFailWindow   = 5 * time.Minute                    // 错误次数统计窗口
+)
+
+// pairingSession 存在 Redis 中的配对信息
+type pairingSession struct {
+	UserID         uint   `json:"user_id"`
+	SourceDeviceID string `json:"source_device_id,omitempty"` // 发起配对的设备标识
+	CreatedAt      int64  `json:"created_at"`
+}
+
+// pairingKey 配对码的 Redis key
+func pairingKey(code string) string {
+	return fmt.Sprintf("device_pairing:%s", code)
+}
+
+// pairingFailKey 配对失败计数的 Redis key
+func pairingFailKey(ip string) string {
+	return fmt.Sprintf("device_pairing_fail:%s", ip)
+}
+
+// generatePairingCode 生成随机配对码
+func generatePairingCode() (string, error) {
+	code := make([]byte, pairingCodeLength)
+	max := big.NewInt(int64(len(pairingCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = pairingCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/device/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/internal/session"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+	"github.com/sunyuanling/server/websocket/protocol"
+)
+
+type redeemPairing struct {
+	*base.BaseHandler
+}
+
+func NewRedeemPairing(db *gorm.DB, redis *redis.Client) _interface.RedeemPairing {
+	return &redeemPairing{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// RedeemPairingRequest 兑换配对码请求参数
+type RedeemPairingRequest struct {
+	Code       string `json:"code" binding:"required"`
+	DeviceID   string `json:"device_id"` // 为空时由服务端生成
+	DeviceType string `json:"device_type"`
+	DeviceName string `json:"device_name"`
+	Platform   string `json:"platform"`
+	AppVersion string `json:"app_version"`
+}
+
+// HandlerPOST 新设备使用配对码登记设备并创建绑定该设备的会话（无需登录）
+func (h *redeemPairing) HandlerPOST(c *gin.Context) {
+	var req RedeemPairingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+	if len([]rune(req.DeviceName)) > 100 {
+		response.BadRequest(c, "设备名称不能超过100个字符")
+		return
+	}
+
+	ctx := context.Background()
+	ip := c.ClientIP()
+
+	// 限制同一IP的错误次数，防止暴力枚举配对码
+	failCount, _ := h.Redis.Get(ctx, pairingFailKey(ip)).Int()
+	if failCount >= pairingMaxFailures {
+		response.TooManyRequests(c, "尝试次数过多，请稍后再试")
+		return
+	}
+
+	// 配对码只能使用一次：取出的同时删除
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	raw, err := h.Redis.GetDel(ctx, pairingKey(code)).Result()
+	if errors.Is(err, redis.Nil) {
+		h.incrPairingFail(ctx, ip)
+		response.BadRequest(c, "配对码无效或已过期")
+		return
+	}
+	if err != nil {
+		logger.Error("读取配对码失败", zap.Error(err))
+		response.InternalError(c, "配对失败")
+		return
+	}
+
+	var pairing pairingSession
+	if err := json.Unmarshal([]byte(raw), &pairing); err != nil {
+		logger.Error("解析配对信息失败", zap.Error(err))
+		response.InternalError(c, "配对失败")
+		return
+	}
+
+	var user model.User
+	if err := h.DB.First(&user, pairing.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.BadRequest(c, "配对码无效或已过期")
+			return
+		}
+		logger.Error("查询用户失败", zap.Uint("user_id", pairing.UserID), zap.Error(err))
+		response.InternalError(c, "配对失败")
+		return
+	}
+	if user.Status != model.StatusActive {
+		response.Forbidden(c, "账号已被禁用")
+		return
+	}
+
+	if req.DeviceID == "" {
+		req.DeviceID = uuid.New().String()
+	}
+
+	existing, err := model.FindDeviceByIdentifier(h.DB, user.ID, req.DeviceID)
+	if err != nil {
+		logger.Error("查询设备失败", zap.Error(err))
+		response.InternalError(c, "配对失败")
+		return
+	}
+	if existing != nil && !existing.IsActive() {
+		response.Forbidden(c, "设备已被禁用")
+		return
+	}
+
+	name := req.DeviceName
+	if name == "" {
+		name = req.DeviceType
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	device := &model.Device{
+		UserID:     user.ID,
+		DeviceName: name,
+		DeviceType: websocket.ModelDeviceType(req.DeviceType, req.Platform),
+		DeviceID:   req.DeviceID,
+		OSVersion:  req.Platform,
+		AppVersion: req.AppVersion,
+		IPAddress:  ip,
+	}
+	if err := model.RegisterDevice(h.DB, device); err != nil {
+		logger.Error("登记设备失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		response.InternalError(c, "配对失败")
+		return
+	}
+
+	// 创建绑定到该设备的会话：与登录相同，使用短期访问 token 和刷新令牌，
+	// 修改密码、禁用账号、注销设备时随会话一起失效
+	roles, err := rbac.GlobalRoles(h.DB, user.ID)
+	if err != nil {
+		logger.Error("加载用户角色失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		response.InternalError(c, "配对失败")
+		return
+	}
+	tokens, err := session.Issue(h.DB, session.Subject{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Roles:    roles,
+	}, session.Client{
+		DeviceID:  device.DeviceID,
+		IP:        ip,
+		UserAgent: c.GetHeader("User-Agent"),
+	})
+	if err != nil {
+		logger.Error("生成设备token失败", zap.Error(err))
+		response.InternalError(c, "配对失败")
+		return
+	}
+
+	h.Redis.Del(ctx, pairingFailKey(ip))
+
+	// 通知发起方和新设备配对完成（离线时暂存，上线后补发）
+	notice := protocol.DevicePaired{
+		Event:          protocol.EventDevicePaired,
+		DeviceID:       device.DeviceID,
+		DeviceName:     device.DeviceName,
+		DeviceType:     device.DeviceType,
+		SourceDeviceID: pairing.SourceDeviceID,
+		PairedAt:       time.Now().Unix(),
+	}
+	if pairing.SourceDeviceID != "" {
+		if err := websocket.QueueForDevice(user.ID, pairing.SourceDeviceID, websocket.MessageTypeSystem, notice); err != nil {
+			logger.Warn("通知发起设备失败", zap.String("device_id", pairing.SourceDeviceID), zap.Error(err))
+		}
+	}
+	if err := websocket.QueueForDevice(user.ID, device.DeviceID, websocket.MessageTypeSystem, notice); err != nil {
+		logger.Warn("通知新设备失败", zap.String("device_id", device.DeviceID), zap.Error(err))
+	}
+
+	logger.Info("设备配对成功",
+		zap.Uint("user_id", user.ID),
+		zap.String("device_id", device.DeviceID),
+		zap.String("source_device_id", pairing.SourceDeviceID),
+	)
+
+	response.Success(c, gin.H{
+		"token":              tokens.AccessToken,
+		"refresh_token":      tokens.RefreshToken,
+		"expires_in":         tokens.ExpiresIn,
+		"refresh_expires_in": tokens.RefreshExpiresIn,
+		"session_id":         tokens.SessionID,
+		"device": DeviceItem{
+			ID:         device.ID,
+			DeviceName: device.DeviceName,
+			DeviceType: device.DeviceType,
+			DeviceID:   device.DeviceID,
+			OSVersion:  device.OSVersion,
+			AppVersion: device.AppVersion,
+			IPAddress:  device.IPAddress,
+			LastActive: device.LastActive,
+			Status:     device.Status,
+			CreatedAt:  device.CreatedAt,
+		},
+		"user": gin.H{
+			"id":       user.ID,
+			"username": user.Username,
+			"email":    user.Email,
+			"role":     user.Role,
+		},
+	})
+}
+
+// incrPairingFail 记录一次配对失败
+func (h *redeemPairing) incrPairingFail(ctx context.Context, ip string) {
+	key := pairingFailKey(ip)
+	if err := h.Redis.Incr(ctx, key).Err(); err != nil {
+		logger.Error("Redis Incr失败", zap.Error(err))
+		return
+	}
+	h.Redis.Expire(ctx, key, pairingFailWindow)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/device/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type renameDevice struct {
+	*base.BaseHandler
+}
+
+func NewRenameDevice(db *gorm.DB, redis *redis.Client) _interface.RenameDevice {
+	return &renameDevice{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// RenameDeviceRequest 重命名请求参数
+type RenameDeviceRequest struct {
+	ID         uint   `json:"id" binding:"required"`          // device 表主键
+	DeviceName string `json:"device_name" binding:"required"` // 新名称
+}
+
+// HandlerPOST 重命名设备
+func (h *renameDevice) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req RenameDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	req.DeviceName = strings.TrimSpace(req.DeviceName)
+	if req.DeviceName == "" || len(req.DeviceName) > 100 {
+		response.BadRequest(c, "设备名称不能为空且不超过100个字符")
+		return
+	}
+
+	var device model.Device
+	if err := h.DB.Where("id = ? AND user_id = ?", req.ID, userID).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "设备不存在")
+			return
+		}
+		logger.Error("查询设备失败", zap.Uint("device_record_id", req.ID), zap.Error(err))
+		response.InternalError(c, "查询设备失败")
+		return
+	}
+
+	if err := h.DB.Model(&device).Update("device_name", req.DeviceName).Error; err != nil {
+		logger.Error("重命名设备失败", zap.Uint("device_record_id", req.ID), zap.Error(err))
+		response.InternalError(c, "重命名设备失败")
+		return
+	}
+
+	logger.Info("设备已重命名",
+		zap.Uint("user_id", userID),
+		zap.Uint("device_record_id", device.ID),
+		zap.String("device_name", req.DeviceName),
+	)
+
+	response.Success(c, gin.H{
+		"id":          device.ID,
+		"device_name": req.DeviceName,
+	})
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/device/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/session"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type revokeDevice struct {
+	*base.BaseHandler
+}
+
+func NewRevokeDevice(db *gorm.DB, redis *redis.Client) _interface.RevokeDevice {
+	return &revokeDevice{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// RevokeDeviceRequest 注销设备请求参数
+type RevokeDeviceRequest struct {
+	ID uint `json:"id" binding:"required"` // device 表主键
+}
+
+// HandlerPOST 注销设备：标记为禁用并断开其 WebSocket 连接
+// 被禁用的设备之后无法再建立 WebSocket 连接
+func (h *revokeDevice) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req RevokeDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	var device model.Device
+	if err := h.DB.Where("id = ? AND user_id = ?", req.ID, userID).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "设备不存在")
+			return
+		}
+		logger.Error("查询设备失败", zap.Uint("device_record_id", req.ID), zap.Error(err))
+		response.InternalError(c, "查询设备失败")
+		return
+	}
+
+	if err := h.DB.Model(&device).Update("status", model.DeviceStatusInactive).Error; err != nil {
+		logger.Error("注销设备失败", zap.Uint("device_record_id", req.ID), zap.Error(err))
+		response.InternalError(c, "注销设备失败")
+		return
+	}
+
+	// 吊销该设备已签发的 token、注销绑定该设备的会话并断开连接
+	if _, err := session.RevokeDevice(h.DB, userID, device.DeviceID); err != nil {
+		logger.Error("吊销设备token失败", zap.String("device_id", device.DeviceID), zap.Error(err))
+	}
+	websocket.DisconnectDevice(userID, device.DeviceID)
+
+	logger.Info("设备已注销",
+		zap.Uint("user_id", userID),
+		zap.Uint("device_record_id", device.ID),
+		zap.String("device_id", device.DeviceID),
+	)
+
+	response.Success(c, gin.H{
+		"id":      device.ID,
+		"message": "设备已注销",
+	})
+}
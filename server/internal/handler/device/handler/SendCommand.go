@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/device/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/session"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type sendCommand struct {
+	*base.BaseHandler
+}
+
+func NewSendCommand(db *gorm.DB, redis *redis.Client) _interface.SendCommand {
+	return &sendCommand{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// SendCommandRequest 下发指令请求参数
+type SendCommandRequest struct {
+	ID      uint            `json:"id" binding:"required"`      // device 表主键
+	Command string          `json:"command" binding:"required"` // sign_out/clear_local_cache/upload_diagnostics/pause_sync
+	Params  json.RawMessage `json:"params"`                     // 指令参数（可选）
+}
+
+// HandlerPOST 向设备下发远程指令
+// 设备离线时指令保存在 device_command 表，设备下次连接时下发；sign_out 会立即吊销该设备的 token
+func (h *sendCommand) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req SendCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+	if !model.IsValidDeviceCommand(req.Command) {
+		response.BadRequest(c, "不支持的指令")
+		return
+	}
+	if len(req.Params) > 0 && !json.Valid(req.Params) {
+		response.BadRequest(c, "指令参数格式错误")
+		return
+	}
+
+	var device model.Device
+	if err := h.DB.Where("id = ? AND user_id = ?", req.ID, userID).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "设备不存在")
+			return
+		}
+		logger.Error("查询设备失败", zap.Uint("device_record_id", req.ID), zap.Error(err))
+		response.InternalError(c, "查询设备失败")
+		return
+	}
+
+	cmd := &model.DeviceCommand{
+		UserID:         userID,
+		DeviceRecordID: device.ID,
+		DeviceID:       device.DeviceID,
+		Command:        req.Command,
+		Status:         model.DeviceCommandStatusPending,
+		IssuedFrom:     payload.DeviceID,
+	}
+	if len(req.Params) > 0 && string(req.Params) != "null" {
+		cmd.Params = string(req.Params)
+	}
+	if err := h.DB.Create(cmd).Error; err != nil {
+		logger.Error("保存设备指令失败", zap.Uint("device_record_id", device.ID), zap.Error(err))
+		response.InternalError(c, "下发指令失败")
+		return
+	}
+
+	// 退出登录：服务端立即吊销该设备的 token 和会话，设备即使不执行指令也无法再重连或刷新
+	if req.Command == model.DeviceCommandSignOut {
+		if _, err := session.RevokeDevice(h.DB, userID, device.DeviceID); err != nil {
+			logger.Error("吊销设备token失败", zap.String("device_id", device.DeviceID), zap.Error(err))
+			response.InternalError(c, "下发指令失败")
+			return
+		}
+	}
+
+	delivered := websocket.DispatchCommand(cmd)
+
+	logger.Info("已创建设备指令",
+		zap.Uint("user_id", userID),
+		zap.String("device_id", device.DeviceID),
+		zap.Uint("command_id", cmd.ID),
+		zap.String("command", cmd.Command),
+		zap.Bool("delivered", delivered),
+	)
+
+	response.Success(c, gin.H{
+		"command_id": cmd.ID,
+		"status":     cmd.Status,
+		"delivered":  delivered,
+	})
+}
@@ -0,0 +1,38 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// ListDevices 获取当前用户的设备列表
+type ListDevices interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// RenameDevice 重命名设备
+type RenameDevice interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// RevokeDevice 注销（禁用）设备
+type RevokeDevice interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// CreatePairing 已登录设备生成配对码
+type CreatePairing interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// RedeemPairing 新设备使用配对码换取设备token
+type RedeemPairing interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// SendCommand 向设备下发远程指令
+type SendCommand interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListCommands 查询设备的指令记录
+type ListCommands interface {
+	HandlerPOST(c *gin.Context)
+}
@@ -0,0 +1,44 @@
+package device
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/handler"
+	deviceHandler "github.com/sunyuanling/server/internal/handler/device/handler"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/middleware"
+)
+
+// Router 设备模块路由
+type Router struct{}
+
+// NewRouter 创建设备模块路由
+func NewRouter() handler.ModuleRouter {
+	return &Router{}
+}
+
+// RegisterRoutes 注册设备相关路由
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	listDevices := deviceHandler.NewListDevices(db, redis)
+	renameDevice := deviceHandler.NewRenameDevice(db, redis)
+	revokeDevice := deviceHandler.NewRevokeDevice(db, redis)
+	createPairing := deviceHandler.NewCreatePairing(db, redis)
+	redeemPairing := deviceHandler.NewRedeemPairing(db, redis)
+	sendCommand := deviceHandler.NewSendCommand(db, redis)
+	listCommands := deviceHandler.NewListCommands(db, redis)
+
+	group.POST("/pairing/redeem", redeemPairing.HandlerPOST) // 使用配对码登记新设备（无需登录）
+
+	// 以下接口需要设备管理权限
+	manage := group.Group("", middleware.RequirePermission(db, rbac.PermDeviceManage))
+	manage.POST("/list", listDevices.HandlerPOST)    // 设备列表
+	manage.POST("/rename", renameDevice.HandlerPOST) // 重命名设备
+	manage.POST("/revoke", revokeDevice.HandlerPOST) // 注销设备
+
+	manage.POST("/pairing/create", createPairing.HandlerPOST) // 生成配对码
+
+	manage.POST("/command/send", sendCommand.HandlerPOST)  // 下发远程指令
+	manage.POST("/command/list", listCommands.HandlerPOST) // 指令记录
+}
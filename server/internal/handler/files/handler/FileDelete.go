@@ -0,0 +1,127 @@
+// handler/FileDelete.go
+package handler
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/access"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/files/interface"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+	"github.com/sunyuanling/server/websocket/protocol"
+)
+
+type fileDelete struct {
+	*base.BaseHandler
+	cfg *config.Config
+}
+
+// FileDeleteRequest 删除请求参数
+type FileDeleteRequest struct {
+	Path      string `json:"path" binding:"required"` // 所在目录路径（或完整路径）
+	Name      string `json:"name"`                    // 文件/目录名，为空时 path 即完整路径
+	Recursive bool   `json:"recursive"`               // 删除非空目录
+}
+
+// HandlerPOST 删除文件或目录
+func (f *fileDelete) HandlerPOST(c *gin.Context) {
+	payload, ok := f.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req FileDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数解析失败: "+err.Error())
+		return
+	}
+
+	fullPath := filepath.FromSlash(req.Path)
+	if req.Name != "" && filepath.Base(fullPath) != req.Name {
+		fullPath = filepath.Join(fullPath, req.Name)
+	}
+	fullPath = filepath.Clean(fullPath)
+
+	// 只能删除允许存储路径下的内容，存储根目录本身不允许删除
+	if !access.InAllowedPaths(f.cfg, fullPath) || isAllowedRoot(f.cfg, fullPath) {
+		logger.Warn("删除路径访问被拒绝",
+			zap.Uint("user_id", userID),
+			zap.String("path", fullPath),
+		)
+		response.Forbidden(c, "无权访问该路径")
+		return
+	}
+	if !checkPathPermission(c, f.DB, rbac.PermFilesDelete, fullPath) {
+		return
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			response.NotFound(c, "文件不存在")
+			return
+		}
+		logger.Error("检查文件状态失败", zap.String("path", fullPath), zap.Error(err))
+		response.InternalError(c, "检查文件状态失败")
+		return
+	}
+
+	if info.IsDir() && req.Recursive {
+		err = os.RemoveAll(fullPath)
+	} else {
+		err = os.Remove(fullPath)
+	}
+	if err != nil {
+		if info.IsDir() && !req.Recursive {
+			response.BadRequest(c, "目录不为空，如需删除请设置 recursive")
+			return
+		}
+		logger.Error("删除文件失败",
+			zap.Uint("user_id", userID),
+			zap.String("path", fullPath),
+			zap.Error(err),
+		)
+		response.InternalError(c, "删除失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionFileDelete,
+		TargetType: audit.TargetFile,
+		TargetID:   fullPath,
+		Detail:     map[string]interface{}{"is_dir": info.IsDir(), "recursive": req.Recursive},
+	})
+	logger.Info("文件已删除",
+		zap.Uint("user_id", userID),
+		zap.String("path", fullPath),
+		zap.Bool("is_dir", info.IsDir()),
+	)
+
+	// 通知共享该路径的分组成员和共享接收人
+	websocket.NotifyFolderChange(userID, fullPath, protocol.FolderActionDeleted)
+
+	response.Success(c, gin.H{
+		"path":   fullPath,
+		"is_dir": info.IsDir(),
+	})
+}
+
+// NewFileDelete 创建删除处理器
+func NewFileDelete(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.FileDelete {
+	return &fileDelete{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+	}
+}
@@ -9,17 +9,22 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/audit"
 	"github.com/sunyuanling/server/internal/base"
 	_interface "github.com/sunyuanling/server/internal/handler/files/interface"
+	"github.com/sunyuanling/server/internal/instrument"
 	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
 	"github.com/sunyuanling/server/pkg/logger"
 	"github.com/sunyuanling/server/pkg/response"
 	tokenFunc "github.com/sunyuanling/server/pkg/tokn"
 	"github.com/sunyuanling/server/websocket"
+	"github.com/sunyuanling/server/websocket/protocol"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -63,12 +68,10 @@ func (g *getFile) HandlerGET(c *gin.Context) {
 		return
 	}
 
-	var deviceID uint
-	if deviceIDStr != "" {
-		if id, err := strconv.ParseUint(deviceIDStr, 10, 32); err == nil {
-			deviceID = uint(id)
-		}
+	if deviceIDStr == "" {
+		deviceIDStr = payload.DeviceID
 	}
+	deviceID := g.resolveDeviceID(userID, deviceIDStr)
 
 	// 5. 智能构建完整路径
 	var fullPath string
@@ -100,6 +103,9 @@ func (g *getFile) HandlerGET(c *gin.Context) {
 		response.Forbidden(c, "无权访问该路径")
 		return
 	}
+	if !checkPathPermission(c, g.DB, rbac.PermFilesRead, fullPath) {
+		return
+	}
 
 	// 7. 获取文件信息
 	fileInfo, err := os.Stat(fullPath)
@@ -167,6 +173,17 @@ func (g *getFile) HandlerGET(c *gin.Context) {
 	// 11. 处理 Range 请求（断点续传）
 	rangeHeader := c.GetHeader("Range")
 
+	detail := map[string]interface{}{"size": fileSize, "history_id": history.ID}
+	if rangeHeader != "" {
+		detail["range"] = rangeHeader
+	}
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionFileDownload,
+		TargetType: audit.TargetFile,
+		TargetID:   fullPath,
+		Detail:     detail,
+	})
+
 	if rangeHeader == "" {
 		// 没有 Range，返回完整文件
 		g.serveFullFile(c, file, fileInfo, name, userID, history.ID)
@@ -190,16 +207,18 @@ func (g *getFile) serveFullFile(c *gin.Context, file *os.File, fileInfo os.FileI
 			Update("download_status", model.DownloadStatusDownloading).Error
 	}
 
-	_ = websocket.SendToUser(userID, "file_download", map[string]interface{}{
-		"event":      "start",
-		"file_name":  fileName,
-		"file_size":  fileInfo.Size(),
-		"history_id": historyID,
+	_ = websocket.SendToUser(userID, websocket.MessageTypeFileDownload, protocol.FileTransfer{
+		Event:     protocol.FileEventStart,
+		FileName:  fileName,
+		FileSize:  fileInfo.Size(),
+		HistoryID: historyID,
 	})
 
 	c.Status(http.StatusOK)
 
+	started := time.Now()
 	written, err := io.Copy(c.Writer, file)
+	instrument.ObserveTransfer(instrument.Download, written, started, err)
 	if err != nil {
 		logger.Error("文件传输失败", zap.Error(err))
 		_ = g.DB.Model(&model.DownloadHistory{}).
@@ -217,11 +236,11 @@ func (g *getFile) serveFullFile(c *gin.Context, file *os.File, fileInfo os.FileI
 			}).Error
 	}
 
-	_ = websocket.SendToUser(userID, "file_download", map[string]interface{}{
-		"event":      "completed",
-		"file_name":  fileName,
-		"file_size":  written,
-		"history_id": historyID,
+	_ = websocket.SendToUser(userID, websocket.MessageTypeFileDownload, protocol.FileTransfer{
+		Event:     protocol.FileEventCompleted,
+		FileName:  fileName,
+		FileSize:  written,
+		HistoryID: historyID,
 	})
 
 	logger.Info("文件下载完成",
@@ -287,8 +306,13 @@ func (g *getFile) serveRangeFile(c *gin.Context, file *os.File, fileSize int64,
 
 	c.Status(http.StatusPartialContent)
 
+	started := time.Now()
 	written, err := io.CopyN(c.Writer, file, contentLength)
-	if err != nil && err != io.EOF {
+	if err == io.EOF {
+		err = nil
+	}
+	instrument.ObserveTransfer(instrument.Download, written, started, err)
+	if err != nil {
 		logger.Error("文件传输失败", zap.Error(err))
 		return
 	}
@@ -303,11 +327,11 @@ func (g *getFile) serveRangeFile(c *gin.Context, file *os.File, fileSize int64,
 				}).Error
 		}
 
-		_ = websocket.SendToUser(userID, "file_download", map[string]interface{}{
-			"event":      "completed",
-			"file_name":  fileName,
-			"file_size":  fileSize,
-			"history_id": historyID,
+		_ = websocket.SendToUser(userID, websocket.MessageTypeFileDownload, protocol.FileTransfer{
+			Event:     protocol.FileEventCompleted,
+			FileName:  fileName,
+			FileSize:  fileSize,
+			HistoryID: historyID,
 		})
 	}
 
@@ -320,6 +344,34 @@ func (g *getFile) serveRangeFile(c *gin.Context, file *os.File, fileSize int64,
 	)
 }
 
+// resolveDeviceID 将请求中的 device_id 解析为 device 表主键
+// 优先按客户端设备标识（WebSocket 连接时上报的 device_id）查找，其次按主键查找，均需属于当前用户
+func (g *getFile) resolveDeviceID(userID uint, deviceIDStr string) uint {
+	if deviceIDStr == "" {
+		return 0
+	}
+
+	device, err := model.FindDeviceByIdentifier(g.DB, userID, deviceIDStr)
+	if err != nil {
+		logger.Warn("查询设备失败", zap.String("device_id", deviceIDStr), zap.Error(err))
+		return 0
+	}
+	if device != nil {
+		return device.ID
+	}
+
+	id, err := strconv.ParseUint(deviceIDStr, 10, 32)
+	if err != nil {
+		return 0
+	}
+	var count int64
+	g.DB.Model(&model.Device{}).Where("id = ? AND user_id = ?", id, userID).Count(&count)
+	if count == 0 {
+		return 0
+	}
+	return uint(id)
+}
+
 // isPathAllowed 路径安全检查
 func (g *getFile) isPathAllowed(path string) bool {
 	cleanPath := filepath.Clean(path)
@@ -6,17 +6,22 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/audit"
 	"github.com/sunyuanling/server/internal/base"
 	_interface "github.com/sunyuanling/server/internal/handler/files/interface"
+	"github.com/sunyuanling/server/internal/instrument"
 	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
 	"github.com/sunyuanling/server/pkg/logger"
 	"github.com/sunyuanling/server/pkg/response"
 	tokenFunc "github.com/sunyuanling/server/pkg/tokn"
 	"github.com/sunyuanling/server/websocket"
+	"github.com/sunyuanling/server/websocket/protocol"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -122,6 +127,9 @@ func (f *fileUpload) HandlerPOST(c *gin.Context) {
 		response.Forbidden(c, "无权访问该路径")
 		return
 	}
+	if !checkPathPermission(c, f.DB, rbac.PermFilesWrite, fullPath) {
+		return
+	}
 
 	// 8. 验证文件扩展名
 	ext := filepath.Ext(req.Name)
@@ -190,6 +198,9 @@ func (f *fileUpload) handleCheck(c *gin.Context, fullPath, fileName string, user
 
 // handleUpload 处理上传逻辑
 func (f *fileUpload) handleUpload(c *gin.Context, fullPath, fileName string, userID uint) {
+	// 请求体在解析表单时读取，从这里开始计时
+	started := time.Now()
+
 	// 1. 检查文件是否已存在
 	if _, err := os.Stat(fullPath); err == nil {
 		logger.Warn("文件已存在",
@@ -260,16 +271,19 @@ func (f *fileUpload) handleUpload(c *gin.Context, fullPath, fileName string, use
 	}
 
 	// 7. 发送 WebSocket 通知：开始上传
-	_ = websocket.SendToUser(userID, "file_upload", map[string]interface{}{
-		"event":        "start",
-		"file_name":    fileName,
-		"file_size":    fileHeader.Size,
-		"history_id":   history.ID,
-		"storage_path": fullPath,
+	_ = websocket.SendToUser(userID, websocket.MessageTypeFileUpload, protocol.FileTransfer{
+		Event:       protocol.FileEventStart,
+		FileName:    fileName,
+		FileSize:    fileHeader.Size,
+		HistoryID:   history.ID,
+		StoragePath: fullPath,
 	})
 
-	// 8. 保存文件
+	// 8. 保存文件（记录是否覆盖已有文件，用于共享文件夹通知）
+	_, statErr := os.Stat(fullPath)
+	overwritten := statErr == nil
 	if err := c.SaveUploadedFile(fileHeader, fullPath); err != nil {
+		instrument.ObserveTransfer(instrument.Upload, 0, started, err)
 		logger.Error("保存文件失败",
 			zap.Error(err),
 			zap.String("path", fullPath),
@@ -281,20 +295,35 @@ func (f *fileUpload) handleUpload(c *gin.Context, fullPath, fileName string, use
 		return
 	}
 
+	instrument.ObserveTransfer(instrument.Upload, fileHeader.Size, started, nil)
+
 	// 9. 标记为完成
 	if history.ID > 0 {
 		_ = history.MarkAsCompleted(f.DB)
 	}
 
 	// 10. 发送 WebSocket 通知：上传完成
-	_ = websocket.SendToUser(userID, "file_upload", map[string]interface{}{
-		"event":        "completed",
-		"file_name":    fileName,
-		"file_size":    fileHeader.Size,
-		"history_id":   history.ID,
-		"storage_path": fullPath,
+	_ = websocket.SendToUser(userID, websocket.MessageTypeFileUpload, protocol.FileTransfer{
+		Event:       protocol.FileEventCompleted,
+		FileName:    fileName,
+		FileSize:    fileHeader.Size,
+		HistoryID:   history.ID,
+		StoragePath: fullPath,
 	})
 
+	// 11. 通知共享了该目录的分组
+	action := protocol.FolderActionCreated
+	if overwritten {
+		action = protocol.FolderActionUpdated
+	}
+	websocket.NotifyFolderChange(userID, fullPath, action)
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionFileUpload,
+		TargetType: audit.TargetFile,
+		TargetID:   fullPath,
+		Detail:     map[string]interface{}{"size": fileHeader.Size, "overwritten": overwritten},
+	})
 	logger.Info("文件上传完成",
 		zap.Uint("user_id", userID),
 		zap.String("file_name", fileName),
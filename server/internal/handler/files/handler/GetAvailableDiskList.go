@@ -16,6 +16,7 @@ import (
 
 	"github.com/sunyuanling/server/config"
 	"github.com/sunyuanling/server/internal/base"
+	"github.com/sunyuanling/server/internal/diskhealth"
 	_interface "github.com/sunyuanling/server/internal/handler/files/interface"
 	"github.com/sunyuanling/server/pkg/logger"
 	"github.com/sunyuanling/server/pkg/response"
@@ -88,10 +89,12 @@ type IOStats struct {
 
 // HealthStatus 健康状态
 type HealthStatus struct {
-	Status       string  `json:"status"`        // healthy, warning, critical
-	SpaceWarning bool    `json:"space_warning"` // 空间不足警告
-	Message      string  `json:"message"`       // 状态消息
-	Temperature  float64 `json:"temperature"`   // 温度（如果可用）
+	Status       string              `json:"status"`        // healthy, warning, critical
+	SpaceWarning bool                `json:"space_warning"` // 空间不足警告
+	Message      string              `json:"message"`       // 状态消息
+	Temperature  float64             `json:"temperature"`   // 温度（如果可用，多块磁盘时取最高）
+	FSErrors     int64               `json:"fs_errors"`     // 文件系统记录的错误数（ext4）
+	Disks        []diskhealth.Health `json:"disks"`         // 所在物理磁盘的 SMART 状态
 }
 
 // GetDiskRequest 请求参数
@@ -305,7 +308,7 @@ func (h *getAvailableDiskList) getDetailedDiskInfo(diskPath string) (*DiskInfoDe
 	detail.IOStats = h.getIOStats(partition.Device)
 
 	// 计算健康状态
-	detail.HealthStatus = h.calculateHealthStatus(usage)
+	detail.HealthStatus = h.calculateHealthStatus(usage, partition.Device)
 
 	return detail, nil
 }
@@ -405,26 +408,49 @@ func (h *getAvailableDiskList) getIOStats(device string) *IOStats {
 	return nil
 }
 
-// calculateHealthStatus 计算健康状态
-func (h *getAvailableDiskList) calculateHealthStatus(usage *disk.UsageStat) HealthStatus {
+// calculateHealthStatus 计算健康状态：空间使用率、文件系统错误和所在物理磁盘的 SMART 状态取最差的一项
+func (h *getAvailableDiskList) calculateHealthStatus(usage *disk.UsageStat, device string) HealthStatus {
 	status := HealthStatus{
 		Status:       "healthy",
 		SpaceWarning: false,
 	}
+	var problems []string
 
 	// 根据使用率判断健康状态
 	if usage.UsedPercent >= 95 {
 		status.Status = "critical"
 		status.SpaceWarning = true
-		status.Message = "磁盘空间严重不足"
+		problems = append(problems, "磁盘空间严重不足")
 	} else if usage.UsedPercent >= 90 {
 		status.Status = "warning"
 		status.SpaceWarning = true
-		status.Message = "磁盘空间不足"
-	} else {
-		status.Message = "磁盘状态正常"
+		problems = append(problems, "磁盘空间不足")
+	}
+
+	// 文件系统错误（ext4 自挂载以来的错误数）
+	if n, ok := diskhealth.FilesystemErrors(device); ok && n > 0 {
+		status.FSErrors = n
+		status.Status = diskhealth.Worse(status.Status, "warning")
+		problems = append(problems, fmt.Sprintf("文件系统记录了 %d 个错误，建议检查", n))
 	}
 
+	// 物理磁盘的 SMART 数据、温度和坏扇区增长（由后台定时采集）
+	status.Disks = diskhealth.ForDevice(device)
+	for _, d := range status.Disks {
+		if d.Temperature != nil && *d.Temperature > status.Temperature {
+			status.Temperature = *d.Temperature
+		}
+		status.Status = diskhealth.Worse(status.Status, d.Status)
+		for _, p := range d.Problems {
+			problems = append(problems, d.Device+"："+p)
+		}
+	}
+
+	if len(problems) == 0 {
+		status.Message = "磁盘状态正常"
+	} else {
+		status.Message = strings.Join(problems, "；")
+	}
 	return status
 }
 
@@ -456,14 +482,8 @@ func (h *getAvailableDiskList) shouldSkipPartition(partition disk.PartitionStat)
 // checkIfSSD 检查是否为SSD
 func (h *getAvailableDiskList) checkIfSSD(device string) bool {
 	if runtime.GOOS == "linux" {
-		// Linux: 检查 /sys/block/*/queue/rotational
-		deviceName := strings.TrimPrefix(device, "/dev/")
-		rotationalPath := fmt.Sprintf("/sys/block/%s/queue/rotational", deviceName)
-
-		data, err := os.ReadFile(rotationalPath)
-		if err == nil {
-			return strings.TrimSpace(string(data)) == "0"
-		}
+		// Linux: 分区（sda1、nvme0n1p2）、LVM 等先映射到物理磁盘，再检查 queue/rotational
+		return diskhealth.IsSSD(device)
 	}
 
 	// 其他系统暂不支持
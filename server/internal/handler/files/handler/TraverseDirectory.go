@@ -11,6 +11,7 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"github.com/sunyuanling/server/internal/base"
+	"github.com/sunyuanling/server/internal/rbac"
 	"github.com/sunyuanling/server/pkg/logger"
 	"github.com/sunyuanling/server/pkg/response"
 
@@ -80,6 +81,11 @@ func (h *traverseDirectory) HandlerPOST(c *gin.Context) {
 		req.Path = req.Path + string(filepath.Separator)
 	}
 
+	// 校验目录读取权限
+	if !checkPathPermission(c, h.DB, rbac.PermFilesRead, req.Path) {
+		return
+	}
+
 	// 检查路径是否存在
 	fileInfo, err := os.Stat(req.Path)
 	if os.IsNotExist(err) {
@@ -242,6 +248,15 @@ func (h *traverseDirectory) HandlePOSTWithPagination(c *gin.Context) {
 
 	req.Path = filepath.Clean(req.Path)
 
+	// 校验目录读取权限
+	if !c.GetBool("Auth") {
+		response.Unauthorized(c, "请先登录")
+		return
+	}
+	if !checkPathPermission(c, h.DB, rbac.PermFilesRead, req.Path) {
+		return
+	}
+
 	// 路径验证（同上）
 	fileInfo, err := os.Stat(req.Path)
 	if err != nil || !fileInfo.IsDir() {
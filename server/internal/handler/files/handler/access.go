@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/access"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	tokenFunc "github.com/sunyuanling/server/pkg/tokn"
+)
+
+// checkPathPermission 校验当前用户对路径的权限（rbac 全局/目录范围授权，或文件夹共享），失败时已写入响应
+func checkPathPermission(c *gin.Context, db *gorm.DB, perm, path string) bool {
+	payload, ok := c.MustGet("UserInfo").(*tokenFunc.TokenPayload)
+	if !ok {
+		response.InternalError(c, "用户信息类型错误")
+		return false
+	}
+	userID := uint(payload.UserID)
+
+	allowed, err := access.CanAccess(c, db, userID, perm, path)
+	if err != nil {
+		logger.Error("校验路径权限失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "校验路径权限失败")
+		return false
+	}
+	if !allowed {
+		logger.Warn("路径权限不足",
+			zap.Uint("user_id", userID),
+			zap.String("permission", perm),
+			zap.String("path", path),
+		)
+		audit.Log(c, audit.Event{
+			Action:     audit.ActionFileAccess,
+			Result:     model.AuditDenied,
+			TargetType: audit.TargetFile,
+			TargetID:   path,
+			Detail:     map[string]interface{}{"permission": perm},
+		})
+		response.Forbidden(c, "无权访问该路径")
+		return false
+	}
+	return true
+}
+
+// isAllowedRoot 路径是否为允许的存储根目录本身（不允许删除）
+func isAllowedRoot(cfg *config.Config, path string) bool {
+	clean := strings.ToUpper(filepath.Clean(path))
+	for _, allowed := range cfg.GetAllowedPaths() {
+		if clean == strings.ToUpper(filepath.Clean(allowed)) {
+			return true
+		}
+	}
+	return false
+}
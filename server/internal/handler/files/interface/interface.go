@@ -30,3 +30,8 @@ type FileUpload interface {
 type FileDownloadHis interface {
 	HandlerPOST(c *gin.Context)
 }
+
+// FileDelete 删除文件或目录
+type FileDelete interface {
+	HandlerPOST(c *gin.Context)
+}
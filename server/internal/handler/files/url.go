@@ -8,6 +8,8 @@ import (
 	"github.com/sunyuanling/server/config"
 	"github.com/sunyuanling/server/internal/handler"
 	filesHandler "github.com/sunyuanling/server/internal/handler/files/handler"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/middleware"
 )
 
 type Router struct {
@@ -29,11 +31,17 @@ func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redi
 	getFile := filesHandler.NewGetFile(db, redis, r.cfg)
 	uploadFile := filesHandler.NewFileUpload(db, redis, r.cfg)
 	downloadHistory := filesHandler.NewGetDownloadHis(db, redis, r.cfg)
+	deleteFile := filesHandler.NewFileDelete(db, redis, r.cfg)
+
+	// 权限：磁盘列表需要全局权限；文件读写删除由处理器按路径校验（目录范围授权或文件夹共享）
+	disksRead := middleware.RequirePermission(db, rbac.PermDisksRead)
+	filesRead := middleware.RequireScopedPermission(db, rbac.PermFilesRead)
 
 	// 注册路由
-	group.POST("/available-disks", getAvailableDiskList.HandlerPOST) // 获取可用磁盘列表
-	group.POST("/traverse-directory", traverseDirectory.HandlerPOST) // 遍历目录
-	group.GET("/get-file", getFile.HandlerGET)                       // 获取文件
-	group.POST("/upload-file", uploadFile.HandlerPOST)               // 上传文件
-	group.POST("/download-history", downloadHistory.HandlerPOST)     // 获取下载记录
+	group.POST("/available-disks", disksRead, getAvailableDiskList.HandlerPOST) // 获取可用磁盘列表
+	group.POST("/traverse-directory", traverseDirectory.HandlerPOST)            // 遍历目录
+	group.GET("/get-file", getFile.HandlerGET)                                  // 获取文件
+	group.POST("/upload-file", uploadFile.HandlerPOST)                          // 上传文件
+	group.POST("/delete-file", deleteFile.HandlerPOST)                          // 删除文件/目录
+	group.POST("/download-history", filesRead, downloadHistory.HandlerPOST)     // 获取下载记录
 }
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/group/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type acceptInvite struct {
+	*base.BaseHandler
+}
+
+func NewAcceptInvite(db *gorm.DB, redis *redis.Client) _interface.AcceptInvite {
+	return &acceptInvite{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 接受分组邀请，之后才接收分组消息、共享文件夹通知，其他成员才能看到在线状态
+// 拒绝邀请使用 /remove-member 移除自己
+func (h *acceptInvite) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req GroupIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	group, member, ok := findMembership(c, h.DB, req.ID, userID)
+	if !ok {
+		return
+	}
+	if member.IsActive() {
+		response.Success(c, gin.H{"group_id": group.ID, "status": member.Status})
+		return
+	}
+
+	err := h.DB.Model(&model.WsGroupMember{}).
+		Where("id = ? AND status = ?", member.ID, model.GroupMemberInvited).
+		Update("status", model.GroupMemberActive).Error
+	if err != nil {
+		logger.Error("接受分组邀请失败", zap.Uint("group_id", group.ID), zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "接受分组邀请失败")
+		return
+	}
+
+	websocket.JoinGroup(group.Name, userID)
+
+	logger.Info("已接受分组邀请",
+		zap.Uint("group_id", group.ID),
+		zap.Uint("user_id", userID),
+	)
+
+	response.Success(c, gin.H{
+		"group_id": group.ID,
+		"status":   model.GroupMemberActive,
+	})
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/group/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type addMember struct {
+	*base.BaseHandler
+}
+
+func NewAddMember(db *gorm.DB, redis *redis.Client) _interface.AddMember {
+	return &addMember{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// AddMemberRequest 添加成员请求参数
+type AddMemberRequest struct {
+	ID     uint   `json:"id" binding:"required"`      // 分组ID
+	UserID uint   `json:"user_id" binding:"required"` // 被添加的用户
+	Role   string `json:"role"`                       // admin/member，默认 member
+}
+
+// HandlerPOST 邀请成员或修改成员角色（owner/admin），被邀请的用户接受后才加入分组
+func (h *addMember) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+	if req.Role == "" {
+		req.Role = model.GroupRoleMember
+	}
+	if !model.IsValidGroupRole(req.Role) {
+		response.BadRequest(c, "无效的成员角色")
+		return
+	}
+
+	group, member, ok := loadMembership(c, h.DB, req.ID, userID)
+	if !ok {
+		return
+	}
+	if !member.CanManage() {
+		response.Forbidden(c, "只有分组管理员可以添加成员")
+		return
+	}
+	if req.UserID == group.OwnerID {
+		response.BadRequest(c, "不能修改创建者的角色")
+		return
+	}
+
+	var user model.User
+	if err := h.DB.Select("id").First(&user, req.UserID).Error; err != nil {
+		response.NotFound(c, "用户不存在")
+		return
+	}
+
+	// 已有记录时只修改角色，不改变邀请状态
+	record := &model.WsGroupMember{GroupID: group.ID, UserID: req.UserID, Role: req.Role, Status: model.GroupMemberInvited}
+	err := h.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "group_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(record).Error
+	if err != nil {
+		logger.Error("添加分组成员失败", zap.Uint("group_id", group.ID), zap.Error(err))
+		response.InternalError(c, "添加分组成员失败")
+		return
+	}
+
+	existing, err := model.FindGroupMember(h.DB, group.ID, req.UserID)
+	if err != nil {
+		logger.Error("查询分组成员失败", zap.Uint("group_id", group.ID), zap.Error(err))
+		response.InternalError(c, "查询分组成员失败")
+		return
+	}
+	status := model.GroupMemberInvited
+	if existing != nil {
+		status = existing.Status
+	}
+	if status == model.GroupMemberInvited {
+		notifyInvited(group, userID, []uint{req.UserID})
+	}
+
+	logger.Info("分组成员已邀请",
+		zap.Uint("operator", userID),
+		zap.Uint("group_id", group.ID),
+		zap.Uint("user_id", req.UserID),
+		zap.String("role", req.Role),
+	)
+
+	response.Success(c, gin.H{
+		"group_id": group.ID,
+		"user_id":  req.UserID,
+		"role":     req.Role,
+		"status":   status,
+	})
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/group/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type createGroup struct {
+	*base.BaseHandler
+	cfg *config.Config
+}
+
+func NewCreateGroup(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.CreateGroup {
+	return &createGroup{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+	}
+}
+
+// CreateGroupRequest 创建分组请求参数
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	FolderPath  string `json:"folder_path"` // 关联的共享文件夹（可选）
+	UserIDs     []uint `json:"user_ids"`    // 邀请的成员，接受邀请后加入
+}
+
+// HandlerPOST 创建分组，当前用户为 owner
+func (h *createGroup) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len([]rune(req.Name)) > 100 {
+		response.BadRequest(c, "分组名称不能为空且不超过100个字符")
+		return
+	}
+	if req.FolderPath != "" && !checkFolder(c, h.DB, h.cfg, userID, userID, req.FolderPath) {
+		return
+	}
+
+	var count int64
+	if err := h.DB.Model(&model.WsGroup{}).Where("name = ?", req.Name).Count(&count).Error; err != nil {
+		logger.Error("查询分组失败", zap.Error(err))
+		response.InternalError(c, "创建分组失败")
+		return
+	}
+	if count > 0 {
+		response.Error(c, 409, "分组名称已存在")
+		return
+	}
+
+	group := &model.WsGroup{
+		Name:        req.Name,
+		OwnerID:     userID,
+		Description: req.Description,
+		FolderPath:  req.FolderPath,
+	}
+	if err := model.CreateWsGroup(h.DB, group, req.UserIDs); err != nil {
+		logger.Error("创建分组失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "创建分组失败")
+		return
+	}
+
+	// 其他成员需要接受邀请后才加入
+	websocket.JoinGroup(group.Name, userID)
+	notifyInvited(group, userID, req.UserIDs)
+
+	logger.Info("分组已创建",
+		zap.Uint("user_id", userID),
+		zap.Uint("group_id", group.ID),
+		zap.String("name", group.Name),
+	)
+
+	response.Success(c, group)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/group/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type deleteGroup struct {
+	*base.BaseHandler
+}
+
+func NewDeleteGroup(db *gorm.DB, redis *redis.Client) _interface.DeleteGroup {
+	return &deleteGroup{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// GroupIDRequest 按分组ID操作的请求参数
+type GroupIDRequest struct {
+	ID uint `json:"id" binding:"required"`
+}
+
+// HandlerPOST 删除分组（仅 owner）
+func (h *deleteGroup) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req GroupIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	group, member, ok := loadMembership(c, h.DB, req.ID, userID)
+	if !ok {
+		return
+	}
+	if member.Role != model.GroupRoleOwner {
+		response.Forbidden(c, "只有分组创建者可以删除分组")
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", group.ID).Delete(&model.WsGroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(group).Error
+	})
+	if err != nil {
+		logger.Error("删除分组失败", zap.Uint("group_id", group.ID), zap.Error(err))
+		response.InternalError(c, "删除分组失败")
+		return
+	}
+
+	websocket.DeleteGroup(group.Name)
+
+	logger.Info("分组已删除",
+		zap.Uint("user_id", userID),
+		zap.Uint("group_id", group.ID),
+		zap.String("name", group.Name),
+	)
+
+	response.Success(c, gin.H{
+		"id":      group.ID,
+		"message": "分组已删除",
+	})
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/group/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type groupMembers struct {
+	*base.BaseHandler
+}
+
+func NewGroupMembers(db *gorm.DB, redis *redis.Client) _interface.GroupMembers {
+	return &groupMembers{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// MemberItem 分组成员
+type MemberItem struct {
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username"`
+	Avatar   string    `json:"avatar"`
+	Role     string    `json:"role"`
+	Status   string    `json:"status"` // invited/active
+	Online   bool      `json:"online" gorm:"-"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// HandlerPOST 获取分组成员
+func (h *groupMembers) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req GroupIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	group, _, ok := loadMembership(c, h.DB, req.ID, uint(payload.UserID))
+	if !ok {
+		return
+	}
+
+	var members []MemberItem
+	err := h.DB.Table("ws_group_member m").
+		Select("m.user_id, u.username, u.avatar, m.role, m.status, m.created_at AS joined_at").
+		Joins(`JOIN "user" u ON u.id = m.user_id`).
+		Where("m.group_id = ?", group.ID).
+		Order("m.id asc").
+		Scan(&members).Error
+	if err != nil {
+		logger.Error("查询分组成员失败", zap.Uint("group_id", group.ID), zap.Error(err))
+		response.InternalError(c, "查询分组成员失败")
+		return
+	}
+
+	for i := range members {
+		// 未接受邀请的用户不公开在线状态
+		if members[i].Status == model.GroupMemberActive {
+			members[i].Online = websocket.IsUserOnline(members[i].UserID)
+		}
+	}
+
+	response.Success(c, gin.H{
+		"group": group,
+		"list":  members,
+		"total": len(members),
+	})
+}
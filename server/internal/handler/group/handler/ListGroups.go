@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/group/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listGroups struct {
+	*base.BaseHandler
+}
+
+func NewListGroups(db *gorm.DB, redis *redis.Client) _interface.ListGroups {
+	return &listGroups{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// GroupItem 分组列表项
+type GroupItem struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	OwnerID     uint      `json:"owner_id"`
+	Description string    `json:"description"`
+	FolderPath  string    `json:"folder_path"`
+	Role        string    `json:"role"`         // 当前用户在分组中的角色
+	Status      string    `json:"status"`       // invited：待接受的邀请，active：已加入
+	MemberCount int64     `json:"member_count"` // 成员数
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// HandlerPOST 获取当前用户加入的分组和待接受的邀请
+func (h *listGroups) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var items []GroupItem
+	err := h.DB.Table("ws_group g").
+		Select("g.id, g.name, g.owner_id, g.description, g.folder_path, m.role, m.status, g.created_at, "+
+			"(SELECT COUNT(*) FROM ws_group_member c WHERE c.group_id = g.id AND c.status = ?) AS member_count", model.GroupMemberActive).
+		Joins("JOIN ws_group_member m ON m.group_id = g.id AND m.user_id = ?", userID).
+		Order("g.id asc").
+		Scan(&items).Error
+	if err != nil {
+		logger.Error("查询分组列表失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "查询分组列表失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  items,
+		"total": len(items),
+	})
+}
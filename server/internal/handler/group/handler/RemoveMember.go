@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/group/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type removeMember struct {
+	*base.BaseHandler
+}
+
+func NewRemoveMember(db *gorm.DB, redis *redis.Client) _interface.RemoveMember {
+	return &removeMember{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// RemoveMemberRequest 移除成员请求参数
+type RemoveMemberRequest struct {
+	ID     uint `json:"id" binding:"required"`      // 分组ID
+	UserID uint `json:"user_id" binding:"required"` // 被移除的用户，传自己表示退出分组
+}
+
+// HandlerPOST 移除成员（owner/admin），或成员自己退出
+func (h *removeMember) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req RemoveMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	// 被邀请的用户可以拒绝邀请（移除自己）
+	group, member, ok := findMembership(c, h.DB, req.ID, userID)
+	if !ok {
+		return
+	}
+	if req.UserID != userID && !(member.IsActive() && member.CanManage()) {
+		response.Forbidden(c, "只有分组管理员可以移除成员")
+		return
+	}
+	if req.UserID == group.OwnerID {
+		response.BadRequest(c, "创建者不能退出分组，请删除分组")
+		return
+	}
+
+	result := h.DB.Where("group_id = ? AND user_id = ?", group.ID, req.UserID).Delete(&model.WsGroupMember{})
+	if result.Error != nil {
+		logger.Error("移除分组成员失败", zap.Uint("group_id", group.ID), zap.Error(result.Error))
+		response.InternalError(c, "移除分组成员失败")
+		return
+	}
+	if result.RowsAffected == 0 {
+		response.NotFound(c, "该用户不是分组成员")
+		return
+	}
+
+	websocket.LeaveGroup(group.Name, req.UserID)
+
+	logger.Info("分组成员已移除",
+		zap.Uint("operator", userID),
+		zap.Uint("group_id", group.ID),
+		zap.Uint("user_id", req.UserID),
+	)
+
+	response.Success(c, gin.H{
+		"group_id": group.ID,
+		"user_id":  req.UserID,
+	})
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/group/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type updateGroup struct {
+	*base.BaseHandler
+	cfg *config.Config
+}
+
+func NewUpdateGroup(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.UpdateGroup {
+	return &updateGroup{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+	}
+}
+
+// UpdateGroupRequest 修改分组请求参数，字段为 nil 表示不修改
+type UpdateGroupRequest struct {
+	ID          uint    `json:"id" binding:"required"`
+	Description *string `json:"description"`
+	FolderPath  *string `json:"folder_path"` // 空字符串表示取消关联
+}
+
+// HandlerPOST 修改分组描述和共享文件夹（owner/admin）
+func (h *updateGroup) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req UpdateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	group, member, ok := loadMembership(c, h.DB, req.ID, userID)
+	if !ok {
+		return
+	}
+	if !member.CanManage() {
+		response.Forbidden(c, "只有分组管理员可以修改分组")
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.FolderPath != nil {
+		if *req.FolderPath != "" && !checkFolder(c, h.DB, h.cfg, userID, group.OwnerID, *req.FolderPath) {
+			return
+		}
+		updates["folder_path"] = *req.FolderPath
+	}
+	if len(updates) == 0 {
+		response.Success(c, group)
+		return
+	}
+
+	if err := h.DB.Model(group).Updates(updates).Error; err != nil {
+		logger.Error("修改分组失败", zap.Uint("group_id", group.ID), zap.Error(err))
+		response.InternalError(c, "修改分组失败")
+		return
+	}
+
+	response.Success(c, group)
+}
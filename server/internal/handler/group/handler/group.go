@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/access"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+// loadMembership 查询分组及当前用户的成员记录，不是成员或尚未接受邀请时返回 403，失败时已写入响应
+func loadMembership(c *gin.Context, db *gorm.DB, groupID, userID uint) (*model.WsGroup, *model.WsGroupMember, bool) {
+	group, member, ok := findMembership(c, db, groupID, userID)
+	if !ok {
+		return nil, nil, false
+	}
+	if !member.IsActive() {
+		response.Forbidden(c, "尚未接受该分组的邀请")
+		return nil, nil, false
+	}
+	return group, member, true
+}
+
+// findMembership 查询分组及当前用户的成员记录（含未接受的邀请），不是成员时返回 403，失败时已写入响应
+func findMembership(c *gin.Context, db *gorm.DB, groupID, userID uint) (*model.WsGroup, *model.WsGroupMember, bool) {
+	var group model.WsGroup
+	if err := db.First(&group, groupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "分组不存在")
+			return nil, nil, false
+		}
+		logger.Error("查询分组失败", zap.Uint("group_id", groupID), zap.Error(err))
+		response.InternalError(c, "查询分组失败")
+		return nil, nil, false
+	}
+
+	member, err := model.FindGroupMember(db, groupID, userID)
+	if err != nil {
+		logger.Error("查询分组成员失败", zap.Uint("group_id", groupID), zap.Error(err))
+		response.InternalError(c, "查询分组成员失败")
+		return nil, nil, false
+	}
+	if member == nil {
+		response.Forbidden(c, "不是该分组成员")
+		return nil, nil, false
+	}
+	return &group, member, true
+}
+
+// checkFolder 校验分组共享文件夹：必须在允许的存储路径下，且操作者和分组创建者都可以共享该文件夹
+// 成员通过分组获得该文件夹的访问权限，不能超出创建者自己的权限。失败时已写入响应
+func checkFolder(c *gin.Context, db *gorm.DB, cfg *config.Config, userID, ownerID uint, path string) bool {
+	if !access.InAllowedPaths(cfg, path) {
+		response.Forbidden(c, "无权共享该路径")
+		return false
+	}
+
+	allowed, err := access.CanShare(c, db, userID, path)
+	if err == nil && allowed && ownerID != userID {
+		allowed, err = access.UserCanShare(db, ownerID, path)
+	}
+	if err != nil {
+		logger.Error("检查文件夹共享权限失败", zap.Uint("user_id", userID), zap.String("path", path), zap.Error(err))
+		response.InternalError(c, "检查权限失败")
+		return false
+	}
+	if !allowed {
+		response.Forbidden(c, "无权共享该路径")
+		return false
+	}
+	return true
+}
+
+// notifyInvited 通知被邀请的用户（在线时），接受邀请前不加入 Hub 分组
+func notifyInvited(group *model.WsGroup, inviterID uint, userIDs []uint) {
+	for _, id := range userIDs {
+		if id == inviterID || id == group.OwnerID {
+			continue
+		}
+		_ = websocket.NotifyUser(id, "分组邀请", "你被邀请加入分组「"+group.Name+"」", "info")
+	}
+}
@@ -0,0 +1,43 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// CreateGroup 创建共享空间分组
+type CreateGroup interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListGroups 当前用户加入的分组
+type ListGroups interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// UpdateGroup 修改分组描述和共享文件夹
+type UpdateGroup interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// DeleteGroup 删除分组
+type DeleteGroup interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// GroupMembers 分组成员列表
+type GroupMembers interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// AcceptInvite 接受分组邀请
+type AcceptInvite interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// AddMember 邀请分组成员
+type AddMember interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// RemoveMember 移除分组成员（成员也可以自己退出或拒绝邀请）
+type RemoveMember interface {
+	HandlerPOST(c *gin.Context)
+}
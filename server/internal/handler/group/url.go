@@ -0,0 +1,49 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/handler"
+	groupHandler "github.com/sunyuanling/server/internal/handler/group/handler"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/middleware"
+)
+
+// Router 共享空间分组路由
+type Router struct {
+	cfg *config.Config
+}
+
+// NewRouter 创建分组模块路由（共享文件夹校验需要配置）
+func NewRouter(cfg *config.Config) handler.ModuleRouter {
+	return &Router{
+		cfg: cfg,
+	}
+}
+
+// RegisterRoutes 注册分组相关路由
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	createGroup := groupHandler.NewCreateGroup(db, redis, r.cfg)
+	listGroups := groupHandler.NewListGroups(db, redis)
+	updateGroup := groupHandler.NewUpdateGroup(db, redis, r.cfg)
+	deleteGroup := groupHandler.NewDeleteGroup(db, redis)
+	groupMembers := groupHandler.NewGroupMembers(db, redis)
+	addMember := groupHandler.NewAddMember(db, redis)
+	removeMember := groupHandler.NewRemoveMember(db, redis)
+	acceptInvite := groupHandler.NewAcceptInvite(db, redis)
+
+	group.POST("/list", listGroups.HandlerPOST)            // 我的分组
+	group.POST("/members", groupMembers.HandlerPOST)       // 成员列表
+	group.POST("/accept", acceptInvite.HandlerPOST)        // 接受邀请
+	group.POST("/remove-member", removeMember.HandlerPOST) // 移除成员/退出分组/拒绝邀请
+
+	// 以下接口需要分组管理权限；被邀请的成员没有该权限也能接受邀请、查看和退出分组
+	manage := group.Group("", middleware.RequirePermission(db, rbac.PermGroupManage))
+	manage.POST("/create", createGroup.HandlerPOST)   // 创建分组
+	manage.POST("/update", updateGroup.HandlerPOST)   // 修改分组
+	manage.POST("/delete", deleteGroup.HandlerPOST)   // 删除分组
+	manage.POST("/add-member", addMember.HandlerPOST) // 邀请成员
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	"github.com/sunyuanling/server/internal/diskhealth"
+	_interface "github.com/sunyuanling/server/internal/handler/monitor/interface"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type diskHealth struct {
+	*base.BaseHandler
+}
+
+func NewDiskHealth(db *gorm.DB, redis *redis.Client) _interface.DiskHealth {
+	return &diskHealth{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 各物理磁盘最近一次的 SMART 数据、温度和健康状态
+func (h *diskHealth) HandlerPOST(c *gin.Context) {
+	disks, err := diskhealth.Latest()
+	if err != nil {
+		if errors.Is(err, diskhealth.ErrDisabled) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalError(c, "查询磁盘健康状态失败")
+		return
+	}
+	response.Success(c, gin.H{"list": disks})
+}
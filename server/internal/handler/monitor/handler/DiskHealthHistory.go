@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	"github.com/sunyuanling/server/internal/diskhealth"
+	_interface "github.com/sunyuanling/server/internal/handler/monitor/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type diskHealthHistory struct {
+	*base.BaseHandler
+}
+
+func NewDiskHealthHistory(db *gorm.DB, redis *redis.Client) _interface.DiskHealthHistory {
+	return &diskHealthHistory{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 一块磁盘最近 days 天（默认 30）的温度、通电时间和坏扇区记录，按时间升序
+// disk 为序列号或设备名（如 sda）
+func (h *diskHealthHistory) HandlerPOST(c *gin.Context) {
+	var req struct {
+		Disk string `json:"disk" binding:"required"`
+		Days int    `json:"days"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误")
+		return
+	}
+	if req.Days <= 0 {
+		req.Days = 30
+	}
+
+	samples, err := diskhealth.History(c.Request.Context(), req.Disk, time.Now().AddDate(0, 0, -req.Days))
+	if err != nil {
+		if errors.Is(err, diskhealth.ErrDisabled) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("查询磁盘健康记录失败", zap.Error(err))
+		response.InternalError(c, "查询磁盘健康记录失败")
+		return
+	}
+	response.Success(c, gin.H{"list": samples})
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/monitor/interface"
+	"github.com/sunyuanling/server/internal/monitor"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type latestMetrics struct {
+	*base.BaseHandler
+}
+
+func NewLatestMetrics(db *gorm.DB, redis *redis.Client) _interface.LatestMetrics {
+	return &latestMetrics{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 最近一次采样，服务刚启动还没有采样时 sample 为 null
+func (h *latestMetrics) HandlerPOST(c *gin.Context) {
+	sample, err := monitor.Latest(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, monitor.ErrDisabled) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("查询监控采样失败", zap.Error(err))
+		response.InternalError(c, "查询监控数据失败")
+		return
+	}
+	response.Success(c, gin.H{
+		"interval": monitor.Interval(),
+		"sample":   sample,
+	})
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/monitor/interface"
+	"github.com/sunyuanling/server/internal/monitor"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type metricsHistory struct {
+	*base.BaseHandler
+}
+
+func NewMetricsHistory(db *gorm.DB, redis *redis.Client) _interface.MetricsHistory {
+	return &metricsHistory{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 最近一段时间的采样，按时间升序
+// since 为上次拿到的最后一条采样时间（Unix 毫秒），用于增量拉取；不传时取最近 minutes 分钟（默认 10）
+func (h *metricsHistory) HandlerPOST(c *gin.Context) {
+	var req struct {
+		Since   int64 `json:"since"`
+		Minutes int   `json:"minutes"`
+		Limit   int   `json:"limit"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误")
+			return
+		}
+	}
+	if req.Since <= 0 {
+		if req.Minutes <= 0 {
+			req.Minutes = 10
+		}
+		req.Since = time.Now().Add(-time.Duration(req.Minutes) * time.Minute).UnixMilli()
+	}
+
+	samples, err := monitor.History(c.Request.Context(), req.Since, req.Limit)
+	if err != nil {
+		if errors.Is(err, monitor.ErrDisabled) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("查询监控采样失败", zap.Error(err))
+		response.InternalError(c, "查询监控数据失败")
+		return
+	}
+	response.Success(c, gin.H{
+		"interval": monitor.Interval(),
+		"list":     samples,
+	})
+}
@@ -0,0 +1,23 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// LatestMetrics 最近一次系统监控采样
+type LatestMetrics interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// MetricsHistory 最近一段时间的系统监控采样
+type MetricsHistory interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// DiskHealth 各物理磁盘的健康状态
+type DiskHealth interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// DiskHealthHistory 磁盘健康历史记录
+type DiskHealthHistory interface {
+	HandlerPOST(c *gin.Context)
+}
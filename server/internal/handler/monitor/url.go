@@ -0,0 +1,33 @@
+package monitor
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/handler"
+	monitorHandler "github.com/sunyuanling/server/internal/handler/monitor/handler"
+)
+
+// Router 系统监控路由
+type Router struct{}
+
+// NewRouter 创建系统监控模块路由
+func NewRouter() handler.ModuleRouter {
+	return &Router{}
+}
+
+// RegisterRoutes 注册系统监控相关路由（需要 system:monitor 权限，在网关统一拦截）
+// 实时数据通过 WebSocket 订阅 system_metrics 主题获取
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	latest := monitorHandler.NewLatestMetrics(db, redis)
+	history := monitorHandler.NewMetricsHistory(db, redis)
+	diskHealth := monitorHandler.NewDiskHealth(db, redis)
+	diskHistory := monitorHandler.NewDiskHealthHistory(db, redis)
+
+	group.POST("/latest", latest.HandlerPOST)   // 最近一次采样
+	group.POST("/history", history.HandlerPOST) // 最近一段时间的采样，用于绘制图表
+
+	group.POST("/disk/health", diskHealth.HandlerPOST)   // 各物理磁盘的 SMART 数据和健康状态
+	group.POST("/disk/history", diskHistory.HandlerPOST) // 单块磁盘的温度、坏扇区变化
+}
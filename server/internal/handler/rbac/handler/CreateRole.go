@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/rbac/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+// roleCodePattern 角色代码：小写字母开头，字母数字下划线连字符
+var roleCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+type createRole struct {
+	*base.BaseHandler
+}
+
+func NewCreateRole(db *gorm.DB, redis *redis.Client) _interface.CreateRole {
+	return &createRole{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// CreateRoleRequest 创建角色请求参数
+type CreateRoleRequest struct {
+	Code        string   `json:"code" binding:"required"`
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"` // 权限代码
+}
+
+// HandlerPOST 创建自定义角色
+func (h *createRole) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+	req.Code = strings.TrimSpace(req.Code)
+	req.Name = strings.TrimSpace(req.Name)
+	if !roleCodePattern.MatchString(req.Code) {
+		response.BadRequest(c, "角色代码只能包含小写字母、数字、下划线和连字符，且以字母开头")
+		return
+	}
+	if req.Name == "" || len([]rune(req.Name)) > 100 {
+		response.BadRequest(c, "角色名称不能为空且不超过100个字符")
+		return
+	}
+
+	var exists int64
+	h.DB.Model(&model.Role{}).Where("role_code = ?", req.Code).Count(&exists)
+	if exists > 0 {
+		response.BadRequest(c, "角色代码已存在")
+		return
+	}
+
+	role := &model.Role{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      model.RoleStatusActive,
+	}
+	if err := h.DB.Create(role).Error; err != nil {
+		logger.Error("创建角色失败", zap.String("code", req.Code), zap.Error(err))
+		response.InternalError(c, "创建角色失败")
+		return
+	}
+
+	if err := rbac.SetRolePermissions(h.DB, role, req.Permissions); err != nil {
+		// 权限不合法时回滚角色
+		h.DB.Delete(role)
+		if errors.Is(err, rbac.ErrUnknownPermission) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("设置角色权限失败", zap.Uint("role_id", role.ID), zap.Error(err))
+		response.InternalError(c, "创建角色失败")
+		return
+	}
+
+	logger.Info("角色已创建",
+		zap.Int64("operator", payload.UserID),
+		zap.String("code", role.Code),
+		zap.Strings("permissions", req.Permissions),
+	)
+	response.Success(c, RoleItem{Role: *role, Permissions: req.Permissions})
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/rbac/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type deleteRole struct {
+	*base.BaseHandler
+}
+
+func NewDeleteRole(db *gorm.DB, redis *redis.Client) _interface.DeleteRole {
+	return &deleteRole{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 删除自定义角色，其绑定一并删除
+func (h *deleteRole) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ID uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	var role model.Role
+	if err := h.DB.First(&role, req.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "角色不存在")
+			return
+		}
+		logger.Error("查询角色失败", zap.Uint("role_id", req.ID), zap.Error(err))
+		response.InternalError(c, "查询角色失败")
+		return
+	}
+
+	if err := rbac.DeleteRole(h.DB, &role); err != nil {
+		if errors.Is(err, rbac.ErrSystemRole) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("删除角色失败", zap.Uint("role_id", role.ID), zap.Error(err))
+		response.InternalError(c, "删除角色失败")
+		return
+	}
+
+	logger.Info("角色已删除",
+		zap.Int64("operator", payload.UserID),
+		zap.String("code", role.Code),
+	)
+	response.SuccessWithMsg(c, "角色已删除", gin.H{"id": role.ID})
+}
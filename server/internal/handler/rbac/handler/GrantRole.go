@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/access"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/rbac/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type grantRole struct {
+	*base.BaseHandler
+	cfg *config.Config
+}
+
+func NewGrantRole(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.GrantRole {
+	return &grantRole{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+	}
+}
+
+// GrantRoleRequest 绑定角色请求参数
+type GrantRoleRequest struct {
+	UserID    uint   `json:"user_id" binding:"required"`
+	Role      string `json:"role" binding:"required"` // 角色代码
+	ScopePath string `json:"scope_path"`              // 限定目录（可选），例如给访客只读某个文件夹
+}
+
+// HandlerPOST 给用户绑定角色
+func (h *grantRole) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	operatorID := uint(payload.UserID)
+
+	var req GrantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	req.ScopePath = strings.TrimSpace(req.ScopePath)
+	if req.ScopePath != "" {
+		if !access.InAllowedPaths(h.cfg, req.ScopePath) {
+			response.BadRequest(c, "目录范围必须是允许存储路径下的绝对路径")
+			return
+		}
+		req.ScopePath = filepath.Clean(req.ScopePath)
+	}
+
+	var user model.User
+	if err := h.DB.Select("id", "username").First(&user, req.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "用户不存在")
+			return
+		}
+		logger.Error("查询用户失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "查询用户失败")
+		return
+	}
+
+	binding, err := rbac.Grant(h.DB, user.ID, req.Role, req.ScopePath, operatorID)
+	if err != nil {
+		if errors.Is(err, rbac.ErrRoleNotFound) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("绑定角色失败",
+			zap.Uint("user_id", user.ID),
+			zap.String("role", req.Role),
+			zap.Error(err),
+		)
+		response.InternalError(c, "绑定角色失败")
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionRoleGrant,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+		Detail:     map[string]interface{}{"binding_id": binding.ID, "role": req.Role, "scope_path": req.ScopePath},
+	})
+	logger.Info("角色已绑定",
+		zap.Uint("operator", operatorID),
+		zap.Uint("user_id", user.ID),
+		zap.String("role", req.Role),
+		zap.String("scope_path", req.ScopePath),
+	)
+	response.Success(c, BindingItem{
+		ID:        binding.ID,
+		UserID:    user.ID,
+		Username:  user.Username,
+		RoleID:    binding.RoleID,
+		RoleCode:  binding.Role.Code,
+		RoleName:  binding.Role.Name,
+		ScopePath: binding.ScopePath,
+		GrantedBy: binding.GrantedBy,
+		CreatedAt: binding.CreatedAt,
+	})
+}
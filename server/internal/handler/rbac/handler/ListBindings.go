@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/rbac/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listBindings struct {
+	*base.BaseHandler
+}
+
+func NewListBindings(db *gorm.DB, redis *redis.Client) _interface.ListBindings {
+	return &listBindings{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// BindingItem 角色绑定列表项
+type BindingItem struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username"`
+	RoleID    uint      `json:"role_id"`
+	RoleCode  string    `json:"role_code"`
+	RoleName  string    `json:"role_name"`
+	ScopePath string    `json:"scope_path"` // 空为全局
+	GrantedBy *uint     `json:"granted_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HandlerPOST 角色绑定列表，可按用户或角色筛选
+func (h *listBindings) HandlerPOST(c *gin.Context) {
+	var req struct {
+		UserID   uint   `json:"user_id"`
+		RoleCode string `json:"role_code"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	query := h.DB.Table("user_role ur").
+		Select("ur.id, ur.user_id, u.username, ur.role_id, r.role_code, r.role_name, ur.scope_path, ur.granted_by, ur.created_at").
+		Joins("JOIN role r ON r.id = ur.role_id").
+		Joins(`JOIN "user" u ON u.id = ur.user_id`)
+	if req.UserID > 0 {
+		query = query.Where("ur.user_id = ?", req.UserID)
+	}
+	if req.RoleCode != "" {
+		query = query.Where("r.role_code = ?", req.RoleCode)
+	}
+
+	var items []BindingItem
+	if err := query.Order("ur.user_id, ur.id").Scan(&items).Error; err != nil {
+		logger.Error("查询角色绑定失败", zap.Error(err))
+		response.InternalError(c, "查询角色绑定失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"total":    len(items),
+		"bindings": items,
+	})
+}
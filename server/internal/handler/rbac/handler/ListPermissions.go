@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/rbac/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listPermissions struct {
+	*base.BaseHandler
+}
+
+func NewListPermissions(db *gorm.DB, redis *redis.Client) _interface.ListPermissions {
+	return &listPermissions{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 全部权限（按排序）
+func (h *listPermissions) HandlerPOST(c *gin.Context) {
+	var perms []model.Permission
+	if err := h.DB.Order("sort_order, permission_code").Find(&perms).Error; err != nil {
+		logger.Error("查询权限列表失败", zap.Error(err))
+		response.InternalError(c, "查询权限列表失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"total":       len(perms),
+		"permissions": perms,
+	})
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/rbac/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listRoles struct {
+	*base.BaseHandler
+}
+
+func NewListRoles(db *gorm.DB, redis *redis.Client) _interface.ListRoles {
+	return &listRoles{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// RoleItem 角色列表项
+type RoleItem struct {
+	model.Role
+	Permissions  []string `json:"permissions"`   // 权限代码
+	BindingCount int64    `json:"binding_count"` // 绑定数
+}
+
+// HandlerPOST 全部角色及其权限
+func (h *listRoles) HandlerPOST(c *gin.Context) {
+	var roles []model.Role
+	if err := h.DB.Order("id asc").Find(&roles).Error; err != nil {
+		logger.Error("查询角色列表失败", zap.Error(err))
+		response.InternalError(c, "查询角色列表失败")
+		return
+	}
+
+	items := make([]RoleItem, 0, len(roles))
+	for _, role := range roles {
+		codes, err := rbac.RolePermissionCodes(h.DB, role.ID)
+		if err != nil {
+			logger.Error("查询角色权限失败", zap.Uint("role_id", role.ID), zap.Error(err))
+			response.InternalError(c, "查询角色列表失败")
+			return
+		}
+		var count int64
+		h.DB.Model(&model.UserRole{}).Where("role_id = ?", role.ID).Count(&count)
+
+		items = append(items, RoleItem{Role: role, Permissions: codes, BindingCount: count})
+	}
+
+	response.Success(c, gin.H{
+		"total": len(items),
+		"roles": items,
+	})
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/rbac/interface"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type myPermissions struct {
+	*base.BaseHandler
+}
+
+func NewMyPermissions(db *gorm.DB, redis *redis.Client) _interface.MyPermissions {
+	return &myPermissions{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 当前用户的全局角色、全局权限和目录范围权限（前端据此显示菜单）
+func (h *myPermissions) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	grants, err := rbac.FromContext(c, h.DB, userID)
+	if err != nil {
+		logger.Error("加载用户权限失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "加载用户权限失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"roles":       grants.Roles,
+		"permissions": grants.Permissions(),
+		"scoped":      grants.ScopedPermissions(),
+	})
+}
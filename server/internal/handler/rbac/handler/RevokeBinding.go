@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/rbac/interface"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type revokeBinding struct {
+	*base.BaseHandler
+}
+
+func NewRevokeBinding(db *gorm.DB, redis *redis.Client) _interface.RevokeBinding {
+	return &revokeBinding{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 解除角色绑定
+func (h *revokeBinding) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ID uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	binding, err := rbac.RevokeBinding(h.DB, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			response.NotFound(c, "角色绑定不存在")
+		case errors.Is(err, rbac.ErrLastAdmin):
+			response.BadRequest(c, err.Error())
+		default:
+			logger.Error("解除角色绑定失败", zap.Uint("binding_id", req.ID), zap.Error(err))
+			response.InternalError(c, "解除角色绑定失败")
+		}
+		return
+	}
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionRoleRevoke,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(binding.UserID), 10),
+		Detail:     map[string]interface{}{"binding_id": binding.ID, "role_id": binding.RoleID, "scope_path": binding.ScopePath},
+	})
+	logger.Info("角色绑定已解除",
+		zap.Int64("operator", payload.UserID),
+		zap.Uint("user_id", binding.UserID),
+		zap.Uint("role_id", binding.RoleID),
+		zap.String("scope_path", binding.ScopePath),
+	)
+	response.SuccessWithMsg(c, "角色绑定已解除", gin.H{"id": binding.ID})
+}
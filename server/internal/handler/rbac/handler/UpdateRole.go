@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/rbac/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type updateRole struct {
+	*base.BaseHandler
+}
+
+func NewUpdateRole(db *gorm.DB, redis *redis.Client) _interface.UpdateRole {
+	return &updateRole{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// UpdateRoleRequest 修改角色请求参数（字段为空表示不修改）
+type UpdateRoleRequest struct {
+	ID          uint      `json:"id" binding:"required"`
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Status      *int16    `json:"status"`      // 1启用/0禁用
+	Permissions *[]string `json:"permissions"` // 覆盖权限
+}
+
+// HandlerPOST 修改角色名称、描述、状态和权限
+func (h *updateRole) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	var role model.Role
+	if err := h.DB.First(&role, req.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "角色不存在")
+			return
+		}
+		logger.Error("查询角色失败", zap.Uint("role_id", req.ID), zap.Error(err))
+		response.InternalError(c, "查询角色失败")
+		return
+	}
+
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len([]rune(name)) > 100 {
+			response.BadRequest(c, "角色名称不能为空且不超过100个字符")
+			return
+		}
+		updates["role_name"] = name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Status != nil {
+		if role.IsSystem {
+			response.BadRequest(c, "内置角色不能禁用")
+			return
+		}
+		updates["status"] = *req.Status
+	}
+
+	if req.Permissions != nil {
+		if err := rbac.SetRolePermissions(h.DB, &role, *req.Permissions); err != nil {
+			if errors.Is(err, rbac.ErrUnknownPermission) || errors.Is(err, rbac.ErrSystemRole) {
+				response.BadRequest(c, err.Error())
+				return
+			}
+			logger.Error("设置角色权限失败", zap.Uint("role_id", role.ID), zap.Error(err))
+			response.InternalError(c, "修改角色失败")
+			return
+		}
+	}
+
+	if err := h.DB.Model(&role).Updates(updates).Error; err != nil {
+		logger.Error("修改角色失败", zap.Uint("role_id", role.ID), zap.Error(err))
+		response.InternalError(c, "修改角色失败")
+		return
+	}
+	rbac.InvalidateAll()
+
+	codes, _ := rbac.RolePermissionCodes(h.DB, role.ID)
+	logger.Info("角色已修改",
+		zap.Int64("operator", payload.UserID),
+		zap.String("code", role.Code),
+		zap.Strings("permissions", codes),
+	)
+	response.Success(c, RoleItem{Role: role, Permissions: codes})
+}
@@ -0,0 +1,48 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// MyPermissions 当前用户的角色和权限
+type MyPermissions interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListPermissions 权限列表
+type ListPermissions interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListRoles 角色列表
+type ListRoles interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// CreateRole 创建角色
+type CreateRole interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// UpdateRole 修改角色及其权限
+type UpdateRole interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// DeleteRole 删除自定义角色
+type DeleteRole interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListBindings 角色绑定列表
+type ListBindings interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// GrantRole 给用户绑定角色（可限定目录）
+type GrantRole interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// RevokeBinding 删除角色绑定
+type RevokeBinding interface {
+	HandlerPOST(c *gin.Context)
+}
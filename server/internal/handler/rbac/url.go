@@ -0,0 +1,51 @@
+package rbac
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/handler"
+	rbacHandler "github.com/sunyuanling/server/internal/handler/rbac/handler"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/middleware"
+)
+
+// Router 角色权限管理路由
+type Router struct {
+	cfg *config.Config
+}
+
+// NewRouter 创建角色权限模块路由（目录范围校验需要配置）
+func NewRouter(cfg *config.Config) handler.ModuleRouter {
+	return &Router{
+		cfg: cfg,
+	}
+}
+
+// RegisterRoutes 注册角色权限相关路由
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	myPermissions := rbacHandler.NewMyPermissions(db, redis)
+	listPermissions := rbacHandler.NewListPermissions(db, redis)
+	listRoles := rbacHandler.NewListRoles(db, redis)
+	createRole := rbacHandler.NewCreateRole(db, redis)
+	updateRole := rbacHandler.NewUpdateRole(db, redis)
+	deleteRole := rbacHandler.NewDeleteRole(db, redis)
+	listBindings := rbacHandler.NewListBindings(db, redis)
+	grantRole := rbacHandler.NewGrantRole(db, redis, r.cfg)
+	revokeBinding := rbacHandler.NewRevokeBinding(db, redis)
+
+	group.POST("/me", myPermissions.HandlerPOST) // 当前用户的角色和权限
+
+	// 以下接口需要权限管理权限
+	manage := group.Group("", middleware.RequirePermission(db, rbac.PermRBACManage))
+	manage.POST("/permission/list", listPermissions.HandlerPOST) // 权限列表
+	manage.POST("/role/list", listRoles.HandlerPOST)             // 角色列表
+	manage.POST("/role/create", createRole.HandlerPOST)          // 创建角色
+	manage.POST("/role/update", updateRole.HandlerPOST)          // 修改角色
+	manage.POST("/role/delete", deleteRole.HandlerPOST)          // 删除角色
+	manage.POST("/binding/list", listBindings.HandlerPOST)       // 角色绑定列表
+	manage.POST("/binding/grant", grantRole.HandlerPOST)         // 绑定角色
+	manage.POST("/binding/revoke", revokeBinding.HandlerPOST)    // 解除绑定
+}
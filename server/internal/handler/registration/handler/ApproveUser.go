@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/registration/interface"
+	"github.com/sunyuanling/server/internal/registration"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type approveUser struct {
+	*base.BaseHandler
+}
+
+func NewApproveUser(db *gorm.DB, redis *redis.Client) _interface.ApproveUser {
+	return &approveUser{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 审批通过待审批用户
+func (h *approveUser) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	reviewerID := uint(payload.UserID)
+
+	var req struct {
+		UserID uint `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := registration.Approve(h.DB, req.UserID, reviewerID); err != nil {
+		if errors.Is(err, registration.ErrNotPending) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("审批用户失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "审批失败")
+		return
+	}
+
+	logger.Info("注册审批通过",
+		zap.Uint("reviewer_id", reviewerID),
+		zap.Uint("user_id", req.UserID),
+	)
+	response.Success(c, nil)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/registration/interface"
+	"github.com/sunyuanling/server/internal/registration"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type createInvite struct {
+	*base.BaseHandler
+	cfg *config.Config
+}
+
+func NewCreateInvite(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.CreateInvite {
+	return &createInvite{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+	}
+}
+
+// CreateInviteRequest 生成邀请码请求参数
+type CreateInviteRequest struct {
+	MaxUses     int    `json:"max_uses"`     // 可使用次数，默认 1
+	ExpireHours *int   `json:"expire_hours"` // 有效期（小时），不传使用配置默认值，0 表示不过期
+	Note        string `json:"note"`
+}
+
+// HandlerPOST 生成注册邀请码
+func (h *createInvite) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+	if req.MaxUses < 0 || req.MaxUses > 1000 {
+		response.BadRequest(c, "可使用次数必须在1到1000之间")
+		return
+	}
+	if len([]rune(req.Note)) > 200 {
+		response.BadRequest(c, "备注不能超过200个字符")
+		return
+	}
+
+	hours := h.cfg.Register.InviteExpireHours
+	if req.ExpireHours != nil {
+		hours = *req.ExpireHours
+	}
+	if hours < 0 {
+		response.BadRequest(c, "有效期不能为负数")
+		return
+	}
+
+	invite, err := registration.CreateInvite(h.DB, userID, req.MaxUses, time.Duration(hours)*time.Hour, req.Note)
+	if err != nil {
+		logger.Error("生成邀请码失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "生成邀请码失败")
+		return
+	}
+
+	logger.Info("邀请码已生成",
+		zap.Uint("user_id", userID),
+		zap.Uint("invite_id", invite.ID),
+		zap.Int("max_uses", invite.MaxUses),
+	)
+
+	response.Success(c, invite)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/registration/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listInvites struct {
+	*base.BaseHandler
+}
+
+func NewListInvites(db *gorm.DB, redis *redis.Client) _interface.ListInvites {
+	return &listInvites{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// InviteItem 邀请码列表项
+type InviteItem struct {
+	model.InviteCode
+	Usable bool `json:"usable"` // 当前是否可用
+}
+
+// HandlerPOST 邀请码列表，usable_only 为 true 时只返回可用的
+func (h *listInvites) HandlerPOST(c *gin.Context) {
+	var req struct {
+		UsableOnly bool `json:"usable_only"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	var invites []model.InviteCode
+	if err := h.DB.Order("id desc").Find(&invites).Error; err != nil {
+		logger.Error("查询邀请码失败", zap.Error(err))
+		response.InternalError(c, "查询邀请码失败")
+		return
+	}
+
+	items := make([]InviteItem, 0, len(invites))
+	for _, invite := range invites {
+		usable := invite.IsUsable()
+		if req.UsableOnly && !usable {
+			continue
+		}
+		items = append(items, InviteItem{InviteCode: invite, Usable: usable})
+	}
+
+	response.Success(c, gin.H{
+		"list":  items,
+		"total": len(items),
+	})
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/registration/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listPending struct {
+	*base.BaseHandler
+}
+
+func NewListPending(db *gorm.DB, redis *redis.Client) _interface.ListPending {
+	return &listPending{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// PendingUser 待激活用户
+type PendingUser struct {
+	ID         uint      `json:"id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	Phone      string    `json:"phone"`
+	Status     int16     `json:"status"` // 2待验证邮箱/3待审批
+	Mode       string    `json:"mode"`   // 注册时的策略
+	IP         string    `json:"ip"`
+	InviteCode string    `json:"invite_code"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// HandlerPOST 待激活用户列表，默认只列出待审批的，include_unverified 为 true 时包含待验证邮箱的
+func (h *listPending) HandlerPOST(c *gin.Context) {
+	var req struct {
+		IncludeUnverified bool `json:"include_unverified"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	statuses := []int16{model.StatusPendingApproval}
+	if req.IncludeUnverified {
+		statuses = append(statuses, model.StatusPendingVerify)
+	}
+
+	var items []PendingUser
+	err := h.DB.Table(`"user" u`).
+		Select("u.id, u.username, u.email, u.phone, u.status, r.mode, r.ip, i.code AS invite_code, u.created_at").
+		Joins("LEFT JOIN user_registration r ON r.user_id = u.id").
+		Joins("LEFT JOIN invite_code i ON i.id = r.invite_code_id").
+		Where("u.status IN ?", statuses).
+		Order("u.id asc").
+		Scan(&items).Error
+	if err != nil {
+		logger.Error("查询待激活用户失败", zap.Error(err))
+		response.InternalError(c, "查询待激活用户失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  items,
+		"total": len(items),
+	})
+}
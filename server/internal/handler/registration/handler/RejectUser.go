@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/registration/interface"
+	"github.com/sunyuanling/server/internal/registration"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type rejectUser struct {
+	*base.BaseHandler
+}
+
+func NewRejectUser(db *gorm.DB, redis *redis.Client) _interface.RejectUser {
+	return &rejectUser{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 拒绝待审批用户（删除该注册）
+func (h *rejectUser) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID uint `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := registration.Reject(h.DB, req.UserID); err != nil {
+		if errors.Is(err, registration.ErrNotPending) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("拒绝注册失败", zap.Uint("user_id", req.UserID), zap.Error(err))
+		response.InternalError(c, "拒绝注册失败")
+		return
+	}
+
+	logger.Info("注册已拒绝",
+		zap.Int64("reviewer_id", payload.UserID),
+		zap.Uint("user_id", req.UserID),
+	)
+	response.Success(c, nil)
+}
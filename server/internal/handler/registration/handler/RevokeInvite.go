@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/registration/interface"
+	"github.com/sunyuanling/server/internal/registration"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type revokeInvite struct {
+	*base.BaseHandler
+}
+
+func NewRevokeInvite(db *gorm.DB, redis *redis.Client) _interface.RevokeInvite {
+	return &revokeInvite{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 作废邀请码
+func (h *revokeInvite) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ID uint `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := registration.RevokeInvite(h.DB, req.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "邀请码不存在或已作废")
+			return
+		}
+		logger.Error("作废邀请码失败", zap.Uint("invite_id", req.ID), zap.Error(err))
+		response.InternalError(c, "作废邀请码失败")
+		return
+	}
+
+	logger.Info("邀请码已作废",
+		zap.Int64("user_id", payload.UserID),
+		zap.Uint("invite_id", req.ID),
+	)
+	response.Success(c, nil)
+}
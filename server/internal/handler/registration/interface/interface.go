@@ -0,0 +1,33 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// CreateInvite 生成注册邀请码
+type CreateInvite interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListInvites 邀请码列表
+type ListInvites interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// RevokeInvite 作废邀请码
+type RevokeInvite interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListPending 待验证邮箱/待审批的用户
+type ListPending interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ApproveUser 审批通过注册
+type ApproveUser interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// RejectUser 拒绝注册
+type RejectUser interface {
+	HandlerPOST(c *gin.Context)
+}
@@ -0,0 +1,40 @@
+package registration
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/handler"
+	registrationHandler "github.com/sunyuanling/server/internal/handler/registration/handler"
+)
+
+// Router 注册管理路由（邀请码、待审批用户）
+type Router struct {
+	cfg *config.Config
+}
+
+// NewRouter 创建注册管理模块路由（邀请码默认有效期需要配置）
+func NewRouter(cfg *config.Config) handler.ModuleRouter {
+	return &Router{
+		cfg: cfg,
+	}
+}
+
+// RegisterRoutes 注册注册管理相关路由
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	createInvite := registrationHandler.NewCreateInvite(db, redis, r.cfg)
+	listInvites := registrationHandler.NewListInvites(db, redis)
+	revokeInvite := registrationHandler.NewRevokeInvite(db, redis)
+	listPending := registrationHandler.NewListPending(db, redis)
+	approveUser := registrationHandler.NewApproveUser(db, redis)
+	rejectUser := registrationHandler.NewRejectUser(db, redis)
+
+	group.POST("/invite/create", createInvite.HandlerPOST)  // 生成邀请码
+	group.POST("/invite/list", listInvites.HandlerPOST)     // 邀请码列表
+	group.POST("/invite/revoke", revokeInvite.HandlerPOST)  // 作废邀请码
+	group.POST("/pending/list", listPending.HandlerPOST)    // 待激活用户列表
+	group.POST("/pending/approve", approveUser.HandlerPOST) // 审批通过
+	group.POST("/pending/reject", rejectUser.HandlerPOST)   // 拒绝注册
+}
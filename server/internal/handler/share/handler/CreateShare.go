@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/access"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/share/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type createShare struct {
+	*base.BaseHandler
+	cfg *config.Config
+}
+
+func NewCreateShare(db *gorm.DB, redis *redis.Client, cfg *config.Config) _interface.CreateShare {
+	return &createShare{
+		BaseHandler: base.NewBaseHandler(db, redis),
+		cfg:         cfg,
+	}
+}
+
+// CreateShareRequest 共享文件夹请求参数
+type CreateShareRequest struct {
+	FolderPath  string `json:"folder_path" binding:"required"`
+	GranteeType string `json:"grantee_type" binding:"required"` // user/group
+	GranteeID   uint   `json:"grantee_id" binding:"required"`
+	Permission  string `json:"permission"` // read/write/manage，默认 read
+}
+
+// HandlerPOST 将文件夹共享给用户或分组，已存在时更新权限
+func (h *createShare) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req CreateShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+	if req.Permission == "" {
+		req.Permission = model.SharePermRead
+	}
+	if !model.IsValidSharePermission(req.Permission) {
+		response.BadRequest(c, "无效的共享权限（可选: read / write / manage）")
+		return
+	}
+	if req.GranteeType == model.ShareGranteeUser && req.GranteeID == userID {
+		response.BadRequest(c, "不能共享给自己")
+		return
+	}
+
+	folder := filepath.Clean(filepath.FromSlash(req.FolderPath))
+	if !access.InAllowedPaths(h.cfg, folder) {
+		response.Forbidden(c, "无权共享该路径")
+		return
+	}
+	if !canShareFolder(c, h.DB, userID, folder) {
+		return
+	}
+
+	exists, err := granteeExists(h.DB, req.GranteeType, req.GranteeID)
+	if err != nil {
+		logger.Error("查询被共享对象失败", zap.Error(err))
+		response.InternalError(c, "共享失败")
+		return
+	}
+	if !exists {
+		response.NotFound(c, "被共享的用户或分组不存在")
+		return
+	}
+
+	share := &model.FolderShare{
+		OwnerID:     userID,
+		FolderPath:  folder,
+		GranteeType: req.GranteeType,
+		GranteeID:   req.GranteeID,
+		Permission:  req.Permission,
+	}
+	err = h.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "folder_path"}, {Name: "grantee_type"}, {Name: "grantee_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"permission", "updated_at"}),
+	}).Create(share).Error
+	if err != nil {
+		logger.Error("创建共享失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "共享失败")
+		return
+	}
+
+	// 重新读取，冲突更新时 share 中的 owner 等字段以数据库为准
+	if err := h.DB.Where("folder_path = ? AND grantee_type = ? AND grantee_id = ?",
+		folder, req.GranteeType, req.GranteeID).First(share).Error; err != nil {
+		logger.Error("查询共享失败", zap.Error(err))
+		response.InternalError(c, "共享失败")
+		return
+	}
+
+	websocket.NotifyFolderShared(share)
+
+	logger.Info("文件夹已共享",
+		zap.Uint("user_id", userID),
+		zap.Uint("share_id", share.ID),
+		zap.String("folder_path", folder),
+		zap.String("grantee_type", share.GranteeType),
+		zap.Uint("grantee_id", share.GranteeID),
+		zap.String("permission", share.Permission),
+	)
+
+	response.Success(c, share)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/share/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type deleteShare struct {
+	*base.BaseHandler
+}
+
+func NewDeleteShare(db *gorm.DB, redis *redis.Client) _interface.DeleteShare {
+	return &deleteShare{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// DeleteShareRequest 取消共享请求参数
+type DeleteShareRequest struct {
+	ShareID uint `json:"share_id" binding:"required"`
+}
+
+// HandlerPOST 取消共享（共享接收人也可以自己退出用户共享）
+func (h *deleteShare) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req DeleteShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	share, ok := h.loadShare(c, req.ShareID, userID)
+	if !ok {
+		return
+	}
+
+	// 先通知（需要查询分组成员），再删除
+	websocket.NotifyFolderUnshared(share)
+
+	if err := h.DB.Delete(share).Error; err != nil {
+		logger.Error("取消共享失败", zap.Uint("share_id", share.ID), zap.Error(err))
+		response.InternalError(c, "取消共享失败")
+		return
+	}
+
+	logger.Info("共享已取消",
+		zap.Uint("user_id", userID),
+		zap.Uint("share_id", share.ID),
+		zap.String("folder_path", share.FolderPath),
+	)
+
+	response.Success(c, nil)
+}
+
+// loadShare 查询要取消的共享：共享给自己的用户共享可直接退出，其余需要管理权限，失败时已写入响应
+func (h *deleteShare) loadShare(c *gin.Context, shareID, userID uint) (*model.FolderShare, bool) {
+	var share model.FolderShare
+	err := h.DB.Where("id = ? AND grantee_type = ? AND grantee_id = ?", shareID, model.ShareGranteeUser, userID).
+		First(&share).Error
+	if err == nil {
+		return &share, true
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("查询共享失败", zap.Uint("share_id", shareID), zap.Error(err))
+		response.InternalError(c, "查询共享失败")
+		return nil, false
+	}
+	return loadManageableShare(c, h.DB, shareID, userID)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/share/interface"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type listShares struct {
+	*base.BaseHandler
+}
+
+func NewListShares(db *gorm.DB, redis *redis.Client) _interface.ListShares {
+	return &listShares{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// ListSharesRequest 共享列表请求参数
+type ListSharesRequest struct {
+	FolderPath string `json:"folder_path"` // 可选：只看某个文件夹的共享
+}
+
+// HandlerPOST 当前用户共享出去的文件夹
+func (h *listShares) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req ListSharesRequest
+	_ = c.ShouldBindJSON(&req)
+
+	query := shareItemQuery(h.DB).Where("s.owner_id = ?", userID)
+	if req.FolderPath != "" {
+		query = query.Where("s.folder_path = ?", req.FolderPath)
+	}
+
+	var items []ShareItem
+	if err := query.Order("s.id asc").Scan(&items).Error; err != nil {
+		logger.Error("查询共享列表失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "查询共享列表失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  items,
+		"total": len(items),
+	})
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/share/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type sharedWithMe struct {
+	*base.BaseHandler
+}
+
+func NewSharedWithMe(db *gorm.DB, redis *redis.Client) _interface.SharedWithMe {
+	return &sharedWithMe{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// HandlerPOST 共享给当前用户的文件夹（直接共享或通过所在分组）
+func (h *sharedWithMe) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var items []ShareItem
+	err := shareItemQuery(h.DB).
+		Where("(s.grantee_type = ? AND s.grantee_id = ?) OR (s.grantee_type = ? AND s.grantee_id IN (?))",
+			model.ShareGranteeUser, userID,
+			model.ShareGranteeGroup, model.ActiveGroupIDs(h.DB, userID),
+		).
+		Order("s.id asc").
+		Scan(&items).Error
+	if err != nil {
+		logger.Error("查询共享给我的文件夹失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "查询共享失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  items,
+		"total": len(items),
+	})
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/share/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	"github.com/sunyuanling/server/websocket"
+)
+
+type updateShare struct {
+	*base.BaseHandler
+}
+
+func NewUpdateShare(db *gorm.DB, redis *redis.Client) _interface.UpdateShare {
+	return &updateShare{
+		BaseHandler: base.NewBaseHandler(db, redis),
+	}
+}
+
+// UpdateShareRequest 修改共享权限请求参数
+type UpdateShareRequest struct {
+	ShareID    uint   `json:"share_id" binding:"required"`
+	Permission string `json:"permission" binding:"required"`
+}
+
+// HandlerPOST 修改共享权限
+func (h *updateShare) HandlerPOST(c *gin.Context) {
+	payload, ok := h.CurrentUser(c)
+	if !ok {
+		return
+	}
+	userID := uint(payload.UserID)
+
+	var req UpdateShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+	if !model.IsValidSharePermission(req.Permission) {
+		response.BadRequest(c, "无效的共享权限（可选: read / write / manage）")
+		return
+	}
+
+	share, ok := loadManageableShare(c, h.DB, req.ShareID, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Model(share).Update("permission", req.Permission).Error; err != nil {
+		logger.Error("修改共享权限失败", zap.Uint("share_id", share.ID), zap.Error(err))
+		response.InternalError(c, "修改共享权限失败")
+		return
+	}
+	share.Permission = req.Permission
+
+	websocket.NotifyFolderShared(share)
+
+	logger.Info("共享权限已修改",
+		zap.Uint("user_id", userID),
+		zap.Uint("share_id", share.ID),
+		zap.String("permission", share.Permission),
+	)
+
+	response.Success(c, share)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/access"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+// ShareItem 共享列表项
+type ShareItem struct {
+	ID          uint      `json:"id"`
+	OwnerID     uint      `json:"owner_id"`
+	OwnerName   string    `json:"owner_name"`
+	FolderPath  string    `json:"folder_path"`
+	GranteeType string    `json:"grantee_type"`
+	GranteeID   uint      `json:"grantee_id"`
+	GranteeName string    `json:"grantee_name"` // 用户名或分组名
+	Permission  string    `json:"permission"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// shareItemQuery 共享列表查询（带共享人和被共享对象名称）
+func shareItemQuery(db *gorm.DB) *gorm.DB {
+	return db.Table("folder_share s").
+		Select("s.id, s.owner_id, o.username AS owner_name, s.folder_path, s.grantee_type, s.grantee_id, "+
+			"COALESCE(gu.username, gg.name) AS grantee_name, s.permission, s.created_at, s.updated_at").
+		Joins(`LEFT JOIN "user" o ON o.id = s.owner_id`).
+		Joins(`LEFT JOIN "user" gu ON s.grantee_type = ? AND gu.id = s.grantee_id`, model.ShareGranteeUser).
+		Joins("LEFT JOIN ws_group gg ON s.grantee_type = ? AND gg.id = s.grantee_id", model.ShareGranteeGroup)
+}
+
+// loadManageableShare 查询共享并校验当前用户可以管理（共享人本人，或对文件夹有共享权限），失败时已写入响应
+func loadManageableShare(c *gin.Context, db *gorm.DB, shareID, userID uint) (*model.FolderShare, bool) {
+	var share model.FolderShare
+	if err := db.First(&share, shareID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "共享不存在")
+			return nil, false
+		}
+		logger.Error("查询共享失败", zap.Uint("share_id", shareID), zap.Error(err))
+		response.InternalError(c, "查询共享失败")
+		return nil, false
+	}
+
+	if share.OwnerID == userID {
+		return &share, true
+	}
+	if !canShareFolder(c, db, userID, share.FolderPath) {
+		return nil, false
+	}
+	return &share, true
+}
+
+// canShareFolder 校验当前用户可以共享该文件夹，失败时已写入响应
+func canShareFolder(c *gin.Context, db *gorm.DB, userID uint, path string) bool {
+	allowed, err := access.CanShare(c, db, userID, path)
+	if err != nil {
+		logger.Error("校验共享权限失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "校验共享权限失败")
+		return false
+	}
+	if !allowed {
+		response.Forbidden(c, "无权共享该文件夹")
+		return false
+	}
+	return true
+}
+
+// granteeExists 被共享的用户或分组是否存在
+func granteeExists(db *gorm.DB, granteeType string, granteeID uint) (bool, error) {
+	var count int64
+	var err error
+	switch granteeType {
+	case model.ShareGranteeUser:
+		err = db.Model(&model.User{}).Where("id = ?", granteeID).Count(&count).Error
+	case model.ShareGranteeGroup:
+		err = db.Model(&model.WsGroup{}).Where("id = ?", granteeID).Count(&count).Error
+	default:
+		return false, nil
+	}
+	return count > 0, err
+}
@@ -0,0 +1,28 @@
+package _interface
+
+import "github.com/gin-gonic/gin"
+
+// CreateShare 将文件夹共享给用户或分组
+type CreateShare interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// UpdateShare 修改共享权限
+type UpdateShare interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// DeleteShare 取消共享
+type DeleteShare interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// ListShares 当前用户共享出去（或可管理）的共享
+type ListShares interface {
+	HandlerPOST(c *gin.Context)
+}
+
+// SharedWithMe 共享给当前用户的文件夹
+type SharedWithMe interface {
+	HandlerPOST(c *gin.Context)
+}
@@ -0,0 +1,38 @@
+package share
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/handler"
+	shareHandler "github.com/sunyuanling/server/internal/handler/share/handler"
+)
+
+// Router 文件夹共享路由
+type Router struct {
+	cfg *config.Config
+}
+
+// NewRouter 创建文件夹共享模块路由（共享路径校验需要配置）
+func NewRouter(cfg *config.Config) handler.ModuleRouter {
+	return &Router{
+		cfg: cfg,
+	}
+}
+
+// RegisterRoutes 注册文件夹共享相关路由（权限由处理器按文件夹校验）
+func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, redis *redis.Client) {
+	createShare := shareHandler.NewCreateShare(db, redis, r.cfg)
+	updateShare := shareHandler.NewUpdateShare(db, redis)
+	deleteShare := shareHandler.NewDeleteShare(db, redis)
+	listShares := shareHandler.NewListShares(db, redis)
+	sharedWithMe := shareHandler.NewSharedWithMe(db, redis)
+
+	group.POST("/create", createShare.HandlerPOST)   // 共享文件夹
+	group.POST("/update", updateShare.HandlerPOST)   // 修改共享权限
+	group.POST("/delete", deleteShare.HandlerPOST)   // 取消共享
+	group.POST("/list", listShares.HandlerPOST)      // 我共享出去的
+	group.POST("/with-me", sharedWithMe.HandlerPOST) // 共享给我的
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/user/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/passwordreset"
+	"github.com/sunyuanling/server/middleware"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/mailer"
+	"github.com/sunyuanling/server/pkg/ratelimit"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type forgotPasswordHandler struct {
+	*base.BaseHandler
+	mailer mailer.Mailer
+	ttl    time.Duration
+}
+
+func NewForgotPasswordHandler(db *gorm.DB, client *redis.Client, m mailer.Mailer, cfg *config.Config) _interface.UserForgotPassword {
+	return &forgotPasswordHandler{
+		BaseHandler: base.NewBaseHandler(db, client),
+		mailer:      m,
+		ttl:         time.Duration(cfg.Security.PasswordResetMinutes) * time.Minute,
+	}
+}
+
+// HandlePOST 发送找回密码验证码；无论邮箱是否注册都返回相同结果
+func (h *forgotPasswordHandler) HandlePOST(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请输入正确的邮箱")
+		return
+	}
+
+	err := passwordreset.Request(c.Request.Context(), h.DB, h.Redis, h.mailer, req.Email, h.ttl)
+	if errors.Is(err, passwordreset.ErrTooFrequent) {
+		response.TooManyRequests(c, err.Error())
+		return
+	}
+	if err != nil {
+		logger.Error("发送找回密码验证码失败",
+			zap.String("email", req.Email),
+			zap.String("ip", c.ClientIP()),
+			zap.Error(err),
+		)
+		response.InternalError(c, "验证码发送失败，请稍后再试")
+		return
+	}
+
+	logger.Info("找回密码请求",
+		zap.String("email", req.Email),
+		zap.String("ip", c.ClientIP()),
+	)
+	response.Success(c, gin.H{
+		"message":    "如果该邮箱已注册，验证码已发送",
+		"expires_in": int64(h.ttl.Seconds()),
+	})
+}
+
+type confirmResetPasswordHandler struct {
+	*base.BaseHandler
+	accountLimiter *ratelimit.Limiter // 验证码错误按邮箱计数，防止换IP暴力猜测
+}
+
+func NewConfirmResetPasswordHandler(db *gorm.DB, client *redis.Client, cfg *config.Config) _interface.UserConfirmResetPassword {
+	return &confirmResetPasswordHandler{
+		BaseHandler:    base.NewBaseHandler(db, client),
+		accountLimiter: ratelimit.New(client, ratelimit.ResetAccount(cfg.Security)),
+	}
+}
+
+// HandlePOST 校验验证码并设置新密码，成功后该用户所有已登录会话失效
+func (h *confirmResetPasswordHandler) HandlePOST(c *gin.Context) {
+	var req struct {
+		Email       string `json:"email" binding:"required"`
+		Code        string `json:"code" binding:"required"`
+		NewPassword string `json:"newPassword" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "传入参数错误")
+		return
+	}
+	if len(req.NewPassword) < 6 {
+		response.BadRequest(c, "密码长度至少6位")
+		return
+	}
+
+	ctx := c.Request.Context()
+	accountKey := strings.ToLower(strings.TrimSpace(req.Email))
+	if status, err := h.accountLimiter.Check(ctx, accountKey); err == nil && !status.Allowed {
+		middleware.RejectRateLimited(c, status, "验证码错误次数过多，请稍后再试")
+		return
+	}
+
+	userID, err := passwordreset.Confirm(ctx, h.DB, h.Redis, req.Email, req.Code, req.NewPassword)
+	if errors.Is(err, passwordreset.ErrInvalidCode) || errors.Is(err, passwordreset.ErrTooManyAttempts) {
+		if _, err := h.accountLimiter.Hit(ctx, accountKey); err != nil {
+			logger.Error("记录验证码错误次数失败", zap.Error(err))
+		}
+	}
+	switch {
+	case errors.Is(err, passwordreset.ErrInvalidCode):
+		logger.Warn("找回密码验证码错误",
+			zap.String("email", req.Email),
+			zap.String("ip", c.ClientIP()),
+		)
+		audit.Log(c, audit.Event{
+			Action: audit.ActionPasswordReset,
+			Result: model.AuditFailure,
+			Actor:  accountKey,
+			Detail: map[string]interface{}{"method": "email_code", "reason": "bad_code"},
+		})
+		response.BadRequest(c, err.Error())
+		return
+	case errors.Is(err, passwordreset.ErrTooManyAttempts):
+		response.TooManyRequests(c, err.Error())
+		return
+	case errors.Is(err, passwordreset.ErrUserDisabled):
+		response.Forbidden(c, err.Error())
+		return
+	case err != nil && userID == 0:
+		logger.Error("重置密码失败", zap.String("email", req.Email), zap.Error(err))
+		response.InternalError(c, "重置密码失败")
+		return
+	case err != nil:
+		// 密码已更新，只是注销旧会话失败
+		logger.Error("重置密码后注销会话失败", zap.Uint("user_id", userID), zap.Error(err))
+	}
+
+	h.accountLimiter.Reset(ctx, accountKey)
+
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionPasswordReset,
+		ActorID:    userID,
+		Actor:      accountKey,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(userID), 10),
+		Detail:     map[string]interface{}{"method": "email_code"},
+	})
+	logger.Info("用户通过邮箱验证码重置密码",
+		zap.Uint("user_id", userID),
+		zap.String("ip", c.ClientIP()),
+	)
+	response.Success(c, gin.H{"message": "密码已重置，请重新登录"})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/base"
+	_interface "github.com/sunyuanling/server/internal/handler/user/interface"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/registration"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/mailer"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+type registerPolicyHandler struct {
+	policy config.RegisterConfig
+}
+
+func NewRegisterPolicyHandler(cfg *config.Config) _interface.UserRegisterPolicy {
+	return &registerPolicyHandler{policy: cfg.Register}
+}
+
+// HandlePOST 返回当前注册策略
+func (h *registerPolicyHandler) HandlePOST(c *gin.Context) {
+	response.Success(c, gin.H{
+		"mode":            h.policy.Mode,
+		"open":            h.policy.Mode != registration.ModeClosed,
+		"invite_required": h.policy.Mode == registration.ModeInvite,
+		"email_required":  h.policy.Mode == registration.ModeEmailVerify,
+	})
+}
+
+type verifyEmailHandler struct {
+	*base.BaseHandler
+}
+
+func NewVerifyEmailHandler(db *gorm.DB, client *redis.Client) _interface.UserVerifyEmail {
+	return &verifyEmailHandler{
+		BaseHandler: base.NewBaseHandler(db, client),
+	}
+}
+
+// HandlePOST 校验注册邮箱验证码，通过后账号激活
+func (h *verifyEmailHandler) HandlePOST(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required"`
+		Code  string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "传入参数错误")
+		return
+	}
+
+	user, err := registration.VerifyEmail(c.Request.Context(), h.DB, h.Redis, req.Email, req.Code)
+	switch {
+	case errors.Is(err, registration.ErrInvalidCode):
+		logger.Warn("注册邮箱验证码错误",
+			zap.String("email", req.Email),
+			zap.String("ip", c.ClientIP()),
+		)
+		response.BadRequest(c, err.Error())
+		return
+	case errors.Is(err, registration.ErrTooManyAttempts):
+		response.TooManyRequests(c, err.Error())
+		return
+	case err != nil:
+		logger.Error("注册邮箱验证失败", zap.String("email", req.Email), zap.Error(err))
+		response.InternalError(c, "邮箱验证失败")
+		return
+	}
+
+	logger.Info("注册邮箱验证通过",
+		zap.Uint("user_id", user.ID),
+		zap.String("ip", c.ClientIP()),
+	)
+	response.Success(c, gin.H{"message": "邮箱验证成功，请登录"})
+}
+
+type resendVerificationHandler struct {
+	*base.BaseHandler
+	mailer mailer.Mailer
+	ttl    time.Duration
+}
+
+func NewResendVerificationHandler(db *gorm.DB, client *redis.Client, m mailer.Mailer, cfg *config.Config) _interface.UserResendVerification {
+	return &resendVerificationHandler{
+		BaseHandler: base.NewBaseHandler(db, client),
+		mailer:      m,
+		ttl:         time.Duration(cfg.Register.VerifyMinutes) * time.Minute,
+	}
+}
+
+// HandlePOST 重新发送注册验证码；邮箱不存在或已验证时同样返回成功
+func (h *resendVerificationHandler) HandlePOST(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请输入正确的邮箱")
+		return
+	}
+
+	var user model.User
+	err := h.DB.Where("email = ? AND status = ?", strings.TrimSpace(req.Email), model.StatusPendingVerify).First(&user).Error
+	if err == nil {
+		err = registration.SendVerification(c.Request.Context(), h.Redis, h.mailer, &user, h.ttl)
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	if errors.Is(err, registration.ErrTooFrequent) {
+		response.TooManyRequests(c, err.Error())
+		return
+	}
+	if err != nil {
+		logger.Error("重新发送注册验证码失败", zap.String("email", req.Email), zap.Error(err))
+		response.InternalError(c, "验证码发送失败，请稍后再试")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message":    "如果该邮箱正在等待验证，验证码已发送",
+		"expires_in": int64(h.ttl.Seconds()),
+	})
+}
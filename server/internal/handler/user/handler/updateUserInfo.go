@@ -13,12 +13,15 @@ import (
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/audit"
 	"github.com/sunyuanling/server/internal/base"
 	"github.com/sunyuanling/server/internal/model"
 	_ "github.com/sunyuanling/server/pkg/logger"
@@ -112,6 +115,18 @@ func (h *UpdateUserInfoHandler) HandlePOST(c *gin.Context) {
 		return
 	}
 
+	fields := make([]string, 0, len(updates))
+	for field := range updates {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionProfileUpdate,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(userID), 10),
+		Detail:     map[string]interface{}{"fields": fields},
+	})
+
 	response.Success(c, gin.H{"message": "更新成功"})
 }
 
@@ -3,27 +3,36 @@ package handler
 
 import (
 	"errors"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"github.com/sunyuanling/server/config"
 	"github.com/sunyuanling/server/internal/base"
 	_interface "github.com/sunyuanling/server/internal/handler/user/interface"
 	userModel "github.com/sunyuanling/server/internal/handler/user/model"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/registration"
 	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/mailer"
 	"github.com/sunyuanling/server/pkg/password" // 导入密码工具
 	"github.com/sunyuanling/server/pkg/response"
 )
 
 type registerHandler struct {
 	*base.BaseHandler
+	mailer mailer.Mailer
+	policy config.RegisterConfig
 }
 
-func NewRegisterHandler(db *gorm.DB, redis *redis.Client) _interface.UserRegister {
+func NewRegisterHandler(db *gorm.DB, redis *redis.Client, m mailer.Mailer, cfg *config.Config) _interface.UserRegister {
 	return &registerHandler{
 		BaseHandler: base.NewBaseHandler(db, redis),
+		mailer:      m,
+		policy:      cfg.Register,
 	}
 }
 
@@ -35,6 +44,14 @@ func (h *registerHandler) HandlePOST(c *gin.Context) {
 		Phone    string `json:"phone"`
 		Avatar   string `json:"avatar"`
 		Sex      string `json:"sex"`
+		Invite   string `json:"invite_code"` // 邀请码（invite 策略必填）
+	}
+
+	// 0. 注册策略（按IP的频率限制由路由中间件处理）
+	mode := h.policy.Mode
+	if mode == registration.ModeClosed {
+		response.Forbidden(c, "当前未开放注册")
+		return
 	}
 
 	// 1. 绑定参数
@@ -67,6 +84,16 @@ func (h *registerHandler) HandlePOST(c *gin.Context) {
 		return
 	}
 
+	// 策略要求的字段
+	if mode == registration.ModeInvite && req.Invite == "" {
+		response.BadRequest(c, "请输入邀请码")
+		return
+	}
+	if mode == registration.ModeEmailVerify && req.Email == "" {
+		response.BadRequest(c, "请提供邮箱用于验证")
+		return
+	}
+
 	// 5. 检查是否已存在
 	var existingUser userModel.User
 	err := h.DB.Where("username = ? OR email = ? OR phone = ?",
@@ -107,11 +134,31 @@ func (h *registerHandler) HandlePOST(c *gin.Context) {
 		Email:    req.Email,
 		Phone:    req.Phone,
 		Avatar:   req.Avatar,
-		Status:   1, // 默认正常状态
+		Status:   registration.InitialStatus(mode), // 需要验证邮箱或审批时为待激活状态
 	}
 
-	// 8. 插入数据库
-	if err := h.DB.Create(&user).Error; err != nil {
+	// 8. 插入数据库（使用邀请码、创建用户、注册记录在同一事务）
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		record := model.UserRegistration{Mode: mode, IP: c.ClientIP()}
+		if mode == registration.ModeInvite {
+			invite, err := registration.ClaimInvite(tx, req.Invite)
+			if err != nil {
+				return err
+			}
+			record.InviteCodeID = &invite.ID
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		record.UserID = user.ID
+		return tx.Create(&record).Error
+	})
+	if errors.Is(err, registration.ErrInviteInvalid) {
+		logger.Warn("注册邀请码无效", zap.String("ip", c.ClientIP()))
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
 		logger.Error("创建用户失败",
 			zap.Error(err),
 			zap.String("username", req.Username),
@@ -120,15 +167,41 @@ func (h *registerHandler) HandlePOST(c *gin.Context) {
 		return
 	}
 
+	// 邮箱验证：发送验证码（失败时用户可以重新发送）
+	verifySent := false
+	if mode == registration.ModeEmailVerify {
+		err := registration.SendVerification(c.Request.Context(), h.Redis, h.mailer, &model.User{
+			ID:       user.ID,
+			Username: user.Username,
+			Email:    user.Email,
+		}, time.Duration(h.policy.VerifyMinutes)*time.Minute)
+		if err != nil {
+			logger.Error("发送注册验证邮件失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		} else {
+			verifySent = true
+		}
+	}
+
 	// 9. 记录日志
 	logger.Info("用户注册成功",
 		zap.Uint("user_id", user.ID),
 		zap.String("username", user.Username),
+		zap.String("mode", mode),
+		zap.Int16("status", user.Status),
 		zap.String("ip", c.ClientIP()),
 	)
 
-	// 10. 返回成功（不返回密码）
+	// 10. 返回成功（不返回密码），next 提示客户端下一步
+	next := "login"
+	switch user.Status {
+	case model.StatusPendingVerify:
+		next = "verify_email"
+	case model.StatusPendingApproval:
+		next = "wait_approval"
+	}
 	response.Success(c, gin.H{
+		"next":        next,
+		"verify_sent": verifySent,
 		"user": gin.H{
 			"id":         user.ID,
 			"username":   user.Username,
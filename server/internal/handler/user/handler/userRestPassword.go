@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"strconv"
+
+	"github.com/sunyuanling/server/internal/audit"
 	"github.com/sunyuanling/server/internal/base"
 	_interface "github.com/sunyuanling/server/internal/handler/user/interface"
 	"github.com/sunyuanling/server/internal/handler/user/model"
+	internalModel "github.com/sunyuanling/server/internal/model"
 	"github.com/sunyuanling/server/pkg/logger"
 	"github.com/sunyuanling/server/pkg/password"
 	"github.com/sunyuanling/server/pkg/response"
@@ -50,6 +54,15 @@ func (h *userResetPasswordHandler) HandlePOST(c *gin.Context) {
 	}
 
 	if !password.VerifyPassword(user.Password, req.OldPassword) {
+		audit.Log(c, audit.Event{
+			Action:     audit.ActionPasswordReset,
+			Result:     internalModel.AuditFailure,
+			ActorID:    user.ID,
+			Actor:      user.Username,
+			TargetType: audit.TargetUser,
+			TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+			Detail:     map[string]interface{}{"method": "old_password", "reason": "bad_password"},
+		})
 		response.BadRequest(c, "旧密码错误")
 		return
 	}
@@ -69,5 +82,13 @@ func (h *userResetPasswordHandler) HandlePOST(c *gin.Context) {
 		return
 	}
 
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionPasswordReset,
+		ActorID:    user.ID,
+		Actor:      user.Username,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+		Detail:     map[string]interface{}{"method": "old_password"},
+	})
 	response.Success(c, gin.H{"user": "密码重置成功"})
 }
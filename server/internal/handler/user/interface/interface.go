@@ -16,3 +16,28 @@ type UserResetPassword interface {
 type UserUpdateUserInfo interface {
 	HandlePOST(c *gin.Context)
 }
+
+// UserForgotPassword 找回密码：发送邮件验证码
+type UserForgotPassword interface {
+	HandlePOST(c *gin.Context)
+}
+
+// UserConfirmResetPassword 找回密码：校验验证码并设置新密码
+type UserConfirmResetPassword interface {
+	HandlePOST(c *gin.Context)
+}
+
+// UserRegisterPolicy 当前注册策略（客户端据此显示邀请码/邮箱等输入项）
+type UserRegisterPolicy interface {
+	HandlePOST(c *gin.Context)
+}
+
+// UserVerifyEmail 注册邮箱验证
+type UserVerifyEmail interface {
+	HandlePOST(c *gin.Context)
+}
+
+// UserResendVerification 重新发送注册邮箱验证码
+type UserResendVerification interface {
+	HandlePOST(c *gin.Context)
+}
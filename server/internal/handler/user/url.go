@@ -4,9 +4,14 @@ import (
 	"github.com/sunyuanling/server/config"
 	"github.com/sunyuanling/server/internal/handler"
 	userHandler "github.com/sunyuanling/server/internal/handler/user/handler"
+	"github.com/sunyuanling/server/middleware"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/mailer"
+	"github.com/sunyuanling/server/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -24,10 +29,35 @@ func NewUserRouter(cfg *config.Config) handler.ModuleRouter {
 
 // RegisterRoutes 注册用户相关路由
 func (r *Router) RegisterRoutes(group *gin.RouterGroup, db *gorm.DB, client *redis.Client) {
-	userRegister := userHandler.NewRegisterHandler(db, client)
+	// 邮件发送（注册邮箱验证、找回密码）
+	m, err := mailer.New(r.cfg.Mail)
+	if err != nil {
+		logger.Error("初始化邮件发送失败，改为写入本地文件", zap.Error(err))
+		m = mailer.NewFileMailer(r.cfg.Mail.FileDir)
+	}
+
+	// 按IP限流：注册尝试；找回密码和邮箱验证共用一个额度
+	registerLimit := middleware.RateLimitByIP(ratelimit.New(client, ratelimit.Register(r.cfg)))
+	resetLimit := middleware.RateLimitByIP(ratelimit.New(client, ratelimit.PasswordReset(r.cfg.Security)))
+
+	userRegister := userHandler.NewRegisterHandler(db, client, m, r.cfg)
 	userResetPassword := userHandler.NewUserResetPasswordHandler(db, client)
 	updateUserInfo := userHandler.NewUpdateUserInfoHandler(db, client, r.cfg)
-	group.POST("/register", userRegister.HandlePOST)
+	group.POST("/register", registerLimit, userRegister.HandlePOST)
 	group.POST("/resetPassword", userResetPassword.HandlePOST)
 	group.POST("/updateInfo", updateUserInfo.HandlePOST)
+
+	// 注册策略与邮箱验证
+	registerPolicy := userHandler.NewRegisterPolicyHandler(r.cfg)
+	verifyEmail := userHandler.NewVerifyEmailHandler(db, client)
+	resendVerification := userHandler.NewResendVerificationHandler(db, client, m, r.cfg)
+	group.POST("/registerPolicy", registerPolicy.HandlePOST)
+	group.POST("/verifyEmail", resetLimit, verifyEmail.HandlePOST)
+	group.POST("/resendVerification", resetLimit, resendVerification.HandlePOST)
+
+	// 找回密码（邮件验证码）
+	forgotPassword := userHandler.NewForgotPasswordHandler(db, client, m, r.cfg)
+	confirmResetPassword := userHandler.NewConfirmResetPasswordHandler(db, client, r.cfg)
+	group.POST("/forgotPassword", resetLimit, forgotPassword.HandlePOST)
+	group.POST("/confirmResetPassword", resetLimit, confirmResetPassword.HandlePOST)
 }
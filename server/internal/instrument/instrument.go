@@ -0,0 +1,218 @@
+// Package instrument 服务端 Prometheus 指标：HTTP 请求、文件传输、WebSocket、数据库连接池、Redis 耗时和存储空间
+//
+// 请求类指标由中间件和处理器实时记录，连接池、WebSocket、磁盘空间等在每次抓取时读取。
+package instrument
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shirou/gopsutil/v3/disk"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/pkg/metrics"
+	"github.com/sunyuanling/server/websocket"
+)
+
+// 传输方向
+const (
+	Upload   = "upload"
+	Download = "download"
+)
+
+// transferBuckets 文件传输耗时分桶（秒）
+var transferBuckets = []float64{0.1, 0.5, 1, 5, 15, 30, 60, 300, 900, 3600}
+
+// redisBuckets Redis 命令耗时分桶（秒）
+var redisBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.5, 1}
+
+var (
+	httpRequests = metrics.NewCounterVec("filesync_http_requests_total",
+		"HTTP 请求数", "method", "route", "status")
+	httpDuration = metrics.NewHistogramVec("filesync_http_request_duration_seconds",
+		"HTTP 请求耗时（秒）", metrics.DefBuckets, "method", "route")
+	httpInFlight = metrics.NewGaugeVec("filesync_http_requests_in_flight",
+		"正在处理的 HTTP 请求数")
+
+	transferBytes = metrics.NewCounterVec("filesync_transfer_bytes_total",
+		"文件上传、下载的字节数", "direction")
+	transferDuration = metrics.NewHistogramVec("filesync_transfer_duration_seconds",
+		"文件上传、下载耗时（秒）", transferBuckets, "direction")
+	transfers = metrics.NewCounterVec("filesync_transfers_total",
+		"文件上传、下载次数", "direction", "result")
+
+	redisDuration = metrics.NewHistogramVec("filesync_redis_command_duration_seconds",
+		"Redis 命令耗时（秒），pipeline 按 pipeline 记一次", redisBuckets, "command")
+	redisErrors = metrics.NewCounterVec("filesync_redis_errors_total",
+		"Redis 命令错误数（不含 key 不存在）", "command")
+)
+
+// Init 注册全部指标，并给 Redis 客户端加上耗时统计
+func Init(db *gorm.DB, rdb *redis.Client, cfg *config.Config) {
+	started := float64(time.Now().Unix())
+
+	metrics.MustRegister(
+		httpRequests, httpDuration, httpInFlight,
+		transferBytes, transferDuration, transfers,
+		redisDuration, redisErrors,
+	)
+	metrics.MustRegister(websocketMetrics()...)
+	metrics.MustRegister(dbMetrics(db)...)
+	metrics.MustRegister(storageMetrics(cfg)...)
+	metrics.MustRegister(
+		metrics.NewGaugeFunc("process_start_time_seconds", "进程启动时间（Unix 秒）", func() float64 { return started }),
+		metrics.NewGaugeFunc("go_goroutines", "goroutine 数量", func() float64 { return float64(runtime.NumGoroutine()) }),
+		metrics.NewFunc(metrics.TypeGauge, "go_memstats_bytes", "Go 运行时内存（字节）", []string{"kind"},
+			func(emit func(float64, ...string)) {
+				var ms runtime.MemStats
+				runtime.ReadMemStats(&ms)
+				emit(float64(ms.HeapAlloc), "heap_alloc")
+				emit(float64(ms.HeapInuse), "heap_inuse")
+				emit(float64(ms.Sys), "sys")
+			}),
+	)
+
+	rdb.AddHook(redisHook{})
+}
+
+// ObserveRequest 记录一次 HTTP 请求，route 为路由模板（如 /api/files/upload）
+func ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	httpRequests.WithLabelValues(method, route, statusLabel(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+// RequestStarted 请求开始，返回结束时调用的函数
+func RequestStarted() func() {
+	g := httpInFlight.WithLabelValues()
+	g.Inc()
+	return g.Dec
+}
+
+// ObserveTransfer 记录一次文件上传或下载，written 为实际传输的字节数
+func ObserveTransfer(direction string, written int64, started time.Time, err error) {
+	if written > 0 {
+		transferBytes.WithLabelValues(direction).Add(float64(written))
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	} else {
+		transferDuration.WithLabelValues(direction).Observe(time.Since(started).Seconds())
+	}
+	transfers.WithLabelValues(direction, result).Inc()
+}
+
+// statusLabel 状态码标签
+func statusLabel(status int) string {
+	if status <= 0 {
+		return "0"
+	}
+	return strconv.Itoa(status)
+}
+
+// websocketMetrics WebSocket 连接和消息数（来自 Hub 统计）
+func websocketMetrics() []metrics.Metric {
+	stats := func() websocket.Stats { return websocket.GetHub().GetStats() }
+	return []metrics.Metric{
+		metrics.NewGaugeFunc("filesync_websocket_connections", "当前 WebSocket 连接数",
+			func() float64 { return float64(stats().ActiveConnections) }),
+		metrics.NewGaugeFunc("filesync_websocket_users", "当前在线用户数",
+			func() float64 { return float64(stats().ActiveUsers) }),
+		metrics.NewFunc(metrics.TypeCounter, "filesync_websocket_connections_total", "累计 WebSocket 连接数", nil,
+			func(emit func(float64, ...string)) { emit(float64(stats().TotalConnections)) }),
+		metrics.NewFunc(metrics.TypeCounter, "filesync_websocket_messages_total", "WebSocket 消息数", []string{"direction"},
+			func(emit func(float64, ...string)) {
+				s := stats()
+				emit(float64(s.TotalMessagesSent), "sent")
+				emit(float64(s.TotalMessagesRecv), "received")
+			}),
+	}
+}
+
+// dbMetrics 数据库连接池状态
+func dbMetrics(db *gorm.DB) []metrics.Metric {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil
+	}
+	return []metrics.Metric{
+		metrics.NewFunc(metrics.TypeGauge, "filesync_db_connections", "数据库连接池连接数", []string{"state"},
+			func(emit func(float64, ...string)) {
+				s := sqlDB.Stats()
+				emit(float64(s.InUse), "in_use")
+				emit(float64(s.Idle), "idle")
+				emit(float64(s.MaxOpenConnections), "max_open")
+			}),
+		metrics.NewFunc(metrics.TypeCounter, "filesync_db_wait_total", "等待空闲连接的次数", nil,
+			func(emit func(float64, ...string)) { emit(float64(sqlDB.Stats().WaitCount)) }),
+		metrics.NewFunc(metrics.TypeCounter, "filesync_db_wait_seconds_total", "等待空闲连接的累计时间（秒）", nil,
+			func(emit func(float64, ...string)) { emit(sqlDB.Stats().WaitDuration.Seconds()) }),
+	}
+}
+
+// storageMetrics 每个允许的存储路径所在磁盘的可用和总空间
+func storageMetrics(cfg *config.Config) []metrics.Metric {
+	paths := cfg.GetAllowedPaths()
+	usage := func(emit func(float64, ...string), pick func(*disk.UsageStat) uint64) {
+		for _, p := range paths {
+			if u, err := disk.Usage(p); err == nil {
+				emit(float64(pick(u)), p)
+			}
+		}
+	}
+	return []metrics.Metric{
+		metrics.NewFunc(metrics.TypeGauge, "filesync_storage_free_bytes", "存储路径所在磁盘的可用空间（字节）", []string{"path"},
+			func(emit func(float64, ...string)) {
+				usage(emit, func(u *disk.UsageStat) uint64 { return u.Free })
+			}),
+		metrics.NewFunc(metrics.TypeGauge, "filesync_storage_size_bytes", "存储路径所在磁盘的总空间（字节）", []string{"path"},
+			func(emit func(float64, ...string)) {
+				usage(emit, func(u *disk.UsageStat) uint64 { return u.Total })
+			}),
+	}
+}
+
+// redisHook 统计 Redis 命令耗时和错误
+type redisHook struct{}
+
+func (redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			redisErrors.WithLabelValues("dial").Inc()
+		}
+		return conn, err
+	}
+}
+
+func (redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		name := strings.ToLower(cmd.Name())
+		redisDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		if err != nil && !errors.Is(err, redis.Nil) {
+			redisErrors.WithLabelValues(name).Inc()
+		}
+		return err
+	}
+}
+
+func (redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		redisDuration.WithLabelValues("pipeline").Observe(time.Since(start).Seconds())
+		if err != nil && !errors.Is(err, redis.Nil) {
+			redisErrors.WithLabelValues("pipeline").Inc()
+		}
+		return err
+	}
+}
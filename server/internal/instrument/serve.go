@@ -0,0 +1,87 @@
+package instrument
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/metrics"
+)
+
+// GinHandler 主服务上的 /metrics
+// 配置了 token 时校验 Authorization: Bearer <token>，未配置时只允许本机直接访问：
+// 按 TCP 对端地址判断（不使用可伪造的 X-Forwarded-For），经本机反向代理转发的请求同样拒绝
+func GinHandler(token string) gin.HandlerFunc {
+	h := metrics.Default.Handler()
+	return func(c *gin.Context) {
+		if !authorized(c.Request, c.RemoteIP(), token, true) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// Serve 在单独的地址上提供 /metrics，配置了 token 时校验，返回关闭函数
+func Serve(addr, token string) func() {
+	h := metrics.Default.Handler()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, "", token, false) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+	go func() {
+		logger.Info("Prometheus 指标服务已启动", zap.String("address", addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Prometheus 指标服务启动失败", zap.String("address", addr), zap.Error(err))
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}
+}
+
+// authorized 校验抓取请求，remoteIP 为 TCP 对端地址
+func authorized(r *http.Request, remoteIP, token string, localOnly bool) bool {
+	if token != "" {
+		auth := r.Header.Get("Authorization")
+		got, ok := strings.CutPrefix(auth, "Bearer ")
+		return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+	}
+	if !localOnly {
+		return true
+	}
+	// 本机反向代理转发的外部请求对端也是 127.0.0.1，带转发头时不算本机访问
+	for _, h := range forwardedHeaders {
+		if r.Header.Get(h) != "" {
+			return false
+		}
+	}
+	ip := net.ParseIP(remoteIP)
+	return ip != nil && ip.IsLoopback()
+}
+
+// forwardedHeaders 反向代理添加的转发头
+var forwardedHeaders = []string{"X-Forwarded-For", "X-Real-IP", "Forwarded"}
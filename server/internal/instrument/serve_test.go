@@ -0,0 +1,79 @@
+package instrument
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGinHandlerLocalOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       int
+	}{
+		{"本机直接访问", "127.0.0.1:51000", nil, http.StatusOK},
+		{"本机 IPv6", "[::1]:51000", nil, http.StatusOK},
+		{"外部地址", "203.0.113.7:51000", nil, http.StatusUnauthorized},
+		// 伪造的转发头不能让外部请求被当作本机
+		{"外部地址伪造 X-Forwarded-For", "203.0.113.7:51000", map[string]string{"X-Forwarded-For": "127.0.0.1"}, http.StatusUnauthorized},
+		{"外部地址伪造 X-Real-IP", "203.0.113.7:51000", map[string]string{"X-Real-IP": "127.0.0.1"}, http.StatusUnauthorized},
+		// 本机反向代理转发的外部请求
+		{"本机代理转发", "127.0.0.1:51000", map[string]string{"X-Forwarded-For": "203.0.113.7"}, http.StatusUnauthorized},
+		{"本机代理转发 Forwarded", "127.0.0.1:51000", map[string]string{"Forwarded": "for=203.0.113.7"}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			// 信任全部代理时 ClientIP 会采用 X-Forwarded-For，RemoteIP 不受影响
+			_ = r.SetTrustedProxies([]string{"0.0.0.0/0", "::/0"})
+			r.GET("/metrics", GinHandler(""))
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("状态码 = %d, 期望 %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizedToken(t *testing.T) {
+	tests := []struct {
+		name string
+		auth string
+		want bool
+	}{
+		{"正确的 token", "Bearer s3cret", true},
+		{"错误的 token", "Bearer wrong", false},
+		{"缺少 Bearer", "s3cret", false},
+		{"没有 Authorization", "", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		// 配置了 token 时本机访问也需要 token
+		if got := authorized(req, "127.0.0.1", "s3cret", true); got != tt.want {
+			t.Errorf("%s: authorized = %v, 期望 %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 单独监听地址未配置 token 时不校验
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if !authorized(req, "", "", false) {
+		t.Error("单独监听地址未配置 token 时应允许访问")
+	}
+}
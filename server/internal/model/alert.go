@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Alert 告警记录表
+// 同一规则、同一对象（rule + alert_key）同时只有一条 firing 记录，恢复后再次触发会新建一条
+type Alert struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement" json:"id"`                            // 告警ID
+	Rule        string     `gorm:"type:varchar(32);not null" json:"rule"`                         // 规则
+	AlertKey    string     `gorm:"type:varchar(255);not null;default:''" json:"key"`              // 告警对象（磁盘挂载点、IP、设备等），用于去重
+	Severity    string     `gorm:"type:varchar(16);not null" json:"severity"`                     // 级别：warning/critical
+	Status      string     `gorm:"type:varchar(16);not null;default:firing" json:"status"`        // 状态：firing/resolved
+	Title       string     `gorm:"type:varchar(200);not null" json:"title"`                       // 标题
+	Message     string     `gorm:"type:text" json:"message"`                                      // 详细说明（最近一次评估）
+	Value       float64    `gorm:"not null;default:0" json:"value"`                               // 最近一次评估的值
+	Threshold   float64    `gorm:"not null;default:0" json:"threshold"`                           // 触发阈值
+	FiredAt     time.Time  `gorm:"type:timestamp;not null;index:idx_alert_fired" json:"fired_at"` // 触发时间
+	LastSeenAt  time.Time  `gorm:"type:timestamp;not null" json:"last_seen_at"`                   // 最近一次评估仍满足条件的时间
+	ResolvedAt  *time.Time `gorm:"type:timestamp" json:"resolved_at"`                             // 恢复时间
+	NotifiedAt  *time.Time `gorm:"type:timestamp" json:"notified_at"`                             // 最近一次发送通知的时间
+	NotifyCount int        `gorm:"not null;default:0" json:"notify_count"`                        // 已发送通知次数（不含恢复通知）
+}
+
+// TableName 指定表名
+func (Alert) TableName() string {
+	return "alert"
+}
+
+// 告警级别
+const (
+	AlertWarning  = "warning"
+	AlertCritical = "critical"
+)
+
+// 告警状态
+const (
+	AlertFiring   = "firing"   // 触发中
+	AlertResolved = "resolved" // 已恢复
+)
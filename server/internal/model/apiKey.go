@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// APIKey 个人API Key表
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`                         // Key ID
+	UserID     uint       `gorm:"not null;index" json:"user_id"`                              // 所属用户
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`                     // 名称
+	KeyPrefix  string     `gorm:"type:varchar(16);uniqueIndex;not null" json:"key_prefix"`    // 公开前缀
+	KeyHash    string     `gorm:"type:varchar(64);not null" json:"-"`                         // 密文部分SHA-256
+	Scope      string     `gorm:"type:varchar(20);not null;default:read" json:"scope"`        // read/upload/read_write
+	FolderPath string     `gorm:"type:varchar(1000)" json:"folder_path"`                      // 限定的文件夹
+	ExpiresAt  *time.Time `gorm:"type:timestamp" json:"expires_at"`                           // 过期时间
+	LastUsedAt *time.Time `gorm:"type:timestamp" json:"last_used_at"`                         // 最后使用时间
+	LastUsedIP string     `gorm:"type:varchar(64)" json:"last_used_ip"`                       // 最后使用IP
+	RevokedAt  *time.Time `gorm:"type:timestamp" json:"revoked_at"`                           // 吊销时间
+	CreatedAt  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"` // 创建时间
+}
+
+// TableName 指定表名
+func (APIKey) TableName() string {
+	return "api_key"
+}
+
+// API Key 权限范围
+const (
+	APIKeyScopeRead      = "read"       // 只读：浏览、下载
+	APIKeyScopeUpload    = "upload"     // 只上传
+	APIKeyScopeReadWrite = "read_write" // 读写（不含删除）
+)
+
+// IsActive Key 是否可用（未吊销、未过期）
+func (k *APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
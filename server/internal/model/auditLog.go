@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// AuditLog 审计日志表（只追加）
+type AuditLog struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`                      // 日志ID
+	CreatedAt  time.Time `gorm:"type:timestamp;not null;index" json:"created_at"`         // 发生时间
+	ActorID    *uint     `gorm:"index" json:"actor_id"`                                   // 操作人，未识别身份时为空
+	Actor      string    `gorm:"type:varchar(100)" json:"actor"`                          // 操作人用户名或登录标识
+	Action     string    `gorm:"type:varchar(64);not null;index" json:"action"`           // 事件类型
+	TargetType string    `gorm:"type:varchar(32)" json:"target_type"`                     // 操作对象类型
+	TargetID   string    `gorm:"type:varchar(1000)" json:"target_id"`                     // 操作对象标识
+	Result     string    `gorm:"type:varchar(16);not null;default:success" json:"result"` // success/failure/denied
+	IP         string    `gorm:"type:varchar(64)" json:"ip"`                              // 客户端IP
+	UserAgent  string    `gorm:"type:varchar(500)" json:"user_agent"`                     // 客户端User-Agent
+	DeviceID   string    `gorm:"type:varchar(100)" json:"device_id"`                      // 设备标识
+	Detail     string    `gorm:"type:text" json:"detail,omitempty"`                       // 附加信息（JSON）
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+// 审计结果
+const (
+	AuditSuccess = "success" // 成功
+	AuditFailure = "failure" // 失败（凭证错误、参数错误等）
+	AuditDenied  = "denied"  // 被拒绝（锁定、权限不足、账号状态不允许）
+)
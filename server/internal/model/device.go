@@ -1,24 +1,29 @@
 package model
 
 import (
-	"gorm.io/gorm"
+	"errors"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Device 设备表
 type Device struct {
-	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`                         // 设备ID
-	UserID     uint       `gorm:"not null;index:idx_device_user" json:"user_id"`              // 用户ID
-	DeviceName string     `gorm:"type:varchar(100);not null" json:"device_name"`              // 设备名称
-	DeviceType string     `gorm:"type:varchar(20);not null;index" json:"device_type"`         // 设备类型：mobile/web/windows/mac/linux
-	DeviceID   string     `gorm:"type:varchar(100);not null" json:"device_id"`                // 设备唯一标识
-	OSVersion  string     `gorm:"type:varchar(50)" json:"os_version"`                         // 操作系统版本
-	AppVersion string     `gorm:"type:varchar(50)" json:"app_version"`                        // 应用版本
-	IPAddress  string     `gorm:"type:varchar(50)" json:"ip_address"`                         // IP地址
-	LastActive *time.Time `gorm:"type:timestamp" json:"last_active"`                          // 最后活跃时间
-	Status     int16      `gorm:"type:smallint;default:1;index" json:"status"`                // 状态：1正常/0禁用
-	CreatedAt  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"` // 创建时间
-	UpdatedAt  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"` // 更新时间
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id"`                         // 设备ID
+	UserID       uint       `gorm:"not null;index:idx_device_user" json:"user_id"`              // 用户ID
+	DeviceName   string     `gorm:"type:varchar(100);not null" json:"device_name"`              // 设备名称
+	DeviceType   string     `gorm:"type:varchar(20);not null;index" json:"device_type"`         // 设备类型：mobile/web/windows/mac/linux
+	DeviceID     string     `gorm:"type:varchar(100);not null" json:"device_id"`                // 设备唯一标识
+	OSVersion    string     `gorm:"type:varchar(50)" json:"os_version"`                         // 操作系统版本
+	AppVersion   string     `gorm:"type:varchar(50)" json:"app_version"`                        // 应用版本
+	IPAddress    string     `gorm:"type:varchar(50)" json:"ip_address"`                         // IP地址
+	LastActive   *time.Time `gorm:"type:timestamp" json:"last_active"`                          // 最后活跃时间
+	Status       int16      `gorm:"type:smallint;default:1;index" json:"status"`                // 状态：1正常/0禁用
+	TrustedUntil *time.Time `gorm:"type:timestamp" json:"trusted_until"`                        // 两步验证受信任截止时间
+	TrustHash    string     `gorm:"column:trust_token_hash;type:varchar(64)" json:"-"`          // 信任凭证SHA-256（凭证只在记住设备时返回一次）
+	CreatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"` // 创建时间
+	UpdatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"` // 更新时间
 
 	// 关联
 	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"` // 所属用户
@@ -64,9 +69,49 @@ func (d *Device) IsActive() bool {
 	return d.Status == DeviceStatusActive
 }
 
+// IsTrusted 是否为两步验证受信任设备
+func (d *Device) IsTrusted() bool {
+	return d.IsActive() && d.TrustedUntil != nil && time.Now().Before(*d.TrustedUntil)
+}
+
 // UpdateLastActive 更新最后活跃时间
 func (d *Device) UpdateLastActive(tx *gorm.DB) error {
 	now := time.Now()
 	d.LastActive = &now
 	return tx.Model(d).Update("last_active", now).Error
 }
+
+// FindDeviceByIdentifier 按客户端设备标识查找用户的设备
+// 未找到时返回 (nil, nil)
+func FindDeviceByIdentifier(tx *gorm.DB, userID uint, deviceID string) (*Device, error) {
+	var d Device
+	err := tx.Where("user_id = ? AND device_id = ?", userID, deviceID).First(&d).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// RegisterDevice 登记设备：(user_id, device_id) 已存在则更新设备信息，不存在则新建
+// 不会修改已有设备的 status 和 device_name（名称由用户重命名决定）
+func RegisterDevice(tx *gorm.DB, d *Device) error {
+	now := time.Now()
+	d.LastActive = &now
+	d.UpdatedAt = now
+
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "device_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"device_type", "os_version", "app_version", "ip_address", "last_active", "updated_at",
+		}),
+	}).Create(d).Error
+	if err != nil {
+		return err
+	}
+
+	// 重新读取，拿到已有记录的 ID / 名称 / 状态
+	return tx.Where("user_id = ? AND device_id = ?", d.UserID, d.DeviceID).First(d).Error
+}
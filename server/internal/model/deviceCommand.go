@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeviceCommand 设备远程指令表
+type DeviceCommand struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         uint       `gorm:"not null;index:idx_device_command_user" json:"user_id"`
+	DeviceRecordID uint       `gorm:"not null" json:"device_record_id"`
+	DeviceID       string     `gorm:"type:varchar(100);not null;index:idx_device_command_device" json:"device_id"`
+	Command        string     `gorm:"type:varchar(50);not null" json:"command"`
+	Params         string     `gorm:"type:text" json:"params"`
+	Status         string     `gorm:"type:varchar(20);default:pending" json:"status"`
+	Result         string     `gorm:"type:text" json:"result"`
+	IssuedFrom     string     `gorm:"type:varchar(100)" json:"issued_from"`
+	SentAt         *time.Time `gorm:"type:timestamp" json:"sent_at"`
+	AckedAt        *time.Time `gorm:"type:timestamp" json:"acked_at"`
+	CreatedAt      time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (DeviceCommand) TableName() string {
+	return "device_command"
+}
+
+// 设备指令常量
+const (
+	DeviceCommandSignOut           = "sign_out"           // 退出登录
+	DeviceCommandClearLocalCache   = "clear_local_cache"  // 清除本地缓存
+	DeviceCommandUploadDiagnostics = "upload_diagnostics" // 上传诊断日志
+	DeviceCommandPauseSync         = "pause_sync"         // 暂停同步
+)
+
+// 指令状态常量
+const (
+	DeviceCommandStatusPending   = "pending"   // 等待下发（设备离线）
+	DeviceCommandStatusSent      = "sent"      // 已下发，等待设备确认
+	DeviceCommandStatusCompleted = "completed" // 设备执行成功
+	DeviceCommandStatusFailed    = "failed"    // 设备执行失败
+	DeviceCommandStatusExpired   = "expired"   // 已过期/已失效
+)
+
+// IsValidDeviceCommand 判断是否为支持的设备指令
+func IsValidDeviceCommand(command string) bool {
+	switch command {
+	case DeviceCommandSignOut, DeviceCommandClearLocalCache,
+		DeviceCommandUploadDiagnostics, DeviceCommandPauseSync:
+		return true
+	}
+	return false
+}
+
+// IsFinished 判断指令是否已结束
+func (dc *DeviceCommand) IsFinished() bool {
+	return dc.Status == DeviceCommandStatusCompleted ||
+		dc.Status == DeviceCommandStatusFailed ||
+		dc.Status == DeviceCommandStatusExpired
+}
+
+// PendingDeviceCommands 获取设备尚未下发或尚未确认的指令（按创建顺序）
+func PendingDeviceCommands(tx *gorm.DB, userID uint, deviceID string) ([]DeviceCommand, error) {
+	var cmds []DeviceCommand
+	err := tx.Where("user_id = ? AND device_id = ? AND status IN ?", userID, deviceID,
+		[]string{DeviceCommandStatusPending, DeviceCommandStatusSent}).
+		Order("id asc").
+		Find(&cmds).Error
+	return cmds, err
+}
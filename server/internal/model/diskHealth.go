@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// DiskHealthSample 磁盘健康采样表，用于观察温度、通电时间和坏扇区随时间的变化
+// 设备不提供的项为空
+type DiskHealthSample struct {
+	ID                   uint64    `gorm:"primaryKey;autoIncrement" json:"id"`                                   // 记录ID
+	DiskID               string    `gorm:"type:varchar(128);not null;index:idx_disk_health_disk" json:"disk_id"` // 磁盘标识：序列号，没有序列号时为设备名
+	Device               string    `gorm:"type:varchar(64);not null" json:"device"`                              // 设备名（如 sda、nvme0n1）
+	Model                string    `gorm:"type:varchar(128)" json:"model"`                                       // 型号
+	SmartPassed          *bool     `json:"smart_passed"`                                                         // SMART 总体状态
+	Temperature          *float64  `json:"temperature"`                                                          // 温度（℃）
+	PowerOnHours         *int64    `json:"power_on_hours"`                                                       // 通电时间（小时）
+	ReallocatedSectors   *int64    `json:"reallocated_sectors"`                                                  // 已重映射扇区数
+	PendingSectors       *int64    `json:"pending_sectors"`                                                      // 等待重映射的扇区数
+	UncorrectableSectors *int64    `json:"uncorrectable_sectors"`                                                // 无法校正的扇区数
+	MediaErrors          *int64    `json:"media_errors"`                                                         // NVMe 介质错误数
+	PercentageUsed       *int      `json:"percentage_used"`                                                      // NVMe 寿命已用百分比
+	CreatedAt            time.Time `gorm:"type:timestamp;not null;index:idx_disk_health_disk" json:"created_at"` // 采样时间
+}
+
+// TableName 指定表名
+func (DiskHealthSample) TableName() string {
+	return "disk_health_sample"
+}
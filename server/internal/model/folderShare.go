@@ -0,0 +1,107 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FolderShare 文件夹共享表
+type FolderShare struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`                         // 共享ID
+	OwnerID     uint      `gorm:"not null;index:idx_folder_share_owner" json:"owner_id"`      // 共享人ID
+	FolderPath  string    `gorm:"type:varchar(1000);not null" json:"folder_path"`             // 共享的文件夹
+	GranteeType string    `gorm:"type:varchar(10);not null" json:"grantee_type"`              // 被共享对象类型：user/group
+	GranteeID   uint      `gorm:"not null" json:"grantee_id"`                                 // 用户ID或分组ID
+	Permission  string    `gorm:"type:varchar(10);not null;default:read" json:"permission"`   // read/write/manage
+	CreatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"` // 创建时间
+	UpdatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"` // 更新时间
+}
+
+// TableName 指定表名
+func (FolderShare) TableName() string {
+	return "folder_share"
+}
+
+// 被共享对象类型
+const (
+	ShareGranteeUser  = "user"  // 用户
+	ShareGranteeGroup = "group" // 分组（ws_group）
+)
+
+// 共享权限
+const (
+	SharePermRead   = "read"   // 只读
+	SharePermWrite  = "write"  // 读写（含删除）
+	SharePermManage = "manage" // 读写并可再共享
+)
+
+// SharePermissionLevel 权限等级，便于比较；无效权限返回 0
+func SharePermissionLevel(permission string) int {
+	switch permission {
+	case SharePermRead:
+		return 1
+	case SharePermWrite:
+		return 2
+	case SharePermManage:
+		return 3
+	}
+	return 0
+}
+
+// IsValidSharePermission 判断共享权限是否有效
+func IsValidSharePermission(permission string) bool {
+	return SharePermissionLevel(permission) > 0
+}
+
+// SharesForUser 用户收到的共享（直接共享给用户，或共享给其所在分组）
+func SharesForUser(tx *gorm.DB, userID uint) ([]FolderShare, error) {
+	var shares []FolderShare
+	err := tx.Where("(grantee_type = ? AND grantee_id = ?) OR (grantee_type = ? AND grantee_id IN (?))",
+		ShareGranteeUser, userID,
+		ShareGranteeGroup, ActiveGroupIDs(tx, userID),
+	).Order("id asc").Find(&shares).Error
+	return shares, err
+}
+
+// ShareLevelForPath 用户通过共享对路径拥有的最高权限等级，没有共享时返回 0
+func ShareLevelForPath(tx *gorm.DB, userID uint, path string) (int, error) {
+	shares, err := SharesForUser(tx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	level := 0
+	for _, s := range shares {
+		if IsPathWithin(path, s.FolderPath) {
+			if l := SharePermissionLevel(s.Permission); l > level {
+				level = l
+			}
+		}
+	}
+	return level, nil
+}
+
+// SharesForPath 共享文件夹包含该路径的共享
+func SharesForPath(tx *gorm.DB, path string) ([]FolderShare, error) {
+	var shares []FolderShare
+	if err := tx.Find(&shares).Error; err != nil {
+		return nil, err
+	}
+
+	matched := shares[:0]
+	for _, s := range shares {
+		if IsPathWithin(path, s.FolderPath) {
+			matched = append(matched, s)
+		}
+	}
+	return matched, nil
+}
+
+// ShareRecipientIDs 共享的接收用户（分组共享展开为成员）
+func ShareRecipientIDs(tx *gorm.DB, share *FolderShare) ([]uint, error) {
+	if share.GranteeType == ShareGranteeUser {
+		return []uint{share.GranteeID}, nil
+	}
+	return GroupMemberIDs(tx, share.GranteeID)
+}
@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// Role 角色表
+type Role struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`                                 // 角色ID
+	Code        string    `gorm:"column:role_code;type:varchar(50);uniqueIndex;not null" json:"code"` // 角色代码
+	Name        string    `gorm:"column:role_name;type:varchar(100);not null" json:"name"`            // 角色名称
+	Description string    `gorm:"type:text" json:"description"`                                       // 角色描述
+	Status      int16     `gorm:"type:smallint;default:1" json:"status"`                              // 状态：1启用/0禁用
+	IsSystem    bool      `gorm:"not null;default:false" json:"is_system"`                            // 是否内置角色
+	CreatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`         // 创建时间
+	UpdatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`         // 更新时间
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "role"
+}
+
+// Permission 权限表
+type Permission struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`                                       // 权限ID
+	Code        string    `gorm:"column:permission_code;type:varchar(50);uniqueIndex;not null" json:"code"` // 权限代码
+	Name        string    `gorm:"column:permission_name;type:varchar(100);not null" json:"name"`            // 权限名称
+	ParentID    *uint     `json:"parent_id"`                                                                // 父权限ID
+	Type        string    `gorm:"column:permission_type;type:varchar(20)" json:"type"`                      // 权限类型：menu/button/api
+	Description string    `gorm:"type:text" json:"description"`                                             // 权限描述
+	SortOrder   int       `gorm:"default:0" json:"sort_order"`                                              // 排序
+	Status      int16     `gorm:"type:smallint;default:1" json:"status"`                                    // 状态：1启用/0禁用
+	CreatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`               // 创建时间
+}
+
+// TableName 指定表名
+func (Permission) TableName() string {
+	return "permission"
+}
+
+// RolePermission 角色权限关联表
+type RolePermission struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoleID       uint      `gorm:"not null" json:"role_id"`
+	PermissionID uint      `gorm:"not null" json:"permission_id"`
+	CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName 指定表名
+func (RolePermission) TableName() string {
+	return "role_permission"
+}
+
+// UserRole 角色绑定（用户-角色-生效范围）
+type UserRole struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;index:idx_user_role_user" json:"user_id"`
+	RoleID    uint      `gorm:"not null;index:idx_user_role_role" json:"role_id"`
+	ScopePath string    `gorm:"type:varchar(1000);not null;default:''" json:"scope_path"` // 空为全局，非空为目录范围
+	GrantedBy *uint     `json:"granted_by"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	// 关联
+	Role *Role `gorm:"foreignKey:RoleID" json:"role,omitempty"`
+}
+
+// TableName 指定表名
+func (UserRole) TableName() string {
+	return "user_role"
+}
+
+// 状态常量
+const (
+	RoleStatusActive       = 1 // 启用
+	PermissionStatusActive = 1 // 启用
+)
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// InviteCode 注册邀请码表
+type InviteCode struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`                         // 邀请码ID
+	Code      string     `gorm:"type:varchar(32);uniqueIndex;not null" json:"code"`          // 邀请码
+	CreatedBy *uint      `json:"created_by"`                                                 // 创建人
+	MaxUses   int        `gorm:"not null;default:1" json:"max_uses"`                         // 最多可使用次数
+	UsedCount int        `gorm:"not null;default:0" json:"used_count"`                       // 已使用次数
+	Note      string     `gorm:"type:varchar(200)" json:"note"`                              // 备注
+	ExpiresAt *time.Time `gorm:"type:timestamp" json:"expires_at"`                           // 过期时间，为空表示不过期
+	RevokedAt *time.Time `gorm:"type:timestamp" json:"revoked_at"`                           // 作废时间
+	CreatedAt time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"` // 创建时间
+}
+
+// TableName 指定表名
+func (InviteCode) TableName() string {
+	return "invite_code"
+}
+
+// IsUsable 邀请码当前是否可用
+func (i *InviteCode) IsUsable() bool {
+	if i.RevokedAt != nil || i.UsedCount >= i.MaxUses {
+		return false
+	}
+	return i.ExpiresAt == nil || time.Now().Before(*i.ExpiresAt)
+}
+
+// UserRegistration 用户注册记录表
+type UserRegistration struct {
+	UserID          uint       `gorm:"primaryKey" json:"user_id"`                                  // 用户ID
+	Mode            string     `gorm:"type:varchar(20);not null" json:"mode"`                      // 注册时的策略
+	InviteCodeID    *uint      `json:"invite_code_id"`                                             // 使用的邀请码
+	IP              string     `gorm:"type:varchar(64)" json:"ip"`                                 // 注册IP
+	EmailVerifiedAt *time.Time `gorm:"type:timestamp" json:"email_verified_at"`                    // 邮箱验证时间
+	ReviewedBy      *uint      `json:"reviewed_by"`                                                // 审批人
+	ReviewedAt      *time.Time `gorm:"type:timestamp" json:"reviewed_at"`                          // 审批时间
+	CreatedAt       time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"` // 注册时间
+}
+
+// TableName 指定表名
+func (UserRegistration) TableName() string {
+	return "user_registration"
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// UserSession 登录会话表
+type UserSession struct {
+	ID          string     `gorm:"type:varchar(64);primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index:idx_user_session_user" json:"user_id"`
+	DeviceID    string     `gorm:"type:varchar(100)" json:"device_id"`
+	IPAddress   string     `gorm:"type:varchar(50)" json:"ip_address"`
+	UserAgent   string     `gorm:"type:varchar(500)" json:"user_agent"`
+	RefreshHash string     `gorm:"type:varchar(64);not null" json:"-"`
+	CreatedAt   time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	LastUsedAt  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"last_used_at"`
+	ExpiresAt   time.Time  `gorm:"type:timestamp;not null" json:"expires_at"`
+	RevokedAt   *time.Time `gorm:"type:timestamp" json:"revoked_at"`
+}
+
+// TableName 指定表名
+func (UserSession) TableName() string {
+	return "user_session"
+}
+
+// IsActive 会话未注销且未过期
+func (s *UserSession) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
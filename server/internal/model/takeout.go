@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// TakeoutJob 数据导出任务表
+type TakeoutJob struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id"`                         // 任务ID
+	UserID       uint       `gorm:"not null;index:idx_takeout_job_user" json:"user_id"`         // 用户ID
+	Status       string     `gorm:"type:varchar(20);not null;default:pending" json:"status"`    // 状态
+	Progress     int        `gorm:"not null;default:0" json:"progress"`                         // 进度（0-100）
+	ArchivePath  string     `gorm:"type:varchar(1000)" json:"-"`                                // 压缩包路径，不返回给前端
+	ArchiveSize  int64      `gorm:"not null;default:0" json:"archive_size"`                     // 压缩包大小（字节）
+	FileCount    int        `gorm:"not null;default:0" json:"file_count"`                       // 打包的文件数
+	ErrorMessage string     `gorm:"type:text" json:"error_message,omitempty"`                   // 失败原因
+	CreatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"` // 创建时间
+	StartedAt    *time.Time `gorm:"type:timestamp" json:"started_at"`                           // 开始时间
+	CompletedAt  *time.Time `gorm:"type:timestamp" json:"completed_at"`                         // 完成时间
+	ExpiresAt    *time.Time `gorm:"type:timestamp" json:"expires_at"`                           // 压缩包过期时间
+}
+
+// TableName 指定表名
+func (TakeoutJob) TableName() string {
+	return "takeout_job"
+}
+
+// 导出任务状态
+const (
+	TakeoutStatusPending   = "pending"   // 排队
+	TakeoutStatusRunning   = "running"   // 进行中
+	TakeoutStatusCompleted = "completed" // 完成，可下载
+	TakeoutStatusFailed    = "failed"    // 失败
+	TakeoutStatusExpired   = "expired"   // 压缩包已过期删除
+)
+
+// IsDownloadable 压缩包是否可下载
+func (j *TakeoutJob) IsDownloadable() bool {
+	if j.Status != TakeoutStatusCompleted || j.ArchivePath == "" {
+		return false
+	}
+	return j.ExpiresAt == nil || time.Now().Before(*j.ExpiresAt)
+}
+
+// AccountDeletion 账号注销申请表
+type AccountDeletion struct {
+	UserID         uint      `gorm:"primaryKey" json:"user_id"`                               // 用户ID
+	RequestedAt    time.Time `gorm:"type:timestamp;not null" json:"requested_at"`             // 申请时间
+	PurgeAt        time.Time `gorm:"type:timestamp;not null;index" json:"purge_at"`           // 彻底删除时间
+	Reason         string    `gorm:"type:varchar(500)" json:"reason"`                         // 注销原因
+	PreviousStatus int16     `gorm:"type:smallint;not null;default:1" json:"previous_status"` // 申请前的账号状态
+	TrashedFiles   string    `gorm:"type:text" json:"-"`                                      // 移入回收站的文件（JSON）
+}
+
+// TableName 指定表名
+func (AccountDeletion) TableName() string {
+	return "account_deletion"
+}
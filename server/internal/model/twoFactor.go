@@ -0,0 +1,85 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserTwoFactor 两步验证（TOTP）配置
+type UserTwoFactor struct {
+	UserID       uint       `gorm:"primaryKey" json:"user_id"`                                  // 用户ID
+	Secret       string     `gorm:"type:text;not null" json:"-"`                                // TOTP密钥（加密存储）
+	Enabled      bool       `gorm:"not null;default:false" json:"enabled"`                      // 是否已启用
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"`                                // 最后使用的时间步
+	EnabledAt    *time.Time `gorm:"type:timestamp" json:"enabled_at"`                           // 启用时间
+	CreatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"` // 创建时间
+	UpdatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"` // 更新时间
+}
+
+// TableName 指定表名
+func (UserTwoFactor) TableName() string {
+	return "user_two_factor"
+}
+
+// UserRecoveryCode 两步验证恢复码
+type UserRecoveryCode struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index:idx_recovery_code_user" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `gorm:"type:timestamp" json:"used_at"`
+	CreatedAt time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName 指定表名
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_code"
+}
+
+// TwoFactorPolicy 按角色强制两步验证
+type TwoFactorPolicy struct {
+	Role      string    `gorm:"type:varchar(20);primaryKey" json:"role"`
+	Required  bool      `gorm:"not null;default:false" json:"required"`
+	UpdatedBy uint      `json:"updated_by"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (TwoFactorPolicy) TableName() string {
+	return "two_factor_policy"
+}
+
+// FindTwoFactor 查询用户的两步验证配置，未配置时返回 (nil, nil)
+func FindTwoFactor(tx *gorm.DB, userID uint) (*UserTwoFactor, error) {
+	var tf UserTwoFactor
+	err := tx.Where("user_id = ?", userID).First(&tf).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// IsTwoFactorRequired 任一角色被强制要求两步验证时返回 true
+func IsTwoFactorRequired(tx *gorm.DB, roles []string) (bool, error) {
+	if len(roles) == 0 {
+		return false, nil
+	}
+	var count int64
+	err := tx.Model(&TwoFactorPolicy{}).
+		Where("role IN ? AND required = ?", roles, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UnusedRecoveryCodeCount 剩余可用恢复码数量
+func UnusedRecoveryCodeCount(tx *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -15,7 +15,7 @@ type User struct {
 	Phone     string     `gorm:"type:varchar(20)" json:"phone"`                              // 手机号
 	Avatar    string     `gorm:"type:varchar(255)" json:"avatar"`                            // 头像URL
 	Role      string     `gorm:"type:varchar(20);default:user" json:"role"`                  // 角色：admin/user
-	Status    int16      `gorm:"type:smallint;default:1;index" json:"status"`                // 状态：1正常/0禁用
+	Status    int16      `gorm:"type:smallint;default:1;index" json:"status"`                // 状态：1正常/0禁用/2待验证邮箱/3待审批/4注销冷静期
 	LastLogin *time.Time `gorm:"type:timestamp" json:"last_login"`                           // 最后登录时间
 	CreatedAt time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"` // 创建时间
 	UpdatedAt time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"` // 更新时间
@@ -33,8 +33,11 @@ const (
 )
 
 const (
-	StatusActive   = 1 // 正常
-	StatusInactive = 0 // 禁用
+	StatusActive          = 1 // 正常
+	StatusInactive        = 0 // 禁用
+	StatusPendingVerify   = 2 // 待验证邮箱
+	StatusPendingApproval = 3 // 待管理员审批
+	StatusPendingDeletion = 4 // 已申请注销，冷静期内
 )
 
 // BeforeCreate GORM钩子：创建前
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// UserIdentity 外部身份（OIDC）与本地账号的关联表
+type UserIdentity struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`                         // 关联ID
+	UserID      uint       `gorm:"not null;index" json:"user_id"`                              // 本地用户ID
+	Provider    string     `gorm:"type:varchar(50);not null" json:"provider"`                  // 身份提供方标识
+	Subject     string     `gorm:"type:varchar(255);not null" json:"subject"`                  // ID Token 中的 sub
+	Email       string     `gorm:"type:varchar(100)" json:"email"`                             // 身份提供方返回的邮箱
+	LastLoginAt *time.Time `gorm:"type:timestamp" json:"last_login_at"`                        // 最近一次登录时间
+	CreatedAt   time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"` // 关联时间
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return "user_identity"
+}
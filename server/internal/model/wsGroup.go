@@ -0,0 +1,166 @@
+package model
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WsGroup 共享空间分组表
+type WsGroup struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
+	OwnerID     uint      `gorm:"not null;index:idx_ws_group_owner" json:"owner_id"`
+	Description string    `gorm:"type:varchar(500)" json:"description"`
+	FolderPath  string    `gorm:"type:varchar(500)" json:"folder_path"`
+	CreatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (WsGroup) TableName() string {
+	return "ws_group"
+}
+
+// WsGroupMember 分组成员表
+type WsGroupMember struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	GroupID   uint      `gorm:"not null" json:"group_id"`
+	UserID    uint      `gorm:"not null;index:idx_ws_group_member_user" json:"user_id"`
+	Role      string    `gorm:"type:varchar(20);default:member" json:"role"`
+	Status    string    `gorm:"type:varchar(20);not null;default:active" json:"status"` // invited/active
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName 指定表名
+func (WsGroupMember) TableName() string {
+	return "ws_group_member"
+}
+
+// 分组成员角色
+const (
+	GroupRoleOwner  = "owner"  // 创建者
+	GroupRoleAdmin  = "admin"  // 管理员
+	GroupRoleMember = "member" // 成员
+)
+
+// 成员状态：被添加的用户需要接受邀请后才成为成员（接收分组消息、在线状态和分组共享）
+const (
+	GroupMemberInvited = "invited"
+	GroupMemberActive  = "active"
+)
+
+// IsValidGroupRole 判断是否为可分配的成员角色（owner 只能是创建者）
+func IsValidGroupRole(role string) bool {
+	return role == GroupRoleAdmin || role == GroupRoleMember
+}
+
+// IsActive 是否已接受邀请
+func (m *WsGroupMember) IsActive() bool {
+	return m.Status == GroupMemberActive
+}
+
+// CanManage 是否可以管理成员和设置
+func (m *WsGroupMember) CanManage() bool {
+	return m.Role == GroupRoleOwner || m.Role == GroupRoleAdmin
+}
+
+// CreateWsGroup 创建分组，创建者自动成为 owner，memberIDs 作为普通成员收到邀请
+func CreateWsGroup(tx *gorm.DB, group *WsGroup, memberIDs []uint) error {
+	return tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+
+		members := []WsGroupMember{{GroupID: group.ID, UserID: group.OwnerID, Role: GroupRoleOwner, Status: GroupMemberActive}}
+		for _, id := range memberIDs {
+			if id != group.OwnerID {
+				members = append(members, WsGroupMember{GroupID: group.ID, UserID: id, Role: GroupRoleMember, Status: GroupMemberInvited})
+			}
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+	})
+}
+
+// FindGroupMember 查询用户在分组中的成员记录（含未接受的邀请），不是成员时返回 nil, nil
+func FindGroupMember(tx *gorm.DB, groupID, userID uint) (*WsGroupMember, error) {
+	var m WsGroupMember
+	err := tx.Where("group_id = ? AND user_id = ?", groupID, userID).First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// UserGroupNames 获取用户加入的所有分组名称（不含未接受的邀请）
+func UserGroupNames(tx *gorm.DB, userID uint) ([]string, error) {
+	var names []string
+	err := tx.Model(&WsGroup{}).
+		Joins("JOIN ws_group_member m ON m.group_id = ws_group.id").
+		Where("m.user_id = ? AND m.status = ?", userID, GroupMemberActive).
+		Pluck("ws_group.name", &names).Error
+	return names, err
+}
+
+// GroupMemberIDs 获取分组所有成员ID（不含未接受的邀请）
+func GroupMemberIDs(tx *gorm.DB, groupID uint) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&WsGroupMember{}).
+		Where("group_id = ? AND status = ?", groupID, GroupMemberActive).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// ActiveGroupIDs 用户已加入的分组ID子查询，用于分组共享的判断
+func ActiveGroupIDs(tx *gorm.DB, userID uint) *gorm.DB {
+	return tx.Model(&WsGroupMember{}).Select("group_id").Where("user_id = ? AND status = ?", userID, GroupMemberActive)
+}
+
+// GroupsForPath 获取共享文件夹包含该路径的分组
+func GroupsForPath(tx *gorm.DB, path string) ([]WsGroup, error) {
+	var groups []WsGroup
+	if err := tx.Where("folder_path IS NOT NULL AND folder_path <> ''").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	matched := groups[:0]
+	for _, g := range groups {
+		if IsPathWithin(path, g.FolderPath) {
+			matched = append(matched, g)
+		}
+	}
+	return matched, nil
+}
+
+// pathCaseInsensitive 路径比较是否忽略大小写：只有 Windows 文件系统不区分大小写，
+// Linux 上 /data/alice 和 /data/ALICE 是不同的目录
+var pathCaseInsensitive = runtime.GOOS == "windows"
+
+// SetPathCaseInsensitive 按存储模式设置路径比较是否忽略大小写，启动时调用
+func SetPathCaseInsensitive(v bool) {
+	pathCaseInsensitive = v
+}
+
+// IsPathWithin 判断 path 是否为 dir 本身或其子路径（仅 Windows 下不区分大小写）
+func IsPathWithin(path, dir string) bool {
+	p := filepath.Clean(path)
+	d := filepath.Clean(dir)
+	if pathCaseInsensitive {
+		p, d = strings.ToUpper(p), strings.ToUpper(d)
+	}
+	if p == d {
+		return true
+	}
+	if !strings.HasSuffix(d, string(filepath.Separator)) {
+		d += string(filepath.Separator)
+	}
+	return strings.HasPrefix(p, d)
+}
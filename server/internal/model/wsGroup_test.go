@@ -0,0 +1,34 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsPathWithin(t *testing.T) {
+	defer SetPathCaseInsensitive(pathCaseInsensitive)
+
+	sep := string(filepath.Separator)
+	dir := filepath.Join(sep+"data", "alice")
+	tests := []struct {
+		path            string
+		caseInsensitive bool
+		want            bool
+	}{
+		{dir, false, true},
+		{filepath.Join(dir, "docs", "a.txt"), false, true},
+		{dir + sep, false, true},
+		{filepath.Join(sep+"data", "alice2"), false, false}, // 前缀相同的兄弟目录
+		{filepath.Join(sep + "data"), false, false},
+		// 区分大小写的文件系统上是不同的目录
+		{filepath.Join(sep+"data", "ALICE"), false, false},
+		{filepath.Join(sep+"data", "ALICE", "docs"), false, false},
+		{filepath.Join(sep+"data", "ALICE", "docs"), true, true},
+	}
+	for _, tt := range tests {
+		SetPathCaseInsensitive(tt.caseInsensitive)
+		if got := IsPathWithin(tt.path, dir); got != tt.want {
+			t.Errorf("IsPathWithin(%q, %q) 忽略大小写=%v: %v, 期望 %v", tt.path, dir, tt.caseInsensitive, got, tt.want)
+		}
+	}
+}
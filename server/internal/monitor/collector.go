@@ -0,0 +1,300 @@
+package monitor
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+	"go.uber.org/zap"
+
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket/protocol"
+)
+
+// collector 采样器，保存上一次的累计计数用于计算速率
+// 只在采集协程中使用，不需要加锁
+type collector struct {
+	interval int
+	proc     *process.Process
+	started  time.Time
+
+	lastTime time.Time
+	lastCPU  []cpu.TimesStat // 每个逻辑核
+	lastAll  cpu.TimesStat   // 总计
+	lastNet  map[string]net.IOCountersStat
+	lastDisk map[string]disk.IOCountersStat
+
+	warned map[string]bool // 不支持的采集项只警告一次
+}
+
+func newCollector(interval int) *collector {
+	c := &collector{
+		interval: interval,
+		started:  time.Now(),
+		warned:   make(map[string]bool),
+	}
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		c.warn("process", err)
+	}
+	c.proc = proc
+	return c
+}
+
+// sample 采集一次，第一次调用只记录基准值，返回 nil
+func (c *collector) sample() *protocol.SystemMetrics {
+	now := time.Now()
+	elapsed := now.Sub(c.lastTime).Seconds()
+	first := c.lastTime.IsZero()
+
+	m := &protocol.SystemMetrics{
+		Time:     now.UnixMilli(),
+		Interval: c.interval,
+	}
+	m.CPU = c.cpu()
+	m.Network = c.network(elapsed)
+	m.DiskIO = c.diskIO(elapsed)
+	m.Process = c.process()
+	m.Load = c.load()
+	m.Memory = c.memory()
+	m.Runtime = goRuntime()
+
+	c.lastTime = now
+	if first || elapsed <= 0 {
+		return nil
+	}
+	return m
+}
+
+// cpu 按 cpu.Times 的差值计算使用率，不阻塞采集协程
+func (c *collector) cpu() protocol.CPUMetrics {
+	var out protocol.CPUMetrics
+
+	perCore, err := cpu.Times(true)
+	if err != nil {
+		c.warn("cpu", err)
+		return out
+	}
+	all, err := cpu.Times(false)
+	if err != nil || len(all) == 0 {
+		c.warn("cpu", err)
+		return out
+	}
+
+	if len(c.lastCPU) == len(perCore) {
+		out.PerCore = make([]float64, len(perCore))
+		for i := range perCore {
+			out.PerCore[i] = busyPercent(c.lastCPU[i], perCore[i])
+		}
+		out.Percent = busyPercent(c.lastAll, all[0])
+	}
+	c.lastCPU = perCore
+	c.lastAll = all[0]
+	return out
+}
+
+// busyPercent 两次 CPU 时间之间非空闲时间的占比
+func busyPercent(prev, cur cpu.TimesStat) float64 {
+	total := cpuTotal(cur) - cpuTotal(prev)
+	idle := (cur.Idle + cur.Iowait) - (prev.Idle + prev.Iowait)
+	if total <= 0 {
+		return 0
+	}
+	return clampPercent((total - idle) / total * 100)
+}
+
+// cpuTotal CPU 时间合计（guest 已计入 user，不重复累加）
+func cpuTotal(t cpu.TimesStat) float64 {
+	return t.User + t.System + t.Idle + t.Nice + t.Iowait + t.Irq + t.Softirq + t.Steal
+}
+
+// load 系统负载，Windows 不支持时返回 nil
+func (c *collector) load() *protocol.LoadMetrics {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	avg, err := load.Avg()
+	if err != nil {
+		c.warn("load", err)
+		return nil
+	}
+	return &protocol.LoadMetrics{Load1: avg.Load1, Load5: avg.Load5, Load15: avg.Load15}
+}
+
+// memory 内存和交换区
+func (c *collector) memory() protocol.MemoryMetrics {
+	var out protocol.MemoryMetrics
+	if vm, err := mem.VirtualMemory(); err != nil {
+		c.warn("memory", err)
+	} else {
+		out.Total = vm.Total
+		out.Used = vm.Used
+		out.Available = vm.Available
+		out.UsedPercent = vm.UsedPercent
+	}
+	if swap, err := mem.SwapMemory(); err != nil {
+		c.warn("swap", err)
+	} else {
+		out.SwapTotal = swap.Total
+		out.SwapUsed = swap.Used
+		out.SwapPercent = swap.UsedPercent
+	}
+	return out
+}
+
+// network 各网卡的吞吐，跳过回环和容器虚拟网卡
+func (c *collector) network(elapsed float64) []protocol.NetworkMetrics {
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		c.warn("network", err)
+		return nil
+	}
+
+	current := make(map[string]net.IOCountersStat, len(counters))
+	out := make([]protocol.NetworkMetrics, 0, len(counters))
+	for _, n := range counters {
+		if skipInterface(n.Name) {
+			continue
+		}
+		current[n.Name] = n
+		item := protocol.NetworkMetrics{
+			Name:      n.Name,
+			BytesSent: n.BytesSent,
+			BytesRecv: n.BytesRecv,
+			Errors:    n.Errin + n.Errout,
+			Drops:     n.Dropin + n.Dropout,
+		}
+		if prev, ok := c.lastNet[n.Name]; ok && elapsed > 0 {
+			item.SendRate = rate(prev.BytesSent, n.BytesSent, elapsed)
+			item.RecvRate = rate(prev.BytesRecv, n.BytesRecv, elapsed)
+		}
+		out = append(out, item)
+	}
+	c.lastNet = current
+	return out
+}
+
+// skipInterface 回环、docker 网桥和 veth 等虚拟网卡不参与统计
+func skipInterface(name string) bool {
+	lower := strings.ToLower(name)
+	if lower == "lo" || strings.HasPrefix(lower, "loopback") {
+		return true
+	}
+	for _, prefix := range []string{"veth", "docker", "br-", "virbr"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// diskIO 各块设备的读写速率，跳过 loop 和 ram 设备
+func (c *collector) diskIO(elapsed float64) []protocol.DiskIOMetrics {
+	counters, err := disk.IOCounters()
+	if err != nil {
+		c.warn("disk_io", err)
+		return nil
+	}
+
+	out := make([]protocol.DiskIOMetrics, 0, len(counters))
+	for name, d := range counters {
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			delete(counters, name)
+			continue
+		}
+		item := protocol.DiskIOMetrics{Name: name}
+		if prev, ok := c.lastDisk[name]; ok && elapsed > 0 {
+			item.ReadRate = rate(prev.ReadBytes, d.ReadBytes, elapsed)
+			item.WriteRate = rate(prev.WriteBytes, d.WriteBytes, elapsed)
+			item.ReadIOPS = rate(prev.ReadCount, d.ReadCount, elapsed)
+			item.WriteIOPS = rate(prev.WriteCount, d.WriteCount, elapsed)
+			// IoTime 为设备忙碌的毫秒数
+			item.BusyPercent = clampPercent(rate(prev.IoTime, d.IoTime, elapsed) / 10)
+		}
+		out = append(out, item)
+	}
+	c.lastDisk = counters
+	return out
+}
+
+// process 服务进程自身的资源占用
+func (c *collector) process() protocol.ProcessMetrics {
+	out := protocol.ProcessMetrics{
+		PID:    int32(os.Getpid()),
+		Uptime: int64(time.Since(c.started).Seconds()),
+	}
+	if c.proc == nil {
+		return out
+	}
+
+	// Percent(0) 返回与上一次调用之间的占用
+	if pct, err := c.proc.Percent(0); err == nil {
+		out.CPUPercent = pct
+	}
+	if info, err := c.proc.MemoryInfo(); err == nil {
+		out.RSS = info.RSS
+		out.VMS = info.VMS
+	}
+	if n, err := c.proc.NumThreads(); err == nil {
+		out.Threads = n
+	}
+	if runtime.GOOS != "windows" {
+		if n, err := c.proc.NumFDs(); err == nil {
+			out.OpenFiles = n
+		}
+	}
+	return out
+}
+
+// goRuntime Go 运行时状态
+func goRuntime() protocol.RuntimeMetrics {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	out := protocol.RuntimeMetrics{
+		Goroutines:   runtime.NumGoroutine(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		HeapAlloc:    ms.HeapAlloc,
+		HeapInuse:    ms.HeapInuse,
+		Sys:          ms.Sys,
+		NumGC:        ms.NumGC,
+		PauseTotalNs: ms.PauseTotalNs,
+	}
+	if ms.NumGC > 0 {
+		out.LastPauseNs = ms.PauseNs[(ms.NumGC+255)%256]
+	}
+	return out
+}
+
+// rate 累计计数的每秒增量，计数回绕（重启、网卡重建）时返回 0
+func rate(prev, cur uint64, elapsed float64) float64 {
+	if cur < prev || elapsed <= 0 {
+		return 0
+	}
+	return float64(cur-prev) / elapsed
+}
+
+func clampPercent(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 100 {
+		return 100
+	}
+	return v
+}
+
+// warn 采集项不可用时只记录一次警告，避免每个采样周期刷日志
+func (c *collector) warn(item string, err error) {
+	if c.warned[item] {
+		return
+	}
+	c.warned[item] = true
+	logger.Warn("系统监控采集项不可用", zap.String("item", item), zap.Error(err))
+}
@@ -0,0 +1,174 @@
+// Package monitor 系统监控：定时采集 CPU、负载、内存、网络、磁盘 IO、进程和 Go 运行时状态
+//
+// 最近一段时间的采样保存在 Redis 有序集合中（按采样时间排序），供监控图表查询；
+// 订阅了 system_metrics 主题的 WebSocket 连接会实时收到每次采样。
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket"
+	"github.com/sunyuanling/server/websocket/protocol"
+)
+
+// redisKey 采样保存的有序集合，score 为采样时间（Unix 毫秒）
+const redisKey = "monitor:samples"
+
+// maxHistory 单次查询最多返回的采样数
+const maxHistory = 2000
+
+var ErrDisabled = errors.New("系统监控未启用")
+
+// service 采集服务
+type service struct {
+	rdb      *redis.Client
+	interval time.Duration
+	window   time.Duration
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+var std *service
+
+// Init 启动采集协程，并注册 WebSocket 订阅主题（需要 system:monitor 权限）
+// 采样间隔小于 0 时不启动
+func Init(db *gorm.DB, rdb *redis.Client, cfg config.MonitorConfig) {
+	if cfg.IntervalSeconds < 0 {
+		logger.Info("系统监控采集已关闭")
+		return
+	}
+
+	s := &service{
+		rdb:      rdb,
+		interval: time.Duration(cfg.IntervalSeconds) * time.Second,
+		window:   time.Duration(cfg.WindowMinutes) * time.Minute,
+		done:     make(chan struct{}),
+	}
+
+	websocket.RegisterTopic(protocol.TopicSystemMetrics, func(userID uint) bool {
+		grants, err := rbac.Load(db, userID)
+		return err == nil && grants.Has(rbac.PermSystemMonitor)
+	})
+
+	s.wg.Add(1)
+	go s.run(newCollector(cfg.IntervalSeconds))
+	std = s
+
+	logger.Info("系统监控采集已启动",
+		zap.Duration("interval", s.interval),
+		zap.Duration("window", s.window),
+	)
+}
+
+// Close 停止采集
+func Close() {
+	if std == nil {
+		return
+	}
+	close(std.done)
+	std.wg.Wait()
+}
+
+// Latest 最近一次采样，还没有采样时返回 nil
+func Latest(ctx context.Context) (json.RawMessage, error) {
+	if std == nil {
+		return nil, ErrDisabled
+	}
+	items, err := std.rdb.ZRevRange(ctx, redisKey, 0, 0).Result()
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return json.RawMessage(items[0]), nil
+}
+
+// History 采样时间晚于 since（Unix 毫秒）的采样，按时间升序，最多 limit 条（取最新的）
+func History(ctx context.Context, since int64, limit int) ([]json.RawMessage, error) {
+	if std == nil {
+		return nil, ErrDisabled
+	}
+	if limit <= 0 || limit > maxHistory {
+		limit = maxHistory
+	}
+
+	items, err := std.rdb.ZRevRangeByScore(ctx, redisKey, &redis.ZRangeBy{
+		Min:   "(" + strconv.FormatInt(since, 10),
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]json.RawMessage, len(items))
+	for i, item := range items {
+		out[len(items)-1-i] = json.RawMessage(item)
+	}
+	return out, nil
+}
+
+// Interval 采样间隔（秒），未启用时为 0
+func Interval() int {
+	if std == nil {
+		return 0
+	}
+	return int(std.interval / time.Second)
+}
+
+// run 采集主循环
+func (s *service) run(c *collector) {
+	defer s.wg.Done()
+
+	// 先取一次基准值，下一次采样才能计算速率
+	c.sample()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if m := c.sample(); m != nil {
+				s.publish(m)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// publish 写入 Redis 滚动窗口并推送给订阅连接
+func (s *service) publish(m *protocol.SystemMetrics) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		logger.Error("序列化监控采样失败", zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	cutoff := m.Time - s.window.Milliseconds()
+	pipe := s.rdb.Pipeline()
+	pipe.ZAdd(ctx, redisKey, redis.Z{Score: float64(m.Time), Member: data})
+	pipe.ZRemRangeByScore(ctx, redisKey, "-inf", "("+strconv.FormatInt(cutoff, 10))
+	pipe.Expire(ctx, redisKey, s.window+s.interval)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warn("保存监控采样失败", zap.Error(err))
+	}
+
+	if websocket.HasSubscribers(protocol.TopicSystemMetrics) {
+		websocket.PublishTopic(protocol.TopicSystemMetrics, websocket.MessageTypeMetrics, json.RawMessage(data))
+	}
+}
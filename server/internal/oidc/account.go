@@ -0,0 +1,270 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/password"
+)
+
+// RegistrationMode 自动创建的账号在注册记录中的方式
+const RegistrationMode = "oidc"
+
+var (
+	ErrNoAccount      = errors.New("该外部账号未关联本地账号")
+	ErrDomainNotAllow = errors.New("该邮箱域名不允许自动创建账号")
+	ErrIdentityInUse  = errors.New("该外部账号已关联其他本地账号")
+	ErrAlreadyLinked  = errors.New("当前账号已关联该身份提供方的其他外部账号")
+	ErrUserDisabled   = errors.New("账号已被禁用")
+)
+
+// usernameInvalidRun 生成用户名时替换掉的字符
+var usernameInvalidRun = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// Resolution 外部身份对应的本地账号
+type Resolution struct {
+	User    *model.User
+	Linked  bool // 本次新建了关联
+	Created bool // 本次自动创建了账号
+}
+
+// ResolveUser 按 (provider, sub) 查找本地账号；没有关联时依次尝试：
+// 已登录用户主动关联、按已验证邮箱关联、自动创建账号
+func ResolveUser(db *gorm.DB, p *Provider, claims *Claims, linkUserID uint, ip string) (*Resolution, error) {
+	cfg := p.cfg
+	var res *Resolution
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var identity model.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", cfg.Name, claims.Subject).First(&identity).Error
+		switch {
+		case err == nil:
+			if linkUserID != 0 && identity.UserID != linkUserID {
+				return ErrIdentityInUse
+			}
+			user, err := loadUser(tx, identity.UserID)
+			if err != nil {
+				return err
+			}
+			res = &Resolution{User: user}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		case linkUserID != 0:
+			user, err := loadUser(tx, linkUserID)
+			if err != nil {
+				return err
+			}
+			res = &Resolution{User: user, Linked: true}
+		default:
+			res, err = matchOrProvision(tx, p, claims, ip)
+			if err != nil {
+				return err
+			}
+		}
+
+		if res.Linked {
+			var count int64
+			if err := tx.Model(&model.UserIdentity{}).
+				Where("user_id = ? AND provider = ?", res.User.ID, cfg.Name).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrAlreadyLinked
+			}
+			identity = model.UserIdentity{UserID: res.User.ID, Provider: cfg.Name, Subject: claims.Subject}
+			if err := tx.Create(&identity).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.Model(&identity).Updates(map[string]interface{}{
+			"email":         claims.Email,
+			"last_login_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// matchOrProvision 按已验证邮箱关联已有账号，或自动创建账号
+func matchOrProvision(tx *gorm.DB, p *Provider, claims *Claims, ip string) (*Resolution, error) {
+	cfg := p.cfg
+	email, verified := verifiedEmail(claims)
+
+	// 只按身份提供方声明已验证的邮箱关联，否则任何人都能用别人的邮箱注册外部账号接管本地账号
+	if cfg.LinkByEmail && verified {
+		var user model.User
+		err := tx.Where("LOWER(email) = ?", email).First(&user).Error
+		if err == nil {
+			if err := checkActive(&user); err != nil {
+				return nil, err
+			}
+			return &Resolution{User: &user, Linked: true}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if !cfg.AutoProvision {
+		return nil, ErrNoAccount
+	}
+	if len(cfg.AllowedDomains) > 0 && (!verified || !domainAllowed(email, cfg.AllowedDomains)) {
+		return nil, ErrDomainNotAllow
+	}
+
+	user, err := provisionUser(tx, claims, email, verified, ip)
+	if err != nil {
+		return nil, err
+	}
+	return &Resolution{User: user, Linked: true, Created: true}, nil
+}
+
+// provisionUser 创建本地账号（随机密码，用户之后可以通过找回密码设置）
+func provisionUser(tx *gorm.DB, claims *Claims, email string, verified bool, ip string) (*model.User, error) {
+	username, err := uniqueUsername(tx, claims)
+	if err != nil {
+		return nil, err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	hashed, err := password.HashPassword(base64.RawURLEncoding.EncodeToString(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	user := model.User{
+		Username: username,
+		Password: hashed,
+		Avatar:   claims.Picture,
+		Role:     model.RoleUser,
+		Status:   model.StatusActive,
+	}
+	// 只保存已验证的邮箱，且邮箱已被占用时留空（避免唯一约束冲突）
+	if verified {
+		var count int64
+		if err := tx.Model(&model.User{}).Where("LOWER(email) = ?", email).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			user.Email = email
+		}
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := model.UserRegistration{UserID: user.ID, Mode: RegistrationMode, IP: ip}
+	if user.Email != "" {
+		record.EmailVerifiedAt = &now
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// uniqueUsername 从 preferred_username / 邮箱前缀 / 姓名生成用户名，重名时追加数字
+func uniqueUsername(tx *gorm.DB, claims *Claims) (string, error) {
+	base := ""
+	for _, candidate := range []string{claims.PreferredUsername, strings.Split(claims.Email, "@")[0], claims.Name} {
+		candidate = strings.Trim(usernameInvalidRun.ReplaceAllString(candidate, "_"), "_.-")
+		if len(candidate) >= 3 {
+			base = candidate
+			break
+		}
+	}
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	name := base
+	for i := 1; i <= 100; i++ {
+		var count int64
+		if err := tx.Model(&model.User{}).Where("username = ?", name).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return name, nil
+		}
+		name = fmt.Sprintf("%s%d", base, i+1)
+	}
+	return "", fmt.Errorf("无法为 %s 生成可用的用户名", base)
+}
+
+func loadUser(tx *gorm.DB, userID uint) (*model.User, error) {
+	var user model.User
+	if err := tx.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if err := checkActive(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// checkActive 只有正常状态的账号可以通过外部身份登录或关联（禁用、待验证、待审批、注销冷静期内都拒绝）
+func checkActive(user *model.User) error {
+	if user.Status != model.StatusActive {
+		return ErrUserDisabled
+	}
+	return nil
+}
+
+// verifiedEmail 规范化后的邮箱，以及身份提供方是否声明其已验证
+func verifiedEmail(claims *Claims) (string, bool) {
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	return email, email != "" && bool(claims.EmailVerified)
+}
+
+func domainAllowed(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, d := range domains {
+		if strings.EqualFold(domain, strings.TrimPrefix(d, "@")) {
+			return true
+		}
+	}
+	return false
+}
+
+// Identities 用户已关联的外部身份
+func Identities(db *gorm.DB, userID uint) ([]model.UserIdentity, error) {
+	var list []model.UserIdentity
+	err := db.Where("user_id = ?", userID).Order("id").Find(&list).Error
+	return list, err
+}
+
+// Unlink 取消关联，未找到时返回 gorm.ErrRecordNotFound
+func Unlink(db *gorm.DB, userID, identityID uint) error {
+	result := db.Where("id = ? AND user_id = ?", identityID, userID).Delete(&model.UserIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
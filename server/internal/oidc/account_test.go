@@ -0,0 +1,80 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sunyuanling/server/internal/model"
+)
+
+func TestVerifiedEmail(t *testing.T) {
+	// 只有身份提供方声明已验证的邮箱才会用于关联已有账号
+	tests := []struct {
+		name     string
+		claims   string
+		email    string
+		verified bool
+	}{
+		{"已验证", `{"email":"Alice@Example.com ","email_verified":true}`, "alice@example.com", true},
+		{"字符串形式的 true", `{"email":"alice@example.com","email_verified":"true"}`, "alice@example.com", true},
+		{"未验证", `{"email":"alice@example.com","email_verified":false}`, "alice@example.com", false},
+		{"字符串形式的 false", `{"email":"alice@example.com","email_verified":"false"}`, "alice@example.com", false},
+		{"缺少 email_verified", `{"email":"alice@example.com"}`, "alice@example.com", false},
+		{"没有邮箱", `{"email_verified":true}`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Claims
+			if err := json.Unmarshal([]byte(tt.claims), &c); err != nil {
+				t.Fatal(err)
+			}
+			email, verified := verifiedEmail(&c)
+			if email != tt.email || verified != tt.verified {
+				t.Errorf("verifiedEmail = (%q, %v), 期望 (%q, %v)", email, verified, tt.email, tt.verified)
+			}
+		})
+	}
+}
+
+func TestCheckActive(t *testing.T) {
+	tests := []struct {
+		status int16
+		ok     bool
+	}{
+		{model.StatusActive, true},
+		{model.StatusInactive, false},
+		{model.StatusPendingVerify, false},
+		{model.StatusPendingApproval, false},
+		{model.StatusPendingDeletion, false},
+	}
+	for _, tt := range tests {
+		err := checkActive(&model.User{Status: tt.status})
+		if tt.ok && err != nil {
+			t.Errorf("状态 %d: %v", tt.status, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrUserDisabled) {
+			t.Errorf("状态 %d 错误 = %v, 期望 ErrUserDisabled", tt.status, err)
+		}
+	}
+}
+
+func TestDomainAllowed(t *testing.T) {
+	domains := []string{"example.com", "@corp.example.org"}
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"alice@example.com", true},
+		{"alice@EXAMPLE.com", true},
+		{"bob@corp.example.org", true},
+		{"eve@evil.com", false},
+		{"eve@sub.example.com", false},
+		{"no-at-sign", false},
+	}
+	for _, tt := range tests {
+		if got := domainAllowed(tt.email, domains); got != tt.want {
+			t.Errorf("domainAllowed(%q) = %v, 期望 %v", tt.email, got, tt.want)
+		}
+	}
+}
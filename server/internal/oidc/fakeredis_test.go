@@ -0,0 +1,122 @@
+package oidc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newFakeRedis 只实现 state 存取用到的命令（SET / GET / GETDEL / DEL），测试环境没有 Redis
+func newFakeRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu   sync.Mutex
+		data = map[string]string{}
+	)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveRedis(conn, &mu, data)
+		}
+	}()
+
+	rdb := redis.NewClient(&redis.Options{Addr: ln.Addr().String(), Protocol: 2, DisableIdentity: true})
+	t.Cleanup(func() {
+		rdb.Close()
+		ln.Close()
+	})
+	return rdb
+}
+
+func serveRedis(conn net.Conn, mu *sync.Mutex, data map[string]string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		reply := execute(data, args)
+		mu.Unlock()
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func execute(data map[string]string, args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SET":
+		data[args[1]] = args[2] // 测试中不会过期，忽略 EX/PX
+		return "+OK\r\n"
+	case "GET", "GETDEL":
+		v, ok := data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		if strings.EqualFold(args[0], "GETDEL") {
+			delete(data, args[1])
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "DEL":
+		n := 0
+		for _, k := range args[1:] {
+			if _, ok := data[k]; ok {
+				delete(data, k)
+				n++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// readCommand 读取一条 RESP 数组形式的命令
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected %q", line)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("bad array length %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
@@ -0,0 +1,172 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const stateKey = "oidc_state:%s" // 授权请求（state -> AuthRequest）
+
+var (
+	ErrStateInvalid  = errors.New("登录请求已过期，请重新发起")
+	ErrTokenExchange = errors.New("向身份提供方换取令牌失败")
+)
+
+// AuthRequest 发起授权时保存的上下文，回调时按 state 取回（只能使用一次）
+type AuthRequest struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	DeviceID     string `json:"device_id,omitempty"`
+	TrustToken   string `json:"trust_token,omitempty"`  // 受信任设备凭证，回调时用于判断是否跳过两步验证
+	LinkUserID   uint   `json:"link_user_id,omitempty"` // 已登录用户关联外部身份时为当前用户ID
+}
+
+// Begin 生成 state、nonce 和 PKCE 校验码，返回跳转到身份提供方的授权地址
+func (r *Registry) Begin(ctx context.Context, rdb *redis.Client, p *Provider, req AuthRequest) (string, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomToken(24)
+	if err != nil {
+		return "", err
+	}
+	if req.Nonce, err = randomToken(24); err != nil {
+		return "", err
+	}
+	if req.CodeVerifier, err = randomToken(48); err != nil {
+		return "", err
+	}
+	req.Provider = p.cfg.Name
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	if err := rdb.Set(ctx, fmt.Sprintf(stateKey, state), data, r.stateTTL).Err(); err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(req.CodeVerifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {req.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + query.Encode(), nil
+}
+
+// Finish 消费 state，用授权码换取令牌并校验 ID Token
+func (r *Registry) Finish(ctx context.Context, rdb *redis.Client, state, code string) (*AuthRequest, *Claims, error) {
+	if state == "" || code == "" {
+		return nil, nil, ErrStateInvalid
+	}
+	data, err := rdb.GetDel(ctx, fmt.Sprintf(stateKey, state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil, ErrStateInvalid
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var req AuthRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, nil, ErrStateInvalid
+	}
+	p, err := r.Get(req.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawIDToken, err := p.exchange(ctx, code, req.CodeVerifier)
+	if err != nil {
+		return nil, nil, err
+	}
+	claims, err := p.VerifyIDToken(ctx, rawIDToken, req.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &req, claims, nil
+}
+
+// exchange 授权码换取令牌，返回 ID Token
+func (p *Provider) exchange(ctx context.Context, code, verifier string) (string, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID) // 公共客户端
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic：按规范先对 ID 和密钥做表单编码
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrTokenExchange, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: 响应解析失败（%d）", ErrTokenExchange, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("%w: %s %s", ErrTokenExchange, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: 响应中没有 id_token", ErrTokenExchange)
+	}
+	return body.IDToken, nil
+}
+
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
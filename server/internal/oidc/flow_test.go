@@ -0,0 +1,119 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFinishPKCERoundTrip(t *testing.T) {
+	for _, secret := range []string{"", "s3cret"} {
+		name := "公共客户端"
+		if secret != "" {
+			name = "client_secret_basic"
+		}
+		t.Run(name, func(t *testing.T) {
+			idp := newTestIdP(t)
+			idp.secret = secret
+			r := idp.registry(secret)
+			rdb := newFakeRedis(t)
+			ctx := context.Background()
+			p, _ := r.Get("test")
+
+			authURL, err := r.Begin(ctx, rdb, p, AuthRequest{DeviceID: "device-1", LinkUserID: 7})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(authURL, idp.URL+"/authorize?") {
+				t.Fatalf("授权地址 = %s", authURL)
+			}
+			u, _ := url.Parse(authURL)
+			q := u.Query()
+			for key, want := range map[string]string{
+				"response_type":         "code",
+				"client_id":             testClientID,
+				"redirect_uri":          testRedirectURL,
+				"scope":                 "openid email",
+				"code_challenge_method": "S256",
+			} {
+				if got := q.Get(key); got != want {
+					t.Errorf("%s = %q, 期望 %q", key, got, want)
+				}
+			}
+			if q.Get("state") == "" || q.Get("nonce") == "" || len(q.Get("code_challenge")) != 43 {
+				t.Fatalf("缺少 state/nonce/code_challenge: %v", q)
+			}
+
+			// 令牌端点按授权时的 code_challenge 校验 code_verifier
+			code := idp.authorize(q.Get("code_challenge"), idp.claims(q.Get("nonce")))
+			req, claims, err := r.Finish(ctx, rdb, q.Get("state"), code)
+			if err != nil {
+				t.Fatalf("Finish: %v", err)
+			}
+			if claims.Subject != "user-1" {
+				t.Errorf("sub = %q", claims.Subject)
+			}
+			if req.Provider != "test" || req.DeviceID != "device-1" || req.LinkUserID != 7 {
+				t.Errorf("授权请求上下文 = %+v", req)
+			}
+
+			// state 只能使用一次
+			if _, _, err := r.Finish(ctx, rdb, q.Get("state"), code); !errors.Is(err, ErrStateInvalid) {
+				t.Errorf("重复使用 state 错误 = %v, 期望 ErrStateInvalid", err)
+			}
+		})
+	}
+}
+
+func TestFinishRejects(t *testing.T) {
+	idp := newTestIdP(t)
+	r := idp.registry("")
+	rdb := newFakeRedis(t)
+	ctx := context.Background()
+	p, _ := r.Get("test")
+
+	begin := func() url.Values {
+		authURL, err := r.Begin(ctx, rdb, p, AuthRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, _ := url.Parse(authURL)
+		return u.Query()
+	}
+
+	t.Run("未知 state", func(t *testing.T) {
+		if _, _, err := r.Finish(ctx, rdb, "unknown", "code"); !errors.Is(err, ErrStateInvalid) {
+			t.Errorf("错误 = %v, 期望 ErrStateInvalid", err)
+		}
+	})
+
+	t.Run("缺少 code", func(t *testing.T) {
+		if _, _, err := r.Finish(ctx, rdb, begin().Get("state"), ""); !errors.Is(err, ErrStateInvalid) {
+			t.Errorf("错误 = %v, 期望 ErrStateInvalid", err)
+		}
+	})
+
+	t.Run("code_verifier 与其他授权请求的 challenge 不匹配", func(t *testing.T) {
+		q, other := begin(), begin()
+		code := idp.authorize(other.Get("code_challenge"), idp.claims(q.Get("nonce")))
+		if _, _, err := r.Finish(ctx, rdb, q.Get("state"), code); !errors.Is(err, ErrTokenExchange) {
+			t.Errorf("错误 = %v, 期望 ErrTokenExchange", err)
+		}
+	})
+
+	t.Run("ID Token 的 nonce 属于其他授权请求", func(t *testing.T) {
+		q, other := begin(), begin()
+		code := idp.authorize(q.Get("code_challenge"), idp.claims(other.Get("nonce")))
+		if _, _, err := r.Finish(ctx, rdb, q.Get("state"), code); !errors.Is(err, ErrInvalidIDToken) {
+			t.Errorf("错误 = %v, 期望 ErrInvalidIDToken", err)
+		}
+	})
+
+	t.Run("授权码无效", func(t *testing.T) {
+		if _, _, err := r.Finish(ctx, rdb, begin().Get("state"), "forged"); !errors.Is(err, ErrTokenExchange) {
+			t.Errorf("错误 = %v, 期望 ErrTokenExchange", err)
+		}
+	})
+}
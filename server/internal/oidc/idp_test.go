@@ -0,0 +1,235 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sunyuanling/server/config"
+)
+
+const (
+	testClientID    = "filesync"
+	testRedirectURL = "https://app.example.com/oidc/callback"
+)
+
+var b64 = base64.RawURLEncoding
+
+// testIdP 用 httptest 模拟身份提供方：发现文档、JWKS 和令牌端点
+type testIdP struct {
+	*httptest.Server
+	t *testing.T
+
+	mu       sync.Mutex
+	rsaKeys  map[string]*rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	issuer   string           // 发现文档中返回的 issuer（默认为服务地址）
+	secret   string           // 非空时令牌端点要求 client_secret_basic
+	grants   map[string]grant // 授权码 -> 授权时的 PKCE challenge 和要放入 ID Token 的声明
+	jwksHits atomic.Int32
+}
+
+type grant struct {
+	challenge string
+	claims    map[string]interface{}
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	idp := &testIdP{t: t, rsaKeys: map[string]*rsa.PrivateKey{}, grants: map[string]grant{}}
+	idp.rotate("rsa-1")
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp.ecKey = ecKey
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+	idp.Server = httptest.NewServer(mux)
+	idp.issuer = idp.URL
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// rotate 换成新的 RSA 签名密钥（旧密钥从 JWKS 中移除）
+func (idp *testIdP) rotate(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	idp.mu.Lock()
+	idp.rsaKeys = map[string]*rsa.PrivateKey{kid: key}
+	idp.mu.Unlock()
+}
+
+func (idp *testIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	issuer := idp.issuer
+	idp.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                 issuer,
+		"authorization_endpoint": idp.URL + "/authorize",
+		"token_endpoint":         idp.URL + "/token",
+		"jwks_uri":               idp.URL + "/jwks",
+	})
+}
+
+func (idp *testIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	idp.jwksHits.Add(1)
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	keys := []map[string]string{{
+		"kty": "EC", "kid": "ec-1", "use": "sig", "crv": "P-256",
+		"x": b64.EncodeToString(idp.ecKey.X.FillBytes(make([]byte, 32))),
+		"y": b64.EncodeToString(idp.ecKey.Y.FillBytes(make([]byte, 32))),
+	}}
+	for kid, key := range idp.rsaKeys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA", "kid": kid, "use": "sig",
+			"n": b64.EncodeToString(key.N.Bytes()),
+			"e": b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	// 加密用途的密钥应被忽略
+	keys = append(keys, map[string]string{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "AQAB", "e": "AQAB"})
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+}
+
+// token 令牌端点：校验授权码、redirect_uri、客户端身份和 PKCE code_verifier
+func (idp *testIdP) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	idp.mu.Lock()
+	g, ok := idp.grants[r.PostForm.Get("code")]
+	delete(idp.grants, r.PostForm.Get("code"))
+	secret := idp.secret
+	idp.mu.Unlock()
+
+	if secret != "" {
+		id, pass, basic := r.BasicAuth()
+		if !basic || id != testClientID || pass != secret || r.PostForm.Has("client_id") {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+	} else if r.PostForm.Get("client_id") != testClientID {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok || r.PostForm.Get("grant_type") != "authorization_code":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+	case r.PostForm.Get("redirect_uri") != testRedirectURL:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "redirect_uri"})
+	case b64.EncodeToString(verifier[:]) != g.challenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "code_verifier"})
+	default:
+		writeJSON(w, http.StatusOK, map[string]string{"id_token": idp.sign("RS256", "rsa-1", g.claims), "token_type": "Bearer"})
+	}
+}
+
+// authorize 模拟用户在身份提供方完成登录，返回授权码
+func (idp *testIdP) authorize(challenge string, claims map[string]interface{}) string {
+	code := "code-" + challenge[:8]
+	idp.mu.Lock()
+	idp.grants[code] = grant{challenge: challenge, claims: claims}
+	idp.mu.Unlock()
+	return code
+}
+
+// claims 一组有效的 ID Token 声明
+func (idp *testIdP) claims(nonce string) map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss":            idp.URL,
+		"sub":            "user-1",
+		"aud":            testClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "alice@example.com",
+		"email_verified": true,
+	}
+}
+
+// sign 按 alg 签发 ID Token；RS256 使用 kid 对应的密钥，ES256 使用 ec-1
+func (idp *testIdP) sign(alg, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch alg {
+	case "RS256":
+		idp.mu.Lock()
+		key, ok := idp.rsaKeys[kid]
+		idp.mu.Unlock()
+		if !ok {
+			// 未发布的密钥：签名有效但 JWKS 中找不到
+			var err error
+			if key, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+				idp.t.Fatal(err)
+			}
+		}
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			idp.t.Fatal(err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, idp.ecKey, digest[:])
+		if err != nil {
+			idp.t.Fatal(err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return input + "." + b64.EncodeToString(sig)
+}
+
+// registry 只配置了这一个身份提供方的 Registry
+func (idp *testIdP) registry(clientSecret string) *Registry {
+	return NewRegistry(config.OIDCConfig{
+		StateMinutes: 10,
+		Providers: []config.OIDCProviderConfig{{
+			Name:         "test",
+			Issuer:       idp.URL,
+			ClientID:     testClientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  testRedirectURL,
+			Scopes:       []string{"openid", "email"},
+		}},
+	})
+}
+
+func (idp *testIdP) provider() *Provider {
+	p, err := idp.registry("").Get("test")
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	return p
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,202 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const clockSkew = time.Minute // 允许的时钟偏差
+
+var ErrInvalidIDToken = errors.New("ID Token 无效")
+
+// Claims ID Token 中用到的声明
+type Claims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp"`
+	Expiry            int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	NotBefore         int64    `json:"nbf"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     flexBool `json:"email_verified"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+	Picture           string   `json:"picture"`
+}
+
+// audience aud 可以是字符串或字符串数组
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return err
+	}
+	*a = multi
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// flexBool 部分身份提供方把 email_verified 返回成字符串 "true"
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、签发方、受众、有效期和 nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*Claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: 格式错误", ErrInvalidIDToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: 头部解析失败", ErrInvalidIDToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: 签名解析失败", ErrInvalidIDToken)
+	}
+
+	key, err := p.publicKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: 声明解析失败", ErrInvalidIDToken)
+	}
+	if err := p.validateClaims(&claims, nonce, time.Now()); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	return &claims, nil
+}
+
+func (p *Provider) validateClaims(c *Claims, nonce string, now time.Time) error {
+	if c.Issuer != p.cfg.Issuer {
+		return fmt.Errorf("签发方不匹配: %s", c.Issuer)
+	}
+	if c.Subject == "" {
+		return errors.New("缺少 sub")
+	}
+	if !c.Audience.contains(p.cfg.ClientID) {
+		return errors.New("受众不匹配")
+	}
+	if len(c.Audience) > 1 && c.AuthorizedParty != p.cfg.ClientID {
+		return errors.New("azp 不匹配")
+	}
+	if c.Expiry == 0 || now.After(time.Unix(c.Expiry, 0).Add(clockSkew)) {
+		return errors.New("已过期")
+	}
+	if c.IssuedAt != 0 && now.Add(clockSkew).Before(time.Unix(c.IssuedAt, 0)) {
+		return errors.New("签发时间晚于当前时间")
+	}
+	if c.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(c.NotBefore, 0)) {
+		return errors.New("尚未生效")
+	}
+	if c.Nonce != nonce {
+		return errors.New("nonce 不匹配")
+	}
+	return nil
+}
+
+// verifySignature 支持 RS256/384/512、PS256/384/512、ES256/384/512（不接受 none 和 HMAC）
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("不支持的签名算法: %s", alg)
+	}
+
+	var h hash.Hash
+	var hashID crypto.Hash
+	switch alg[2:] {
+	case "256":
+		h, hashID = sha256.New(), crypto.SHA256
+	case "384":
+		h, hashID = sha512.New384(), crypto.SHA384
+	case "512":
+		h, hashID = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("不支持的签名算法: %s", alg)
+	}
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("签名算法与公钥类型不匹配")
+		}
+		if alg[0] == 'R' {
+			return rsa.VerifyPKCS1v15(pub, hashID, digest, signature)
+		}
+		return rsa.VerifyPSS(pub, hashID, digest, signature, nil)
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("签名算法与公钥类型不匹配")
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("签名长度错误")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("签名校验失败")
+		}
+		return nil
+	default:
+		return fmt.Errorf("不支持的签名算法: %s", alg)
+	}
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
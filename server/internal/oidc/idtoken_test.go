@@ -0,0 +1,146 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyIDToken(t *testing.T) {
+	idp := newTestIdP(t)
+	const nonce = "nonce-1"
+
+	tests := []struct {
+		name   string
+		alg    string
+		kid    string
+		mutate func(c map[string]interface{})
+		ok     bool
+	}{
+		{"RS256", "RS256", "rsa-1", nil, true},
+		{"ES256", "ES256", "ec-1", nil, true},
+		{"aud 为数组且 azp 为本客户端", "RS256", "rsa-1", func(c map[string]interface{}) {
+			c["aud"] = []string{testClientID, "other"}
+			c["azp"] = testClientID
+		}, true},
+		{"email_verified 为字符串", "RS256", "rsa-1", func(c map[string]interface{}) { c["email_verified"] = "true" }, true},
+		{"时钟偏差内已过期", "RS256", "rsa-1", func(c map[string]interface{}) { c["exp"] = time.Now().Add(-30 * time.Second).Unix() }, true},
+
+		{"签发方不一致", "RS256", "rsa-1", func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }, false},
+		{"受众不是本客户端", "RS256", "rsa-1", func(c map[string]interface{}) { c["aud"] = "other" }, false},
+		{"aud 为数组但缺少 azp", "RS256", "rsa-1", func(c map[string]interface{}) { c["aud"] = []string{testClientID, "other"} }, false},
+		{"azp 不是本客户端", "RS256", "rsa-1", func(c map[string]interface{}) {
+			c["aud"] = []string{testClientID, "other"}
+			c["azp"] = "other"
+		}, false},
+		{"已过期", "RS256", "rsa-1", func(c map[string]interface{}) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() }, false},
+		{"缺少 exp", "RS256", "rsa-1", func(c map[string]interface{}) { delete(c, "exp") }, false},
+		{"签发时间在未来", "RS256", "rsa-1", func(c map[string]interface{}) { c["iat"] = time.Now().Add(5 * time.Minute).Unix() }, false},
+		{"尚未生效", "RS256", "rsa-1", func(c map[string]interface{}) { c["nbf"] = time.Now().Add(5 * time.Minute).Unix() }, false},
+		{"nonce 不一致", "RS256", "rsa-1", func(c map[string]interface{}) { c["nonce"] = "replayed" }, false},
+		{"缺少 nonce", "RS256", "rsa-1", func(c map[string]interface{}) { delete(c, "nonce") }, false},
+		{"缺少 sub", "RS256", "rsa-1", func(c map[string]interface{}) { delete(c, "sub") }, false},
+		{"未发布的密钥", "RS256", "rsa-unknown", nil, false},
+		{"算法与密钥类型不一致", "ES256", "rsa-1", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := idp.claims(nonce)
+			if tt.mutate != nil {
+				tt.mutate(claims)
+			}
+			raw := idp.sign(tt.alg, tt.kid, claims)
+
+			got, err := idp.provider().VerifyIDToken(context.Background(), raw, nonce)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("VerifyIDToken: %v", err)
+				}
+				if got.Subject != "user-1" || got.Email != "alice@example.com" || !bool(got.EmailVerified) {
+					t.Errorf("声明 = %+v", got)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("应拒绝该 ID Token")
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenSignature(t *testing.T) {
+	idp := newTestIdP(t)
+	p := idp.provider()
+	valid := idp.sign("RS256", "rsa-1", idp.claims("n"))
+	parts := strings.Split(valid, ".")
+
+	// 换成另一组声明但保留原签名
+	forgedClaims := idp.claims("n")
+	forgedClaims["sub"] = "admin"
+	forged := strings.Split(idp.sign("RS256", "rsa-1", forgedClaims), ".")[1]
+
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"篡改声明", parts[0] + "." + forged + "." + parts[2]},
+		{"alg none", b64.EncodeToString([]byte(`{"alg":"none","kid":"rsa-1"}`)) + "." + parts[1] + "."},
+		{"HS256", b64.EncodeToString([]byte(`{"alg":"HS256","kid":"rsa-1"}`)) + "." + parts[1] + "." + parts[2]},
+		{"段数不对", parts[0] + "." + parts[1]},
+		{"签名不是 base64url", parts[0] + "." + parts[1] + ".!!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.VerifyIDToken(context.Background(), tt.raw, "n"); !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("错误 = %v, 期望 ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenKeyRotation(t *testing.T) {
+	idp := newTestIdP(t)
+	p := idp.provider()
+	ctx := context.Background()
+
+	if _, err := p.VerifyIDToken(ctx, idp.sign("RS256", "rsa-1", idp.claims("n")), "n"); err != nil {
+		t.Fatal(err)
+	}
+	if hits := idp.jwksHits.Load(); hits != 1 {
+		t.Fatalf("JWKS 请求次数 = %d, 期望 1", hits)
+	}
+
+	// 身份提供方轮换密钥：刚拉取过公钥时不为未知 kid 重新拉取，避免被用来放大请求
+	idp.rotate("rsa-2")
+	rotated := idp.sign("RS256", "rsa-2", idp.claims("n"))
+	if _, err := p.VerifyIDToken(ctx, rotated, "n"); err == nil {
+		t.Fatal("最小刷新间隔内不应找到新密钥")
+	}
+	if hits := idp.jwksHits.Load(); hits != 1 {
+		t.Fatalf("最小刷新间隔内 JWKS 请求次数 = %d, 期望 1", hits)
+	}
+
+	// 超过最小刷新间隔后遇到未知 kid 重新拉取
+	p.mu.Lock()
+	p.keysAt = time.Now().Add(-keysMinRefresh - time.Second)
+	p.mu.Unlock()
+	if _, err := p.VerifyIDToken(ctx, rotated, "n"); err != nil {
+		t.Fatalf("轮换后的密钥应能验证: %v", err)
+	}
+	if hits := idp.jwksHits.Load(); hits != 2 {
+		t.Errorf("JWKS 请求次数 = %d, 期望 2", hits)
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	idp := newTestIdP(t)
+	idp.mu.Lock()
+	idp.issuer = "https://evil.example.com"
+	idp.mu.Unlock()
+
+	if _, err := idp.provider().Discover(context.Background()); !errors.Is(err, ErrDiscovery) {
+		t.Errorf("错误 = %v, 期望 ErrDiscovery", err)
+	}
+}
@@ -0,0 +1,268 @@
+// Package oidc OpenID Connect 依赖方：服务发现、授权码 + PKCE、ID Token 校验，以及外部身份与本地账号的关联
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sunyuanling/server/config"
+)
+
+const (
+	discoveryTTL    = time.Hour        // 发现文档缓存时间
+	keysTTL         = time.Hour        // 签名公钥缓存时间
+	keysMinRefresh  = 30 * time.Second // 遇到未知 kid 时重新拉取公钥的最小间隔
+	httpTimeout     = 10 * time.Second
+	maxResponseSize = 1 << 20
+)
+
+var (
+	ErrUnknownProvider = errors.New("未知的身份提供方")
+	ErrDiscovery       = errors.New("获取身份提供方配置失败")
+)
+
+// Discovery 发现文档（只解析用到的字段）
+type Discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint"`
+	SigningAlgs           []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// Provider 一个身份提供方，发现文档和签名公钥按需拉取并缓存
+type Provider struct {
+	cfg    config.OIDCProviderConfig
+	client *http.Client
+
+	mu           sync.Mutex
+	discovery    *Discovery
+	discoveredAt time.Time
+	keys         map[string]crypto.PublicKey
+	keysAt       time.Time
+}
+
+// Config 身份提供方配置
+func (p *Provider) Config() config.OIDCProviderConfig {
+	return p.cfg
+}
+
+// Registry 已配置的身份提供方
+type Registry struct {
+	providers map[string]*Provider
+	order     []string
+	stateTTL  time.Duration
+}
+
+// NewRegistry 根据配置创建身份提供方列表
+func NewRegistry(cfg config.OIDCConfig) *Registry {
+	r := &Registry{
+		providers: make(map[string]*Provider, len(cfg.Providers)),
+		stateTTL:  time.Duration(cfg.StateMinutes) * time.Minute,
+	}
+	for _, pc := range cfg.Providers {
+		r.providers[pc.Name] = &Provider{
+			cfg:    pc,
+			client: &http.Client{Timeout: httpTimeout},
+		}
+		r.order = append(r.order, pc.Name)
+	}
+	return r
+}
+
+// Get 按标识查找身份提供方
+func (r *Registry) Get(name string) (*Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// List 按配置顺序返回全部身份提供方
+func (r *Registry) List() []*Provider {
+	list := make([]*Provider, 0, len(r.order))
+	for _, name := range r.order {
+		list = append(list, r.providers[name])
+	}
+	return list
+}
+
+// Discover 获取发现文档（缓存一小时），并校验其中的 issuer 与配置一致
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+
+	var d Discovery
+	if err := p.getJSON(ctx, p.cfg.Issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	if d.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("%w: issuer 不一致（配置 %s，实际 %s）", ErrDiscovery, p.cfg.Issuer, d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: 缺少必要的端点", ErrDiscovery)
+	}
+
+	p.discovery = &d
+	p.discoveredAt = time.Now()
+	return p.discovery, nil
+}
+
+// publicKey 按 kid 查找签名公钥；找不到时重新拉取（身份提供方可能已轮换密钥）
+func (p *Provider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	d, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok && time.Since(p.keysAt) < keysTTL {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysAt) < keysMinRefresh {
+		if key, ok := p.lookupKey(kid); ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("未找到签名公钥: %s", kid)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, d.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("获取签名公钥失败: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue // 跳过不支持的密钥类型
+		}
+		keys[k.Kid] = pub
+	}
+	p.keys = keys
+	p.keysAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("未找到签名公钥: %s", kid)
+}
+
+// lookupKey kid 为空且只有一把公钥时直接使用
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if key, ok := p.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 返回 %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v)
+}
+
+// jsonWebKey JWKS 中的一把公钥（支持 RSA 和 EC）
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA 公钥指数无效")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("不支持的曲线: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC 公钥不在曲线上")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("不支持的密钥类型: %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("公钥参数无效")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,133 @@
+// Package onetimecode 邮件一次性数字验证码：Redis 只保存哈希，限制发送频率和错误次数
+package onetimecode
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrTooFrequent     = errors.New("发送过于频繁，请稍后再试")
+	ErrInvalidCode     = errors.New("验证码错误或已过期")
+	ErrTooManyAttempts = errors.New("验证码错误次数过多，请重新获取")
+)
+
+// Store 一类验证码的存储，Redis 键为 <Prefix>:<用户ID>、<Prefix>_fail:<用户ID>、<Prefix>_cooldown:<用户ID>
+type Store struct {
+	Prefix         string        // Redis 键前缀
+	Digits         int           // 验证码位数
+	MaxTries       int           // 每个验证码允许的错误次数
+	ResendInterval time.Duration // 同一用户重新发送的最短间隔
+}
+
+func (s Store) codeKey(userID uint) string {
+	return fmt.Sprintf("%s:%d", s.Prefix, userID)
+}
+
+func (s Store) failKey(userID uint) string {
+	return fmt.Sprintf("%s_fail:%d", s.Prefix, userID)
+}
+
+func (s Store) cooldownKey(userID uint) string {
+	return fmt.Sprintf("%s_cooldown:%d", s.Prefix, userID)
+}
+
+// Issue 生成新验证码并保存哈希，旧验证码和错误次数作废；冷却期内返回 ErrTooFrequent
+func (s Store) Issue(ctx context.Context, rdb *redis.Client, userID uint, ttl time.Duration) (string, error) {
+	ok, err := rdb.SetNX(ctx, s.cooldownKey(userID), 1, s.ResendInterval).Result()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrTooFrequent
+	}
+
+	code, err := newCode(s.Digits)
+	if err != nil {
+		return "", err
+	}
+	pipe := rdb.TxPipeline()
+	pipe.Set(ctx, s.codeKey(userID), hashCode(code), ttl)
+	pipe.Del(ctx, s.failKey(userID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// Revoke 作废验证码并解除发送冷却，用于邮件发送失败后允许立即重试
+func (s Store) Revoke(ctx context.Context, rdb *redis.Client, userID uint) {
+	rdb.Del(ctx, s.codeKey(userID), s.cooldownKey(userID))
+}
+
+// Verify 校验并消费验证码，错误时计数，超过次数后作废验证码
+func (s Store) Verify(ctx context.Context, rdb *redis.Client, userID uint, code string) error {
+	key := s.codeKey(userID)
+	stored, err := rdb.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrInvalidCode
+	}
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hashCode(strings.TrimSpace(code)))) != 1 {
+		return s.recordFailure(ctx, rdb, userID)
+	}
+
+	// 一次性：删除成功才算消费，防止并发重复使用
+	n, err := rdb.Del(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrInvalidCode
+	}
+	rdb.Del(ctx, s.failKey(userID))
+	return nil
+}
+
+// recordFailure 记录一次验证码错误，超过次数后作废验证码
+func (s Store) recordFailure(ctx context.Context, rdb *redis.Client, userID uint) error {
+	key := s.failKey(userID)
+	count, err := rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if ttl, err := rdb.TTL(ctx, s.codeKey(userID)).Result(); err == nil && ttl > 0 {
+		rdb.Expire(ctx, key, ttl)
+	}
+	if count >= int64(s.MaxTries) {
+		rdb.Del(ctx, s.codeKey(userID), key)
+		return ErrTooManyAttempts
+	}
+	return ErrInvalidCode
+}
+
+// newCode 生成数字验证码
+func newCode(digits int) (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < digits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// hashCode Redis 中只保存验证码哈希
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
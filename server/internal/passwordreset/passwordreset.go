@@ -0,0 +1,102 @@
+// Package passwordreset 找回密码：邮件发送一次性验证码，校验后设置新密码并注销所有会话
+package passwordreset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/onetimecode"
+	"github.com/sunyuanling/server/internal/session"
+	"github.com/sunyuanling/server/pkg/mailer"
+	"github.com/sunyuanling/server/pkg/password"
+)
+
+// resetCodes 找回密码验证码
+var resetCodes = onetimecode.Store{
+	Prefix:         "pwd_reset",
+	Digits:         6,
+	MaxTries:       5,
+	ResendInterval: time.Minute,
+}
+
+var (
+	ErrTooFrequent     = onetimecode.ErrTooFrequent
+	ErrInvalidCode     = onetimecode.ErrInvalidCode
+	ErrTooManyAttempts = onetimecode.ErrTooManyAttempts
+	ErrUserDisabled    = errors.New("账号已被禁用")
+)
+
+// Request 向邮箱对应的用户发送找回密码验证码
+// 邮箱未注册时不报错（避免被用来探测账号是否存在），返回值只反映发送频率和发送是否成功
+func Request(ctx context.Context, db *gorm.DB, rdb *redis.Client, m mailer.Mailer, email string, ttl time.Duration) error {
+	var user model.User
+	err := db.Where("email = ?", strings.TrimSpace(email)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.Status != model.StatusActive {
+		return nil
+	}
+
+	code, err := resetCodes.Issue(ctx, rdb, user.ID, ttl)
+	if err != nil {
+		return err
+	}
+
+	minutes := int(ttl.Minutes())
+	err = m.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "找回密码验证码",
+		Body: fmt.Sprintf("%s，您好：\n\n您正在重置密码，验证码为：%s\n验证码 %d 分钟内有效，只能使用一次。\n\n如果不是您本人操作，请忽略此邮件。\n",
+			user.Username, code, minutes),
+	})
+	if err != nil {
+		// 发送失败时作废验证码，允许立即重试
+		resetCodes.Revoke(ctx, rdb, user.ID)
+		return err
+	}
+	return nil
+}
+
+// Confirm 校验验证码并设置新密码，成功后注销该用户的所有会话，返回用户ID
+func Confirm(ctx context.Context, db *gorm.DB, rdb *redis.Client, email, code, newPassword string) (uint, error) {
+	var user model.User
+	err := db.Where("email = ?", strings.TrimSpace(email)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrInvalidCode
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := resetCodes.Verify(ctx, rdb, user.ID, code); err != nil {
+		return 0, err
+	}
+
+	if user.Status != model.StatusActive {
+		return 0, ErrUserDisabled
+	}
+
+	hashed, err := password.HashPassword(newPassword)
+	if err != nil {
+		return 0, err
+	}
+	if err := db.Model(&user).Update("password", hashed).Error; err != nil {
+		return 0, err
+	}
+
+	if _, err := session.RevokeAll(db, user.ID, ""); err != nil {
+		return user.ID, fmt.Errorf("注销会话失败: %w", err)
+	}
+	return user.ID, nil
+}
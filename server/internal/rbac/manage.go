@@ -0,0 +1,194 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/model"
+)
+
+var (
+	ErrUnknownPermission = errors.New("权限不存在")
+	ErrSystemRole        = errors.New("内置角色不允许该操作")
+	ErrLastAdmin         = errors.New("不能移除最后一个全局管理员")
+)
+
+// RolePermissionCodes 角色的权限代码
+func RolePermissionCodes(db *gorm.DB, roleID uint) ([]string, error) {
+	var codes []string
+	err := db.Table("role_permission AS rp").
+		Joins("JOIN permission p ON p.id = rp.permission_id").
+		Where("rp.role_id = ?", roleID).
+		Order("p.sort_order, p.permission_code").
+		Pluck("p.permission_code", &codes).Error
+	return codes, err
+}
+
+// SetRolePermissions 覆盖角色的权限；admin 角色的权限不允许修改
+func SetRolePermissions(db *gorm.DB, role *model.Role, codes []string) error {
+	if role.Code == RoleAdmin {
+		return ErrSystemRole
+	}
+
+	var perms []model.Permission
+	if len(codes) > 0 {
+		if err := db.Where("permission_code IN ?", codes).Find(&perms).Error; err != nil {
+			return err
+		}
+	}
+	if len(perms) != len(uniq(codes)) {
+		found := make(map[string]bool, len(perms))
+		for _, p := range perms {
+			found[p.Code] = true
+		}
+		for _, code := range codes {
+			if !found[code] {
+				return fmt.Errorf("%w: %s", ErrUnknownPermission, code)
+			}
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(perms) == 0 {
+			return nil
+		}
+		records := make([]model.RolePermission, 0, len(perms))
+		for _, p := range perms {
+			records = append(records, model.RolePermission{RoleID: role.ID, PermissionID: p.ID})
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	InvalidateAll()
+	return nil
+}
+
+// DeleteRole 删除自定义角色（绑定和角色权限级联删除）
+func DeleteRole(db *gorm.DB, role *model.Role) error {
+	if role.IsSystem {
+		return ErrSystemRole
+	}
+	if err := db.Delete(role).Error; err != nil {
+		return err
+	}
+	InvalidateAll()
+	return nil
+}
+
+// RevokeBinding 删除角色绑定；不允许移除最后一个全局 admin 绑定，避免系统无人可管理
+func RevokeBinding(db *gorm.DB, bindingID uint) (*model.UserRole, error) {
+	var binding model.UserRole
+	if err := db.Preload("Role").First(&binding, bindingID).Error; err != nil {
+		return nil, err
+	}
+
+	if binding.Role != nil && binding.Role.Code == RoleAdmin && binding.ScopePath == "" {
+		var admins int64
+		err := db.Model(&model.UserRole{}).
+			Where("role_id = ? AND scope_path = ''", binding.RoleID).
+			Count(&admins).Error
+		if err != nil {
+			return nil, err
+		}
+		if admins <= 1 {
+			return nil, ErrLastAdmin
+		}
+	}
+
+	return Revoke(db, bindingID)
+}
+
+// SetGlobalRoles 覆盖用户的全局角色，目录范围的绑定保持不变；不允许因此失去最后一个全局管理员
+func SetGlobalRoles(db *gorm.DB, userID uint, roleCodes []string, grantedBy uint) error {
+	roleCodes = uniq(roleCodes)
+	var roles []model.Role
+	if err := db.Where("role_code IN ?", roleCodes).Find(&roles).Error; err != nil {
+		return err
+	}
+	if len(roles) != len(roleCodes) {
+		return ErrRoleNotFound
+	}
+
+	keepAdmin := false
+	roleIDs := make([]uint, 0, len(roles))
+	for _, r := range roles {
+		roleIDs = append(roleIDs, r.ID)
+		keepAdmin = keepAdmin || r.Code == RoleAdmin
+	}
+	if !keepAdmin {
+		last, err := IsLastAdmin(db, userID)
+		if err != nil {
+			return err
+		}
+		if last {
+			return ErrLastAdmin
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND scope_path = '' AND role_id NOT IN ?", userID, roleIDs).
+			Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			binding := model.UserRole{UserID: userID, RoleID: roleID, GrantedBy: &grantedBy}
+			if err := tx.Where("user_id = ? AND role_id = ? AND scope_path = ''", userID, roleID).
+				FirstOrCreate(&binding).Error; err != nil {
+				return err
+			}
+		}
+		// 同步旧的 user.role 字段（token 中的 role 和没有绑定时的回退角色）
+		legacy := model.RoleUser
+		if keepAdmin {
+			legacy = model.RoleAdmin
+		}
+		return tx.Model(&model.User{}).Where("id = ?", userID).Update("role", legacy).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	Invalidate(userID)
+	return nil
+}
+
+// IsLastAdmin 用户是否是唯一一个正常状态的全局管理员（禁用、删除或降级前检查）
+func IsLastAdmin(db *gorm.DB, userID uint) (bool, error) {
+	grants, err := load(db, userID)
+	if err != nil {
+		return false, err
+	}
+	if !grants.HasRole(RoleAdmin) {
+		return false, nil
+	}
+
+	var others int64
+	err = db.Table("user_role AS ur").
+		Joins("JOIN role r ON r.id = ur.role_id").
+		Joins(`JOIN "user" u ON u.id = ur.user_id`).
+		Where("r.role_code = ? AND ur.scope_path = '' AND ur.user_id <> ? AND u.status = ?",
+			RoleAdmin, userID, model.StatusActive).
+		Count(&others).Error
+	return others == 0, err
+}
+
+// uniq 去重
+func uniq(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	out := items[:0:0]
+	for _, s := range items {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
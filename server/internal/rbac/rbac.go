@@ -0,0 +1,406 @@
+// Package rbac 基于角色的访问控制：角色、权限、角色绑定（可限定目录范围）和权限判断
+package rbac
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/model"
+)
+
+// 权限代码
+const (
+	PermFilesRead      = "files:read"      // 浏览目录、下载文件
+	PermFilesWrite     = "files:write"     // 上传、覆盖文件
+	PermFilesDelete    = "files:delete"    // 删除文件和目录
+	PermDisksRead      = "disks:read"      // 查看可用磁盘
+	PermDeviceManage   = "device:manage"   // 设备管理
+	PermGroupManage    = "group:manage"    // 分组管理
+	PermUserManage     = "user:manage"     // 用户管理
+	PermSecurityManage = "security:manage" // 安全策略
+	PermRBACManage     = "rbac:manage"     // 角色权限管理
+	PermAuditRead      = "audit:read"      // 查看审计日志
+	PermSystemMonitor  = "system:monitor"  // 查看系统监控
+)
+
+// 内置角色代码
+const (
+	RoleAdmin = "admin" // 管理员
+	RoleUser  = "user"  // 普通用户
+	RoleGuest = "guest" // 访客（只读）
+)
+
+// grantsTTL 权限缓存时间，角色/绑定变更时会主动失效
+const grantsTTL = time.Minute
+
+// contextKey 中间件写入 gin.Context 的 key
+const contextKey = "Grants"
+
+var ErrRoleNotFound = errors.New("角色不存在")
+
+// Grants 用户的有效权限
+type Grants struct {
+	UserID uint
+	Roles  []string            // 全局角色代码
+	global map[string]bool     // 全局权限
+	scoped map[string][]string // 权限 -> 生效目录
+}
+
+// Has 是否拥有全局权限
+func (g *Grants) Has(perm string) bool {
+	return g != nil && g.global[perm]
+}
+
+// HasRole 是否拥有全局角色
+func (g *Grants) HasRole(role string) bool {
+	if g == nil {
+		return false
+	}
+	for _, r := range g.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAny 是否拥有该权限（全局或任一目录范围内）
+func (g *Grants) HasAny(perm string) bool {
+	return g.Has(perm) || (g != nil && len(g.scoped[perm]) > 0)
+}
+
+// Can 是否可以对路径执行操作：全局权限，或路径位于某个授权目录内
+func (g *Grants) Can(perm, path string) bool {
+	if g.Has(perm) {
+		return true
+	}
+	if g == nil {
+		return false
+	}
+	for _, dir := range g.scoped[perm] {
+		if model.IsPathWithin(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// Restrict 按 API Key 的范围收窄权限：只保留 perms 中的权限，folder 非空时只在该目录内生效
+func (g *Grants) Restrict(perms []string, folder string) *Grants {
+	restricted := &Grants{
+		UserID: g.UserID,
+		Roles:  g.Roles,
+		global: make(map[string]bool),
+		scoped: make(map[string][]string),
+	}
+	for _, perm := range perms {
+		if folder == "" {
+			if g.Has(perm) {
+				restricted.global[perm] = true
+			} else if dirs := g.Scopes(perm); len(dirs) > 0 {
+				restricted.scoped[perm] = dirs
+			}
+			continue
+		}
+
+		if g.Can(perm, folder) {
+			restricted.scoped[perm] = []string{folder}
+			continue
+		}
+		for _, dir := range g.Scopes(perm) {
+			if model.IsPathWithin(dir, folder) {
+				restricted.scoped[perm] = append(restricted.scoped[perm], dir)
+			}
+		}
+	}
+	return restricted
+}
+
+// Scopes 权限的目录范围（只有目录范围授权时才有意义）
+func (g *Grants) Scopes(perm string) []string {
+	if g == nil {
+		return nil
+	}
+	return g.scoped[perm]
+}
+
+// Permissions 全局权限列表
+func (g *Grants) Permissions() []string {
+	perms := make([]string, 0, len(g.global))
+	for p := range g.global {
+		perms = append(perms, p)
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// ScopedPermissions 目录范围权限
+func (g *Grants) ScopedPermissions() map[string][]string {
+	return g.scoped
+}
+
+// ========== 缓存 ==========
+
+type cacheEntry struct {
+	grants  *Grants
+	expires time.Time
+}
+
+var (
+	cacheMu sync.RWMutex
+	cache   = make(map[uint]cacheEntry)
+)
+
+// Invalidate 清除用户的权限缓存
+func Invalidate(userID uint) {
+	cacheMu.Lock()
+	delete(cache, userID)
+	cacheMu.Unlock()
+}
+
+// InvalidateAll 清除全部权限缓存（角色权限变更时）
+func InvalidateAll() {
+	cacheMu.Lock()
+	cache = make(map[uint]cacheEntry)
+	cacheMu.Unlock()
+}
+
+// Load 加载用户的有效权限（带缓存）
+func Load(db *gorm.DB, userID uint) (*Grants, error) {
+	now := time.Now()
+	cacheMu.RLock()
+	entry, ok := cache[userID]
+	cacheMu.RUnlock()
+	if ok && now.Before(entry.expires) {
+		return entry.grants, nil
+	}
+
+	grants, err := load(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	cache[userID] = cacheEntry{grants: grants, expires: now.Add(grantsTTL)}
+	cacheMu.Unlock()
+	return grants, nil
+}
+
+// bindingRow 角色绑定查询结果
+type bindingRow struct {
+	RoleID    uint
+	RoleCode  string
+	ScopePath string
+}
+
+// load 从数据库加载权限；账号不存在或不是正常状态（禁用、待审批、注销冷静期等）时没有任何权限
+// 修改账号状态时会 Invalidate，已签发的 token 和 API Key 随之失去权限
+func load(db *gorm.DB, userID uint) (*Grants, error) {
+	grants := &Grants{
+		UserID: userID,
+		global: make(map[string]bool),
+		scoped: make(map[string][]string),
+	}
+
+	var status []int16
+	if err := db.Model(&model.User{}).Where("id = ?", userID).Pluck("status", &status).Error; err != nil {
+		return nil, err
+	}
+	if len(status) == 0 || status[0] != model.StatusActive {
+		return grants, nil
+	}
+
+	bindings, err := userBindings(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(bindings) == 0 {
+		return grants, nil
+	}
+
+	roleIDs := make([]uint, 0, len(bindings))
+	for _, b := range bindings {
+		roleIDs = append(roleIDs, b.RoleID)
+	}
+
+	var rows []struct {
+		RoleID uint
+		Code   string
+	}
+	err = db.Table("role_permission AS rp").
+		Select("rp.role_id, p.permission_code AS code").
+		Joins("JOIN permission p ON p.id = rp.permission_id").
+		Where("rp.role_id IN ? AND p.status = ?", roleIDs, model.PermissionStatusActive).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	rolePerms := make(map[uint][]string)
+	for _, r := range rows {
+		rolePerms[r.RoleID] = append(rolePerms[r.RoleID], r.Code)
+	}
+
+	seenRole := make(map[string]bool)
+	for _, b := range bindings {
+		if b.ScopePath == "" {
+			if !seenRole[b.RoleCode] {
+				seenRole[b.RoleCode] = true
+				grants.Roles = append(grants.Roles, b.RoleCode)
+			}
+			for _, p := range rolePerms[b.RoleID] {
+				grants.global[p] = true
+			}
+			continue
+		}
+		for _, p := range rolePerms[b.RoleID] {
+			grants.scoped[p] = append(grants.scoped[p], b.ScopePath)
+		}
+	}
+	sort.Strings(grants.Roles)
+	return grants, nil
+}
+
+// userBindings 用户的角色绑定；没有任何绑定时回退到 user.role 字段（迁移前创建的账号）
+func userBindings(db *gorm.DB, userID uint) ([]bindingRow, error) {
+	var bindings []bindingRow
+	err := db.Table("user_role AS ur").
+		Select("ur.role_id, r.role_code, ur.scope_path").
+		Joins("JOIN role r ON r.id = ur.role_id").
+		Where("ur.user_id = ? AND r.status = ?", userID, model.RoleStatusActive).
+		Scan(&bindings).Error
+	if err != nil || len(bindings) > 0 {
+		return bindings, err
+	}
+
+	var hasAny int64
+	if err := db.Model(&model.UserRole{}).Where("user_id = ?", userID).Count(&hasAny).Error; err != nil {
+		return nil, err
+	}
+	if hasAny > 0 {
+		// 有绑定但角色都已禁用
+		return nil, nil
+	}
+
+	legacy, err := legacyRole(db, userID)
+	if err != nil || legacy == nil {
+		return nil, err
+	}
+	return []bindingRow{{RoleID: legacy.ID, RoleCode: legacy.Code}}, nil
+}
+
+// legacyRole user.role 字段对应的角色，不存在时返回 (nil, nil)
+func legacyRole(db *gorm.DB, userID uint) (*model.Role, error) {
+	var user model.User
+	if err := db.Select("id", "role").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if user.Role == "" {
+		return nil, nil
+	}
+
+	var role model.Role
+	err := db.Where("role_code = ? AND status = ?", user.Role, model.RoleStatusActive).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// ========== 角色绑定 ==========
+
+// Grant 给用户绑定角色，scopePath 为空表示全局
+// 用户此前没有任何绑定时，先把 user.role 回退角色落库，避免新增一个目录授权后失去原有权限
+func Grant(db *gorm.DB, userID uint, roleCode, scopePath string, grantedBy uint) (*model.UserRole, error) {
+	var role model.Role
+	if err := db.Where("role_code = ?", roleCode).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	binding := &model.UserRole{UserID: userID, RoleID: role.ID, ScopePath: scopePath, GrantedBy: &grantedBy}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.UserRole{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			legacy, err := legacyRole(tx, userID)
+			if err != nil {
+				return err
+			}
+			if legacy != nil && !(legacy.ID == role.ID && scopePath == "") {
+				if err := tx.Create(&model.UserRole{UserID: userID, RoleID: legacy.ID}).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		err := tx.Where("user_id = ? AND role_id = ? AND scope_path = ?", userID, role.ID, scopePath).
+			FirstOrCreate(binding).Error
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	Invalidate(userID)
+	binding.Role = &role
+	return binding, nil
+}
+
+// Revoke 删除角色绑定，返回被删除的绑定
+func Revoke(db *gorm.DB, bindingID uint) (*model.UserRole, error) {
+	var binding model.UserRole
+	if err := db.First(&binding, bindingID).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Delete(&binding).Error; err != nil {
+		return nil, err
+	}
+	Invalidate(binding.UserID)
+	return &binding, nil
+}
+
+// GlobalRoles 用户的全局角色代码（签发 token 用）
+func GlobalRoles(db *gorm.DB, userID uint) ([]string, error) {
+	grants, err := Load(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	return grants.Roles, nil
+}
+
+// ========== gin 辅助 ==========
+
+// SetContext 中间件把权限写入上下文
+func SetContext(c *gin.Context, grants *Grants) {
+	c.Set(contextKey, grants)
+}
+
+// FromContext 获取当前请求用户的权限：中间件已加载时直接使用，否则按需加载
+func FromContext(c *gin.Context, db *gorm.DB, userID uint) (*Grants, error) {
+	if v, ok := c.Get(contextKey); ok {
+		if grants, ok := v.(*Grants); ok && grants.UserID == userID {
+			return grants, nil
+		}
+	}
+	grants, err := Load(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	SetContext(c, grants)
+	return grants, nil
+}
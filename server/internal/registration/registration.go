@@ -0,0 +1,202 @@
+// Package registration 注册策略：关闭注册、邀请码、邮箱验证、管理员审批
+package registration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/onetimecode"
+	"github.com/sunyuanling/server/pkg/mailer"
+)
+
+// 注册策略
+const (
+	ModeClosed       = "closed"        // 关闭注册
+	ModeInvite       = "invite"        // 凭邀请码注册
+	ModeEmailVerify  = "email_verify"  // 验证邮箱后激活
+	ModeAdminApprove = "admin_approve" // 管理员审批后激活
+	ModeOpen         = "open"          // 直接注册
+)
+
+// verifyCodes 注册邮箱验证码
+var verifyCodes = onetimecode.Store{
+	Prefix:         "register_verify",
+	Digits:         6,
+	MaxTries:       5,
+	ResendInterval: time.Minute,
+}
+
+var (
+	ErrInviteInvalid   = errors.New("邀请码无效或已过期")
+	ErrTooFrequent     = onetimecode.ErrTooFrequent
+	ErrInvalidCode     = onetimecode.ErrInvalidCode
+	ErrTooManyAttempts = onetimecode.ErrTooManyAttempts
+	ErrNotPending      = errors.New("该用户不在待审批状态")
+)
+
+// InitialStatus 注册策略对应的新用户状态
+func InitialStatus(mode string) int16 {
+	switch mode {
+	case ModeEmailVerify:
+		return model.StatusPendingVerify
+	case ModeAdminApprove:
+		return model.StatusPendingApproval
+	}
+	return model.StatusActive
+}
+
+// ========== 邀请码 ==========
+
+// CreateInvite 生成邀请码，ttl 为 0 表示不过期
+func CreateInvite(db *gorm.DB, createdBy uint, maxUses int, ttl time.Duration, note string) (*model.InviteCode, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	invite := &model.InviteCode{
+		Code:      base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf),
+		CreatedBy: &createdBy,
+		MaxUses:   maxUses,
+		Note:      note,
+	}
+	if ttl > 0 {
+		expires := time.Now().Add(ttl)
+		invite.ExpiresAt = &expires
+	}
+	if err := db.Create(invite).Error; err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// ClaimInvite 使用一次邀请码（条件更新，并发注册不会超过可用次数），需在注册事务内调用
+func ClaimInvite(tx *gorm.DB, code string) (*model.InviteCode, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, ErrInviteInvalid
+	}
+
+	result := tx.Model(&model.InviteCode{}).
+		Where("code = ? AND revoked_at IS NULL AND used_count < max_uses AND (expires_at IS NULL OR expires_at > ?)", code, time.Now()).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInviteInvalid
+	}
+
+	var invite model.InviteCode
+	if err := tx.Where("code = ?", code).First(&invite).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// RevokeInvite 作废邀请码
+func RevokeInvite(db *gorm.DB, id uint) error {
+	result := db.Model(&model.InviteCode{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ========== 邮箱验证 ==========
+
+// SendVerification 向待验证用户发送邮箱验证码
+func SendVerification(ctx context.Context, rdb *redis.Client, m mailer.Mailer, user *model.User, ttl time.Duration) error {
+	code, err := verifyCodes.Issue(ctx, rdb, user.ID, ttl)
+	if err != nil {
+		return err
+	}
+
+	err = m.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "注册邮箱验证码",
+		Body: fmt.Sprintf("%s，您好：\n\n您的注册邮箱验证码为：%s\n验证码 %d 分钟内有效。\n\n如果不是您本人操作，请忽略此邮件。\n",
+			user.Username, code, int(ttl.Minutes())),
+	})
+	if err != nil {
+		// 发送失败时作废验证码，允许立即重试
+		verifyCodes.Revoke(ctx, rdb, user.ID)
+		return err
+	}
+	return nil
+}
+
+// VerifyEmail 校验邮箱验证码并激活用户
+func VerifyEmail(ctx context.Context, db *gorm.DB, rdb *redis.Client, email, code string) (*model.User, error) {
+	var user model.User
+	err := db.Where("email = ? AND status = ?", strings.TrimSpace(email), model.StatusPendingVerify).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidCode
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyCodes.Verify(ctx, rdb, user.ID, code); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("status", model.StatusActive).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.UserRegistration{}).Where("user_id = ?", user.ID).
+			Update("email_verified_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	user.Status = model.StatusActive
+	return &user, nil
+}
+
+// ========== 管理员审批 ==========
+
+// Approve 审批通过待审批用户
+func Approve(db *gorm.DB, userID, reviewerID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND status = ?", userID, model.StatusPendingApproval).
+			Update("status", model.StatusActive)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotPending
+		}
+		return tx.Model(&model.UserRegistration{}).Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"reviewed_by": reviewerID, "reviewed_at": time.Now()}).Error
+	})
+}
+
+// Reject 拒绝待审批用户：删除该账号，用户名和邮箱可以重新注册
+func Reject(db *gorm.DB, userID uint) error {
+	result := db.Where("id = ? AND status = ?", userID, model.StatusPendingApproval).Delete(&model.User{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotPending
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+// Package session 管理登录会话：签发访问token和刷新令牌、轮换刷新令牌、注销会话
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	token "github.com/sunyuanling/server/pkg/tokn"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("刷新令牌无效")
+	ErrSessionExpired      = errors.New("会话已过期或已注销")
+	ErrRefreshTokenReused  = errors.New("刷新令牌已被使用，会话已注销")
+	ErrUserDisabled        = errors.New("账号已被禁用")
+)
+
+// Subject 会话所属用户
+type Subject struct {
+	UserID   uint
+	Username string
+	Email    string
+	Roles    []string // 全局角色代码（rbac）
+}
+
+// Client 发起登录/刷新的客户端信息
+type Client struct {
+	DeviceID  string
+	IP        string
+	UserAgent string
+}
+
+// Tokens 返回给客户端的令牌
+type Tokens struct {
+	AccessToken      string `json:"token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`         // 访问token有效期（秒）
+	RefreshExpiresIn int64  `json:"refresh_expires_in"` // 刷新令牌有效期（秒）
+	SessionID        string `json:"session_id"`
+}
+
+// Issue 创建会话并签发令牌
+func Issue(db *gorm.DB, subject Subject, client Client) (*Tokens, error) {
+	tm := token.GetGlobalTokenManager()
+	secret, hash, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s := &model.UserSession{
+		ID:          uuid.New().String(),
+		UserID:      subject.UserID,
+		DeviceID:    client.DeviceID,
+		IPAddress:   client.IP,
+		UserAgent:   truncate(client.UserAgent, 500),
+		RefreshHash: hash,
+		CreatedAt:   now,
+		LastUsedAt:  now,
+		ExpiresAt:   now.Add(tm.RefreshTTL()),
+	}
+	if err := db.Create(s).Error; err != nil {
+		return nil, err
+	}
+
+	return issueTokens(subject, s, secret)
+}
+
+// Refresh 校验刷新令牌并轮换，返回新的令牌
+// 已轮换掉的旧刷新令牌再次出现视为泄露，直接注销整个会话
+func Refresh(db *gorm.DB, refreshToken string, client Client) (*Tokens, error) {
+	sessionID, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var s model.UserSession
+	if err := db.Where("id = ?", sessionID).First(&s).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if !s.IsActive() {
+		return nil, ErrSessionExpired
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(s.RefreshHash)) != 1 {
+		logger.Warn("检测到刷新令牌重放，注销会话",
+			zap.String("session_id", s.ID),
+			zap.Uint("user_id", s.UserID),
+			zap.String("ip", client.IP),
+		)
+		if err := revoke(db, &s); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	var user model.User
+	if err := db.First(&user, s.UserID).Error; err != nil {
+		return nil, err
+	}
+	if user.Status != model.StatusActive {
+		return nil, ErrUserDisabled
+	}
+
+	newSecret, hash, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	// 以旧哈希为条件更新，并发刷新时只有一个请求成功
+	result := db.Model(&model.UserSession{}).
+		Where("id = ? AND refresh_hash = ?", s.ID, s.RefreshHash).
+		Updates(map[string]interface{}{
+			"refresh_hash": hash,
+			"last_used_at": time.Now(),
+			"ip_address":   client.IP,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 角色在刷新时重新读取，角色变更在下一次刷新后反映到 token 中
+	roles, err := rbac.GlobalRoles(db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return issueTokens(Subject{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Roles:    roles,
+	}, &s, newSecret)
+}
+
+// List 用户的有效会话（按最后使用时间倒序）
+func List(db *gorm.DB, userID uint) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+	err := db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at desc").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 访问时间记录在 Redis 中，取较新的一个
+	tm := token.GetGlobalTokenManager()
+	for i := range sessions {
+		if seen := tm.SessionLastSeen(sessions[i].ID); seen.After(sessions[i].LastUsedAt) {
+			sessions[i].LastUsedAt = seen
+		}
+	}
+	return sessions, nil
+}
+
+// Revoke 注销用户的指定会话，会话不存在时返回 gorm.ErrRecordNotFound
+func Revoke(db *gorm.DB, userID uint, sessionID string) error {
+	var s model.UserSession
+	if err := db.Where("id = ? AND user_id = ?", sessionID, userID).First(&s).Error; err != nil {
+		return err
+	}
+	return revoke(db, &s)
+}
+
+// RevokeAll 注销用户的所有会话，exceptID 非空时保留该会话，返回注销数量
+func RevokeAll(db *gorm.DB, userID uint, exceptID string) (int, error) {
+	query := db.Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptID != "" {
+		query = query.Where("id <> ?", exceptID)
+	}
+
+	var sessions []model.UserSession
+	if err := query.Find(&sessions).Error; err != nil {
+		return 0, err
+	}
+	for i := range sessions {
+		if err := revoke(db, &sessions[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(sessions), nil
+}
+
+// RevokeDevice 注销设备：吊销该设备签发的全部 token，并注销绑定该设备的会话（刷新令牌随之失效），返回注销的会话数量
+func RevokeDevice(db *gorm.DB, userID uint, deviceID string) (int, error) {
+	if err := token.GetGlobalTokenManager().RevokeDevice(userID, deviceID); err != nil {
+		return 0, err
+	}
+
+	var sessions []model.UserSession
+	err := db.Where("user_id = ? AND device_id = ? AND revoked_at IS NULL", userID, deviceID).
+		Find(&sessions).Error
+	if err != nil {
+		return 0, err
+	}
+	for i := range sessions {
+		if err := revoke(db, &sessions[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(sessions), nil
+}
+
+// revoke 标记会话注销，并让其访问token立即失效
+func revoke(db *gorm.DB, s *model.UserSession) error {
+	if s.RevokedAt == nil {
+		now := time.Now()
+		if err := db.Model(s).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		s.RevokedAt = &now
+	}
+	return token.GetGlobalTokenManager().RevokeSession(s.ID)
+}
+
+// issueTokens 签发绑定会话的访问token，会话有设备ID时 token 同时绑定该设备（远程退出、注销设备后立即失效）
+func issueTokens(subject Subject, s *model.UserSession, secret string) (*Tokens, error) {
+	tm := token.GetGlobalTokenManager()
+	access, err := tm.GenerateTokenFromPayload(&token.TokenPayload{
+		UserID:    int64(subject.UserID),
+		Username:  subject.Username,
+		Email:     subject.Email,
+		Roles:     subject.Roles,
+		SessionID: s.ID,
+		DeviceID:  s.DeviceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		AccessToken:      access,
+		RefreshToken:     s.ID + "." + secret,
+		ExpiresIn:        int64(tm.AccessTTL().Seconds()),
+		RefreshExpiresIn: int64(time.Until(s.ExpiresAt).Seconds()),
+		SessionID:        s.ID,
+	}, nil
+}
+
+// newSecret 生成刷新令牌密钥及其哈希（数据库只保存哈希）
+func newSecret() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+	return secret, hashSecret(secret), nil
+}
+
+// hashSecret SHA-256 十六进制
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate 截断到指定长度
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
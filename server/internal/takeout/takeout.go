@@ -0,0 +1,396 @@
+// Package takeout 账号数据导出：把用户的文件、资料、上传下载记录、设备和共享打包成 zip
+//
+// 任务在后台协程中执行，进度通过 WebSocket 推送给本人；压缩包过期后自动删除。
+package takeout
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket"
+	"github.com/sunyuanling/server/websocket/protocol"
+)
+
+var (
+	ErrJobRunning = errors.New("已有进行中的导出任务，请等待完成")
+	ErrNotStarted = errors.New("数据导出服务未启用")
+)
+
+// metadataSteps 打包资料类 JSON 的步数，和文件数一起计算进度
+const metadataSteps = 7
+
+// service 导出任务管理
+type service struct {
+	db      *gorm.DB
+	cfg     *config.Config
+	dir     string
+	expires time.Duration
+
+	mu      sync.Mutex
+	running map[uint]bool // 进行中的用户，防止同一用户并发导出
+	wg      sync.WaitGroup
+	done    chan struct{}
+}
+
+var std *service
+
+// Init 启动导出服务：把上次退出时未完成的任务标记为失败，并每小时清理过期压缩包
+func Init(db *gorm.DB, cfg *config.Config) {
+	s := &service{
+		db:      db,
+		cfg:     cfg,
+		dir:     cfg.Account.TakeoutDir,
+		expires: time.Duration(cfg.Account.TakeoutExpireHours) * time.Hour,
+		running: make(map[uint]bool),
+		done:    make(chan struct{}),
+	}
+
+	err := db.Model(&model.TakeoutJob{}).
+		Where("status IN ?", []string{model.TakeoutStatusPending, model.TakeoutStatusRunning}).
+		Updates(map[string]interface{}{"status": model.TakeoutStatusFailed, "error_message": "服务重启，任务中断"}).Error
+	if err != nil {
+		logger.Error("重置未完成的导出任务失败", zap.Error(err))
+	}
+
+	s.wg.Add(1)
+	go s.janitor()
+	std = s
+}
+
+// Close 停止清理协程并等待进行中的任务结束
+func Close() {
+	if std == nil {
+		return
+	}
+	close(std.done)
+	std.wg.Wait()
+}
+
+// Start 创建导出任务并在后台执行
+func Start(userID uint) (*model.TakeoutJob, error) {
+	if std == nil {
+		return nil, ErrNotStarted
+	}
+	return std.start(userID)
+}
+
+// List 用户最近的导出任务，按时间倒序
+func List(db *gorm.DB, userID uint) ([]model.TakeoutJob, error) {
+	var jobs []model.TakeoutJob
+	err := db.Where("user_id = ?", userID).Order("created_at DESC").Limit(20).Find(&jobs).Error
+	return jobs, err
+}
+
+// Get 用户的某个导出任务，不存在或不属于该用户时返回 gorm.ErrRecordNotFound
+func Get(db *gorm.DB, userID, jobID uint) (*model.TakeoutJob, error) {
+	var job model.TakeoutJob
+	if err := db.Where("id = ? AND user_id = ?", jobID, userID).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *service) start(userID uint) (*model.TakeoutJob, error) {
+	s.mu.Lock()
+	if s.running[userID] {
+		s.mu.Unlock()
+		return nil, ErrJobRunning
+	}
+	s.running[userID] = true
+	s.mu.Unlock()
+
+	job := &model.TakeoutJob{UserID: userID, Status: model.TakeoutStatusPending}
+	if err := s.db.Create(job).Error; err != nil {
+		s.release(userID)
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.release(userID)
+		s.run(job)
+	}()
+	return job, nil
+}
+
+func (s *service) release(userID uint) {
+	s.mu.Lock()
+	delete(s.running, userID)
+	s.mu.Unlock()
+}
+
+// run 执行导出任务
+func (s *service) run(job *model.TakeoutJob) {
+	now := time.Now()
+	job.Status = model.TakeoutStatusRunning
+	job.StartedAt = &now
+	s.save(job, "status", "started_at")
+
+	count, err := s.build(job)
+	if err != nil {
+		logger.Error("导出账号数据失败", zap.Uint("job_id", job.ID), zap.Uint("user_id", job.UserID), zap.Error(err))
+		job.Status = model.TakeoutStatusFailed
+		job.ErrorMessage = err.Error()
+		s.save(job, "status", "error_message")
+		return
+	}
+
+	info, err := os.Stat(job.ArchivePath)
+	if err == nil {
+		job.ArchiveSize = info.Size()
+	}
+	completed := time.Now()
+	expires := completed.Add(s.expires)
+	job.Status = model.TakeoutStatusCompleted
+	job.Progress = 100
+	job.FileCount = count
+	job.CompletedAt = &completed
+	job.ExpiresAt = &expires
+	s.save(job, "status", "progress", "file_count", "archive_path", "archive_size", "completed_at", "expires_at")
+
+	logger.Info("账号数据导出完成",
+		zap.Uint("job_id", job.ID),
+		zap.Uint("user_id", job.UserID),
+		zap.Int("files", count),
+		zap.Int64("size", job.ArchiveSize),
+	)
+}
+
+// build 写出压缩包，返回打包的文件数
+func (s *service) build(job *model.TakeoutJob) (int, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return 0, fmt.Errorf("创建导出目录失败: %w", err)
+	}
+	final := filepath.Join(s.dir, fmt.Sprintf("takeout-%d-%d.zip", job.UserID, job.ID))
+	tmp := final + ".part"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("创建压缩包失败: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	zw := zip.NewWriter(f)
+	count, err := s.write(zw, job)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, final); err != nil {
+		return 0, err
+	}
+	job.ArchivePath = final
+	return count, nil
+}
+
+// write 按顺序写入资料和文件
+func (s *service) write(zw *zip.Writer, job *model.TakeoutJob) (int, error) {
+	userID := job.UserID
+	var uploads []model.UploadHistory
+	if err := s.db.Where("user_id = ?", userID).Order("id").Find(&uploads).Error; err != nil {
+		return 0, err
+	}
+	var files []model.UploadHistory
+	for _, u := range uploads {
+		if u.UploadStatus == model.UploadStatusCompleted && u.StoragePath != "" {
+			files = append(files, u)
+		}
+	}
+	total := metadataSteps + len(files)
+	step := 0
+	advance := func() {
+		step++
+		s.progress(job, step*99/total)
+	}
+
+	var user model.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return 0, err
+	}
+	sections := []struct {
+		name  string
+		query func() (interface{}, error)
+	}{
+		{"account.json", func() (interface{}, error) { return user, nil }},
+		{"upload_history.json", func() (interface{}, error) { return uploads, nil }},
+		{"download_history.json", find[model.DownloadHistory](s.db.Where("user_id = ?", userID).Order("id"))},
+		{"devices.json", find[model.Device](s.db.Where("user_id = ?", userID).Order("id"))},
+		{"shares.json", find[model.FolderShare](s.db.Where("owner_id = ?", userID).Order("id"))},
+		{"groups.json", find[model.WsGroupMember](s.db.Where("user_id = ?", userID).Order("id"))},
+		{"files.json", find[model.File](s.db.Where("user_id = ?", userID).Order("id"))},
+	}
+	for _, sec := range sections {
+		data, err := sec.query()
+		if err != nil {
+			return 0, err
+		}
+		if err := writeJSON(zw, sec.name, data); err != nil {
+			return 0, err
+		}
+		advance()
+	}
+
+	// 只打包允许存储路径内、仍然存在的文件；缺失的文件记录在 missing_files.json
+	count := 0
+	var missing []string
+	for _, u := range files {
+		name := path.Join("files", fmt.Sprintf("%d_%s", u.ID, filepath.Base(u.StoragePath)))
+		ok, err := s.addFile(zw, name, u.StoragePath)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			count++
+		} else {
+			missing = append(missing, u.StoragePath)
+		}
+		advance()
+	}
+	if len(missing) > 0 {
+		if err := writeJSON(zw, "missing_files.json", missing); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// addFile 把磁盘文件写入压缩包，文件不存在或不在允许路径内时返回 false
+func (s *service) addFile(zw *zip.Writer, name, src string) (bool, error) {
+	allowed := false
+	for _, dir := range s.cfg.GetAllowedPaths() {
+		allowed = allowed || model.IsPathWithin(src, dir)
+	}
+	if !allowed {
+		return false, nil
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return false, err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return false, err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return false, err
+	}
+	_, err = io.Copy(w, f)
+	return err == nil, err
+}
+
+// progress 进度有变化时更新数据库并推送
+func (s *service) progress(job *model.TakeoutJob, percent int) {
+	if percent <= job.Progress {
+		return
+	}
+	job.Progress = percent
+	s.save(job, "progress")
+}
+
+// save 更新任务字段并推送进度
+func (s *service) save(job *model.TakeoutJob, columns ...string) {
+	if err := s.db.Model(job).Select(columns).Updates(job).Error; err != nil {
+		logger.Error("更新导出任务失败", zap.Uint("job_id", job.ID), zap.Error(err))
+	}
+	_ = websocket.SendToUser(job.UserID, websocket.MessageTypeSystem, protocol.TakeoutProgress{
+		Event:    protocol.EventTakeout,
+		JobID:    job.ID,
+		Status:   job.Status,
+		Progress: job.Progress,
+		Error:    job.ErrorMessage,
+		Time:     time.Now().Unix(),
+	})
+}
+
+// janitor 每小时删除过期的压缩包
+func (s *service) janitor() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		s.expire()
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *service) expire() {
+	var jobs []model.TakeoutJob
+	err := s.db.Where("status = ? AND expires_at < ?", model.TakeoutStatusCompleted, time.Now()).Find(&jobs).Error
+	if err != nil {
+		logger.Error("查询过期导出任务失败", zap.Error(err))
+		return
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		if err := os.Remove(job.ArchivePath); err != nil && !os.IsNotExist(err) {
+			logger.Warn("删除过期导出压缩包失败", zap.Uint("job_id", job.ID), zap.Error(err))
+			continue
+		}
+		err := s.db.Model(job).Updates(map[string]interface{}{
+			"status":       model.TakeoutStatusExpired,
+			"archive_path": "",
+		}).Error
+		if err != nil {
+			logger.Error("更新导出任务失败", zap.Uint("job_id", job.ID), zap.Error(err))
+		}
+	}
+	if len(jobs) > 0 {
+		logger.Info("已清理过期导出压缩包", zap.Int("count", len(jobs)))
+	}
+}
+
+// find 延迟执行查询
+func find[T any](query *gorm.DB) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		var rows []T
+		err := query.Find(&rows).Error
+		return rows, err
+	}
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,389 @@
+// Package twofactor 两步验证（TOTP）：绑定、校验、恢复码、登录挑战和受信任设备
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/encryption"
+	"github.com/sunyuanling/server/internal/model"
+	token "github.com/sunyuanling/server/pkg/tokn"
+	"github.com/sunyuanling/server/pkg/totp"
+)
+
+const (
+	RecoveryCodeCount = 10              // 每次生成的恢复码数量
+	ChallengeTTL      = 5 * time.Minute // 登录第二步有效期
+	maxChallengeTries = 5               // 每个登录挑战允许的验证码错误次数
+	challengeKey      = "login_2fa:%s"  // 登录挑战
+	challengeFailKey  = "login_2fa_fail:%s"
+)
+
+var (
+	ErrNotEnrolled      = errors.New("未绑定两步验证")
+	ErrAlreadyEnabled   = errors.New("两步验证已启用")
+	ErrInvalidCode      = errors.New("验证码错误")
+	ErrChallengeInvalid = errors.New("登录验证已过期，请重新登录")
+	ErrTooManyAttempts  = errors.New("验证码错误次数过多，请重新登录")
+)
+
+// secretEncryptor TOTP 密钥与 token 使用同一密钥环加密
+func secretEncryptor() (*encryption.Encryptor, error) {
+	tm := token.GetGlobalTokenManager()
+	if tm == nil {
+		return nil, encryption.ErrKeyNotLoaded
+	}
+	return tm.Encryptor(), nil
+}
+
+// Enroll 生成新的 TOTP 密钥（未启用状态），返回明文密钥
+// 已启用时返回 ErrAlreadyEnabled，需先关闭再重新绑定
+func Enroll(db *gorm.DB, userID uint) (string, error) {
+	existing, err := model.FindTwoFactor(db, userID)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil && existing.Enabled {
+		return "", ErrAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+	enc, err := secretEncryptor()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := enc.Encrypt(secret)
+	if err != nil {
+		return "", err
+	}
+
+	tf := &model.UserTwoFactor{UserID: userID, Secret: sealed}
+	if existing != nil {
+		err = db.Model(existing).Updates(map[string]interface{}{
+			"secret":         sealed,
+			"last_used_step": 0,
+			"updated_at":     time.Now(),
+		}).Error
+	} else {
+		err = db.Create(tf).Error
+	}
+	if err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Enable 用首个验证码确认绑定并启用，返回新生成的恢复码
+func Enable(db *gorm.DB, userID uint, code string) ([]string, error) {
+	tf, err := model.FindTwoFactor(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		return nil, ErrNotEnrolled
+	}
+	if tf.Enabled {
+		return nil, ErrAlreadyEnabled
+	}
+	if err := checkTOTP(db, tf, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(tf).Updates(map[string]interface{}{
+			"enabled":    true,
+			"enabled_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable 关闭两步验证，删除密钥和恢复码，并取消所有受信任设备
+func Disable(db *gorm.DB, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserTwoFactor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		_, err := UntrustDevices(tx.Where("user_id = ? AND trusted_until IS NOT NULL", userID))
+		return err
+	})
+}
+
+// IsEnabled 用户是否已启用两步验证
+func IsEnabled(db *gorm.DB, userID uint) (bool, error) {
+	tf, err := model.FindTwoFactor(db, userID)
+	if err != nil || tf == nil {
+		return false, err
+	}
+	return tf.Enabled, nil
+}
+
+// Verify 校验已启用用户的 TOTP 验证码或恢复码（恢复码使用后作废）
+func Verify(db *gorm.DB, userID uint, code, recoveryCode string) error {
+	tf, err := model.FindTwoFactor(db, userID)
+	if err != nil {
+		return err
+	}
+	if tf == nil || !tf.Enabled {
+		return ErrNotEnrolled
+	}
+
+	if recoveryCode != "" {
+		return useRecoveryCode(db, userID, recoveryCode)
+	}
+	return checkTOTP(db, tf, code)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码（旧恢复码全部作废）
+func RegenerateRecoveryCodes(db *gorm.DB, userID uint) ([]string, error) {
+	var codes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+// checkTOTP 校验验证码，并记录时间步防止同一验证码重复使用
+func checkTOTP(db *gorm.DB, tf *model.UserTwoFactor, code string) error {
+	enc, err := secretEncryptor()
+	if err != nil {
+		return err
+	}
+	secret, err := enc.Decrypt(tf.Secret)
+	if err != nil {
+		return fmt.Errorf("解密两步验证密钥失败: %w", err)
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok || step <= tf.LastUsedStep {
+		return ErrInvalidCode
+	}
+
+	// 密钥轮换后顺便用当前密钥重新加密（失败不影响本次验证）
+	if encryption.KeyID(tf.Secret) != enc.ActiveKeyID() {
+		if sealed, err := enc.Encrypt(secret); err == nil {
+			db.Model(&model.UserTwoFactor{}).Where("user_id = ?", tf.UserID).Update("secret", sealed)
+		}
+	}
+
+	// 以旧步数为条件更新，并发提交同一验证码时只有一个成功
+	result := db.Model(&model.UserTwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", tf.UserID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidCode
+	}
+	tf.LastUsedStep = step
+	return nil
+}
+
+// useRecoveryCode 使用一个恢复码
+func useRecoveryCode(db *gorm.DB, userID uint, code string) error {
+	result := db.Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 删除旧恢复码并生成新的一组
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.UserRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, RecoveryCodeCount)
+	records := make([]model.UserRecoveryCode, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, model.UserRecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// newRecoveryCode 生成恢复码，格式 xxxxx-xxxxx（小写十六进制）
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 5)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	s := hex.EncodeToString(buf)
+	return s[:5] + "-" + s[5:], nil
+}
+
+// hashRecoveryCode 忽略大小写、空格和连字符后取 SHA-256
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// ========== 登录挑战 ==========
+
+// Challenge 密码验证通过后等待第二步的登录
+type Challenge struct {
+	UserID   uint   `json:"user_id"`
+	DeviceID string `json:"device_id,omitempty"`
+	IP       string `json:"ip"`
+	LoginKey string `json:"login_key,omitempty"` // 按账号限流的登录标识，第二步失败同样计入
+	Enroll   bool   `json:"enroll"`              // 角色强制要求但尚未绑定，需要先绑定
+}
+
+// CreateChallenge 创建登录挑战，返回票据
+func CreateChallenge(rdb *redis.Client, ch Challenge) (string, error) {
+	data, err := json.Marshal(ch)
+	if err != nil {
+		return "", err
+	}
+	ticket := uuid.New().String()
+	if err := rdb.Set(context.Background(), fmt.Sprintf(challengeKey, ticket), data, ChallengeTTL).Err(); err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+// GetChallenge 读取登录挑战（不消费）
+func GetChallenge(rdb *redis.Client, ticket string) (*Challenge, error) {
+	if ticket == "" {
+		return nil, ErrChallengeInvalid
+	}
+	data, err := rdb.Get(context.Background(), fmt.Sprintf(challengeKey, ticket)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrChallengeInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ch Challenge
+	if err := json.Unmarshal(data, &ch); err != nil {
+		return nil, ErrChallengeInvalid
+	}
+	return &ch, nil
+}
+
+// ConsumeChallenge 第二步成功后作废票据，返回 false 表示已被并发请求消费
+func ConsumeChallenge(rdb *redis.Client, ticket string) bool {
+	ctx := context.Background()
+	rdb.Del(ctx, fmt.Sprintf(challengeFailKey, ticket))
+	n, err := rdb.Del(ctx, fmt.Sprintf(challengeKey, ticket)).Result()
+	return err == nil && n > 0
+}
+
+// RecordChallengeFailure 记录一次验证码错误，超过次数后作废票据
+func RecordChallengeFailure(rdb *redis.Client, ticket string) error {
+	ctx := context.Background()
+	key := fmt.Sprintf(challengeFailKey, ticket)
+	count, err := rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	rdb.Expire(ctx, key, ChallengeTTL)
+	if count >= maxChallengeTries {
+		rdb.Del(ctx, fmt.Sprintf(challengeKey, ticket), key)
+		return ErrTooManyAttempts
+	}
+	return nil
+}
+
+// ========== 受信任设备 ==========
+
+// IsTrustedDevice 设备是否在受信任期内，且客户端出示了记住设备时下发的信任凭证
+// device_id 由客户端自报，不能单独作为跳过第二步的依据
+func IsTrustedDevice(db *gorm.DB, userID uint, deviceID, trustToken string) (bool, error) {
+	if deviceID == "" || trustToken == "" {
+		return false, nil
+	}
+	d, err := model.FindDeviceByIdentifier(db, userID, deviceID)
+	if err != nil || d == nil {
+		return false, err
+	}
+	if d.TrustHash == "" || subtle.ConstantTimeCompare([]byte(d.TrustHash), []byte(hashTrustToken(trustToken))) != 1 {
+		return false, nil
+	}
+	return d.IsTrusted(), nil
+}
+
+// TrustDevice 记住设备，仅对已登记的设备生效，返回信任凭证（只返回这一次，数据库只保存哈希），未记住时返回空字符串
+// 再次记住同一设备会生成新凭证，旧凭证失效
+func TrustDevice(db *gorm.DB, userID uint, deviceID string, days int) (string, error) {
+	if deviceID == "" {
+		return "", nil
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	trustToken := hex.EncodeToString(buf)
+
+	result := db.Model(&model.Device{}).
+		Where("user_id = ? AND device_id = ? AND status = ?", userID, deviceID, model.DeviceStatusActive).
+		Updates(map[string]interface{}{
+			"trusted_until":    time.Now().AddDate(0, 0, days),
+			"trust_token_hash": hashTrustToken(trustToken),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return "", result.Error
+	}
+	return trustToken, nil
+}
+
+// UntrustDevices 取消查询条件匹配的设备的信任，返回取消的数量
+func UntrustDevices(query *gorm.DB) (int64, error) {
+	result := query.Model(&model.Device{}).
+		Updates(map[string]interface{}{"trusted_until": nil, "trust_token_hash": ""})
+	return result.RowsAffected, result.Error
+}
+
+// hashTrustToken 信任凭证的 SHA-256
+func hashTrustToken(trustToken string) string {
+	sum := sha256.Sum256([]byte(trustToken))
+	return hex.EncodeToString(sum[:])
+}
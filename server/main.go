@@ -4,14 +4,27 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
 
 	"go.uber.org/zap"
 
 	"github.com/sunyuanling/server/config"
 	"github.com/sunyuanling/server/gateway"
+	"github.com/sunyuanling/server/internal/account"
+	"github.com/sunyuanling/server/internal/alert"
+	"github.com/sunyuanling/server/internal/audit"
+	"github.com/sunyuanling/server/internal/diskhealth"
+	"github.com/sunyuanling/server/internal/instrument"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/monitor"
+	"github.com/sunyuanling/server/internal/takeout"
 	"github.com/sunyuanling/server/pkg/database"
 	"github.com/sunyuanling/server/pkg/logger"
 	token "github.com/sunyuanling/server/pkg/tokn"
+	"github.com/sunyuanling/server/pkg/tracing"
 )
 
 func main() {
@@ -23,6 +36,9 @@ func main() {
 	}
 	fmt.Println("配置加载成功")
 
+	// 路径权限判断只在 Windows 存储模式下忽略大小写
+	model.SetPathCaseInsensitive(cfg.File.Mode == "windows" || runtime.GOOS == "windows")
+
 	// ========== 2. 初始化日志系统 ==========
 	if err := logger.Init(cfg); err != nil {
 		log.Fatalf("日志初始化失败: %v", err)
@@ -38,6 +54,11 @@ func main() {
 	if err := token.InitGlobalTokenManager(cfg); err != nil {
 		logger.Fatal("token初始化失败", zap.Error(err))
 	}
+	logger.Info("token初始化成功",
+		zap.String("key_id", token.GetGlobalTokenManager().Encryptor().ActiveKeyID()),
+		zap.String("format", token.GetGlobalTokenManager().Format()),
+		zap.Strings("accepted_formats", token.GetGlobalTokenManager().AcceptedFormats()),
+	)
 
 	// ========== 3. 连接PostgreSQL ==========
 	logger.Info("连接PostgreSQL数据库...")
@@ -63,13 +84,49 @@ func main() {
 	)
 
 	// 测试Redis
-	ctx := context.Background()
-	pong, err := rdb.Ping(ctx).Result()
+	pong, err := rdb.Ping(context.Background()).Result()
 	if err != nil {
 		logger.Fatal("Redis Ping失败", zap.Error(err))
 	}
 	logger.Debug("Redis Ping测试", zap.String("response", pong))
 
+	// Prometheus 指标（需在其他组件使用 Redis 之前挂上统计钩子）
+	stopMetrics := func() {}
+	if cfg.Metrics.Enabled {
+		instrument.Init(db, rdb, cfg)
+		if cfg.Metrics.Listen != "" {
+			stopMetrics = instrument.Serve(cfg.Metrics.Listen, cfg.Metrics.Token)
+		}
+	}
+
+	// 链路追踪：HTTP 请求、数据库、Redis 的 span 导出到 OpenTelemetry Collector
+	if cfg.Tracing.Enabled {
+		tracing.Init(cfg.Tracing)
+		if err := db.Use(tracing.GormPlugin()); err != nil {
+			logger.Fatal("注册数据库追踪失败", zap.Error(err))
+		}
+		rdb.AddHook(tracing.RedisHook())
+	}
+
+	// token吊销记录存放在Redis
+	token.GetGlobalTokenManager().SetRevocationStore(rdb)
+
+	// 审计日志异步写入，退出前写完队列
+	audit.Init(db, cfg.Audit)
+
+	// 数据导出任务和到期注销清理在后台执行
+	takeout.Init(db, cfg)
+	account.StartPurger(db, cfg)
+
+	// 系统监控定时采集
+	monitor.Init(db, rdb, cfg.Monitor)
+
+	// 磁盘健康（SMART）定时采集
+	diskhealth.Init(db, cfg.DiskHealth)
+
+	// 告警规则定时评估
+	alert.Init(db, cfg)
+
 	// ========== 5. 初始化网关（传递配置） ==========
 	logger.Info("初始化API网关...")
 	gw := gateway.NewGateway(db, rdb, cfg)
@@ -78,7 +135,10 @@ func main() {
 	}
 	gw.SetupRoutes()
 
-	// ========== 6. 启动服务器 ==========
+	// ========== 6. 启动服务器（SIGINT/SIGTERM 时优雅关闭） ==========
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	logger.Info("服务器启动",
 		zap.String("address", addr),
@@ -90,7 +150,26 @@ func main() {
 	fmt.Printf("📁 文件存储模式: %s\n", cfg.File.Mode)
 	fmt.Printf("💾 允许的存储路径: %v\n\n", cfg.GetAllowedPaths())
 
-	if err := gw.Run(addr); err != nil {
-		logger.Fatal("服务器启动失败", zap.Error(err))
+	runErr := gw.Run(ctx, addr)
+	if runErr != nil {
+		logger.Error("服务器运行失败", zap.Error(runErr))
+	}
+
+	// ========== 7. 关闭后台任务 ==========
+	// 已停止接收请求；各任务退出时可能还会写审计日志，所以审计最后关闭并写完队列
+	logger.Info("正在关闭后台任务...")
+	alert.Close()
+	diskhealth.Close()
+	monitor.Close()
+	takeout.Close()
+	audit.Close()
+	tracing.Close()
+	stopMetrics()
+	logger.Info("服务已退出")
+
+	if runErr != nil {
+		rdb.Close()
+		logger.Sync()
+		os.Exit(1)
 	}
 }
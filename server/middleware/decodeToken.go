@@ -2,12 +2,16 @@ package middleware
 
 import (
 	"fmt"
+	"github.com/sunyuanling/server/internal/apikey"
+	"github.com/sunyuanling/server/internal/rbac"
 	"github.com/sunyuanling/server/pkg/logger"
 	tokenFunc "github.com/sunyuanling/server/pkg/tokn"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // CORS 中间件跨域处理
@@ -16,7 +20,8 @@ func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Token")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Token, X-Request-ID, traceparent")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
 
 		if c.Request.Method == "OPTIONS" {
@@ -29,23 +34,28 @@ func CORS() gin.HandlerFunc {
 }
 
 // AuthToken 中间件token认证处理（全局，不拦截）
-// 支持从 Header 或 Query 参数获取 token
-func AuthToken() gin.HandlerFunc {
+// 支持从 Header 或 Query 参数获取 token，以及 Authorization: Bearer 方式的 token 或个人 API Key
+func AuthToken(db *gorm.DB) gin.HandlerFunc {
 	logger.Info("AuthToken中间件已加载")
 	return func(c *gin.Context) {
+		// 0. Authorization: Bearer 中的个人 API Key
+		bearer := bearerToken(c)
+		if apikey.LooksLikeKey(bearer) {
+			authAPIKey(c, db, bearer)
+			c.Next()
+			return
+		}
+
 		// 1. 优先从 Header 获取 token
 		token := c.GetHeader("Token")
+		if token == "" {
+			token = bearer
+		}
 
-		// 2. 如果 Header 没有，尝试从 Query 参数获取
+		// 2. 如果 Header 没有，尝试从 Query 参数获取（访问日志中该参数会被隐藏）
 		if token == "" {
 			token = c.Query("token")
-			if token != "" {
-				logger.Info("从 Query 参数获取到 token")
-			}
-		} else {
-			logger.Info("从 Header 获取到 token")
 		}
-		logger.Info("token: " + token)
 
 		// 3. 如果都没有 token，标记为未认证
 		if token == "" {
@@ -57,7 +67,7 @@ func AuthToken() gin.HandlerFunc {
 		// 4. 验证 token
 		payload, err := tokenFunc.GetGlobalTokenManager().ValidateToken(token)
 		if err != nil {
-			logger.Warn("Token验证失败: " + err.Error())
+			Log(c).Warn("Token验证失败", zap.String("ip", c.ClientIP()), zap.Error(err))
 			c.Set("Auth", false)
 			c.Next()
 			return
@@ -65,7 +75,7 @@ func AuthToken() gin.HandlerFunc {
 
 		// 5. 检查 payload
 		if payload == nil {
-			logger.Warn("Token验证成功，但 payload 为空")
+			Log(c).Warn("Token验证成功，但 payload 为空")
 			c.Set("Auth", false)
 			c.Next()
 			return
@@ -75,7 +85,10 @@ func AuthToken() gin.HandlerFunc {
 		c.Set("Auth", true)
 		c.Set("UserInfo", payload)
 
-		logger.Info("Token验证成功",
+		// 7. 记录会话最后使用时间（会话吊销已在 ValidateToken 中检查）
+		tokenFunc.GetGlobalTokenManager().TouchSession(payload.SessionID)
+
+		Log(c).Debug("Token验证成功",
 			zap.Uint("user_id", uint(payload.UserID)),
 			zap.String("username", payload.Username),
 		)
@@ -83,6 +96,69 @@ func AuthToken() gin.HandlerFunc {
 	}
 }
 
+// bearerToken 读取 Authorization: Bearer 后的凭证
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// apiKeyPaths API Key 只能访问文件相关接口，账号、会话、Key 管理等仍需登录 token
+var apiKeyPaths = []string{
+	"/api/files/",
+	"/api/share/with-me",
+	"/api/rbac/me",
+}
+
+// authAPIKey 使用 API Key 认证：用户信息写入上下文，权限按 Key 的范围收窄
+func authAPIKey(c *gin.Context, db *gorm.DB, raw string) {
+	c.Set("Auth", false)
+
+	allowed := false
+	for _, prefix := range apiKeyPaths {
+		if strings.HasPrefix(c.Request.URL.Path, prefix) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		Log(c).Warn("API Key不能访问该接口",
+			zap.String("path", c.Request.URL.Path),
+			zap.String("ip", c.ClientIP()),
+		)
+		return
+	}
+
+	key, user, err := apikey.Authenticate(db, raw, c.ClientIP())
+	if err != nil {
+		Log(c).Warn("API Key验证失败", zap.String("ip", c.ClientIP()), zap.Error(err))
+		return
+	}
+
+	grants, err := rbac.Load(db, user.ID)
+	if err != nil {
+		Log(c).Error("加载API Key用户权限失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		return
+	}
+	rbac.SetContext(c, grants.Restrict(apikey.Permissions(key.Scope), key.FolderPath))
+	apikey.SetContext(c, key)
+
+	c.Set("Auth", true)
+	c.Set("UserInfo", &tokenFunc.TokenPayload{
+		UserID:    int64(user.ID),
+		Username:  user.Username,
+		Email:     user.Email,
+		ExtraData: map[string]interface{}{"auth_method": "api_key", "api_key_id": key.ID},
+	})
+
+	Log(c).Debug("API Key验证成功",
+		zap.Uint("user_id", user.ID),
+		zap.Uint("api_key_id", key.ID),
+	)
+}
+
 func interfaceMapToStringMap(m interface{}) map[string]interface{} {
 	res := make(map[string]interface{})
 	switch mm := m.(type) {
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunyuanling/server/internal/instrument"
+)
+
+// Metrics 记录 HTTP 请求数和耗时，按路由模板统计（未匹配的路由记为 unmatched）
+// WebSocket 连接是长连接，只计入连接指标，不计入请求耗时
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.IsWebsocket() {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		done := instrument.RequestStarted()
+		c.Next()
+		done()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		instrument.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
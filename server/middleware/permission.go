@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/response"
+	tokenFunc "github.com/sunyuanling/server/pkg/tokn"
+)
+
+// RequirePermission 权限拦截：必须登录，且拥有全部指定的全局权限
+func RequirePermission(db *gorm.DB, perms ...string) gin.HandlerFunc {
+	return requirePermission(db, false, perms)
+}
+
+// RequireScopedPermission 权限拦截：拥有全局权限或任一目录范围内的权限即可放行
+// 具体路径由处理器再用 rbac.Grants.Can 校验
+func RequireScopedPermission(db *gorm.DB, perms ...string) gin.HandlerFunc {
+	return requirePermission(db, true, perms)
+}
+
+// RequireRole 角色拦截：必须登录，且拥有指定的全局角色
+func RequireRole(db *gorm.DB, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, grants, ok := loadGrants(c, db)
+		if !ok {
+			return
+		}
+		// API Key 只用于文件访问，不能代替账号本人使用角色
+		payload := c.MustGet("UserInfo").(*tokenFunc.TokenPayload)
+		_, viaAPIKey := payload.ExtraData["api_key_id"]
+		if viaAPIKey || !grants.HasRole(role) {
+			logger.Warn("角色不足",
+				zap.Uint("user_id", userID),
+				zap.String("role", role),
+				zap.String("path", c.Request.URL.Path),
+			)
+			response.Forbidden(c, "权限不足")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func requirePermission(db *gorm.DB, scoped bool, perms []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, grants, ok := loadGrants(c, db)
+		if !ok {
+			return
+		}
+
+		for _, perm := range perms {
+			allowed := grants.Has(perm)
+			if scoped {
+				allowed = grants.HasAny(perm)
+			}
+			if !allowed {
+				logger.Warn("权限不足",
+					zap.Uint("user_id", userID),
+					zap.String("permission", perm),
+					zap.String("path", c.Request.URL.Path),
+				)
+				response.Forbidden(c, "权限不足")
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// loadGrants 取当前登录用户的权限，失败时已写响应并中止
+func loadGrants(c *gin.Context, db *gorm.DB) (uint, *rbac.Grants, bool) {
+	if !c.GetBool("Auth") {
+		response.Unauthorized(c, "请先登录")
+		c.Abort()
+		return 0, nil, false
+	}
+	payload, ok := c.MustGet("UserInfo").(*tokenFunc.TokenPayload)
+	if !ok {
+		response.InternalError(c, "用户信息类型错误")
+		c.Abort()
+		return 0, nil, false
+	}
+
+	userID := uint(payload.UserID)
+	grants, err := rbac.FromContext(c, db, userID)
+	if err != nil {
+		logger.Error("加载用户权限失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.InternalError(c, "加载用户权限失败")
+		c.Abort()
+		return 0, nil, false
+	}
+	return userID, grants, true
+}
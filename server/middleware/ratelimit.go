@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/pkg/ratelimit"
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+// RateLimitByIP 按客户端IP限流：每次请求计数一次，超过次数后锁定（Redis 异常时放行）
+func RateLimitByIP(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := limiter.Hit(c.Request.Context(), c.ClientIP())
+		if err != nil {
+			logger.Error("限流检查失败",
+				zap.String("policy", limiter.Policy().Name),
+				zap.Error(err),
+			)
+			c.Next()
+			return
+		}
+		if !status.Allowed {
+			RejectRateLimited(c, status, "请求过于频繁，请稍后再试")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RejectRateLimited 返回 429，并在 Retry-After 中告知剩余锁定秒数
+func RejectRateLimited(c *gin.Context, status ratelimit.Status, msg string) {
+	seconds := int64(status.RetryAfter.Seconds()) + 1
+	c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+	logger.Warn("请求被限流",
+		zap.String("path", c.FullPath()),
+		zap.String("ip", c.ClientIP()),
+		zap.Int64("retry_after", seconds),
+	)
+	response.TooManyRequests(c, msg)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/sunyuanling/server/pkg/response"
+)
+
+// Recovery 捕获处理器中的 panic，通过请求 logger 记录堆栈并返回 500
+// 与 gin.Recovery 不同，不输出请求头（其中有 Authorization、Token）
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			// 客户端断开连接时写响应失败，不算服务端错误
+			if err, ok := rec.(error); ok && brokenPipe(err) {
+				Log(c).Warn("客户端连接已断开", zap.String("path", c.Request.URL.Path), zap.Error(err))
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+
+			Log(c).Error("处理请求时发生panic",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Any("panic", rec),
+				zap.Stack("stack"),
+			)
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			response.Error(c, http.StatusInternalServerError, "服务器内部错误")
+			c.Abort()
+		}()
+		c.Next()
+	}
+}
+
+// brokenPipe 是否为连接被对端关闭导致的写入失败
+func brokenPipe(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var sysErr *os.SyscallError
+	if !errors.As(opErr, &sysErr) {
+		return false
+	}
+	msg := strings.ToLower(sysErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/sunyuanling/server/pkg/logger"
+	tokenFunc "github.com/sunyuanling/server/pkg/tokn"
+	"github.com/sunyuanling/server/pkg/tracing"
+)
+
+// RequestIDHeader 请求 ID 的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey 请求 ID 在 gin.Context 中的键
+const requestIDKey = "RequestID"
+
+// maxRequestIDLength 接受上游（反向代理、客户端）传入的请求 ID 的最大长度
+const maxRequestIDLength = 128
+
+// redacted 替换敏感参数值
+const redacted = "[REDACTED]"
+
+// sensitiveParams 访问日志中需要隐藏值的查询参数（小写）
+var sensitiveParams = map[string]bool{
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"key":           true,
+	"api_key":       true,
+	"apikey":        true,
+	"password":      true,
+	"secret":        true,
+	"code":          true, // OIDC 授权码、邮箱验证码
+	"state":         true,
+	"signature":     true,
+	"sig":           true,
+}
+
+// RequestLog 访问日志：分配请求 ID 并写入响应头，在请求 context 中放入带 request_id（启用追踪时还有 trace_id）的 logger，
+// 请求结束后记录一条访问日志。查询参数中的 token、密码等替换为 [REDACTED]，不记录任何请求头
+func RequestLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		ctx := c.Request.Context()
+		fields := []zap.Field{zap.String("request_id", id)}
+		if sc := tracing.SpanFromContext(ctx).SpanContext(); sc.IsValid() {
+			fields = append(fields, zap.String("trace_id", sc.TraceID.String()))
+		}
+		reqLog := logger.FromContext(context.Background()).With(fields...)
+		c.Request = c.Request.WithContext(logger.NewContext(ctx, reqLog))
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+		fields = []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("ip", c.ClientIP()),
+			zap.Int("bytes", c.Writer.Size()),
+			zap.String("user_agent", c.Request.UserAgent()),
+		}
+		if q := c.Request.URL.RawQuery; q != "" {
+			fields = append(fields, zap.String("query", RedactQuery(q)))
+		}
+		if payload, ok := c.Get("UserInfo"); ok {
+			if p, ok := payload.(*tokenFunc.TokenPayload); ok && p != nil {
+				fields = append(fields, zap.Int64("user_id", p.UserID))
+			}
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate); len(errs) > 0 {
+			fields = append(fields, zap.String("errors", errs.String()))
+		}
+
+		switch {
+		case status >= 500:
+			reqLog.Error("请求完成", fields...)
+		case status >= 400:
+			reqLog.Warn("请求完成", fields...)
+		default:
+			reqLog.Info("请求完成", fields...)
+		}
+	}
+}
+
+// Log 当前请求的 logger（带 request_id），不在请求中时返回全局 logger
+func Log(c *gin.Context) *zap.Logger {
+	return logger.FromContext(c.Request.Context())
+}
+
+// RequestID 当前请求的 ID
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// RedactQuery 隐藏查询字符串中敏感参数的值，保持参数顺序
+func RedactQuery(raw string) string {
+	parts := strings.Split(raw, "&")
+	for i, part := range parts {
+		name, _, hasValue := strings.Cut(part, "=")
+		if !hasValue {
+			continue
+		}
+		key, err := url.QueryUnescape(name)
+		if err != nil {
+			key = name
+		}
+		if sensitiveParams[strings.ToLower(key)] {
+			parts[i] = name + "=" + redacted
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// validRequestID 只接受长度有限、由字母数字和 -_.: 组成的请求 ID，防止日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	tokenFunc "github.com/sunyuanling/server/pkg/tokn"
+	"github.com/sunyuanling/server/pkg/tracing"
+)
+
+// Tracing 为每个请求创建服务端 span，请求头带 W3C traceparent 时沿用上游的追踪
+// span 放入请求 context，处理器把 c.Request.Context() 传给数据库、Redis 时记录为子 span
+// 需放在 RequestLog 之前，访问日志才能带上 trace_id
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if sc, ok := tracing.ParseTraceparent(c.GetHeader("traceparent")); ok {
+			ctx = tracing.ContextWithRemote(ctx, sc)
+		}
+		ctx, span := tracing.Start(ctx, c.Request.Method, tracing.KindServer,
+			tracing.String("http.request.method", c.Request.Method),
+			tracing.String("url.path", c.Request.URL.Path),
+			tracing.String("client.address", c.ClientIP()),
+			tracing.String("user_agent.original", c.Request.UserAgent()),
+		)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route != "" {
+			span.SetName(c.Request.Method + " " + route)
+			span.SetAttributes(tracing.String("http.route", route))
+		}
+		status := c.Writer.Status()
+		span.SetAttributes(tracing.Int("http.response.status_code", status))
+		if id := RequestID(c); id != "" {
+			span.SetAttributes(tracing.String("http.request_id", id))
+		}
+		if payload, ok := c.Get("UserInfo"); ok {
+			if p, ok := payload.(*tokenFunc.TokenPayload); ok && p != nil {
+				span.SetAttributes(tracing.Int64("enduser.id", p.UserID))
+			}
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetFailed(http.StatusText(status))
+		}
+		span.End()
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"github.com/sunyuanling/server/config"
 	"os"
@@ -175,3 +176,21 @@ func Panicf(template string, args ...interface{}) {
 func With(fields ...zap.Field) *zap.Logger {
 	return Logger.With(fields...)
 }
+
+type ctxKey struct{}
+
+// NewContext 把请求级 logger（带 request_id 等字段）放入 context
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext context 中的请求级 logger，没有时返回全局 Logger
+// 返回的 logger 直接调用 zap 方法，不经过本包的包装函数
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok {
+			return l
+		}
+	}
+	return Logger.WithOptions(zap.AddCallerSkip(-1))
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunyuanling/server/pkg/logger"
+)
+
+// fileMailer 不真正发送，把邮件写入本地目录并记录日志，用于本地开发和测试
+type fileMailer struct {
+	dir string
+}
+
+// NewFileMailer 创建写入本地目录的邮件发送器
+func NewFileMailer(dir string) Mailer {
+	return &fileMailer{dir: dir}
+}
+
+// Send 将邮件保存为 .eml 文件
+func (m *fileMailer) Send(_ context.Context, msg Message) error {
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return fmt.Errorf("创建邮件目录失败: %w", err)
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102-150405.000000"), sanitize(msg.To))
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, buildMessage("noreply@localhost", msg), 0o600); err != nil {
+		return fmt.Errorf("写入邮件文件失败: %w", err)
+	}
+
+	logger.Info("邮件已写入本地文件",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("file", path),
+	)
+	return nil
+}
+
+// sanitize 收件人地址转换为安全的文件名
+func sanitize(s string) string {
+	out := []rune(s)
+	for i, r := range out {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '@') {
+			out[i] = '_'
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,32 @@
+// Package mailer 邮件发送：SMTP 实现，以及写入本地目录的 file 实现（本地测试用）
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sunyuanling/server/config"
+)
+
+// Message 邮件内容（纯文本）
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New 根据配置创建邮件发送器
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	case "file", "":
+		return NewFileMailer(cfg.FileDir), nil
+	}
+	return nil, fmt.Errorf("不支持的邮件发送方式: %s", cfg.Driver)
+}
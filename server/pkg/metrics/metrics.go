@@ -0,0 +1,158 @@
+// Package metrics 轻量的 Prometheus 指标：计数、瞬时值、直方图和抓取时计算的指标，按文本格式（0.0.4）导出
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 指标类型
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// ContentType 文本导出格式
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Desc 指标描述
+type Desc struct {
+	Name   string
+	Help   string
+	Type   string
+	Labels []string
+}
+
+// Sample 导出的一行数据
+type Sample struct {
+	Suffix      string   // 直方图的 _bucket/_sum/_count
+	LabelValues []string // 与 Desc.Labels 顺序一致
+	Le          string   // 直方图分桶上界
+	Value       float64
+}
+
+// Metric 可注册到 Registry 的指标
+type Metric interface {
+	describe() Desc
+	collect(emit func(Sample))
+}
+
+// Registry 指标注册表
+type Registry struct {
+	mu      sync.RWMutex
+	metrics []Metric
+	names   map[string]bool
+}
+
+// NewRegistry 创建注册表
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// Default 默认注册表
+var Default = NewRegistry()
+
+// MustRegister 注册到默认注册表
+func MustRegister(ms ...Metric) {
+	Default.MustRegister(ms...)
+}
+
+// MustRegister 注册指标，名称重复时 panic（属于编程错误）
+func (r *Registry) MustRegister(ms ...Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range ms {
+		name := m.describe().Name
+		if r.names[name] {
+			panic("metrics: 指标重复注册: " + name)
+		}
+		r.names[name] = true
+		r.metrics = append(r.metrics, m)
+	}
+}
+
+// WriteText 按 Prometheus 文本格式输出全部指标
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	list := append([]Metric(nil), r.metrics...)
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range list {
+		desc := m.describe()
+		fmt.Fprintf(bw, "# HELP %s %s\n", desc.Name, escapeHelp(desc.Help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", desc.Name, desc.Type)
+		m.collect(func(s Sample) {
+			bw.WriteString(desc.Name)
+			bw.WriteString(s.Suffix)
+			writeLabels(bw, desc.Labels, s.LabelValues, s.Le)
+			bw.WriteByte(' ')
+			bw.WriteString(formatFloat(s.Value))
+			bw.WriteByte('\n')
+		})
+	}
+	return bw.Flush()
+}
+
+// Handler 导出指标的 HTTP 处理器
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = r.WriteText(w)
+	})
+}
+
+// writeLabels 输出 {name="value",...}，没有标签时不输出
+func writeLabels(w *bufio.Writer, names, values []string, le string) {
+	if len(names) == 0 && le == "" {
+		return
+	}
+	w.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(name)
+		w.WriteString(`="`)
+		w.WriteString(escapeLabel(values[i]))
+		w.WriteByte('"')
+	}
+	if le != "" {
+		if len(names) > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(`le="`)
+		w.WriteString(le)
+		w.WriteByte('"')
+	}
+	w.WriteByte('}')
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
+
+// formatFloat 按文本格式输出数值
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText 失败: %v", err)
+	}
+	return b.String()
+}
+
+func TestHistogramExposition(t *testing.T) {
+	r := NewRegistry()
+	// 分桶乱序传入，导出时按升序
+	h := NewHistogramVec("http_request_duration_seconds", "请求耗时", []float64{1, 0.1, 0.5}, "method")
+	r.MustRegister(h)
+
+	get := h.WithLabelValues("GET")
+	for _, v := range []float64{0.05, 0.1, 0.3, 0.7, 2} {
+		get.Observe(v)
+	}
+	h.WithLabelValues("POST").Observe(0.5)
+
+	want := `# HELP http_request_duration_seconds 请求耗时
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{method="GET",le="0.1"} 2
+http_request_duration_seconds_bucket{method="GET",le="0.5"} 3
+http_request_duration_seconds_bucket{method="GET",le="1"} 4
+http_request_duration_seconds_bucket{method="GET",le="+Inf"} 5
+http_request_duration_seconds_sum{method="GET"} 3.15
+http_request_duration_seconds_count{method="GET"} 5
+http_request_duration_seconds_bucket{method="POST",le="0.1"} 0
+http_request_duration_seconds_bucket{method="POST",le="0.5"} 1
+http_request_duration_seconds_bucket{method="POST",le="1"} 1
+http_request_duration_seconds_bucket{method="POST",le="+Inf"} 1
+http_request_duration_seconds_sum{method="POST"} 0.5
+http_request_duration_seconds_count{method="POST"} 1
+`
+	if got := render(t, r); got != want {
+		t.Errorf("导出结果不一致\n得到:\n%s\n期望:\n%s", got, want)
+	}
+}
+
+func TestHistogramWithoutLabels(t *testing.T) {
+	r := NewRegistry()
+	h := NewHistogramVec("job_seconds", "任务耗时", []float64{0.005, 2.5})
+	r.MustRegister(h)
+	h.WithLabelValues().Observe(1)
+
+	want := `# HELP job_seconds 任务耗时
+# TYPE job_seconds histogram
+job_seconds_bucket{le="0.005"} 0
+job_seconds_bucket{le="2.5"} 1
+job_seconds_bucket{le="+Inf"} 1
+job_seconds_sum 1
+job_seconds_count 1
+`
+	if got := render(t, r); got != want {
+		t.Errorf("导出结果不一致\n得到:\n%s\n期望:\n%s", got, want)
+	}
+}
+
+func TestLabelAndHelpEscaping(t *testing.T) {
+	r := NewRegistry()
+	c := NewCounterVec("files_total", "文件数\n路径含 \\ 和 \"引号\"", "path")
+	r.MustRegister(c)
+	c.WithLabelValues("C:\\data\n\"a\"").Inc()
+
+	// HELP 只转义反斜杠和换行，标签值还要转义双引号
+	want := `# HELP files_total 文件数\n路径含 \\ 和 "引号"
+# TYPE files_total counter
+files_total{path="C:\\data\n\"a\""} 1
+`
+	if got := render(t, r); got != want {
+		t.Errorf("导出结果不一致\n得到:\n%s\n期望:\n%s", got, want)
+	}
+}
+
+func TestCounterGaugeAndFunc(t *testing.T) {
+	r := NewRegistry()
+	c := NewCounterVec("requests_total", "请求数", "code", "method")
+	g := NewGaugeVec("connections", "连接数")
+	f := NewFunc(TypeGauge, "disk_free_bytes", "剩余空间", []string{"path"}, func(emit func(float64, ...string)) {
+		emit(1024, "/data")
+		emit(1, "/a", "多余的标签值") // 标签值个数不对时丢弃
+		emit(math.Inf(1), "/b")
+	})
+	r.MustRegister(c, g, f)
+
+	c.WithLabelValues("200", "GET").Add(2)
+	c.WithLabelValues("200", "GET").Add(-5) // 计数不能减少
+	c.WithLabelValues("500", "GET").Inc()
+	conns := g.WithLabelValues()
+	conns.Inc()
+	conns.Inc()
+	conns.Dec()
+
+	want := `# HELP requests_total 请求数
+# TYPE requests_total counter
+requests_total{code="200",method="GET"} 2
+requests_total{code="500",method="GET"} 1
+# HELP connections 连接数
+# TYPE connections gauge
+connections 1
+# HELP disk_free_bytes 剩余空间
+# TYPE disk_free_bytes gauge
+disk_free_bytes{path="/data"} 1024
+disk_free_bytes{path="/b"} +Inf
+`
+	if got := render(t, r); got != want {
+		t.Errorf("导出结果不一致\n得到:\n%s\n期望:\n%s", got, want)
+	}
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := map[float64]string{
+		0:            "0",
+		1.5:          "1.5",
+		1e21:         "1e+21",
+		math.Inf(1):  "+Inf",
+		math.Inf(-1): "-Inf",
+		math.NaN():   "NaN",
+	}
+	for v, want := range tests {
+		if got := formatFloat(v); got != want {
+			t.Errorf("formatFloat(%v) = %q, 期望 %q", v, got, want)
+		}
+	}
+}
+
+func TestMustRegisterDuplicate(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(NewGaugeFunc("up", "", func() float64 { return 1 }))
+	defer func() {
+		if recover() == nil {
+			t.Error("重复注册应 panic")
+		}
+	}()
+	r.MustRegister(NewGaugeFunc("up", "", func() float64 { return 1 }))
+}
+
+func TestHandlerContentType(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(NewGaugeFunc("up", "是否运行", func() float64 { return 1 }))
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "\nup 1\n") {
+		t.Errorf("响应中缺少指标: %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,265 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefBuckets 默认的耗时直方图分桶（秒），适合 HTTP 请求
+var DefBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// ========== 单个值 ==========
+
+// Counter 只增不减的计数
+type Counter struct {
+	bits atomic.Uint64
+}
+
+// Add 增加计数，负数会被忽略
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		return
+	}
+	addFloat(&c.bits, v)
+}
+
+// Inc 计数加一
+func (c *Counter) Inc() { c.Add(1) }
+
+// Value 当前值
+func (c *Counter) Value() float64 { return math.Float64frombits(c.bits.Load()) }
+
+// Gauge 可增可减的瞬时值
+type Gauge struct {
+	bits atomic.Uint64
+}
+
+// Set 设置值
+func (g *Gauge) Set(v float64) { g.bits.Store(math.Float64bits(v)) }
+
+// Add 增加（可为负数）
+func (g *Gauge) Add(v float64) { addFloat(&g.bits, v) }
+
+// Inc 加一
+func (g *Gauge) Inc() { g.Add(1) }
+
+// Dec 减一
+func (g *Gauge) Dec() { g.Add(-1) }
+
+// Value 当前值
+func (g *Gauge) Value() float64 { return math.Float64frombits(g.bits.Load()) }
+
+// Histogram 分桶统计观测值的分布
+type Histogram struct {
+	upper []float64 // 各桶上界（升序，不含 +Inf）
+
+	mu     sync.Mutex
+	counts []uint64 // 落在各桶（非累计）的次数，最后一个为 +Inf
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{upper: buckets, counts: make([]uint64, len(buckets)+1)}
+}
+
+// Observe 记录一次观测值
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v)
+	h.mu.Lock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+	h.mu.Unlock()
+}
+
+// snapshot 累计分桶计数、总和、次数
+func (h *Histogram) snapshot() ([]uint64, float64, uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cumulative := make([]uint64, len(h.counts))
+	var acc uint64
+	for i, n := range h.counts {
+		acc += n
+		cumulative[i] = acc
+	}
+	return cumulative, h.sum, h.count
+}
+
+// addFloat 原子地给 float64 加上 v
+func addFloat(bits *atomic.Uint64, v float64) {
+	for {
+		old := bits.Load()
+		next := math.Float64bits(math.Float64frombits(old) + v)
+		if bits.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+// ========== 带标签的指标 ==========
+
+// child 一组标签值对应的值
+type child[T any] struct {
+	values []string
+	metric T
+}
+
+// vec 按标签值组合保存子指标
+type vec[T any] struct {
+	desc     Desc
+	newChild func() T
+
+	mu       sync.RWMutex
+	children map[string]*child[T]
+}
+
+func newVec[T any](desc Desc, newChild func() T) *vec[T] {
+	return &vec[T]{desc: desc, newChild: newChild, children: make(map[string]*child[T])}
+}
+
+// with 取标签值对应的子指标，不存在时创建；标签值个数不对时 panic（属于编程错误）
+func (v *vec[T]) with(values ...string) T {
+	if len(values) != len(v.desc.Labels) {
+		panic("metrics: " + v.desc.Name + " 标签值个数与标签名不一致")
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	c, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return c.metric
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if c, ok := v.children[key]; ok {
+		return c.metric
+	}
+	c = &child[T]{values: append([]string(nil), values...), metric: v.newChild()}
+	v.children[key] = c
+	return c.metric
+}
+
+// sorted 按标签值排序的子指标，保证输出稳定
+func (v *vec[T]) sorted() []*child[T] {
+	v.mu.RLock()
+	list := make([]*child[T], 0, len(v.children))
+	for _, c := range v.children {
+		list = append(list, c)
+	}
+	v.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return strings.Join(list[i].values, "\xff") < strings.Join(list[j].values, "\xff")
+	})
+	return list
+}
+
+// CounterVec 带标签的计数
+type CounterVec struct{ v *vec[*Counter] }
+
+// NewCounterVec 创建带标签的计数，没有标签时用 WithLabelValues() 取唯一的值
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	desc := Desc{Name: name, Help: help, Type: TypeCounter, Labels: labels}
+	return &CounterVec{v: newVec(desc, func() *Counter { return &Counter{} })}
+}
+
+// WithLabelValues 按标签值（与标签名顺序一致）取计数
+func (c *CounterVec) WithLabelValues(values ...string) *Counter { return c.v.with(values...) }
+
+func (c *CounterVec) describe() Desc { return c.v.desc }
+
+func (c *CounterVec) collect(emit func(Sample)) {
+	for _, ch := range c.v.sorted() {
+		emit(Sample{LabelValues: ch.values, Value: ch.metric.Value()})
+	}
+}
+
+// GaugeVec 带标签的瞬时值
+type GaugeVec struct{ v *vec[*Gauge] }
+
+// NewGaugeVec 创建带标签的瞬时值
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	desc := Desc{Name: name, Help: help, Type: TypeGauge, Labels: labels}
+	return &GaugeVec{v: newVec(desc, func() *Gauge { return &Gauge{} })}
+}
+
+// WithLabelValues 按标签值取瞬时值
+func (g *GaugeVec) WithLabelValues(values ...string) *Gauge { return g.v.with(values...) }
+
+func (g *GaugeVec) describe() Desc { return g.v.desc }
+
+func (g *GaugeVec) collect(emit func(Sample)) {
+	for _, ch := range g.v.sorted() {
+		emit(Sample{LabelValues: ch.values, Value: ch.metric.Value()})
+	}
+}
+
+// HistogramVec 带标签的直方图
+type HistogramVec struct {
+	v       *vec[*Histogram]
+	buckets []float64
+}
+
+// NewHistogramVec 创建带标签的直方图，buckets 为各桶上界（升序）
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	desc := Desc{Name: name, Help: help, Type: TypeHistogram, Labels: labels}
+	return &HistogramVec{
+		v:       newVec(desc, func() *Histogram { return newHistogram(buckets) }),
+		buckets: buckets,
+	}
+}
+
+// WithLabelValues 按标签值取直方图
+func (h *HistogramVec) WithLabelValues(values ...string) *Histogram { return h.v.with(values...) }
+
+func (h *HistogramVec) describe() Desc { return h.v.desc }
+
+func (h *HistogramVec) collect(emit func(Sample)) {
+	for _, ch := range h.v.sorted() {
+		counts, sum, count := ch.metric.snapshot()
+		for i, upper := range h.buckets {
+			emit(Sample{Suffix: "_bucket", LabelValues: ch.values, Le: formatFloat(upper), Value: float64(counts[i])})
+		}
+		emit(Sample{Suffix: "_bucket", LabelValues: ch.values, Le: "+Inf", Value: float64(counts[len(counts)-1])})
+		emit(Sample{Suffix: "_sum", LabelValues: ch.values, Value: sum})
+		emit(Sample{Suffix: "_count", LabelValues: ch.values, Value: float64(count)})
+	}
+}
+
+// ========== 抓取时计算的指标 ==========
+
+// funcMetric 每次抓取时调用函数取值，适合从其他组件读取现成的统计（连接池、磁盘空间等）
+type funcMetric struct {
+	desc Desc
+	fn   func(emit func(value float64, labelValues ...string))
+}
+
+// NewFunc 创建抓取时计算的指标，fn 对每组标签值调用一次 emit
+func NewFunc(typ, name, help string, labels []string, fn func(emit func(value float64, labelValues ...string))) Metric {
+	return &funcMetric{desc: Desc{Name: name, Help: help, Type: typ, Labels: labels}, fn: fn}
+}
+
+// NewGaugeFunc 创建没有标签、抓取时计算的瞬时值
+func NewGaugeFunc(name, help string, fn func() float64) Metric {
+	return NewFunc(TypeGauge, name, help, nil, func(emit func(float64, ...string)) {
+		emit(fn())
+	})
+}
+
+func (f *funcMetric) describe() Desc { return f.desc }
+
+func (f *funcMetric) collect(emit func(Sample)) {
+	f.fn(func(value float64, labelValues ...string) {
+		if len(labelValues) != len(f.desc.Labels) {
+			return
+		}
+		emit(Sample{LabelValues: labelValues, Value: value})
+	})
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/sunyuanling/server/config"
+)
+
+// 策略名
+const (
+	PolicyLoginIP       = "login_ip"       // 登录失败（按IP）
+	PolicyLoginAccount  = "login_account"  // 登录失败（按账号）
+	PolicyRegister      = "register_ip"    // 注册尝试（按IP）
+	PolicyPasswordReset = "pwd_reset_ip"   // 找回密码/邮箱验证（按IP）
+	PolicyResetAccount  = "pwd_reset_acct" // 找回密码验证码校验（按邮箱）
+)
+
+// LoginIP 按IP统计登录失败
+func LoginIP(cfg config.SecurityConfig) Policy {
+	return Policy{
+		Name:       PolicyLoginIP,
+		Limit:      cfg.MaxLoginAttemptsPerIP,
+		Window:     time.Duration(cfg.LoginWindowMinutes) * time.Minute,
+		Lockout:    time.Duration(cfg.LockoutDurationMinutes) * time.Minute,
+		MaxLockout: time.Duration(cfg.MaxLockoutMinutes) * time.Minute,
+	}
+}
+
+// LoginAccount 按账号统计登录失败
+func LoginAccount(cfg config.SecurityConfig) Policy {
+	return Policy{
+		Name:       PolicyLoginAccount,
+		Limit:      cfg.MaxLoginAttempts,
+		Window:     time.Duration(cfg.LoginWindowMinutes) * time.Minute,
+		Lockout:    time.Duration(cfg.LockoutDurationMinutes) * time.Minute,
+		MaxLockout: time.Duration(cfg.MaxLockoutMinutes) * time.Minute,
+	}
+}
+
+// Register 按IP统计注册尝试
+func Register(cfg *config.Config) Policy {
+	return Policy{
+		Name:       PolicyRegister,
+		Limit:      cfg.Register.MaxPerIPPerHour,
+		Window:     time.Hour,
+		Lockout:    time.Hour,
+		MaxLockout: time.Duration(cfg.Security.MaxLockoutMinutes) * time.Minute,
+	}
+}
+
+// PasswordReset 按IP统计找回密码、邮箱验证相关请求
+func PasswordReset(cfg config.SecurityConfig) Policy {
+	return Policy{
+		Name:       PolicyPasswordReset,
+		Limit:      cfg.MaxResetPerIPPerHour,
+		Window:     time.Hour,
+		Lockout:    time.Hour,
+		MaxLockout: time.Duration(cfg.MaxLockoutMinutes) * time.Minute,
+	}
+}
+
+// ResetAccount 按邮箱统计找回密码验证码校验失败
+func ResetAccount(cfg config.SecurityConfig) Policy {
+	return Policy{
+		Name:       PolicyResetAccount,
+		Limit:      cfg.MaxLoginAttempts,
+		Window:     time.Duration(cfg.LoginWindowMinutes) * time.Minute,
+		Lockout:    time.Duration(cfg.LockoutDurationMinutes) * time.Minute,
+		MaxLockout: time.Duration(cfg.MaxLockoutMinutes) * time.Minute,
+	}
+}
@@ -0,0 +1,187 @@
+// Package ratelimit 基于 Redis 的滑动窗口限流，超过次数后锁定，连续锁定时锁定时长逐级翻倍
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	windowKey  = "rl:win:%s:%s"    // 滑动窗口（ZSET，score 为时间戳毫秒）
+	lockKey    = "rl:lock:%s:%s"   // 锁定标记，TTL 即剩余锁定时间
+	strikeKey  = "rl:strike:%s:%s" // 连续锁定次数，用于逐级延长锁定
+	lockPrefix = "rl:lock:"
+	strikeTTL  = 24 * time.Hour // 锁定次数的记忆时间
+)
+
+// ErrNotLocked 没有对应的锁定记录
+var ErrNotLocked = errors.New("没有对应的锁定记录")
+
+// Policy 限流策略
+type Policy struct {
+	Name       string        // 策略名（Redis key 前缀的一部分），如 login_ip
+	Limit      int           // 窗口内允许的次数
+	Window     time.Duration // 滑动窗口长度
+	Lockout    time.Duration // 首次锁定时长
+	MaxLockout time.Duration // 锁定时长上限（逐级翻倍不超过该值）
+}
+
+// Status 限流检查结果
+type Status struct {
+	Allowed    bool          // 是否允许
+	Remaining  int           // 窗口内剩余次数
+	RetryAfter time.Duration // 锁定剩余时间
+}
+
+// Limiter 限流器
+type Limiter struct {
+	rdb    *redis.Client
+	policy Policy
+}
+
+// New 创建限流器
+func New(rdb *redis.Client, policy Policy) *Limiter {
+	if policy.MaxLockout < policy.Lockout {
+		policy.MaxLockout = policy.Lockout
+	}
+	return &Limiter{rdb: rdb, policy: policy}
+}
+
+// Policy 返回限流策略
+func (l *Limiter) Policy() Policy {
+	return l.policy
+}
+
+// Check 只检查是否被锁定，不计数
+func (l *Limiter) Check(ctx context.Context, key string) (Status, error) {
+	ttl, err := l.rdb.PTTL(ctx, fmt.Sprintf(lockKey, l.policy.Name, key)).Result()
+	if err != nil {
+		return Status{Allowed: true}, err
+	}
+	if ttl > 0 {
+		return Status{Allowed: false, RetryAfter: ttl}, nil
+	}
+	return Status{Allowed: true, Remaining: l.policy.Limit}, nil
+}
+
+// Hit 记录一次（失败或尝试），窗口内超过次数时锁定
+func (l *Limiter) Hit(ctx context.Context, key string) (Status, error) {
+	if status, err := l.Check(ctx, key); err != nil || !status.Allowed {
+		return status, err
+	}
+
+	now := time.Now()
+	wk := fmt.Sprintf(windowKey, l.policy.Name, key)
+	pipe := l.rdb.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, wk, "-inf", strconv.FormatInt(now.Add(-l.policy.Window).UnixMilli(), 10))
+	pipe.ZAdd(ctx, wk, redis.Z{Score: float64(now.UnixMilli()), Member: uuid.New().String()})
+	count := pipe.ZCard(ctx, wk)
+	pipe.PExpire(ctx, wk, l.policy.Window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return Status{Allowed: true}, err
+	}
+
+	n := int(count.Val())
+	if n < l.policy.Limit {
+		return Status{Allowed: true, Remaining: l.policy.Limit - n}, nil
+	}
+
+	// 达到上限：锁定，时长按连续锁定次数翻倍
+	lockout, err := l.lock(ctx, key)
+	if err != nil {
+		return Status{Allowed: true}, err
+	}
+	return Status{Allowed: false, RetryAfter: lockout}, nil
+}
+
+// Reset 成功后清除窗口计数和连续锁定记录
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.rdb.Del(ctx,
+		fmt.Sprintf(windowKey, l.policy.Name, key),
+		fmt.Sprintf(strikeKey, l.policy.Name, key),
+	).Err()
+}
+
+func (l *Limiter) lock(ctx context.Context, key string) (time.Duration, error) {
+	sk := fmt.Sprintf(strikeKey, l.policy.Name, key)
+	strikes, err := l.rdb.Incr(ctx, sk).Result()
+	if err != nil {
+		return 0, err
+	}
+	l.rdb.Expire(ctx, sk, strikeTTL)
+
+	lockout := l.policy.Lockout
+	for i := int64(1); i < strikes && lockout < l.policy.MaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > l.policy.MaxLockout {
+		lockout = l.policy.MaxLockout
+	}
+
+	pipe := l.rdb.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf(lockKey, l.policy.Name, key), strikes, lockout)
+	pipe.Del(ctx, fmt.Sprintf(windowKey, l.policy.Name, key))
+	_, err = pipe.Exec(ctx)
+	return lockout, err
+}
+
+// ========== 管理 ==========
+
+// Lockout 当前锁定记录
+type Lockout struct {
+	Policy     string        `json:"policy"`
+	Key        string        `json:"key"`
+	Strikes    int64         `json:"strikes"` // 连续锁定次数
+	RetryAfter time.Duration `json:"-"`       // 剩余锁定时间
+	Until      time.Time     `json:"until"`   // 解除时间
+}
+
+// ListLockouts 列出所有策略当前的锁定
+func ListLockouts(ctx context.Context, rdb *redis.Client) ([]Lockout, error) {
+	var lockouts []Lockout
+	iter := rdb.Scan(ctx, 0, lockPrefix+"*", 200).Iterator()
+	for iter.Next(ctx) {
+		rest := strings.TrimPrefix(iter.Val(), lockPrefix)
+		policy, key, ok := strings.Cut(rest, ":")
+		if !ok {
+			continue
+		}
+
+		ttl, err := rdb.PTTL(ctx, iter.Val()).Result()
+		if err != nil || ttl <= 0 {
+			continue
+		}
+		strikes, _ := rdb.Get(ctx, iter.Val()).Int64()
+		lockouts = append(lockouts, Lockout{
+			Policy:     policy,
+			Key:        key,
+			Strikes:    strikes,
+			RetryAfter: ttl,
+			Until:      time.Now().Add(ttl),
+		})
+	}
+	return lockouts, iter.Err()
+}
+
+// Unlock 解除锁定，同时清除计数
+func Unlock(ctx context.Context, rdb *redis.Client, policy, key string) error {
+	n, err := rdb.Del(ctx,
+		fmt.Sprintf(lockKey, policy, key),
+		fmt.Sprintf(windowKey, policy, key),
+		fmt.Sprintf(strikeKey, policy, key),
+	).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotLocked
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+// Package smart 解析 smartctl 的 JSON 输出（smartctl -j -a），提取磁盘健康相关的 SMART 数据
+//
+// 支持 ATA/SATA（属性表）、NVMe（健康信息日志）和 SCSI/SAS（缺陷列表），
+// 解析只依赖输入的字节，可以直接用 testdata 中采集的输出验证。
+package smart
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 协议
+const (
+	ProtocolATA  = "ATA"
+	ProtocolNVMe = "NVMe"
+	ProtocolSCSI = "SCSI"
+)
+
+// smartctl 退出码的位（见 smartctl(8) RETURN VALUES）
+const (
+	ExitCommandLine   = 1 << 0 // 命令行参数错误
+	ExitDeviceOpen    = 1 << 1 // 打开设备失败或设备不支持
+	ExitCommandFailed = 1 << 2 // 部分 SMART 命令失败或校验和错误
+	ExitDiskFailing   = 1 << 3 // SMART 状态：磁盘即将损坏
+	ExitPrefail       = 1 << 4 // 有 pre-fail 属性低于阈值
+	ExitUsagePast     = 1 << 5 // 有属性曾经低于阈值
+	ExitErrorLog      = 1 << 6 // 错误日志中有记录
+	ExitSelfTestLog   = 1 << 7 // 自检日志中有失败记录
+)
+
+// ATA 属性 ID
+const (
+	attrReallocated   = 5
+	attrPowerOnHours  = 9
+	attrTemperature   = 194
+	attrAirflowTemp   = 190
+	attrPending       = 197
+	attrUncorrectable = 198
+)
+
+var (
+	// ErrNoData smartctl 没有返回可用的 SMART 数据（设备无法打开、不支持 SMART 等）
+	ErrNoData = errors.New("没有可用的 SMART 数据")
+	// ErrStandby 机械盘处于休眠状态，为避免唤醒没有读取
+	ErrStandby = errors.New("磁盘处于休眠状态")
+)
+
+// Report 一块磁盘的 SMART 健康数据，设备不提供的项为 nil
+type Report struct {
+	Device       string `json:"device"`   // smartctl 使用的设备路径
+	Protocol     string `json:"protocol"` // ATA/NVMe/SCSI
+	Model        string `json:"model"`
+	Serial       string `json:"serial"`
+	Firmware     string `json:"firmware"`
+	CapacityByte uint64 `json:"capacity_bytes"`
+	RotationRate *int   `json:"rotation_rate"` // 转速，0 表示固态盘
+
+	Passed *bool `json:"passed"` // SMART 总体状态
+
+	Temperature          *float64 `json:"temperature"`           // 当前温度（℃）
+	PowerOnHours         *int64   `json:"power_on_hours"`        // 通电时间（小时）
+	ReallocatedSectors   *int64   `json:"reallocated_sectors"`   // 已重映射扇区数（SCSI 为增长缺陷数）
+	PendingSectors       *int64   `json:"pending_sectors"`       // 等待重映射的扇区数
+	UncorrectableSectors *int64   `json:"uncorrectable_sectors"` // 无法校正的扇区数
+	MediaErrors          *int64   `json:"media_errors"`          // NVMe 介质错误数
+	PercentageUsed       *int     `json:"percentage_used"`       // NVMe 寿命已用百分比（可超过 100）
+	CriticalWarning      int      `json:"critical_warning"`      // NVMe critical_warning 位
+	FailingAttributes    []string `json:"failing_attributes"`    // 当前低于阈值的 ATA 属性
+
+	ExitStatus int `json:"exit_status"` // smartctl 退出码
+}
+
+// SSD 是否为固态盘，不确定时返回 false
+func (r *Report) SSD() bool {
+	if r.Protocol == ProtocolNVMe {
+		return true
+	}
+	return r.RotationRate != nil && *r.RotationRate == 0
+}
+
+// raw smartctl JSON 中用到的字段
+type raw struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	Device struct {
+		Name     string `json:"name"`
+		Protocol string `json:"protocol"`
+	} `json:"device"`
+	ModelName       string `json:"model_name"`
+	ScsiModelName   string `json:"scsi_model_name"`
+	SerialNumber    string `json:"serial_number"`
+	FirmwareVersion string `json:"firmware_version"`
+	UserCapacity    struct {
+		Bytes uint64 `json:"bytes"`
+	} `json:"user_capacity"`
+	RotationRate *int `json:"rotation_rate"`
+	SmartStatus  *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature *struct {
+		Current *float64 `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime *struct {
+		Hours *int64 `json:"hours"`
+	} `json:"power_on_time"`
+	ATASmartAttributes *struct {
+		Table []struct {
+			ID         int    `json:"id"`
+			Name       string `json:"name"`
+			WhenFailed string `json:"when_failed"`
+			Raw        struct {
+				Value int64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeLog *struct {
+		CriticalWarning int      `json:"critical_warning"`
+		Temperature     *float64 `json:"temperature"`
+		PercentageUsed  *int     `json:"percentage_used"`
+		PowerOnHours    *int64   `json:"power_on_hours"`
+		MediaErrors     *int64   `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+	SCSIGrownDefectList *int64 `json:"scsi_grown_defect_list"`
+}
+
+// Parse 解析 smartctl -j 的输出
+// smartctl 因磁盘问题以非 0 退出时输出仍然有效，只有无法打开设备等情况返回错误
+func Parse(data []byte) (*Report, error) {
+	var in raw
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, fmt.Errorf("解析 smartctl 输出失败: %w", err)
+	}
+
+	exit := in.Smartctl.ExitStatus
+	if exit&(ExitCommandLine|ExitDeviceOpen) != 0 {
+		var msgs []string
+		for _, m := range in.Smartctl.Messages {
+			if strings.Contains(m.String, "STANDBY mode") || strings.Contains(m.String, "SLEEP mode") {
+				return nil, ErrStandby
+			}
+			msgs = append(msgs, m.String)
+		}
+		if len(msgs) == 0 {
+			return nil, ErrNoData
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNoData, strings.Join(msgs, "; "))
+	}
+
+	r := &Report{
+		Device:       in.Device.Name,
+		Protocol:     normalizeProtocol(in.Device.Protocol),
+		Model:        firstNonEmpty(in.ModelName, in.ScsiModelName),
+		Serial:       in.SerialNumber,
+		Firmware:     in.FirmwareVersion,
+		CapacityByte: in.UserCapacity.Bytes,
+		RotationRate: in.RotationRate,
+		ExitStatus:   exit,
+	}
+	if in.SmartStatus != nil {
+		passed := in.SmartStatus.Passed
+		r.Passed = &passed
+	}
+	if in.Temperature != nil {
+		r.Temperature = in.Temperature.Current
+	}
+	if in.PowerOnTime != nil {
+		r.PowerOnHours = in.PowerOnTime.Hours
+	}
+
+	if attrs := in.ATASmartAttributes; attrs != nil {
+		for _, a := range attrs.Table {
+			v := a.Raw.Value
+			switch a.ID {
+			case attrReallocated:
+				r.ReallocatedSectors = &v
+			case attrPending:
+				r.PendingSectors = &v
+			case attrUncorrectable:
+				r.UncorrectableSectors = &v
+			case attrPowerOnHours:
+				if r.PowerOnHours == nil {
+					// 部分厂商在高位存放分钟、秒等，只取低 32 位
+					hours := v & 0xffffffff
+					r.PowerOnHours = &hours
+				}
+			case attrTemperature, attrAirflowTemp:
+				if r.Temperature == nil {
+					// 原始值的低字节为当前温度，高位为最高、最低温度
+					t := float64(v & 0xff)
+					r.Temperature = &t
+				}
+			}
+			if a.WhenFailed == "now" {
+				r.FailingAttributes = append(r.FailingAttributes, a.Name)
+			}
+		}
+	}
+
+	if log := in.NVMeLog; log != nil {
+		r.CriticalWarning = log.CriticalWarning
+		r.PercentageUsed = log.PercentageUsed
+		r.MediaErrors = log.MediaErrors
+		if r.Temperature == nil {
+			r.Temperature = log.Temperature
+		}
+		if r.PowerOnHours == nil {
+			r.PowerOnHours = log.PowerOnHours
+		}
+	}
+
+	if in.SCSIGrownDefectList != nil && r.ReallocatedSectors == nil {
+		r.ReallocatedSectors = in.SCSIGrownDefectList
+	}
+
+	if r.Passed == nil && r.Temperature == nil && r.PowerOnHours == nil && r.ReallocatedSectors == nil {
+		return nil, ErrNoData
+	}
+	return r, nil
+}
+
+// normalizeProtocol 统一协议名称（smartctl 对 SAS 盘输出 SCSI）
+func normalizeProtocol(p string) string {
+	switch strings.ToUpper(p) {
+	case "ATA":
+		return ProtocolATA
+	case "NVME":
+		return ProtocolNVMe
+	case "SCSI":
+		return ProtocolSCSI
+	}
+	return p
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,207 @@
+package smart
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseFixture(t *testing.T, name string) (*Report, error) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("读取 %s 失败: %v", name, err)
+	}
+	return Parse(data)
+}
+
+func mustParse(t *testing.T, name string) *Report {
+	t.Helper()
+	r, err := parseFixture(t, name)
+	if err != nil {
+		t.Fatalf("Parse(%s) 返回错误: %v", name, err)
+	}
+	return r
+}
+
+func TestParseStandby(t *testing.T) {
+	r, err := parseFixture(t, "standby.json")
+	if !errors.Is(err, ErrStandby) {
+		t.Fatalf("err = %v, 期望 ErrStandby", err)
+	}
+	if r != nil {
+		t.Fatalf("休眠时不应返回报告: %+v", r)
+	}
+}
+
+func TestParseOpenFailed(t *testing.T) {
+	_, err := parseFixture(t, "open_failed.json")
+	if !errors.Is(err, ErrNoData) {
+		t.Fatalf("err = %v, 期望 ErrNoData", err)
+	}
+	if errors.Is(err, ErrStandby) {
+		t.Fatal("打开失败不应视为休眠")
+	}
+	// smartctl 的提示信息附在错误中，便于排查权限问题
+	if !strings.Contains(err.Error(), "Permission denied") {
+		t.Errorf("错误中缺少 smartctl 的提示: %v", err)
+	}
+}
+
+func TestParseInvalidJSON(t *testing.T) {
+	if _, err := Parse([]byte("smartctl: command not found")); err == nil {
+		t.Fatal("非 JSON 输出应返回错误")
+	}
+	if _, err := Parse([]byte(`{"smartctl":{"exit_status":0}}`)); !errors.Is(err, ErrNoData) {
+		t.Fatalf("没有任何数据时 err = %v, 期望 ErrNoData", err)
+	}
+}
+
+func TestParseATASSD(t *testing.T) {
+	r := mustParse(t, "ata_ssd.json")
+
+	if r.Device != "/dev/sda" || r.Protocol != ProtocolATA {
+		t.Errorf("设备 = %s %s", r.Device, r.Protocol)
+	}
+	if r.Model != "Samsung SSD 870 EVO 1TB" || r.Serial != "S6PTNM0T512345A" || r.Firmware != "SVT02B6Q" {
+		t.Errorf("型号信息 = %q %q %q", r.Model, r.Serial, r.Firmware)
+	}
+	if r.CapacityByte != 1000204886016 {
+		t.Errorf("容量 = %d", r.CapacityByte)
+	}
+	if !r.SSD() {
+		t.Error("转速为 0 应识别为固态盘")
+	}
+	if r.Passed == nil || !*r.Passed {
+		t.Errorf("Passed = %v, 期望 true", r.Passed)
+	}
+	// temperature.current 优先于属性 190
+	assertFloat(t, "Temperature", r.Temperature, 34)
+	assertInt64(t, "PowerOnHours", r.PowerOnHours, 8123)
+	assertInt64(t, "ReallocatedSectors", r.ReallocatedSectors, 0)
+	if r.PendingSectors != nil || r.UncorrectableSectors != nil {
+		t.Errorf("没有 197/198 属性时应为 nil: %v %v", r.PendingSectors, r.UncorrectableSectors)
+	}
+	if len(r.FailingAttributes) != 0 {
+		t.Errorf("FailingAttributes = %v", r.FailingAttributes)
+	}
+	if r.ExitStatus != 0 {
+		t.Errorf("ExitStatus = %d", r.ExitStatus)
+	}
+}
+
+func TestParseFailingHDD(t *testing.T) {
+	r := mustParse(t, "ata_hdd_failing.json")
+
+	// 磁盘异常时 smartctl 以非 0 退出，但输出仍然有效
+	wantBits := ExitDiskFailing | ExitPrefail | ExitErrorLog | ExitSelfTestLog
+	if r.ExitStatus != wantBits {
+		t.Errorf("ExitStatus = %d, 期望 %d", r.ExitStatus, wantBits)
+	}
+	for _, bit := range []int{ExitDiskFailing, ExitPrefail, ExitErrorLog, ExitSelfTestLog} {
+		if r.ExitStatus&bit == 0 {
+			t.Errorf("退出码缺少位 %d", bit)
+		}
+	}
+	if r.ExitStatus&(ExitCommandLine|ExitDeviceOpen|ExitUsagePast) != 0 {
+		t.Errorf("退出码有多余的位: %d", r.ExitStatus)
+	}
+
+	if r.Passed == nil || *r.Passed {
+		t.Errorf("Passed = %v, 期望 false", r.Passed)
+	}
+	if r.SSD() {
+		t.Error("5400 转机械盘不应识别为固态盘")
+	}
+	if r.RotationRate == nil || *r.RotationRate != 5400 {
+		t.Errorf("RotationRate = %v", r.RotationRate)
+	}
+	assertInt64(t, "ReallocatedSectors", r.ReallocatedSectors, 1904)
+	assertInt64(t, "PendingSectors", r.PendingSectors, 16)
+	assertInt64(t, "UncorrectableSectors", r.UncorrectableSectors, 3)
+	assertInt64(t, "PowerOnHours", r.PowerOnHours, 45612)
+	// 没有 temperature 字段，取属性 194 原始值的低字节
+	assertFloat(t, "Temperature", r.Temperature, 42)
+	if want := []string{"Reallocated_Sector_Ct"}; !reflect.DeepEqual(r.FailingAttributes, want) {
+		t.Errorf("FailingAttributes = %v, 期望 %v", r.FailingAttributes, want)
+	}
+}
+
+func TestParseNVMe(t *testing.T) {
+	r := mustParse(t, "nvme.json")
+
+	if r.Device != "/dev/nvme0n1" || r.Protocol != ProtocolNVMe {
+		t.Errorf("设备 = %s %s", r.Device, r.Protocol)
+	}
+	if !r.SSD() {
+		t.Error("NVMe 应识别为固态盘")
+	}
+	if r.RotationRate != nil {
+		t.Errorf("NVMe 没有转速: %v", *r.RotationRate)
+	}
+	if r.Passed == nil || !*r.Passed {
+		t.Errorf("Passed = %v, 期望 true", r.Passed)
+	}
+	if r.PercentageUsed == nil || *r.PercentageUsed != 3 {
+		t.Errorf("PercentageUsed = %v, 期望 3", r.PercentageUsed)
+	}
+	if r.CriticalWarning != 0 {
+		t.Errorf("CriticalWarning = %d", r.CriticalWarning)
+	}
+	assertInt64(t, "MediaErrors", r.MediaErrors, 0)
+	assertInt64(t, "PowerOnHours", r.PowerOnHours, 6210)
+	assertFloat(t, "Temperature", r.Temperature, 41)
+	if r.ReallocatedSectors != nil || r.PendingSectors != nil {
+		t.Errorf("NVMe 没有扇区计数: %v %v", r.ReallocatedSectors, r.PendingSectors)
+	}
+}
+
+func TestParseNVMeHealthLogFallback(t *testing.T) {
+	// 旧版 smartctl 没有顶层 temperature / power_on_time 时使用健康信息日志中的值
+	data := []byte(`{
+		"smartctl": {"exit_status": 0},
+		"device": {"name": "/dev/nvme1n1", "protocol": "NVMe"},
+		"nvme_smart_health_information_log": {
+			"critical_warning": 4, "temperature": 50, "percentage_used": 104,
+			"power_on_hours": 20000, "media_errors": 7
+		}
+	}`)
+	r, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse 返回错误: %v", err)
+	}
+	if r.CriticalWarning != 4 {
+		t.Errorf("CriticalWarning = %d, 期望 4", r.CriticalWarning)
+	}
+	if r.PercentageUsed == nil || *r.PercentageUsed != 104 {
+		t.Errorf("PercentageUsed = %v, 期望 104", r.PercentageUsed)
+	}
+	assertInt64(t, "MediaErrors", r.MediaErrors, 7)
+	assertInt64(t, "PowerOnHours", r.PowerOnHours, 20000)
+	assertFloat(t, "Temperature", r.Temperature, 50)
+}
+
+func assertInt64(t *testing.T, name string, got *int64, want int64) {
+	t.Helper()
+	if got == nil {
+		t.Errorf("%s = nil, 期望 %d", name, want)
+		return
+	}
+	if *got != want {
+		t.Errorf("%s = %d, 期望 %d", name, *got, want)
+	}
+}
+
+func assertFloat(t *testing.T, name string, got *float64, want float64) {
+	t.Helper()
+	if got == nil {
+		t.Errorf("%s = nil, 期望 %v", name, want)
+		return
+	}
+	if *got != want {
+		t.Errorf("%s = %v, 期望 %v", name, *got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package smart
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Smartctl 调用 smartctl 读取 SMART 数据
+type Smartctl struct {
+	Path string // smartctl 可执行文件，为空时从 PATH 查找
+}
+
+// Available smartctl 是否可用，返回实际路径
+func (s Smartctl) Available() (string, error) {
+	path := s.Path
+	if path == "" {
+		path = "smartctl"
+	}
+	return exec.LookPath(path)
+}
+
+// Read 读取一块磁盘（如 /dev/sda、/dev/nvme0n1）的 SMART 数据
+// -n standby：机械盘处于休眠时不唤醒，此时返回 ErrStandby
+func (s Smartctl) Read(ctx context.Context, device string) (*Report, error) {
+	path, err := s.Available()
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "-j", "-a", "-n", "standby", device)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+
+	// 退出码非 0 也可能是磁盘本身有问题，输出仍然有效，交给 Parse 按退出码判断
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, err
+	}
+	if stdout.Len() == 0 {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" && err != nil {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("smartctl 没有输出: %s", msg)
+	}
+
+	r, perr := Parse(stdout.Bytes())
+	if perr != nil {
+		return nil, perr
+	}
+	if r.Device == "" {
+		r.Device = device
+	}
+	return r, nil
+}
@@ -0,0 +1,193 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sunyuanling/server/encryption"
+)
+
+// token 格式
+const (
+	FormatEncrypted = "encrypted" // AES-GCM 加密的不透明 token（默认）
+	FormatJWTHS256  = "jwt-hs256" // JWT，HMAC-SHA256，密钥由密钥环派生（可选接受 new_server 以原始密钥签名的 token）
+	FormatJWTEdDSA  = "jwt-eddsa" // JWT，Ed25519 签名，公钥通过 JWKS 发布
+	FormatPASETOv4  = "paseto-v4" // PASETO v4.public，Ed25519 签名，公钥同样通过 JWKS 发布
+)
+
+// 从加密密钥派生签名密钥时使用的用途标识，不同用途的密钥互不相同，也不同于加密密钥本身
+const (
+	signingKeyInfo = "filesync token signing ed25519"
+	hmacKeyInfo    = "filesync token signing hs256"
+)
+
+var ErrUnknownFormat = errors.New("unknown token format")
+
+// Format token 编码格式：只负责编码、解密或校验签名，过期和吊销由 TokenManager 检查
+type Format interface {
+	Name() string
+	Encode(payload *TokenPayload) (string, error)
+	Decode(token string) (*TokenPayload, error)
+}
+
+// signingKeys Ed25519 签名密钥，由加密密钥环中的每把密钥派生，kid 即密钥环中的密钥ID；
+// 轮换加密密钥时签名密钥随之轮换，保留的旧密钥继续用于验签
+type signingKeys struct {
+	activeID string
+	private  map[string]ed25519.PrivateKey
+}
+
+func newSigningKeys(enc *encryption.Encryptor) *signingKeys {
+	activeID, seeds := enc.DeriveKeys(signingKeyInfo)
+	keys := &signingKeys{activeID: activeID, private: make(map[string]ed25519.PrivateKey, len(seeds))}
+	for id, seed := range seeds {
+		keys.private[id] = ed25519.NewKeyFromSeed(seed)
+	}
+	return keys
+}
+
+func (k *signingKeys) active() (string, ed25519.PrivateKey) {
+	return k.activeID, k.private[k.activeID]
+}
+
+func (k *signingKeys) public(kid string) (ed25519.PublicKey, bool) {
+	priv, ok := k.private[kid]
+	if !ok {
+		return nil, false
+	}
+	return priv.Public().(ed25519.PublicKey), true
+}
+
+// hmacKeys HS256 密钥，与 Ed25519 签名密钥一样由密钥环中的每把密钥派生，kid 为密钥环中的密钥ID
+// legacy 非空时额外接受没有 kid、以该密钥签名的 token（new_server），只用于验证
+type hmacKeys struct {
+	activeID string
+	keys     map[string][]byte
+	legacy   []byte
+}
+
+func newHMACKeys(enc *encryption.Encryptor, legacy []byte) *hmacKeys {
+	activeID, keys := enc.DeriveKeys(hmacKeyInfo)
+	return &hmacKeys{activeID: activeID, keys: keys, legacy: legacy}
+}
+
+func (k *hmacKeys) active() (string, []byte) {
+	return k.activeID, k.keys[k.activeID]
+}
+
+// verifying 验签使用的密钥：按 kid 查找，没有 kid 时为 legacy 密钥（未启用时不存在）
+func (k *hmacKeys) verifying(kid string) ([]byte, bool) {
+	if kid == "" {
+		return k.legacy, len(k.legacy) > 0
+	}
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// newFormat 按名称创建 token 格式
+func newFormat(name string, enc *encryption.Encryptor, keys *signingKeys, hs256 *hmacKeys, issuer string) (Format, error) {
+	switch name {
+	case FormatEncrypted:
+		return &encryptedFormat{encryptor: enc}, nil
+	case FormatJWTHS256:
+		return &jwtFormat{alg: "HS256", hmac: hs256, issuer: issuer}, nil
+	case FormatJWTEdDSA:
+		return &jwtFormat{alg: "EdDSA", keys: keys, issuer: issuer}, nil
+	case FormatPASETOv4:
+		return &pasetoFormat{keys: keys, issuer: issuer}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+	}
+}
+
+// isAsymmetric 格式是否使用 Ed25519 签名（需要发布 JWKS）
+func isAsymmetric(name string) bool {
+	return name == FormatJWTEdDSA || name == FormatPASETOv4
+}
+
+// ========== 加密格式 ==========
+
+type encryptedFormat struct {
+	encryptor *encryption.Encryptor
+}
+
+func (f *encryptedFormat) Name() string { return FormatEncrypted }
+
+func (f *encryptedFormat) Encode(payload *TokenPayload) (string, error) {
+	jsonBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("序列化失败: %w", err)
+	}
+	token, err := f.encryptor.Encrypt(string(jsonBytes))
+	if err != nil {
+		return "", fmt.Errorf("加密失败: %w", err)
+	}
+	return token, nil
+}
+
+func (f *encryptedFormat) Decode(token string) (*TokenPayload, error) {
+	plaintext, err := f.encryptor.Decrypt(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var payload TokenPayload
+	if err := json.Unmarshal([]byte(plaintext), &payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return &payload, nil
+}
+
+// ========== 签名格式共用的声明 ==========
+
+// claims JWT / PASETO 中的自定义声明，字段名与 new_server 的 JWT 保持一致
+type claims struct {
+	Issuer    string                 `json:"iss,omitempty"`
+	Subject   string                 `json:"sub"`
+	UserID    int64                  `json:"user_id"`
+	Username  string                 `json:"username"`
+	Email     string                 `json:"email,omitempty"`
+	Roles     []string               `json:"roles,omitempty"`
+	DeviceID  string                 `json:"device_id,omitempty"`
+	SessionID string                 `json:"sid,omitempty"`
+	ExtraData map[string]interface{} `json:"extra_data,omitempty"`
+	IssuedMs  int64                  `json:"iat_ms,omitempty"` // 签发时间（毫秒），iat 只精确到秒
+}
+
+func newClaims(p *TokenPayload, issuer string) claims {
+	return claims{
+		Issuer:    issuer,
+		Subject:   fmt.Sprintf("%d", p.UserID),
+		UserID:    p.UserID,
+		Username:  p.Username,
+		Email:     p.Email,
+		Roles:     p.Roles,
+		DeviceID:  p.DeviceID,
+		SessionID: p.SessionID,
+		ExtraData: p.ExtraData,
+		IssuedMs:  p.IssuedMs,
+	}
+}
+
+// payload 转回 TokenPayload，签发方不一致时拒绝（未携带 iss 的旧 token 放行）
+func (c claims) payload(issuer string, issuedAt, expiresAt int64) (*TokenPayload, error) {
+	if c.Issuer != "" && c.Issuer != issuer {
+		return nil, ErrInvalidToken
+	}
+	return &TokenPayload{
+		UserID:    c.UserID,
+		Username:  c.Username,
+		Email:     c.Email,
+		Roles:     c.Roles,
+		DeviceID:  c.DeviceID,
+		SessionID: c.SessionID,
+		ExtraData: c.ExtraData,
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+		IssuedMs:  c.IssuedMs,
+	}, nil
+}
+
+var b64 = base64.RawURLEncoding
@@ -0,0 +1,41 @@
+package token
+
+import "sort"
+
+// JSONWebKey JWKS 中的 Ed25519 公钥
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+// JWKS 验签公钥（当前密钥和保留的旧密钥），未使用 jwt-eddsa / paseto-v4 时为空
+// JWT 和 PASETO 共用同一把 Ed25519 密钥，两者的签名输入格式不同，不会互相伪造
+func (tm *TokenManager) JWKS() []JSONWebKey {
+	keys := make([]JSONWebKey, 0, len(tm.signing.private))
+	if !tm.publishJWKS {
+		return keys
+	}
+	for kid := range tm.signing.private {
+		pub, _ := tm.signing.public(kid)
+		keys = append(keys, JSONWebKey{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   b64.EncodeToString(pub),
+			Kid: kid,
+			Use: "sig",
+			Alg: "EdDSA",
+		})
+	}
+	// 当前密钥排在最前
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i].Kid == tm.signing.activeID) != (keys[j].Kid == tm.signing.activeID) {
+			return keys[i].Kid == tm.signing.activeID
+		}
+		return keys[i].Kid < keys[j].Kid
+	})
+	return keys
+}
@@ -0,0 +1,134 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"strings"
+)
+
+// jwtFormat JWT（HS256 或 EdDSA），只接受与格式一致的 alg，防止算法混淆
+type jwtFormat struct {
+	alg    string
+	hmac   *hmacKeys    // HS256
+	keys   *signingKeys // EdDSA
+	issuer string
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+type jwtClaims struct {
+	claims
+	IssuedAt  int64 `json:"iat"`
+	ExpiresAt int64 `json:"exp"`
+}
+
+func (f *jwtFormat) Name() string {
+	if f.alg == "HS256" {
+		return FormatJWTHS256
+	}
+	return FormatJWTEdDSA
+}
+
+func (f *jwtFormat) Encode(payload *TokenPayload) (string, error) {
+	header := jwtHeader{Alg: f.alg, Typ: "JWT", Kid: f.activeKid()}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(jwtClaims{
+		claims:    newClaims(payload, f.issuer),
+		IssuedAt:  payload.IssuedAt,
+		ExpiresAt: payload.ExpiresAt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := b64.EncodeToString(headerJSON) + "." + b64.EncodeToString(claimsJSON)
+	signature, ok := f.sign(header.Kid, []byte(signingInput))
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	return signingInput + "." + b64.EncodeToString(signature), nil
+}
+
+func (f *jwtFormat) Decode(token string) (*TokenPayload, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	headerJSON, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.Alg != f.alg {
+		return nil, ErrInvalidToken
+	}
+	signature, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !f.verify(header.Kid, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	claimsJSON, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var c jwtClaims
+	if err := json.Unmarshal(claimsJSON, &c); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return c.payload(f.issuer, c.IssuedAt, c.ExpiresAt)
+}
+
+// activeKid 签发使用的密钥ID
+func (f *jwtFormat) activeKid() string {
+	if f.alg == "HS256" {
+		kid, _ := f.hmac.active()
+		return kid
+	}
+	kid, _ := f.keys.active()
+	return kid
+}
+
+// sign 用 kid 对应的密钥签名；legacy 密钥只用于验证，不能签名
+func (f *jwtFormat) sign(kid string, signingInput []byte) ([]byte, bool) {
+	if f.alg == "HS256" {
+		secret, ok := f.hmac.keys[kid]
+		if !ok {
+			return nil, false
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signingInput)
+		return mac.Sum(nil), true
+	}
+	priv, ok := f.keys.private[kid]
+	if !ok {
+		return nil, false
+	}
+	return ed25519.Sign(priv, signingInput), true
+}
+
+// verify 按 kid 查找密钥验签（HS256 没有 kid 时使用 legacy 密钥）
+func (f *jwtFormat) verify(kid string, signingInput, signature []byte) bool {
+	if f.alg == "HS256" {
+		secret, ok := f.hmac.verifying(kid)
+		if !ok {
+			return false
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signingInput)
+		return hmac.Equal(signature, mac.Sum(nil))
+	}
+	pub, ok := f.keys.public(kid)
+	return ok && ed25519.Verify(pub, signingInput, signature)
+}
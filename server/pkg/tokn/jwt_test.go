@@ -0,0 +1,174 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJWTHS256KnownAnswer(t *testing.T) {
+	// RFC 7515 附录 A.1：没有 kid，只能用 legacy 密钥验证
+	key, err := b64.DecodeString("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const token = "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	f := &jwtFormat{alg: "HS256", hmac: &hmacKeys{keys: map[string][]byte{}, legacy: key}, issuer: "joe"}
+	p, err := f.Decode(token)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if p.ExpiresAt != 1300819380 {
+		t.Errorf("ExpiresAt = %d, 期望 1300819380", p.ExpiresAt)
+	}
+
+	// 未启用 legacy 密钥时拒绝
+	f.hmac.legacy = nil
+	if _, err := f.Decode(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("未启用 legacy 密钥时错误 = %v, 期望 ErrInvalidToken", err)
+	}
+
+	// 签发方不一致时拒绝
+	f = &jwtFormat{alg: "HS256", hmac: &hmacKeys{legacy: key}, issuer: testIssuer}
+	if _, err := f.Decode(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("iss 不一致时错误 = %v, 期望 ErrInvalidToken", err)
+	}
+}
+
+func TestJWTEdDSAKnownAnswer(t *testing.T) {
+	// RFC 8037 附录 A.4（Ed25519 签名是确定的，签名结果应逐字节一致）
+	seed, err := b64.DecodeString("nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv := ed25519.NewKeyFromSeed(seed)
+	if got := b64.EncodeToString(priv.Public().(ed25519.PublicKey)); got != "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo" {
+		t.Fatalf("公钥 = %s", got)
+	}
+	const (
+		signingInput = "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc"
+		signature    = "hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+	)
+
+	f := &jwtFormat{alg: "EdDSA", keys: &signingKeys{activeID: "rfc8037", private: map[string]ed25519.PrivateKey{"rfc8037": priv}}}
+	sig, ok := f.sign("rfc8037", []byte(signingInput))
+	if !ok || b64.EncodeToString(sig) != signature {
+		t.Errorf("签名 = %s, 期望 %s", b64.EncodeToString(sig), signature)
+	}
+	if !f.verify("rfc8037", []byte(signingInput), sig) {
+		t.Error("应验证通过")
+	}
+	if f.verify("other", []byte(signingInput), sig) {
+		t.Error("未知 kid 不应验证通过")
+	}
+	if f.verify("rfc8037", []byte(signingInput+"x"), sig) {
+		t.Error("篡改后不应验证通过")
+	}
+}
+
+// hs256Token 按 new_server 的方式签发：没有 kid，直接用原始密钥签名
+func hs256Token(secret []byte, header, claims string) string {
+	input := b64.EncodeToString([]byte(header)) + "." + b64.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return input + "." + b64.EncodeToString(mac.Sum(nil))
+}
+
+func TestJWTLegacySecret(t *testing.T) {
+	enc := testEncryptor(t)
+	legacy := []byte(testKey)
+	claims := `{"user_id":7,"username":"bob","iat":1700000000,"exp":4102444800}`
+	token := hs256Token(legacy, `{"alg":"HS256","typ":"JWT"}`, claims)
+
+	accepting := newManager(t, enc, legacy, FormatJWTHS256)
+	p, err := accepting.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("应接受 legacy 密钥签名的 token: %v", err)
+	}
+	if p.UserID != 7 || p.Username != "bob" || p.IssuedAt != 1700000000 {
+		t.Errorf("解析结果 = %+v", p)
+	}
+
+	if _, err := newManager(t, enc, nil, FormatJWTHS256).ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("未开启 acceptLegacyHS256 时错误 = %v, 期望 ErrInvalidToken", err)
+	}
+
+	// legacy 密钥只用于验证：签发的 token 都带密钥环的 kid，不带 kid 时不能签名
+	issued, err := accepting.GenerateTokenFromPayload(testPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(kidOf(t, FormatJWTHS256, issued), `"kid":"legacy"`) {
+		t.Errorf("签发的 token 应带 kid: %s", issued)
+	}
+	f := accepting.format.(*jwtFormat)
+	if _, ok := f.sign("", []byte("input")); ok {
+		t.Error("不应使用 legacy 密钥签名")
+	}
+
+	// 带 kid 时按 kid 查找，不回退到 legacy 密钥
+	withKid := hs256Token(legacy, `{"alg":"HS256","typ":"JWT","kid":"legacy"}`, claims)
+	if _, err := accepting.ValidateToken(withKid); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("带 kid 的 legacy 签名错误 = %v, 期望 ErrInvalidToken", err)
+	}
+}
+
+func TestJWTRejectsAlgorithmConfusion(t *testing.T) {
+	enc := testEncryptor(t)
+	hs256 := newManager(t, enc, nil, FormatJWTHS256)
+	eddsa := newManager(t, enc, nil, FormatJWTEdDSA)
+
+	hsToken, err := hs256.GenerateTokenFromPayload(testPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	edToken, err := eddsa.GenerateTokenFromPayload(testPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, claims, _ := strings.Cut(edToken, ".")
+	claims, _, _ = strings.Cut(claims, ".")
+
+	tests := []struct {
+		name  string
+		tm    *TokenManager
+		token string
+	}{
+		{"HS256 token 交给 EdDSA", eddsa, hsToken},
+		{"EdDSA token 交给 HS256", hs256, edToken},
+		{"alg none", eddsa, b64.EncodeToString([]byte(`{"alg":"none","kid":"legacy"}`)) + "." + claims + "."},
+		{"空签名", eddsa, b64.EncodeToString([]byte(`{"alg":"EdDSA","kid":"legacy"}`)) + "." + claims + "."},
+		{"段数不对", eddsa, "a.b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.tm.ValidateToken(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("错误 = %v, 期望 ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestJWTUnknownKid(t *testing.T) {
+	enc := testEncryptor(t)
+	for _, format := range []string{FormatJWTHS256, FormatJWTEdDSA} {
+		t.Run(format, func(t *testing.T) {
+			f := newManager(t, enc, nil, format).format.(*jwtFormat)
+			header := b64.EncodeToString([]byte(`{"alg":"` + f.alg + `","kid":"missing"}`))
+			claims := b64.EncodeToString([]byte(`{"user_id":1,"exp":4102444800}`))
+			sig, ok := f.sign(f.activeKid(), []byte(header+"."+claims))
+			if !ok {
+				t.Fatal("当前密钥应能签名")
+			}
+			if _, err := f.Decode(header + "." + claims + "." + b64.EncodeToString(sig)); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("未知 kid 错误 = %v, 期望 ErrInvalidToken", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// pasetoHeader PASETO v4.public 的版本和用途前缀
+const pasetoHeader = "v4.public."
+
+// pasetoFormat PASETO v4.public：Ed25519 对 PAE(header, message, footer, implicit) 签名，
+// 页脚中携带 kid；时间声明按规范使用 RFC 3339 字符串
+type pasetoFormat struct {
+	keys   *signingKeys
+	issuer string
+}
+
+type pasetoClaims struct {
+	claims
+	IssuedAt  string `json:"iat"`
+	ExpiresAt string `json:"exp"`
+}
+
+type pasetoFooter struct {
+	Kid string `json:"kid"`
+}
+
+func (f *pasetoFormat) Name() string { return FormatPASETOv4 }
+
+func (f *pasetoFormat) Encode(payload *TokenPayload) (string, error) {
+	kid, priv := f.keys.active()
+
+	message, err := json.Marshal(pasetoClaims{
+		claims:    newClaims(payload, f.issuer),
+		IssuedAt:  time.Unix(payload.IssuedAt, 0).UTC().Format(time.RFC3339),
+		ExpiresAt: time.Unix(payload.ExpiresAt, 0).UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", err
+	}
+	footer, err := json.Marshal(pasetoFooter{Kid: kid})
+	if err != nil {
+		return "", err
+	}
+	return pasetoSign(priv, message, footer), nil
+}
+
+func (f *pasetoFormat) Decode(token string) (*TokenPayload, error) {
+	message, err := pasetoOpen(token, func(footer []byte) (ed25519.PublicKey, bool) {
+		var ft pasetoFooter
+		if err := json.Unmarshal(footer, &ft); err != nil {
+			return nil, false
+		}
+		return f.keys.public(ft.Kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var c pasetoClaims
+	if err := json.Unmarshal(message, &c); err != nil {
+		return nil, ErrInvalidToken
+	}
+	issuedAt, err := time.Parse(time.RFC3339, c.IssuedAt)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	expiresAt, err := time.Parse(time.RFC3339, c.ExpiresAt)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return c.payload(f.issuer, issuedAt.Unix(), expiresAt.Unix())
+}
+
+// pasetoSign 生成 v4.public token：签名附在消息之后，页脚为空时省略
+func pasetoSign(priv ed25519.PrivateKey, message, footer []byte) string {
+	signature := ed25519.Sign(priv, pae([]byte(pasetoHeader), message, footer, nil))
+	body := make([]byte, 0, len(message)+len(signature))
+	body = append(append(body, message...), signature...)
+	token := pasetoHeader + b64.EncodeToString(body)
+	if len(footer) > 0 {
+		token += "." + b64.EncodeToString(footer)
+	}
+	return token
+}
+
+// pasetoOpen 校验 v4.public token 并返回消息；publicKey 按页脚（如其中的 kid）选择验签公钥
+func pasetoOpen(token string, publicKey func(footer []byte) (ed25519.PublicKey, bool)) ([]byte, error) {
+	if !strings.HasPrefix(token, pasetoHeader) {
+		return nil, ErrInvalidToken
+	}
+	encodedBody, encodedFooter, _ := strings.Cut(strings.TrimPrefix(token, pasetoHeader), ".")
+
+	body, err := b64.DecodeString(encodedBody)
+	if err != nil || len(body) < ed25519.SignatureSize {
+		return nil, ErrInvalidToken
+	}
+	footer, err := b64.DecodeString(encodedFooter)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	pub, ok := publicKey(footer)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	message := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(pub, pae([]byte(pasetoHeader), message, footer, nil), signature) {
+		return nil, ErrInvalidToken
+	}
+	return message, nil
+}
+
+// pae PASETO 的预认证编码：片段数量和每个片段的长度都用 64 位小端整数表示（最高位清零）
+func pae(pieces ...[]byte) []byte {
+	buf := le64(len(pieces))
+	for _, p := range pieces {
+		buf = append(buf, le64(len(p))...)
+		buf = append(buf, p...)
+	}
+	return buf
+}
+
+func le64(n int) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(n)&(1<<63-1))
+	return b
+}
@@ -0,0 +1,138 @@
+package token
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPAE(t *testing.T) {
+	// PASETO 规范 Common.md 中的 PAE 示例
+	tests := []struct {
+		name   string
+		pieces [][]byte
+		want   string
+	}{
+		{"无片段", nil, "\x00\x00\x00\x00\x00\x00\x00\x00"},
+		{"一个空片段", [][]byte{{}}, "\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"},
+		{"test", [][]byte{[]byte("test")}, "\x01\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pae(tt.pieces...); !bytes.Equal(got, []byte(tt.want)) {
+				t.Errorf("pae = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+
+	// 长度的最高位清零
+	if got := le64(-1); got[7] != 0x7f {
+		t.Errorf("le64(-1) = %x, 最高位应清零", got)
+	}
+}
+
+func TestPASETOv4PublicVectors(t *testing.T) {
+	// PASETO 官方测试向量 v4.json 中的 4-S-1 / 4-S-2（implicit assertion 为空）
+	secret, _ := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" +
+		"1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	priv := ed25519.PrivateKey(secret)
+	pub, _ := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	if !bytes.Equal(priv.Public().(ed25519.PublicKey), pub) {
+		t.Fatal("公钥与私钥不匹配")
+	}
+	const payload = `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`
+
+	tests := []struct {
+		name   string
+		footer string
+		token  string
+	}{
+		{
+			"4-S-1", "",
+			"v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+				"bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA",
+		},
+		{
+			"4-S-2", `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`,
+			"v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+				"v3Jt8mx_TdM2ceTGoqwrh4yDFn0XsHvvV_D0DtwQxVrJEBMl0F2caAdgnpKlt4p7xBnx1HcO-SPo8FPp214HDw" +
+				".eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pasetoSign(priv, []byte(payload), []byte(tt.footer)); got != tt.token {
+				t.Errorf("签名结果 = %s\n期望 %s", got, tt.token)
+			}
+
+			var gotFooter []byte
+			message, err := pasetoOpen(tt.token, func(footer []byte) (ed25519.PublicKey, bool) {
+				gotFooter = footer
+				return pub, true
+			})
+			if err != nil {
+				t.Fatalf("pasetoOpen: %v", err)
+			}
+			if string(message) != payload || string(gotFooter) != tt.footer {
+				t.Errorf("消息 = %s, 页脚 = %s", message, gotFooter)
+			}
+
+			// 签名覆盖页脚：替换页脚后验签失败
+			forged := strings.SplitN(tt.token, ".", 4)[:3]
+			forgedToken := strings.Join(forged, ".") + "." + b64.EncodeToString([]byte(`{"kid":"other"}`))
+			if _, err := pasetoOpen(forgedToken, func([]byte) (ed25519.PublicKey, bool) { return pub, true }); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("替换页脚后错误 = %v, 期望 ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestPASETOOpenRejects(t *testing.T) {
+	tm := newTestManager(t, FormatPASETOv4)
+	tok, err := tm.GenerateTokenFromPayload(testPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, footer, _ := strings.Cut(strings.TrimPrefix(tok, pasetoHeader), ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"其他版本", "v3.public." + body + "." + footer},
+		{"local 用途", "v4.local." + body + "." + footer},
+		{"未知 kid", pasetoHeader + body + "." + b64.EncodeToString([]byte(`{"kid":"missing"}`))},
+		{"缺少页脚", pasetoHeader + body},
+		{"消息过短", pasetoHeader + b64.EncodeToString([]byte("short")) + "." + footer},
+		{"非 base64url", pasetoHeader + "!!!." + footer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tm.ValidateToken(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("错误 = %v, 期望 ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestPASETOClaimsTimes(t *testing.T) {
+	// iat / exp 按规范为 RFC 3339 字符串
+	tm := newTestManager(t, FormatPASETOv4)
+	p := testPayload()
+	tok, err := tm.GenerateTokenFromPayload(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _, _ := strings.Cut(strings.TrimPrefix(tok, pasetoHeader), ".")
+	raw, err := b64.DecodeString(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := string(raw[:len(raw)-ed25519.SignatureSize])
+	if !strings.Contains(message, `"iat":"20`) || !strings.Contains(message, `"exp":"20`) || !strings.Contains(message, `Z"`) {
+		t.Errorf("时间声明不是 RFC 3339 字符串: %s", message)
+	}
+}
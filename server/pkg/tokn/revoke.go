@@ -0,0 +1,106 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SetRevocationStore 设置吊销记录存储，未设置时不做吊销检查
+func (tm *TokenManager) SetRevocationStore(rdb *redis.Client) {
+	tm.revocations = rdb
+}
+
+// revokeKey 设备吊销记录的 Redis key
+func revokeKey(userID uint, deviceID string) string {
+	return fmt.Sprintf("token_revoked:%d:%s", userID, deviceID)
+}
+
+// RevokeDevice 吊销设备在此刻之前签发的所有 token（吊销时刻以毫秒记录）
+// 记录保留一个 token 有效期，之后旧 token 自然过期
+func (tm *TokenManager) RevokeDevice(userID uint, deviceID string) error {
+	if tm.revocations == nil || deviceID == "" {
+		return nil
+	}
+	ttl := time.Duration(tm.validityDate) * time.Minute
+	return tm.revocations.Set(context.Background(), revokeKey(userID, deviceID), time.Now().UnixMilli(), ttl).Err()
+}
+
+// IsDeviceRevoked 判断设备在 issuedAtMs（毫秒）签发的 token 是否已被吊销
+// 吊销记录读取失败时返回错误，调用方应拒绝该 token（Redis 不可用时不能放行已吊销的 token）
+func (tm *TokenManager) IsDeviceRevoked(userID uint, deviceID string, issuedAtMs int64) (bool, error) {
+	if tm.revocations == nil || deviceID == "" {
+		return false, nil
+	}
+	revokedAt, err := tm.revocations.Get(context.Background(), revokeKey(userID, deviceID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return issuedBefore(issuedAtMs, revokedAt), nil
+}
+
+// issuedBefore token 是否在吊销时刻之前（含同一时刻）签发
+// 升级前的吊销记录以秒存储，按该秒的最后一毫秒比较
+func issuedBefore(issuedAtMs, revokedAt int64) bool {
+	if revokedAt < 1e12 {
+		revokedAt = revokedAt*1000 + 999
+	}
+	return issuedAtMs <= revokedAt
+}
+
+// sessionRevokeKey 会话吊销记录的 Redis key
+func sessionRevokeKey(sessionID string) string {
+	return fmt.Sprintf("session_revoked:%s", sessionID)
+}
+
+// sessionSeenKey 会话最后使用时间的 Redis key
+func sessionSeenKey(sessionID string) string {
+	return fmt.Sprintf("session_seen:%s", sessionID)
+}
+
+// RevokeSession 吊销会话，该会话签发的访问 token 立即失效
+// 记录保留一个访问 token 有效期即可，刷新令牌由数据库中的会话状态控制
+func (tm *TokenManager) RevokeSession(sessionID string) error {
+	if tm.revocations == nil || sessionID == "" {
+		return nil
+	}
+	return tm.revocations.Set(context.Background(), sessionRevokeKey(sessionID), time.Now().Unix(), tm.AccessTTL()).Err()
+}
+
+// IsSessionRevoked 判断会话是否已吊销，读取失败时返回错误（调用方应拒绝该 token）
+func (tm *TokenManager) IsSessionRevoked(sessionID string) (bool, error) {
+	if tm.revocations == nil || sessionID == "" {
+		return false, nil
+	}
+	n, err := tm.revocations.Exists(context.Background(), sessionRevokeKey(sessionID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// TouchSession 记录会话最后使用时间（每次请求调用，只写 Redis）
+func (tm *TokenManager) TouchSession(sessionID string) {
+	if tm.revocations == nil || sessionID == "" {
+		return
+	}
+	tm.revocations.Set(context.Background(), sessionSeenKey(sessionID), time.Now().Unix(), tm.RefreshTTL())
+}
+
+// SessionLastSeen 获取会话最后使用时间，没有记录时返回零值
+func (tm *TokenManager) SessionLastSeen(sessionID string) time.Time {
+	if tm.revocations == nil || sessionID == "" {
+		return time.Time{}
+	}
+	ts, err := tm.revocations.Get(context.Background(), sessionSeenKey(sessionID)).Int64()
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0)
+}
@@ -0,0 +1,82 @@
+package token
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestIssuedBefore(t *testing.T) {
+	const revokedMs = 1700000000500
+	tests := []struct {
+		name      string
+		issuedAt  int64
+		revokedAt int64
+		want      bool
+	}{
+		{"吊销之前签发", revokedMs - 1, revokedMs, true},
+		{"同一毫秒签发", revokedMs, revokedMs, true},
+		{"同一秒内吊销之后签发", revokedMs + 1, revokedMs, false},
+		{"旧记录以秒存储，同一秒内签发按已吊销处理", 1700000000999, 1700000000, true},
+		{"旧记录以秒存储，下一秒签发", 1700000001000, 1700000000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := issuedBefore(tt.issuedAt, tt.revokedAt); got != tt.want {
+				t.Errorf("issuedBefore(%d, %d) = %v, 期望 %v", tt.issuedAt, tt.revokedAt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssuedAtMilli(t *testing.T) {
+	p := &TokenPayload{IssuedAt: 1700000000, IssuedMs: 1700000000123}
+	if got := p.IssuedAtMilli(); got != 1700000000123 {
+		t.Errorf("IssuedAtMilli() = %d, 期望 1700000000123", got)
+	}
+	// 升级前签发的 token 没有毫秒时间
+	p.IssuedMs = 0
+	if got := p.IssuedAtMilli(); got != 1700000000000 {
+		t.Errorf("IssuedAtMilli() = %d, 期望 1700000000000", got)
+	}
+}
+
+func TestIssuedMsRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatEncrypted, FormatJWTHS256, FormatJWTEdDSA, FormatPASETOv4} {
+		t.Run(format, func(t *testing.T) {
+			tm := newTestManager(t, format)
+			tok, err := tm.GenerateTokenFromPayload(&TokenPayload{UserID: 1, Username: "alice", DeviceID: "d1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := tm.ValidateToken(tok)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.IssuedMs == 0 || p.IssuedMs/1000 != p.IssuedAt {
+				t.Errorf("毫秒签发时间 = %d, 签发时间 = %d", p.IssuedMs, p.IssuedAt)
+			}
+		})
+	}
+}
+
+func TestValidateTokenRevocationUnavailable(t *testing.T) {
+	tm := newTestManager(t, FormatEncrypted)
+	tok, err := tm.GenerateTokenFromPayload(&TokenPayload{UserID: 1, Username: "alice", DeviceID: "d1", SessionID: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 无法连接的 Redis：吊销记录读取失败时必须拒绝，不能按未吊销放行
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer rdb.Close()
+	tm.SetRevocationStore(rdb)
+
+	if _, err := tm.ValidateToken(tok); !errors.Is(err, ErrRevocationUnavailable) {
+		t.Errorf("ValidateToken 错误 = %v, 期望 ErrRevocationUnavailable", err)
+	}
+	if _, err := tm.IsSessionRevoked("s1"); err == nil {
+		t.Error("IsSessionRevoked 应返回 Redis 错误")
+	}
+}
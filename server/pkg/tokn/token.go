@@ -1,12 +1,12 @@
 package token
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	_venv "github.com/sunyuanling/server/venv"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/sunyuanling/server/config"
 	"github.com/sunyuanling/server/encryption"
 )
@@ -14,6 +14,9 @@ import (
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrExpiredToken = errors.New("token has expired")
+	ErrRevokedToken = errors.New("token has been revoked")
+	// ErrRevocationUnavailable 无法读取吊销记录，token 按已吊销处理
+	ErrRevocationUnavailable = errors.New("token revocation status unavailable")
 )
 
 // token 全局单例
@@ -36,8 +39,14 @@ func GetGlobalTokenManager() *TokenManager {
 
 // TokenManager Token 管理器
 type TokenManager struct {
-	encryptor    *encryption.Encryptor
-	validityDate int // 有效期（分钟）
+	encryptor       *encryption.Encryptor
+	format          Format        // 签发使用的格式
+	accepted        []Format      // 验证时接受的格式（签发格式 + 迁移期内的旧格式）
+	signing         *signingKeys  // Ed25519 签名密钥（JWT EdDSA / PASETO）
+	publishJWKS     bool          // 使用了非对称格式时发布公钥
+	validityDate    int           // 有效期（分钟）
+	refreshValidity time.Duration // 刷新令牌（会话）有效期
+	revocations     *redis.Client // 设备/会话吊销记录
 }
 
 // TokenPayload Token 载荷
@@ -46,54 +55,85 @@ type TokenPayload struct {
 	Username  string                 `json:"username"`
 	Email     string                 `json:"email,omitempty"`
 	Roles     []string               `json:"roles,omitempty"`
+	DeviceID  string                 `json:"device_id,omitempty"` // 绑定设备的token（登录或配对时提供了设备标识）对应的设备标识
+	SessionID string                 `json:"sid,omitempty"`       // 登录会话ID，会话注销后token失效
 	ExtraData map[string]interface{} `json:"extra_data,omitempty"`
-	IssuedAt  int64                  `json:"issued_at"`  // 签发时间戳
-	ExpiresAt int64                  `json:"expires_at"` // 过期时间戳
+	IssuedAt  int64                  `json:"issued_at"`              // 签发时间戳
+	ExpiresAt int64                  `json:"expires_at"`             // 过期时间戳
+	IssuedMs  int64                  `json:"issued_at_ms,omitempty"` // 签发时间（毫秒），与吊销时刻比较
+}
+
+// IssuedAtMilli 签发时间（毫秒），升级前签发的 token 没有毫秒时间，取该秒的开始
+func (p *TokenPayload) IssuedAtMilli() int64 {
+	if p.IssuedMs != 0 {
+		return p.IssuedMs
+	}
+	return p.IssuedAt * 1000
 }
 
 // NewTokenManager 创建 Token 管理器
 func NewTokenManager(cfg *config.Config) (*TokenManager, error) {
-	// 获取加密密钥
-	encryptionKey := _venv.GetEncryptionKey()
-
-	// 创建加密器
-	encryptor, err := encryption.NewEncryptor(encryptionKey)
+	// 创建加密器（配置了密钥环时支持多版本密钥，否则只使用 key.yaml 中的密钥）
+	encryptor, err := encryption.LoadEncryptor(_venv.GetEncryptionKey(), cfg.Security.KeyringFile)
 	if err != nil {
 		return nil, fmt.Errorf("创建加密器失败: %w", err)
 	}
 
-	return &TokenManager{
-		encryptor:    encryptor,
-		validityDate: cfg.Token.ValidityDate,
-	}, nil
-}
-
-// GenerateToken 生成 Token（从 JSON 数据）
-func (tm *TokenManager) GenerateToken(jsonData string) (string, error) {
-	// 解析 JSON 到 map
-	var data map[string]interface{}
-	if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
-		return "", fmt.Errorf("解析 JSON 失败: %w", err)
+	refreshHours := cfg.JWT.RefreshExpireHours
+	if refreshHours <= 0 {
+		refreshHours = 720
 	}
 
-	// 添加时间戳
-	now := time.Now()
-	data["issued_at"] = now.Unix()
-	data["expires_at"] = now.Add(time.Duration(tm.validityDate) * time.Minute).Unix()
+	tm := &TokenManager{
+		encryptor:       encryptor,
+		signing:         newSigningKeys(encryptor),
+		validityDate:    cfg.Token.ValidityDate,
+		refreshValidity: time.Duration(refreshHours) * time.Hour,
+	}
 
-	// 序列化为 JSON
-	jsonBytes, err := json.Marshal(data)
-	if err != nil {
-		return "", fmt.Errorf("序列化 JSON 失败: %w", err)
+	// 签发格式和迁移期内额外接受的格式；HS256 的密钥由密钥环派生，
+	// 开启 acceptLegacyHS256 时额外接受 new_server 以 key.yaml 原始密钥签名的 token
+	var legacySecret []byte
+	if cfg.Token.AcceptLegacyHS256 {
+		legacySecret = []byte(_venv.GetEncryptionKey())
 	}
+	hmacKeys := newHMACKeys(encryptor, legacySecret)
+	names := append([]string{cfg.Token.Format}, cfg.Token.Accept...)
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		f, err := newFormat(name, encryptor, tm.signing, hmacKeys, cfg.Token.Issuer)
+		if err != nil {
+			return nil, err
+		}
+		tm.accepted = append(tm.accepted, f)
+		tm.publishJWKS = tm.publishJWKS || isAsymmetric(name)
+	}
+	tm.format = tm.accepted[0]
 
-	// 加密生成 Token
-	token, err := tm.encryptor.Encrypt(string(jsonBytes))
-	if err != nil {
-		return "", fmt.Errorf("加密失败: %w", err)
+	return tm, nil
+}
+
+// Format 签发使用的 token 格式
+func (tm *TokenManager) Format() string {
+	return tm.format.Name()
+}
+
+// AcceptedFormats 验证时接受的 token 格式
+func (tm *TokenManager) AcceptedFormats() []string {
+	names := make([]string, 0, len(tm.accepted))
+	for _, f := range tm.accepted {
+		names = append(names, f.Name())
 	}
+	return names
+}
 
-	return token, nil
+// Encryptor token 使用的加密器（两步验证密钥等也使用同一密钥环加密）
+func (tm *TokenManager) Encryptor() *encryption.Encryptor {
+	return tm.encryptor
 }
 
 // GenerateTokenFromPayload 从 Payload 生成 Token
@@ -101,50 +141,23 @@ func (tm *TokenManager) GenerateTokenFromPayload(payload *TokenPayload) (string,
 	// 设置时间戳
 	now := time.Now()
 	payload.IssuedAt = now.Unix()
+	payload.IssuedMs = now.UnixMilli()
 	payload.ExpiresAt = now.Add(time.Duration(tm.validityDate) * time.Minute).Unix()
 
-	// 序列化为 JSON
-	jsonBytes, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("序列化失败: %w", err)
-	}
-
-	// 加密生成 Token
-	token, err := tm.encryptor.Encrypt(string(jsonBytes))
-	if err != nil {
-		return "", fmt.Errorf("加密失败: %w", err)
-	}
-
-	return token, nil
+	// 按配置的格式编码
+	return tm.format.Encode(payload)
 }
 
-// GenerateTokenFromMap 从 Map 生成 Token
-func (tm *TokenManager) GenerateTokenFromMap(data map[string]interface{}) (string, error) {
-	// 添加时间戳
-	now := time.Now()
-	data["issued_at"] = now.Unix()
-	data["expires_at"] = now.Add(time.Duration(tm.validityDate) * time.Minute).Unix()
-
-	// 加密生成 Token
-	token, err := tm.encryptor.EncryptMap(data)
-	if err != nil {
-		return "", fmt.Errorf("加密失败: %w", err)
-	}
-
-	return token, nil
-}
-
-// ValidateToken 验证并解析 Token
+// ValidateToken 验证并解析 Token（依次尝试接受的格式）
 func (tm *TokenManager) ValidateToken(token string) (*TokenPayload, error) {
-	// 解密 Token
-	plaintext, err := tm.encryptor.Decrypt(token)
-	if err != nil {
-		return nil, ErrInvalidToken
-	}
-
-	// 解析为 Payload
-	var payload TokenPayload
-	if err := json.Unmarshal([]byte(plaintext), &payload); err != nil {
+	var payload *TokenPayload
+	for _, f := range tm.accepted {
+		if p, err := f.Decode(token); err == nil {
+			payload = p
+			break
+		}
+	}
+	if payload == nil {
 		return nil, ErrInvalidToken
 	}
 
@@ -153,40 +166,35 @@ func (tm *TokenManager) ValidateToken(token string) (*TokenPayload, error) {
 		return nil, ErrExpiredToken
 	}
 
-	return &payload, nil
-}
-
-// ValidateTokenToMap 验证并解析 Token 为 Map
-func (tm *TokenManager) ValidateTokenToMap(token string) (map[string]interface{}, error) {
-	// 解密 Token
-	data, err := tm.encryptor.DecryptToMap(token)
+	// 设备绑定的token检查是否已被吊销；吊销记录读取失败时拒绝
+	revoked, err := tm.IsDeviceRevoked(uint(payload.UserID), payload.DeviceID, payload.IssuedAtMilli())
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, fmt.Errorf("%w: %v", ErrRevocationUnavailable, err)
 	}
-
-	// 验证过期时间
-	expiresAt, ok := data["expires_at"].(float64) // JSON 数字默认是 float64
-	if !ok {
-		return nil, ErrInvalidToken
+	if revoked {
+		return nil, ErrRevokedToken
 	}
 
-	if time.Now().Unix() > int64(expiresAt) {
-		return nil, ErrExpiredToken
+	// 所属会话已注销
+	revoked, err = tm.IsSessionRevoked(payload.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRevocationUnavailable, err)
+	}
+	if revoked {
+		return nil, ErrRevokedToken
 	}
 
-	return data, nil
+	return payload, nil
 }
 
-// RefreshToken 刷新 Token（延长有效期）
-func (tm *TokenManager) RefreshToken(oldToken string) (string, error) {
-	// 解析旧 Token
-	payload, err := tm.ValidateToken(oldToken)
-	if err != nil {
-		return "", err
-	}
+// AccessTTL 访问 token 有效期
+func (tm *TokenManager) AccessTTL() time.Duration {
+	return time.Duration(tm.validityDate) * time.Minute
+}
 
-	// 生成新 Token（自动更新时间戳）
-	return tm.GenerateTokenFromPayload(payload)
+// RefreshTTL 刷新令牌（会话）有效期
+func (tm *TokenManager) RefreshTTL() time.Duration {
+	return tm.refreshValidity
 }
 
 // GetRemainingTime 获取 Token 剩余有效时间（秒）
@@ -0,0 +1,208 @@
+package token
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sunyuanling/server/encryption"
+)
+
+// 测试用的 AES-256 密钥
+var (
+	testKey    = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	rotatedKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("r", 32)))
+)
+
+const testIssuer = "filesync-test"
+
+var allFormats = []string{FormatEncrypted, FormatJWTHS256, FormatJWTEdDSA, FormatPASETOv4}
+
+func testEncryptor(t *testing.T) *encryption.Encryptor {
+	t.Helper()
+	enc, err := encryption.NewEncryptor(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enc
+}
+
+// newManager 按 NewTokenManager 的方式组装，不读取 key.yaml；legacySecret 非空时接受没有 kid 的 HS256 token
+func newManager(t *testing.T, enc *encryption.Encryptor, legacySecret []byte, format string, accept ...string) *TokenManager {
+	t.Helper()
+	tm := &TokenManager{encryptor: enc, signing: newSigningKeys(enc), validityDate: 60}
+	hs256 := newHMACKeys(enc, legacySecret)
+	for _, name := range append([]string{format}, accept...) {
+		f, err := newFormat(name, enc, tm.signing, hs256, testIssuer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tm.accepted = append(tm.accepted, f)
+	}
+	tm.format = tm.accepted[0]
+	return tm
+}
+
+func newTestManager(t *testing.T, format string, accept ...string) *TokenManager {
+	t.Helper()
+	return newManager(t, testEncryptor(t), nil, format, accept...)
+}
+
+func testPayload() *TokenPayload {
+	return &TokenPayload{
+		UserID:    42,
+		Username:  "alice",
+		Email:     "alice@example.com",
+		Roles:     []string{"admin", "user"},
+		DeviceID:  "device-1",
+		SessionID: "session-1",
+		ExtraData: map[string]interface{}{"api_key_id": float64(7)},
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, format := range allFormats {
+		t.Run(format, func(t *testing.T) {
+			tm := newTestManager(t, format)
+			want := testPayload()
+			tok, err := tm.GenerateTokenFromPayload(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tm.ValidateToken(tok)
+			if err != nil {
+				t.Fatalf("ValidateToken: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("解析结果 = %+v, 期望 %+v", got, want)
+			}
+
+			// 篡改任意一个字符都应被拒绝
+			tampered := []byte(tok)
+			i := len(tampered) / 2
+			if tampered[i] == 'A' {
+				tampered[i] = 'B'
+			} else {
+				tampered[i] = 'A'
+			}
+			if _, err := tm.ValidateToken(string(tampered)); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("篡改后的 token 错误 = %v, 期望 ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestValidateTokenExpired(t *testing.T) {
+	for _, format := range allFormats {
+		t.Run(format, func(t *testing.T) {
+			tm := newTestManager(t, format)
+			tm.validityDate = -1
+			tok, err := tm.GenerateTokenFromPayload(testPayload())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tm.ValidateToken(tok); !errors.Is(err, ErrExpiredToken) {
+				t.Errorf("ValidateToken 错误 = %v, 期望 ErrExpiredToken", err)
+			}
+		})
+	}
+}
+
+func TestMigrationWindow(t *testing.T) {
+	// 同一密钥环下，任一格式签发的 token 在新格式 + accept 旧格式的管理器中都能验证；
+	// 未列入 accept 的格式不被接受
+	enc := testEncryptor(t)
+	for _, from := range allFormats {
+		issued, err := newManager(t, enc, nil, from).GenerateTokenFromPayload(testPayload())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, to := range allFormats {
+			t.Run(from+"->"+to, func(t *testing.T) {
+				migrating := newManager(t, enc, nil, to, from)
+				if _, err := migrating.ValidateToken(issued); err != nil {
+					t.Errorf("迁移期内应接受 %s token: %v", from, err)
+				}
+				if from == to {
+					return
+				}
+				if _, err := newManager(t, enc, nil, to).ValidateToken(issued); !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("未接受的 %s token 错误 = %v, 期望 ErrInvalidToken", from, err)
+				}
+			})
+		}
+	}
+}
+
+func TestNewFormatUnknown(t *testing.T) {
+	enc := testEncryptor(t)
+	if _, err := newFormat("jwt-rs256", enc, newSigningKeys(enc), newHMACKeys(enc, nil), testIssuer); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("错误 = %v, 期望 ErrUnknownFormat", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	// 轮换前用 legacy 密钥签发
+	before := newTestManager(t, FormatEncrypted)
+	beforeTokens := map[string]string{}
+	for _, format := range allFormats {
+		tm := newManager(t, before.encryptor, nil, format)
+		tok, err := tm.GenerateTokenFromPayload(testPayload())
+		if err != nil {
+			t.Fatal(err)
+		}
+		beforeTokens[format] = tok
+	}
+
+	rotate := func(legacyStatus string) *encryption.Encryptor {
+		enc, err := encryption.NewKeyringEncryptor(testKey, &encryption.Keyring{Keys: []encryption.KeyEntry{
+			{ID: encryption.LegacyKeyID, Status: legacyStatus},
+			{ID: "k2", Key: rotatedKey, Status: encryption.KeyStatusActive},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return enc
+	}
+
+	for _, format := range allFormats {
+		t.Run(format, func(t *testing.T) {
+			// 旧密钥保留期间按 kid 找到旧密钥验证，新 token 使用新 kid
+			retained := newManager(t, rotate(encryption.KeyStatusRetained), nil, format)
+			if _, err := retained.ValidateToken(beforeTokens[format]); err != nil {
+				t.Errorf("旧密钥保留期间应能验证: %v", err)
+			}
+			tok, err := retained.GenerateTokenFromPayload(testPayload())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != FormatEncrypted && !strings.Contains(kidOf(t, format, tok), `"kid":"k2"`) {
+				t.Errorf("新 token 应使用新密钥的 kid: %s", tok)
+			}
+
+			// 旧密钥停用后，用它签发的 token 全部失效
+			retired := newManager(t, rotate(encryption.KeyStatusRetired), nil, format)
+			if _, err := retired.ValidateToken(beforeTokens[format]); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("旧密钥停用后错误 = %v, 期望 ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+// kidOf 解码 JWT 头或 PASETO 页脚（其中带 kid）
+func kidOf(t *testing.T, format, tok string) string {
+	t.Helper()
+	var encoded string
+	if format == FormatPASETOv4 {
+		_, encoded, _ = strings.Cut(strings.TrimPrefix(tok, pasetoHeader), ".")
+	} else {
+		encoded, _, _ = strings.Cut(tok, ".")
+	}
+	data, err := b64.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
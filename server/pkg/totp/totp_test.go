@@ -0,0 +1,120 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret RFC 6238 附录 B 的 SHA1 测试密钥 "12345678901234567890"（Base32）
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeAtRFC6238(t *testing.T) {
+	// RFC 6238 附录 B 的 8 位验证码取后 6 位（截断值对 10^6 取模）
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := CodeAt(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("T=%d: 验证码 = %s, 期望 %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeAtSecretFormat(t *testing.T) {
+	// 认证器显示的密钥可能是小写或带空白
+	got, err := CodeAt(" "+strings.ToLower(rfcSecret)+"\n", 1)
+	if err != nil || got != "287082" {
+		t.Errorf("CodeAt = %q, %v", got, err)
+	}
+	if _, err := CodeAt("not base32!", 1); err == nil {
+		t.Error("无效密钥应返回错误")
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	for offset := int64(-Skew); offset <= Skew; offset++ {
+		code, _ := CodeAt(rfcSecret, current+offset)
+		step, ok := Validate(rfcSecret, code, now)
+		if !ok || step != current+offset {
+			t.Errorf("偏差 %d 步: Validate = %d, %v, 期望 %d, true", offset, step, ok, current+offset)
+		}
+	}
+
+	// 超出允许偏差的验证码
+	for _, offset := range []int64{-Skew - 1, Skew + 1} {
+		code, _ := CodeAt(rfcSecret, current+offset)
+		if _, ok := Validate(rfcSecret, code, now); ok {
+			t.Errorf("偏差 %d 步的验证码不应通过", offset)
+		}
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	now := time.Unix(59, 0)
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"287082", true},
+		{" 287 082 ", true}, // 允许空格分组
+		{"287083", false},
+		{"28708", false},
+		{"2870820", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if _, ok := Validate(rfcSecret, tt.code, now); ok != tt.want {
+			t.Errorf("Validate(%q) = %v, 期望 %v", tt.code, ok, tt.want)
+		}
+	}
+	if _, ok := Validate("not base32!", "287082", now); ok {
+		t.Error("无效密钥不应通过")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := b32.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Errorf("密钥 %q 应为 160 位 Base32: %v", secret, err)
+	}
+	if other, _ := GenerateSecret(); other == secret {
+		t.Error("两次生成的密钥相同")
+	}
+}
+
+func TestURI(t *testing.T) {
+	u, err := url.Parse(URI("FileSync", "alice@example.com", rfcSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/FileSync:alice@example.com" {
+		t.Errorf("URI = %s", u)
+	}
+	q := u.Query()
+	for k, want := range map[string]string{"secret": rfcSecret, "issuer": "FileSync", "algorithm": "SHA1", "digits": "6", "period": "30"} {
+		if got := q.Get(k); got != want {
+			t.Errorf("%s = %q, 期望 %q", k, got, want)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/pkg/logger"
+)
+
+const (
+	queueSize      = 4096             // 等待导出的 span 上限，满了之后丢弃
+	batchSize      = 512              // 单次导出的 span 数
+	flushInterval  = 5 * time.Second  // 不足一批时的导出间隔
+	exportTimeout  = 10 * time.Second // 单次导出的超时
+	shutdownWait   = 5 * time.Second  // 退出时导出剩余 span 的最长时间
+	tracesPath     = "/v1/traces"
+	scopeName      = "github.com/sunyuanling/server"
+	statusCodeErr  = 2
+	maxErrorLength = 1024
+)
+
+// exporter 批量通过 OTLP/HTTP JSON 导出 span
+type exporter struct {
+	url     string
+	headers map[string]string
+	service string
+	client  *http.Client
+
+	queue   chan *Span
+	dropped atomic.Int64
+	failing bool // 只在导出协程中使用，连续失败时只记录一次日志
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newExporter(cfg config.TracingConfig) *exporter {
+	url := strings.TrimRight(cfg.Endpoint, "/")
+	if !strings.HasSuffix(url, tracesPath) {
+		url += tracesPath
+	}
+	e := &exporter{
+		url:     url,
+		headers: cfg.Headers,
+		service: cfg.ServiceName,
+		client:  &http.Client{Timeout: exportTimeout},
+		queue:   make(chan *Span, queueSize),
+		done:    make(chan struct{}),
+	}
+	e.wg.Add(1)
+	go e.run()
+	return e
+}
+
+// enqueue 提交导出，队列满时丢弃，不阻塞请求
+func (e *exporter) enqueue(s *Span) {
+	select {
+	case e.queue <- s:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// shutdown 停止导出协程，导出队列中剩余的 span
+func (e *exporter) shutdown() {
+	close(e.done)
+	e.wg.Wait()
+}
+
+func (e *exporter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	batch := make([]*Span, 0, batchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) > 0 {
+			e.export(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case s := <-e.queue:
+			batch = append(batch, s)
+			if len(batch) >= batchSize {
+				flush(context.Background())
+			}
+		case <-ticker.C:
+			flush(context.Background())
+		case <-e.done:
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownWait)
+			defer cancel()
+			for {
+				select {
+				case s := <-e.queue:
+					batch = append(batch, s)
+					if len(batch) >= batchSize {
+						flush(ctx)
+					}
+				default:
+					flush(ctx)
+					if n := e.dropped.Load(); n > 0 {
+						logger.Warn("链路追踪队列已满，部分 span 被丢弃", zap.Int64("dropped", n))
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// export 发送一批 span，失败时丢弃这一批（追踪数据不重试，避免积压影响服务）
+func (e *exporter) export(ctx context.Context, batch []*Span) {
+	body, err := json.Marshal(e.encode(batch))
+	if err != nil {
+		logger.Error("编码追踪数据失败", zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		logger.Error("创建追踪导出请求失败", zap.Error(err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err == nil {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			err = fmt.Errorf("collector 返回 %s", resp.Status)
+		}
+	}
+	if err != nil {
+		if !e.failing {
+			e.failing = true
+			logger.Warn("导出追踪数据失败", zap.String("endpoint", e.url), zap.Int("spans", len(batch)), zap.Error(err))
+		}
+		return
+	}
+	if e.failing {
+		e.failing = false
+		logger.Info("追踪数据导出已恢复", zap.String("endpoint", e.url))
+	}
+}
+
+// OTLP/JSON 结构（opentelemetry-proto 的 JSON 映射：ID 为十六进制，64 位整数为字符串）
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              Kind           `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            *otlpStatus    `json:"status,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+func (e *exporter) encode(batch []*Span) otlpRequest {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, s := range batch {
+		s.mu.Lock()
+		out := otlpSpan{
+			TraceID:           s.sc.TraceID.String(),
+			SpanID:            s.sc.SpanID.String(),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        encodeAttrs(s.attrs),
+		}
+		if s.parent.IsValid() {
+			out.ParentSpanID = s.parent.String()
+		}
+		if s.failed {
+			msg := s.errMsg
+			if len(msg) > maxErrorLength {
+				// 按字节截断后去掉不完整的 UTF-8 字符
+				msg = strings.ToValidUTF8(msg[:maxErrorLength], "")
+			}
+			out.Status = &otlpStatus{Code: statusCodeErr, Message: msg}
+		}
+		s.mu.Unlock()
+		spans = append(spans, out)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: encodeAttrs([]Attr{
+			String("service.name", e.service),
+			String("telemetry.sdk.language", "go"),
+		})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: scopeName},
+			Spans: spans,
+		}},
+	}}}
+}
+
+func encodeAttrs(attrs []Attr) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		var v otlpValue
+		switch x := a.Value.(type) {
+		case string:
+			v.StringValue = &x
+		case bool:
+			v.BoolValue = &x
+		case int64:
+			s := strconv.FormatInt(x, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &x
+		default:
+			s := fmt.Sprint(x)
+			v.StringValue = &s
+		}
+		out = append(out, otlpKeyValue{Key: a.Key, Value: v})
+	}
+	return out
+}
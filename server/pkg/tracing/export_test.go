@@ -0,0 +1,196 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	// Init 和导出失败时会写日志
+	logger.Logger = zap.NewNop()
+	logger.Sugar = logger.Logger.Sugar()
+	os.Exit(m.Run())
+}
+
+// enable 启用追踪并在测试结束时关闭；endpoint 为 nil 时导出到丢弃请求的地址
+func enable(t *testing.T, ratio float64, endpoint *httptest.Server) {
+	t.Helper()
+	url := "http://127.0.0.1:1"
+	if endpoint != nil {
+		url = endpoint.URL
+	}
+	Init(config.TracingConfig{
+		Enabled:     true,
+		Endpoint:    url,
+		ServiceName: "test-service",
+		SampleRatio: ratio,
+		Headers:     map[string]string{"Authorization": "Bearer collector-token"},
+	})
+	t.Cleanup(Close)
+}
+
+func TestEncode(t *testing.T) {
+	start := time.Unix(1700000000, 123)
+	parent := SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}}
+	s := &Span{
+		sc:     SpanContext{TraceID: parent.TraceID, SpanID: SpanID{3}, Sampled: true},
+		parent: parent.SpanID,
+		name:   "GET /api/files",
+		kind:   KindServer,
+		start:  start,
+		end:    start.Add(1500 * time.Millisecond),
+		attrs: []Attr{
+			String("http.route", "/api/files"),
+			Int("http.response.status_code", 500),
+			Int64("enduser.id", 1<<40),
+			Bool("cache.hit", false),
+			Float64("ratio", 0.25),
+		},
+		failed: true,
+		errMsg: "Internal Server Error",
+	}
+	root := &Span{sc: SpanContext{TraceID: TraceID{9}, SpanID: SpanID{8}}, name: "root", kind: KindInternal, start: start, end: start}
+
+	e := &exporter{service: "filesync-server"}
+	data, err := json.Marshal(e.encode([]*Span{s, root}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 与 opentelemetry-proto 的 JSON 映射逐字段比较：ID 为小写十六进制，64 位整数为字符串
+	want := `{"resourceSpans":[{"resource":{"attributes":[` +
+		`{"key":"service.name","value":{"stringValue":"filesync-server"}},` +
+		`{"key":"telemetry.sdk.language","value":{"stringValue":"go"}}]},` +
+		`"scopeSpans":[{"scope":{"name":"github.com/sunyuanling/server"},"spans":[` +
+		`{"traceId":"01000000000000000000000000000000","spanId":"0300000000000000","parentSpanId":"0200000000000000",` +
+		`"name":"GET /api/files","kind":2,"startTimeUnixNano":"1700000000000000123","endTimeUnixNano":"1700000001500000123",` +
+		`"attributes":[` +
+		`{"key":"http.route","value":{"stringValue":"/api/files"}},` +
+		`{"key":"http.response.status_code","value":{"intValue":"500"}},` +
+		`{"key":"enduser.id","value":{"intValue":"1099511627776"}},` +
+		`{"key":"cache.hit","value":{"boolValue":false}},` +
+		`{"key":"ratio","value":{"doubleValue":0.25}}],` +
+		`"status":{"code":2,"message":"Internal Server Error"}},` +
+		`{"traceId":"09000000000000000000000000000000","spanId":"0800000000000000",` +
+		`"name":"root","kind":1,"startTimeUnixNano":"1700000000000000123","endTimeUnixNano":"1700000000000000123"}` +
+		`]}]}]}`
+	if string(data) != want {
+		t.Errorf("编码结果不一致\n得到: %s\n期望: %s", data, want)
+	}
+}
+
+func TestEncodeTruncatesError(t *testing.T) {
+	// 中文错误信息按字节截断时不能留下半个字符
+	s := &Span{name: "x", failed: true, errMsg: strings.Repeat("数据库错误", 200)}
+	out := (&exporter{}).encode([]*Span{s})
+	msg := out.ResourceSpans[0].ScopeSpans[0].Spans[0].Status.Message
+	if len(msg) > maxErrorLength {
+		t.Errorf("错误信息长度 = %d, 超过 %d", len(msg), maxErrorLength)
+	}
+	if !utf8.ValidString(msg) {
+		t.Error("截断后的错误信息不是有效的 UTF-8")
+	}
+}
+
+func TestExportToCollector(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []otlpRequest
+		headers  []http.Header
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != tracesPath || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req otlpRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, req)
+		headers = append(headers, r.Header.Clone())
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	enable(t, 1, collector)
+	ctx, server := Start(context.Background(), "GET", KindServer)
+	_, child := Start(ctx, "redis.get", KindClient)
+	child.SetError(errors.New("connection refused"))
+	child.End()
+	server.SetName("GET /api/files")
+	server.End()
+	server.End() // 重复调用只导出一次
+	Close()      // 退出时导出队列中剩余的 span
+
+	mu.Lock()
+	defer mu.Unlock()
+	var spans []otlpSpan
+	for _, req := range received {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}
+	if len(spans) != 2 {
+		t.Fatalf("collector 收到 %d 个 span, 期望 2", len(spans))
+	}
+	if got := headers[0].Get("Authorization"); got != "Bearer collector-token" {
+		t.Errorf("导出请求缺少配置的请求头: %q", got)
+	}
+	if got := headers[0].Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	byName := map[string]otlpSpan{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	root, ok := byName["GET /api/files"]
+	if !ok {
+		t.Fatalf("缺少服务端 span: %+v", spans)
+	}
+	redis := byName["redis.get"]
+	if redis.TraceID != root.TraceID || redis.ParentSpanID != root.SpanID {
+		t.Error("子 span 的追踪关系不对")
+	}
+	if redis.Status == nil || redis.Status.Code != statusCodeErr || redis.Status.Message != "connection refused" {
+		t.Errorf("子 span 状态 = %+v", redis.Status)
+	}
+	if root.Status != nil {
+		t.Errorf("服务端 span 不应失败: %+v", root.Status)
+	}
+}
+
+func TestNewExporterURL(t *testing.T) {
+	for endpoint, want := range map[string]string{
+		"http://collector:4318":            "http://collector:4318/v1/traces",
+		"http://collector:4318/":           "http://collector:4318/v1/traces",
+		"http://collector:4318/v1/traces":  "http://collector:4318/v1/traces",
+		"https://otel.example.com/ingest/": "https://otel.example.com/ingest/v1/traces",
+	} {
+		e := newExporter(config.TracingConfig{Endpoint: endpoint})
+		e.shutdown()
+		if e.url != want {
+			t.Errorf("newExporter(%q).url = %q, 期望 %q", endpoint, e.url, want)
+		}
+	}
+}
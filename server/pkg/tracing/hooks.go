@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 数据库和 Redis 操作只在已有 span 的 context 中记录（如 db.WithContext(c.Request.Context())），
+// 后台任务等没有上级 span 的操作不单独成为追踪，避免大量只有一个 span 的追踪
+
+const gormSpanKey = "tracing:span"
+
+// gormPlugin 给 GORM 的增删改查加上 span，SQL 只记录占位符形式，不包含参数值
+type gormPlugin struct{}
+
+// GormPlugin 通过 db.Use 注册
+func GormPlugin() gorm.Plugin { return gormPlugin{} }
+
+func (gormPlugin) Name() string { return "tracing" }
+
+func (gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("tracing:before_create", gormBefore("create")); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("tracing:after_create", gormAfter); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tracing:before_query", gormBefore("query")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("tracing:after_query", gormAfter); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tracing:before_update", gormBefore("update")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("tracing:after_update", gormAfter); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tracing:before_delete", gormBefore("delete")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("tracing:after_delete", gormAfter); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tracing:before_row", gormBefore("row")); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("tracing:after_row", gormAfter); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("tracing:before_raw", gormBefore("raw")); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("tracing:after_raw", gormAfter)
+}
+
+func gormBefore(op string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		if ctx == nil || SpanFromContext(ctx) == nil {
+			return
+		}
+		_, span := Start(ctx, "db."+op, KindClient,
+			String("db.system", "postgresql"),
+			String("db.operation", op),
+		)
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func gormAfter(tx *gorm.DB) {
+	v, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, _ := v.(*Span)
+	if tx.Statement.Table != "" {
+		span.SetAttributes(String("db.sql.table", tx.Statement.Table))
+	}
+	if sql := tx.Statement.SQL.String(); sql != "" {
+		span.SetAttributes(String("db.statement", sql))
+	}
+	span.SetAttributes(Int64("db.rows_affected", tx.Statement.RowsAffected))
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.SetError(tx.Error)
+	}
+	span.End()
+}
+
+// redisHook 给 Redis 命令加上 span，只记录命令名，不记录参数（可能包含 token 等）
+type redisHook struct{}
+
+// RedisHook 通过 rdb.AddHook 注册
+func RedisHook() redis.Hook { return redisHook{} }
+
+func (redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if SpanFromContext(ctx) == nil {
+			return next(ctx, cmd)
+		}
+		name := strings.ToLower(cmd.Name())
+		ctx, span := Start(ctx, "redis."+name, KindClient,
+			String("db.system", "redis"),
+			String("db.operation", name),
+		)
+		err := next(ctx, cmd)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			span.SetError(err)
+		}
+		span.End()
+		return err
+	}
+}
+
+func (redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if SpanFromContext(ctx) == nil {
+			return next(ctx, cmds)
+		}
+		ctx, span := Start(ctx, "redis.pipeline", KindClient,
+			String("db.system", "redis"),
+			String("db.operation", "pipeline"),
+			Int("db.redis.commands", len(cmds)),
+		)
+		err := next(ctx, cmds)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			span.SetError(err)
+		}
+		span.End()
+		return err
+	}
+}
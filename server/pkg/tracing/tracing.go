@@ -0,0 +1,341 @@
+// Package tracing 轻量的分布式追踪：span 记录、W3C traceparent 传播、按比例采样，
+// 通过 OTLP/HTTP（JSON）批量导出到 OpenTelemetry Collector
+//
+// 未启用时 Start 返回不记录的 span，调用方无需判断是否启用。
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunyuanling/server/config"
+	"github.com/sunyuanling/server/pkg/logger"
+)
+
+// Kind span 类型，取值与 OTLP 一致
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// TraceID 16 字节追踪标识
+type TraceID [16]byte
+
+// SpanID 8 字节 span 标识
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// IsValid 全 0 为无效值
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+func (s SpanID) IsValid() bool  { return s != SpanID{} }
+
+// SpanContext 跨进程传播的部分
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid trace ID 和 span ID 都有效
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// Attr span 属性，值为 string、bool、int、int64 或 float64
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, value string) Attr          { return Attr{key, value} }
+func Int(key string, value int) Attr         { return Attr{key, int64(value)} }
+func Int64(key string, value int64) Attr     { return Attr{key, value} }
+func Bool(key string, value bool) Attr       { return Attr{key, value} }
+func Float64(key string, value float64) Attr { return Attr{key, value} }
+
+// Span 一次操作；不记录的 span（未启用或未采样）的方法都是空操作，但仍然传播 SpanContext
+type Span struct {
+	sc        SpanContext
+	parent    SpanID
+	recording bool
+
+	mu     sync.Mutex
+	name   string
+	kind   Kind
+	start  time.Time
+	end    time.Time
+	attrs  []Attr
+	errMsg string
+	failed bool
+	ended  bool
+}
+
+// SpanContext 当前 span 的传播信息
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// IsRecording 是否会被导出
+func (s *Span) IsRecording() bool { return s != nil && s.recording }
+
+// SetName 修改名称（如 HTTP span 在路由匹配后改为路由模板）
+func (s *Span) SetName(name string) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	s.name = name
+	s.mu.Unlock()
+}
+
+// SetAttributes 添加属性
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// SetError 标记为失败，err 为 nil 时忽略
+func (s *Span) SetError(err error) {
+	if err == nil || !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	s.failed = true
+	s.errMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// SetFailed 标记为失败（没有 error 值的情况，如 HTTP 5xx）
+func (s *Span) SetFailed(msg string) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	s.failed = true
+	s.errMsg = msg
+	s.mu.Unlock()
+}
+
+// End 结束并提交导出，重复调用只生效一次
+func (s *Span) End() {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+	if t := current(); t != nil {
+		t.exporter.enqueue(s)
+	}
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// ContextWithSpan 把 span 放入 context，之后 Start 的 span 以它为父
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// SpanFromContext context 中的 span，没有时返回 nil（nil 可以安全调用全部方法）
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// tracer 全局追踪器
+type tracer struct {
+	ratio    float64
+	exporter *exporter
+}
+
+var (
+	stdMu sync.RWMutex
+	std   *tracer
+)
+
+func current() *tracer {
+	stdMu.RLock()
+	defer stdMu.RUnlock()
+	return std
+}
+
+// Enabled 是否已启用
+func Enabled() bool { return current() != nil }
+
+// Init 启用追踪，未启用时不做任何事
+func Init(cfg config.TracingConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	t := &tracer{
+		ratio:    cfg.SampleRatio,
+		exporter: newExporter(cfg),
+	}
+	stdMu.Lock()
+	std = t
+	stdMu.Unlock()
+
+	logger.Info("链路追踪已启用",
+		zap.String("endpoint", t.exporter.url),
+		zap.String("service", cfg.ServiceName),
+		zap.Float64("sample_ratio", cfg.SampleRatio),
+	)
+}
+
+// Close 停止追踪并导出剩余的 span
+func Close() {
+	stdMu.Lock()
+	t := std
+	std = nil
+	stdMu.Unlock()
+	if t != nil {
+		t.exporter.shutdown()
+	}
+}
+
+// Start 创建子 span：ctx 中有 span 时以它为父，否则以 ContextWithRemote 传入的远端 span 为父，都没有时开始新的追踪
+// 返回的 context 包含新 span；未启用时返回不记录的 span
+func Start(ctx context.Context, name string, kind Kind, attrs ...Attr) (context.Context, *Span) {
+	t := current()
+	if t == nil {
+		return ctx, nil
+	}
+
+	var parent SpanContext
+	hasParent := false
+	if p := SpanFromContext(ctx); p != nil {
+		parent, hasParent = p.sc, true
+	} else if r, ok := ctx.Value(remoteKey{}).(SpanContext); ok && r.IsValid() {
+		parent, hasParent = r, true
+	}
+
+	s := &Span{name: name, kind: kind, start: time.Now()}
+	s.sc.SpanID = newSpanID()
+	if hasParent {
+		s.sc.TraceID = parent.TraceID
+		s.sc.Sampled = parent.Sampled
+		s.parent = parent.SpanID
+	} else {
+		s.sc.TraceID = newTraceID()
+		s.sc.Sampled = t.sample(s.sc.TraceID)
+	}
+	s.recording = s.sc.Sampled
+	if s.recording {
+		s.attrs = attrs
+	}
+	return ContextWithSpan(ctx, s), s
+}
+
+// sample 按 trace ID 的低 8 字节采样，同一追踪在各服务的结果一致
+func (t *tracer) sample(id TraceID) bool {
+	if t.ratio >= 1 {
+		return true
+	}
+	if t.ratio <= 0 {
+		return false
+	}
+	v := binary.BigEndian.Uint64(id[8:]) >> 1
+	return float64(v) < t.ratio*float64(uint64(1)<<63)
+}
+
+// ContextWithRemote 记录上游传入的追踪信息，作为之后 Start 的父 span
+func ContextWithRemote(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// ParseTraceparent 解析 W3C traceparent 请求头：00-<trace-id>-<parent-id>-<flags>，各字段只能是小写十六进制
+func ParseTraceparent(header string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, false
+	}
+	for _, p := range parts[:4] {
+		if !lowerHex(p) {
+			return SpanContext{}, false
+		}
+	}
+	// 版本 00 只能有 4 段，更高版本允许追加字段
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&0x01 != 0
+	return sc, true
+}
+
+func lowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Traceparent 格式化为 W3C traceparent，用于向下游传播
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	tests := []struct {
+		name    string
+		header  string
+		ok      bool
+		sampled bool
+	}{
+		{"已采样", "00-" + traceID + "-" + spanID + "-01", true, true},
+		{"未采样", "00-" + traceID + "-" + spanID + "-00", true, false},
+		{"其他标志位", "00-" + traceID + "-" + spanID + "-03", true, true},
+		{"首尾空白", "  00-" + traceID + "-" + spanID + "-01 ", true, true},
+		{"更高版本允许追加字段", "cc-" + traceID + "-" + spanID + "-01-extra", true, true},
+
+		{"空", "", false, false},
+		{"版本 00 不能追加字段", "00-" + traceID + "-" + spanID + "-01-extra", false, false},
+		{"版本 ff 无效", "ff-" + traceID + "-" + spanID + "-01", false, false},
+		{"大写十六进制", "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + spanID + "-01", false, false},
+		{"非十六进制", "00-" + traceID + "-00f067aa0ba902bz-01", false, false},
+		{"trace-id 全 0", "00-00000000000000000000000000000000-" + spanID + "-01", false, false},
+		{"parent-id 全 0", "00-" + traceID + "-0000000000000000-01", false, false},
+		{"trace-id 长度不对", "00-" + traceID[:30] + "-" + spanID + "-01", false, false},
+		{"parent-id 长度不对", "00-" + traceID + "-" + spanID[:14] + "-01", false, false},
+		{"flags 长度不对", "00-" + traceID + "-" + spanID + "-1", false, false},
+		{"段数不足", "00-" + traceID + "-" + spanID, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := ParseTraceparent(tt.header)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, 期望 %v", ok, tt.ok)
+			}
+			if !ok {
+				if sc.IsValid() {
+					t.Errorf("解析失败时应返回空的 SpanContext: %+v", sc)
+				}
+				return
+			}
+			if sc.TraceID.String() != traceID || sc.SpanID.String() != spanID {
+				t.Errorf("ID = %s/%s", sc.TraceID, sc.SpanID)
+			}
+			if sc.Sampled != tt.sampled {
+				t.Errorf("Sampled = %v, 期望 %v", sc.Sampled, tt.sampled)
+			}
+		})
+	}
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	for _, sampled := range []bool{true, false} {
+		sc := SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: sampled}
+		got, ok := ParseTraceparent(sc.Traceparent())
+		if !ok || got != sc {
+			t.Errorf("往返结果 = %+v (%v), 期望 %+v", got, ok, sc)
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	var low, high TraceID
+	binary.BigEndian.PutUint64(low[8:], 0)
+	binary.BigEndian.PutUint64(high[8:], ^uint64(0))
+
+	if (&tracer{ratio: 0}).sample(low) {
+		t.Error("比例为 0 时不应采样")
+	}
+	if !(&tracer{ratio: 1}).sample(high) {
+		t.Error("比例为 1 时应全部采样")
+	}
+	half := &tracer{ratio: 0.5}
+	if !half.sample(low) || half.sample(high) {
+		t.Error("按 trace ID 低 8 字节采样的结果不对")
+	}
+	// 同一 trace ID 的结果固定，各服务一致
+	id := newTraceID()
+	first := half.sample(id)
+	for i := 0; i < 10; i++ {
+		if half.sample(id) != first {
+			t.Fatal("同一追踪的采样结果不一致")
+		}
+	}
+}
+
+func TestStartDisabled(t *testing.T) {
+	ctx, span := Start(context.Background(), "noop", KindInternal)
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Fatal("未启用时不应创建 span")
+	}
+	// nil span 的方法都是空操作
+	span.SetName("x")
+	span.SetAttributes(String("k", "v"))
+	span.SetError(errors.New("boom"))
+	span.SetFailed("boom")
+	span.End()
+	if span.IsRecording() || span.SpanContext().IsValid() {
+		t.Error("nil span 不应记录")
+	}
+}
+
+func TestStartParent(t *testing.T) {
+	enable(t, 1, nil)
+
+	remote := SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: true}
+	ctx, server := Start(ContextWithRemote(context.Background(), remote), "GET", KindServer)
+	if server.sc.TraceID != remote.TraceID || server.parent != remote.SpanID {
+		t.Fatal("应沿用上游的追踪")
+	}
+	_, child := Start(ctx, "db.query", KindClient)
+	if child.sc.TraceID != remote.TraceID || child.parent != server.sc.SpanID {
+		t.Fatal("子 span 应以 context 中的 span 为父")
+	}
+
+	// 上游未采样时不记录，但仍然传播追踪信息
+	unsampled := SpanContext{TraceID: newTraceID(), SpanID: newSpanID()}
+	_, s := Start(ContextWithRemote(context.Background(), unsampled), "GET", KindServer)
+	if s.IsRecording() || s.SpanContext().TraceID != unsampled.TraceID {
+		t.Error("上游未采样时不应记录")
+	}
+	s.SetAttributes(String("k", "v"))
+	if len(s.attrs) != 0 {
+		t.Error("不记录的 span 不应保存属性")
+	}
+
+	// 无效的远端信息被忽略，开始新的追踪
+	_, root := Start(ContextWithRemote(context.Background(), SpanContext{}), "GET", KindServer)
+	if root.parent.IsValid() || !root.sc.TraceID.IsValid() {
+		t.Error("没有父 span 时应开始新的追踪")
+	}
+}
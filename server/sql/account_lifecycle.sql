@@ -0,0 +1,50 @@
+-- 账号注销：用户状态增加 注销冷静期
+ALTER TABLE "user" DROP CONSTRAINT IF EXISTS user_status_check;
+ALTER TABLE "user" ADD CONSTRAINT user_status_check CHECK (status IN (0, 1, 2, 3, 4));
+COMMENT ON COLUMN "user".status IS '状态：1正常/0禁用/2待验证邮箱/3待审批/4注销冷静期';
+
+-- 数据导出任务表
+CREATE TABLE IF NOT EXISTS takeout_job (
+                                           id SERIAL PRIMARY KEY,
+                                           user_id INTEGER NOT NULL,
+                                           status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed', 'expired')),
+                                           progress INTEGER NOT NULL DEFAULT 0,
+                                           archive_path VARCHAR(1000),
+                                           archive_size BIGINT NOT NULL DEFAULT 0,
+                                           file_count INTEGER NOT NULL DEFAULT 0,
+                                           error_message TEXT,
+                                           created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                           started_at TIMESTAMP,
+                                           completed_at TIMESTAMP,
+                                           expires_at TIMESTAMP,
+                                           CONSTRAINT fk_takeout_job_user FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE takeout_job IS '账号数据导出任务';
+COMMENT ON COLUMN takeout_job.status IS '状态：pending排队/running进行中/completed完成/failed失败/expired已过期';
+COMMENT ON COLUMN takeout_job.progress IS '进度（0-100）';
+COMMENT ON COLUMN takeout_job.archive_path IS '压缩包路径';
+COMMENT ON COLUMN takeout_job.archive_size IS '压缩包大小（字节）';
+COMMENT ON COLUMN takeout_job.file_count IS '打包的文件数';
+COMMENT ON COLUMN takeout_job.expires_at IS '压缩包过期时间，过期后删除';
+
+CREATE INDEX IF NOT EXISTS idx_takeout_job_user ON takeout_job(user_id, created_at);
+
+-- 账号注销申请表
+CREATE TABLE IF NOT EXISTS account_deletion (
+                                                user_id INTEGER PRIMARY KEY,
+                                                requested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                purge_at TIMESTAMP NOT NULL,
+                                                reason VARCHAR(500),
+                                                previous_status SMALLINT NOT NULL DEFAULT 1,
+                                                trashed_files TEXT,
+                                                CONSTRAINT fk_account_deletion_user FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE account_deletion IS '账号注销申请（冷静期内可撤销）';
+COMMENT ON COLUMN account_deletion.purge_at IS '彻底删除时间';
+COMMENT ON COLUMN account_deletion.reason IS '注销原因';
+COMMENT ON COLUMN account_deletion.previous_status IS '申请前的账号状态，撤销时恢复';
+COMMENT ON COLUMN account_deletion.trashed_files IS '移入回收站的文件（JSON：原路径 -> 回收站路径）';
+
+CREATE INDEX IF NOT EXISTS idx_account_deletion_purge ON account_deletion(purge_at);
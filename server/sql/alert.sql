@@ -0,0 +1,32 @@
+-- 告警记录表
+CREATE TABLE IF NOT EXISTS alert (
+                                     id BIGSERIAL PRIMARY KEY,
+                                     rule VARCHAR(32) NOT NULL,
+                                     alert_key VARCHAR(255) NOT NULL DEFAULT '',
+                                     severity VARCHAR(16) NOT NULL CHECK (severity IN ('warning', 'critical')),
+                                     status VARCHAR(16) NOT NULL DEFAULT 'firing' CHECK (status IN ('firing', 'resolved')),
+                                     title VARCHAR(200) NOT NULL,
+                                     message TEXT,
+                                     value DOUBLE PRECISION NOT NULL DEFAULT 0,
+                                     threshold DOUBLE PRECISION NOT NULL DEFAULT 0,
+                                     fired_at TIMESTAMP NOT NULL,
+                                     last_seen_at TIMESTAMP NOT NULL,
+                                     resolved_at TIMESTAMP,
+                                     notified_at TIMESTAMP,
+                                     notify_count INTEGER NOT NULL DEFAULT 0
+);
+
+COMMENT ON TABLE alert IS '告警记录';
+COMMENT ON COLUMN alert.rule IS '规则：disk_usage/free_space/disk_health/failed_uploads/login_failures/device_offline';
+COMMENT ON COLUMN alert.alert_key IS '告警对象（磁盘挂载点、账号、IP、设备），同一规则同一对象同时只有一条 firing 记录';
+COMMENT ON COLUMN alert.severity IS '级别：warning/critical';
+COMMENT ON COLUMN alert.status IS '状态：firing触发中/resolved已恢复';
+COMMENT ON COLUMN alert.value IS '最近一次评估的值';
+COMMENT ON COLUMN alert.threshold IS '触发阈值';
+COMMENT ON COLUMN alert.last_seen_at IS '最近一次评估仍满足条件的时间';
+COMMENT ON COLUMN alert.notified_at IS '最近一次发送通知的时间';
+COMMENT ON COLUMN alert.notify_count IS '已发送通知次数（不含恢复通知）';
+
+-- 去重：同一规则同一对象只能有一条触发中的告警
+CREATE UNIQUE INDEX IF NOT EXISTS uk_alert_firing ON alert(rule, alert_key) WHERE status = 'firing';
+CREATE INDEX IF NOT EXISTS idx_alert_fired ON alert(fired_at);
@@ -0,0 +1,30 @@
+-- 个人 API Key（脚本、第三方客户端使用）
+CREATE TABLE IF NOT EXISTS api_key (
+                                       id SERIAL PRIMARY KEY,
+                                       user_id INTEGER NOT NULL,
+                                       name VARCHAR(100) NOT NULL,
+                                       key_prefix VARCHAR(16) NOT NULL,
+                                       key_hash VARCHAR(64) NOT NULL,
+                                       scope VARCHAR(20) NOT NULL DEFAULT 'read' CHECK (scope IN ('read', 'upload', 'read_write')),
+                                       folder_path VARCHAR(1000),
+                                       expires_at TIMESTAMP,
+                                       last_used_at TIMESTAMP,
+                                       last_used_ip VARCHAR(64),
+                                       revoked_at TIMESTAMP,
+                                       created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                       CONSTRAINT uk_api_key_prefix UNIQUE (key_prefix),
+                                       CONSTRAINT fk_api_key_user FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE api_key IS '个人API Key';
+COMMENT ON COLUMN api_key.name IS '名称（用途说明）';
+COMMENT ON COLUMN api_key.key_prefix IS 'Key公开前缀，用于查找';
+COMMENT ON COLUMN api_key.key_hash IS 'Key密文部分SHA-256';
+COMMENT ON COLUMN api_key.scope IS '权限范围：read只读/upload只上传/read_write读写';
+COMMENT ON COLUMN api_key.folder_path IS '限定的文件夹，为空表示不限';
+COMMENT ON COLUMN api_key.expires_at IS '过期时间，为空表示不过期';
+COMMENT ON COLUMN api_key.last_used_at IS '最后使用时间';
+COMMENT ON COLUMN api_key.last_used_ip IS '最后使用IP';
+COMMENT ON COLUMN api_key.revoked_at IS '吊销时间';
+
+CREATE INDEX idx_api_key_user ON api_key(user_id);
@@ -0,0 +1,75 @@
+-- 审计日志：只追加，不允许修改；只有保留期清理（SET LOCAL audit.purge = 'on'）可以删除，
+-- 只有注销账号的匿名化（SET LOCAL audit.anonymise = 'on'）可以清空操作人的可识别信息
+-- 不关联 "user" 外键，用户删除后审计记录仍然保留
+CREATE TABLE IF NOT EXISTS audit_log (
+                                         id BIGSERIAL PRIMARY KEY,
+                                         created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                         actor_id INTEGER,
+                                         actor VARCHAR(100),
+                                         action VARCHAR(64) NOT NULL,
+                                         target_type VARCHAR(32),
+                                         target_id VARCHAR(1000),
+                                         result VARCHAR(16) NOT NULL DEFAULT 'success' CHECK (result IN ('success', 'failure', 'denied')),
+                                         ip VARCHAR(64),
+                                         user_agent VARCHAR(500),
+                                         device_id VARCHAR(100),
+                                         detail TEXT
+);
+
+COMMENT ON TABLE audit_log IS '审计日志（只追加）';
+COMMENT ON COLUMN audit_log.actor_id IS '操作人用户ID，未识别身份（如登录失败）时为空';
+COMMENT ON COLUMN audit_log.actor IS '操作人用户名或登录标识';
+COMMENT ON COLUMN audit_log.action IS '事件类型，如 auth.login、file.upload';
+COMMENT ON COLUMN audit_log.target_type IS '操作对象类型：user/file/session/device/api_key/role_binding';
+COMMENT ON COLUMN audit_log.target_id IS '操作对象标识（用户ID、文件路径等）';
+COMMENT ON COLUMN audit_log.result IS '结果：success成功/failure失败/denied被拒绝';
+COMMENT ON COLUMN audit_log.ip IS '客户端IP';
+COMMENT ON COLUMN audit_log.user_agent IS '客户端User-Agent';
+COMMENT ON COLUMN audit_log.device_id IS '设备标识';
+COMMENT ON COLUMN audit_log.detail IS '附加信息（JSON）';
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action, created_at);
+
+-- 禁止修改；删除只允许保留期清理，修改只允许匿名化：actor、ip、user_agent、device_id 置空，其余列保持不变
+CREATE OR REPLACE FUNCTION audit_log_guard() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND current_setting('audit.purge', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+    IF TG_OP = 'UPDATE' AND current_setting('audit.anonymise', true) = 'on'
+        AND COALESCE(NEW.actor, '') = '' AND COALESCE(NEW.ip, '') = ''
+        AND COALESCE(NEW.user_agent, '') = '' AND COALESCE(NEW.device_id, '') = ''
+        AND NEW.id = OLD.id AND NEW.created_at = OLD.created_at
+        AND NEW.actor_id IS NOT DISTINCT FROM OLD.actor_id
+        AND NEW.action = OLD.action
+        AND NEW.target_type IS NOT DISTINCT FROM OLD.target_type
+        AND NEW.target_id IS NOT DISTINCT FROM OLD.target_id
+        AND NEW.result = OLD.result
+        AND NEW.detail IS NOT DISTINCT FROM OLD.detail THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_log_guard ON audit_log;
+CREATE TRIGGER trg_audit_log_guard
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_guard();
+
+DROP TRIGGER IF EXISTS trg_audit_log_no_truncate ON audit_log;
+CREATE TRIGGER trg_audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_guard();
+
+-- 查看审计日志的权限（管理员角色自动拥有）
+INSERT INTO permission (permission_code, permission_name, permission_type, description, sort_order) VALUES
+    ('audit:read', '查看审计日志', 'api', '查询和导出全部用户的审计日志', 100)
+ON CONFLICT (permission_code) DO NOTHING;
+
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM role r JOIN permission p ON p.permission_code = 'audit:read'
+WHERE r.role_code = 'admin'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
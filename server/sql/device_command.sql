@@ -0,0 +1,49 @@
+-- 设备远程指令表
+CREATE TABLE IF NOT EXISTS device_command (
+                                              id BIGSERIAL PRIMARY KEY,
+                                              user_id INTEGER NOT NULL,
+                                              device_record_id INTEGER NOT NULL,
+                                              device_id VARCHAR(100) NOT NULL,
+                                              command VARCHAR(50) NOT NULL CHECK (command IN ('sign_out', 'clear_local_cache', 'upload_diagnostics', 'pause_sync')),
+                                              params TEXT,
+                                              status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'completed', 'failed', 'expired')),
+                                              result TEXT,
+                                              issued_from VARCHAR(100),
+                                              sent_at TIMESTAMP,
+                                              acked_at TIMESTAMP,
+                                              created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                              updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 添加注释
+COMMENT ON TABLE device_command IS '设备远程指令表';
+COMMENT ON COLUMN device_command.id IS '指令ID';
+COMMENT ON COLUMN device_command.user_id IS '用户ID';
+COMMENT ON COLUMN device_command.device_record_id IS '目标设备（device表主键）';
+COMMENT ON COLUMN device_command.device_id IS '目标设备唯一标识';
+COMMENT ON COLUMN device_command.command IS '指令：sign_out/clear_local_cache/upload_diagnostics/pause_sync';
+COMMENT ON COLUMN device_command.params IS '指令参数（JSON）';
+COMMENT ON COLUMN device_command.status IS '状态：pending/sent/completed/failed/expired';
+COMMENT ON COLUMN device_command.result IS '设备回执结果';
+COMMENT ON COLUMN device_command.issued_from IS '发起指令的设备标识';
+COMMENT ON COLUMN device_command.sent_at IS '下发时间';
+COMMENT ON COLUMN device_command.acked_at IS '设备确认时间';
+
+-- 创建索引
+CREATE INDEX idx_device_command_device ON device_command(device_id, status);
+CREATE INDEX idx_device_command_user ON device_command(user_id);
+CREATE INDEX idx_device_command_created_at ON device_command(created_at DESC);
+
+-- 创建触发器（自动更新updated_at）
+CREATE OR REPLACE FUNCTION update_device_command_updated_at()
+    RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_device_command_updated_at
+    BEFORE UPDATE ON device_command
+    FOR EACH ROW
+EXECUTE FUNCTION update_device_command_updated_at();
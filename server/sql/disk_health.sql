@@ -0,0 +1,31 @@
+-- 磁盘健康采样表（SMART）
+CREATE TABLE IF NOT EXISTS disk_health_sample (
+                                                  id BIGSERIAL PRIMARY KEY,
+                                                  disk_id VARCHAR(128) NOT NULL,
+                                                  device VARCHAR(64) NOT NULL,
+                                                  model VARCHAR(128),
+                                                  smart_passed BOOLEAN,
+                                                  temperature DOUBLE PRECISION,
+                                                  power_on_hours BIGINT,
+                                                  reallocated_sectors BIGINT,
+                                                  pending_sectors BIGINT,
+                                                  uncorrectable_sectors BIGINT,
+                                                  media_errors BIGINT,
+                                                  percentage_used INTEGER,
+                                                  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE disk_health_sample IS '磁盘健康采样（SMART），设备不提供的项为空';
+COMMENT ON COLUMN disk_health_sample.disk_id IS '磁盘标识：序列号，没有序列号时为设备名';
+COMMENT ON COLUMN disk_health_sample.device IS '设备名（如 sda、nvme0n1）';
+COMMENT ON COLUMN disk_health_sample.smart_passed IS 'SMART 总体状态';
+COMMENT ON COLUMN disk_health_sample.temperature IS '温度（℃）';
+COMMENT ON COLUMN disk_health_sample.power_on_hours IS '通电时间（小时）';
+COMMENT ON COLUMN disk_health_sample.reallocated_sectors IS '已重映射扇区数（SAS 盘为增长缺陷数）';
+COMMENT ON COLUMN disk_health_sample.pending_sectors IS '等待重映射的扇区数';
+COMMENT ON COLUMN disk_health_sample.uncorrectable_sectors IS '无法校正的扇区数';
+COMMENT ON COLUMN disk_health_sample.media_errors IS 'NVMe 介质错误数';
+COMMENT ON COLUMN disk_health_sample.percentage_used IS 'NVMe 寿命已用百分比';
+
+CREATE INDEX IF NOT EXISTS idx_disk_health_disk ON disk_health_sample(disk_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_disk_health_created ON disk_health_sample(created_at);
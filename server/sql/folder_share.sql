@@ -0,0 +1,68 @@
+-- 文件夹共享表（注册用户/分组之间的内部共享）
+CREATE TABLE IF NOT EXISTS folder_share (
+                                            id BIGSERIAL PRIMARY KEY,
+                                            owner_id INTEGER NOT NULL,
+                                            folder_path VARCHAR(1000) NOT NULL,
+                                            grantee_type VARCHAR(10) NOT NULL CHECK (grantee_type IN ('user', 'group')),
+                                            grantee_id BIGINT NOT NULL,
+                                            permission VARCHAR(10) NOT NULL DEFAULT 'read' CHECK (permission IN ('read', 'write', 'manage')),
+                                            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                            CONSTRAINT uk_folder_share UNIQUE (folder_path, grantee_type, grantee_id),
+                                            CONSTRAINT fk_folder_share_owner FOREIGN KEY (owner_id) REFERENCES "user"(id) ON DELETE CASCADE
+);
+
+-- 添加注释
+COMMENT ON TABLE folder_share IS '文件夹共享表';
+COMMENT ON COLUMN folder_share.id IS '共享ID';
+COMMENT ON COLUMN folder_share.owner_id IS '共享人ID';
+COMMENT ON COLUMN folder_share.folder_path IS '共享的文件夹（绝对路径）';
+COMMENT ON COLUMN folder_share.grantee_type IS '被共享对象类型：user/group';
+COMMENT ON COLUMN folder_share.grantee_id IS '被共享对象ID（用户ID或 ws_group.id）';
+COMMENT ON COLUMN folder_share.permission IS '权限：read只读/write读写/manage读写并可再共享';
+
+-- 创建索引
+CREATE INDEX idx_folder_share_owner ON folder_share(owner_id);
+CREATE INDEX idx_folder_share_grantee ON folder_share(grantee_type, grantee_id);
+
+-- 删除分组时清理对分组的共享
+CREATE OR REPLACE FUNCTION delete_folder_share_for_group()
+    RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM folder_share WHERE grantee_type = 'group' AND grantee_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_delete_folder_share_for_group
+    AFTER DELETE ON ws_group
+    FOR EACH ROW
+EXECUTE FUNCTION delete_folder_share_for_group();
+
+-- 删除用户时清理对该用户的共享
+CREATE OR REPLACE FUNCTION delete_folder_share_for_user()
+    RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM folder_share WHERE grantee_type = 'user' AND grantee_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_delete_folder_share_for_user
+    AFTER DELETE ON "user"
+    FOR EACH ROW
+EXECUTE FUNCTION delete_folder_share_for_user();
+
+-- 创建触发器（自动更新updated_at）
+CREATE OR REPLACE FUNCTION update_folder_share_updated_at()
+    RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_folder_share_updated_at
+    BEFORE UPDATE ON folder_share
+    FOR EACH ROW
+EXECUTE FUNCTION update_folder_share_updated_at();
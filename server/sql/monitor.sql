@@ -0,0 +1,9 @@
+-- 查看系统监控的权限（管理员角色自动拥有）
+INSERT INTO permission (permission_code, permission_name, permission_type, description, sort_order) VALUES
+    ('system:monitor', '查看系统监控', 'api', '查看 CPU、内存、网络、磁盘 IO 等实时监控数据', 110)
+ON CONFLICT (permission_code) DO NOTHING;
+
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM role r JOIN permission p ON p.permission_code = 'system:monitor'
+WHERE r.role_code = 'admin'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
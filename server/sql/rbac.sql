@@ -0,0 +1,65 @@
+-- ============================================
+-- 基于角色的访问控制（RBAC）
+-- 依赖 create_table_sql.sql 中的 permission / role / role_permission / user_role 表
+-- ============================================
+
+-- 角色：内置角色不可删除
+ALTER TABLE role ADD COLUMN IF NOT EXISTS is_system BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN role.is_system IS '是否内置角色（不可删除）';
+
+-- 角色绑定：scope_path 为空表示全局生效，非空表示只对该目录及其子路径生效
+ALTER TABLE user_role ADD COLUMN IF NOT EXISTS scope_path VARCHAR(1000) NOT NULL DEFAULT '';
+ALTER TABLE user_role ADD COLUMN IF NOT EXISTS granted_by INTEGER;
+ALTER TABLE user_role DROP CONSTRAINT IF EXISTS uk_user_role;
+ALTER TABLE user_role ADD CONSTRAINT uk_user_role_scope UNIQUE (user_id, role_id, scope_path);
+COMMENT ON COLUMN user_role.scope_path IS '生效范围（目录路径），空为全局';
+COMMENT ON COLUMN user_role.granted_by IS '授权的管理员ID';
+
+-- user.role 不再作为权限依据，去掉 admin/user 的取值限制
+ALTER TABLE "user" DROP CONSTRAINT IF EXISTS user_role_check;
+COMMENT ON COLUMN "user".role IS '旧角色字段（已由 user_role 取代，仅在用户没有任何角色绑定时作为回退）';
+
+-- 权限
+INSERT INTO permission (permission_code, permission_name, permission_type, description, sort_order) VALUES
+    ('files:read',      '读取文件',     'api', '浏览目录、下载文件',               10),
+    ('files:write',     '写入文件',     'api', '上传、覆盖文件',                   20),
+    ('files:delete',    '删除文件',     'api', '删除文件和目录',                   30),
+    ('disks:read',      '查看磁盘',     'api', '查看可用磁盘和存储信息',           40),
+    ('device:manage',   '管理设备',     'api', '设备列表、配对、远程指令',         50),
+    ('group:manage',    '管理分组',     'api', '创建分组、共享文件夹、成员管理',   60),
+    ('user:manage',     '管理用户',     'api', '管理其他用户账号',                 70),
+    ('security:manage', '管理安全策略', 'api', '两步验证策略等安全设置',           80),
+    ('rbac:manage',     '管理权限',     'api', '角色、权限和角色绑定',             90)
+ON CONFLICT (permission_code) DO NOTHING;
+
+-- 内置角色
+INSERT INTO role (role_code, role_name, description, is_system) VALUES
+    ('admin', '管理员', '拥有全部权限',                         TRUE),
+    ('user',  '普通用户', '读写文件、管理自己的设备和分组',     TRUE),
+    ('guest', '访客',   '只读；通常绑定到指定目录',             TRUE)
+ON CONFLICT (role_code) DO UPDATE SET is_system = TRUE;
+
+-- 角色权限
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM role r CROSS JOIN permission p
+WHERE r.role_code = 'admin'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM role r JOIN permission p
+    ON p.permission_code IN ('files:read', 'files:write', 'files:delete', 'disks:read', 'device:manage', 'group:manage')
+WHERE r.role_code = 'user'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+INSERT INTO role_permission (role_id, permission_id)
+SELECT r.id, p.id FROM role r JOIN permission p
+    ON p.permission_code IN ('files:read')
+WHERE r.role_code = 'guest'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+-- 迁移旧的 user.role 为全局角色绑定
+INSERT INTO user_role (user_id, role_id, scope_path)
+SELECT u.id, r.id, '' FROM "user" u JOIN role r ON r.role_code = u.role
+ON CONFLICT (user_id, role_id, scope_path) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_user_role_scope ON user_role(user_id, scope_path);
@@ -0,0 +1,50 @@
+-- 注册策略：用户状态增加 待验证邮箱/待审批
+ALTER TABLE "user" DROP CONSTRAINT IF EXISTS user_status_check;
+ALTER TABLE "user" ADD CONSTRAINT user_status_check CHECK (status IN (0, 1, 2, 3));
+COMMENT ON COLUMN "user".status IS '状态：1正常/0禁用/2待验证邮箱/3待审批';
+
+-- 邀请码表
+CREATE TABLE IF NOT EXISTS invite_code (
+                                           id SERIAL PRIMARY KEY,
+                                           code VARCHAR(32) NOT NULL,
+                                           created_by INTEGER,
+                                           max_uses INTEGER NOT NULL DEFAULT 1 CHECK (max_uses > 0),
+                                           used_count INTEGER NOT NULL DEFAULT 0,
+                                           note VARCHAR(200),
+                                           expires_at TIMESTAMP,
+                                           revoked_at TIMESTAMP,
+                                           created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                           CONSTRAINT uk_invite_code UNIQUE (code),
+                                           CONSTRAINT fk_invite_code_creator FOREIGN KEY (created_by) REFERENCES "user"(id) ON DELETE SET NULL
+);
+
+COMMENT ON TABLE invite_code IS '注册邀请码';
+COMMENT ON COLUMN invite_code.code IS '邀请码';
+COMMENT ON COLUMN invite_code.created_by IS '创建人';
+COMMENT ON COLUMN invite_code.max_uses IS '最多可使用次数';
+COMMENT ON COLUMN invite_code.used_count IS '已使用次数';
+COMMENT ON COLUMN invite_code.expires_at IS '过期时间，为空表示不过期';
+COMMENT ON COLUMN invite_code.revoked_at IS '作废时间';
+
+-- 注册记录表（注册方式、来源、审批信息）
+CREATE TABLE IF NOT EXISTS user_registration (
+                                                 user_id INTEGER PRIMARY KEY,
+                                                 mode VARCHAR(20) NOT NULL,
+                                                 invite_code_id INTEGER,
+                                                 ip VARCHAR(64),
+                                                 email_verified_at TIMESTAMP,
+                                                 reviewed_by INTEGER,
+                                                 reviewed_at TIMESTAMP,
+                                                 created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                                 CONSTRAINT fk_registration_user FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE,
+                                                 CONSTRAINT fk_registration_invite FOREIGN KEY (invite_code_id) REFERENCES invite_code(id) ON DELETE SET NULL,
+                                                 CONSTRAINT fk_registration_reviewer FOREIGN KEY (reviewed_by) REFERENCES "user"(id) ON DELETE SET NULL
+);
+
+COMMENT ON TABLE user_registration IS '用户注册记录';
+COMMENT ON COLUMN user_registration.mode IS '注册时的策略：open/invite/email_verify/admin_approve';
+COMMENT ON COLUMN user_registration.invite_code_id IS '使用的邀请码';
+COMMENT ON COLUMN user_registration.ip IS '注册IP';
+COMMENT ON COLUMN user_registration.email_verified_at IS '邮箱验证时间';
+COMMENT ON COLUMN user_registration.reviewed_by IS '审批人';
+COMMENT ON COLUMN user_registration.reviewed_at IS '审批时间';
@@ -0,0 +1,66 @@
+-- 两步验证（TOTP）表
+CREATE TABLE IF NOT EXISTS user_two_factor (
+                                               user_id INTEGER PRIMARY KEY,
+                                               secret TEXT NOT NULL,
+                                               enabled BOOLEAN NOT NULL DEFAULT FALSE,
+                                               last_used_step BIGINT NOT NULL DEFAULT 0,
+                                               enabled_at TIMESTAMP,
+                                               created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                               updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                               CONSTRAINT fk_two_factor_user FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE user_two_factor IS '两步验证（TOTP）配置';
+COMMENT ON COLUMN user_two_factor.secret IS 'TOTP密钥（加密存储）';
+COMMENT ON COLUMN user_two_factor.enabled IS '是否已启用（绑定后验证通过才启用）';
+COMMENT ON COLUMN user_two_factor.last_used_step IS '最后使用的时间步，防止验证码重放';
+COMMENT ON COLUMN user_two_factor.enabled_at IS '启用时间';
+
+-- 恢复码表
+CREATE TABLE IF NOT EXISTS user_recovery_code (
+                                                  id SERIAL PRIMARY KEY,
+                                                  user_id INTEGER NOT NULL,
+                                                  code_hash VARCHAR(64) NOT NULL,
+                                                  used_at TIMESTAMP,
+                                                  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                                  CONSTRAINT fk_recovery_code_user FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE user_recovery_code IS '两步验证恢复码（一次性）';
+COMMENT ON COLUMN user_recovery_code.code_hash IS '恢复码SHA-256';
+COMMENT ON COLUMN user_recovery_code.used_at IS '使用时间，非空表示已使用';
+
+CREATE INDEX idx_recovery_code_user ON user_recovery_code(user_id);
+
+-- 按角色强制两步验证
+CREATE TABLE IF NOT EXISTS two_factor_policy (
+                                                 role VARCHAR(20) PRIMARY KEY,
+                                                 required BOOLEAN NOT NULL DEFAULT FALSE,
+                                                 updated_by INTEGER,
+                                                 updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE two_factor_policy IS '按角色强制两步验证';
+COMMENT ON COLUMN two_factor_policy.role IS '用户角色';
+COMMENT ON COLUMN two_factor_policy.required IS '该角色是否必须启用两步验证';
+COMMENT ON COLUMN two_factor_policy.updated_by IS '最后修改的管理员ID';
+
+-- 受信任设备：两步验证通过并选择记住设备后，在有效期内登录跳过第二步
+ALTER TABLE device ADD COLUMN IF NOT EXISTS trusted_until TIMESTAMP;
+ALTER TABLE device ADD COLUMN IF NOT EXISTS trust_token_hash VARCHAR(64);
+COMMENT ON COLUMN device.trusted_until IS '受信任截止时间（两步验证记住设备）';
+COMMENT ON COLUMN device.trust_token_hash IS '记住设备时下发给客户端的信任凭证SHA-256，登录时需出示凭证';
+
+-- 创建触发器（自动更新updated_at）
+CREATE OR REPLACE FUNCTION update_user_two_factor_updated_at()
+    RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_user_two_factor_updated_at
+    BEFORE UPDATE ON user_two_factor
+    FOR EACH ROW
+EXECUTE FUNCTION update_user_two_factor_updated_at();
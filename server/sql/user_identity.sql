@@ -0,0 +1,22 @@
+-- 外部身份（OIDC）与本地账号的关联表
+CREATE TABLE IF NOT EXISTS user_identity (
+                                             id SERIAL PRIMARY KEY,
+                                             user_id INTEGER NOT NULL,
+                                             provider VARCHAR(50) NOT NULL,
+                                             subject VARCHAR(255) NOT NULL,
+                                             email VARCHAR(100),
+                                             last_login_at TIMESTAMP,
+                                             created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                             CONSTRAINT uk_user_identity UNIQUE (provider, subject),
+                                             CONSTRAINT fk_user_identity_user FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identity_user ON user_identity(user_id);
+
+COMMENT ON TABLE user_identity IS '用户外部身份（OIDC）';
+COMMENT ON COLUMN user_identity.provider IS '身份提供方标识（config.yaml 中 oidc.providers[].name）';
+COMMENT ON COLUMN user_identity.subject IS 'ID Token 中的 sub';
+COMMENT ON COLUMN user_identity.email IS '最近一次登录时身份提供方返回的邮箱';
+COMMENT ON COLUMN user_identity.last_login_at IS '最近一次通过该身份登录的时间';
+
+COMMENT ON COLUMN user_registration.mode IS '注册时的策略：open/invite/email_verify/admin_approve/oidc';
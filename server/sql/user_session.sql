@@ -0,0 +1,30 @@
+-- 登录会话表（刷新令牌）
+CREATE TABLE IF NOT EXISTS user_session (
+                                            id VARCHAR(64) PRIMARY KEY,
+                                            user_id INTEGER NOT NULL,
+                                            device_id VARCHAR(100),
+                                            ip_address VARCHAR(50),
+                                            user_agent VARCHAR(500),
+                                            refresh_hash VARCHAR(64) NOT NULL,
+                                            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                            last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                            expires_at TIMESTAMP NOT NULL,
+                                            revoked_at TIMESTAMP,
+                                            CONSTRAINT fk_user_session_user FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE
+);
+
+-- 添加注释
+COMMENT ON TABLE user_session IS '登录会话表';
+COMMENT ON COLUMN user_session.id IS '会话ID（写入访问token的sid）';
+COMMENT ON COLUMN user_session.user_id IS '用户ID';
+COMMENT ON COLUMN user_session.device_id IS '登录设备标识';
+COMMENT ON COLUMN user_session.ip_address IS '登录IP';
+COMMENT ON COLUMN user_session.user_agent IS '用户代理';
+COMMENT ON COLUMN user_session.refresh_hash IS '当前刷新令牌的SHA-256，每次刷新轮换';
+COMMENT ON COLUMN user_session.last_used_at IS '最后使用时间';
+COMMENT ON COLUMN user_session.expires_at IS '刷新令牌过期时间';
+COMMENT ON COLUMN user_session.revoked_at IS '注销时间';
+
+-- 创建索引
+CREATE INDEX idx_user_session_user ON user_session(user_id);
+CREATE INDEX idx_user_session_expires ON user_session(expires_at);
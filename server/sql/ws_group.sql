@@ -0,0 +1,60 @@
+-- 共享空间分组表（WebSocket 分组持久化）
+CREATE TABLE IF NOT EXISTS ws_group (
+                                        id BIGSERIAL PRIMARY KEY,
+                                        name VARCHAR(100) NOT NULL UNIQUE,
+                                        owner_id INTEGER NOT NULL,
+                                        description VARCHAR(500),
+                                        folder_path VARCHAR(500),
+                                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                        CONSTRAINT fk_ws_group_owner FOREIGN KEY (owner_id) REFERENCES "user"(id) ON DELETE CASCADE
+);
+
+-- 分组成员表
+CREATE TABLE IF NOT EXISTS ws_group_member (
+                                               id BIGSERIAL PRIMARY KEY,
+                                               group_id BIGINT NOT NULL,
+                                               user_id INTEGER NOT NULL,
+                                               role VARCHAR(20) DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member')),
+                                               status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('invited', 'active')),
+                                               created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                               CONSTRAINT uk_ws_group_member UNIQUE (group_id, user_id),
+                                               CONSTRAINT fk_ws_group_member_group FOREIGN KEY (group_id) REFERENCES ws_group(id) ON DELETE CASCADE,
+                                               CONSTRAINT fk_ws_group_member_user FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE
+);
+
+-- 已有数据库：成员需要接受邀请，已有成员视为已接受
+ALTER TABLE ws_group_member ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('invited', 'active'));
+
+-- 添加注释
+COMMENT ON TABLE ws_group IS '共享空间分组表';
+COMMENT ON COLUMN ws_group.id IS '分组ID';
+COMMENT ON COLUMN ws_group.name IS '分组名称（WebSocket 分组名，全局唯一）';
+COMMENT ON COLUMN ws_group.owner_id IS '创建者ID';
+COMMENT ON COLUMN ws_group.description IS '描述';
+COMMENT ON COLUMN ws_group.folder_path IS '关联的共享文件夹路径，目录内文件变化会通知分组';
+
+COMMENT ON TABLE ws_group_member IS '分组成员表';
+COMMENT ON COLUMN ws_group_member.group_id IS '分组ID';
+COMMENT ON COLUMN ws_group_member.user_id IS '用户ID';
+COMMENT ON COLUMN ws_group_member.role IS '角色：owner/admin/member';
+COMMENT ON COLUMN ws_group_member.status IS '状态：invited（已邀请，未接受）/active（已加入）';
+
+-- 创建索引
+CREATE INDEX idx_ws_group_owner ON ws_group(owner_id);
+CREATE INDEX idx_ws_group_folder ON ws_group(folder_path);
+CREATE INDEX idx_ws_group_member_user ON ws_group_member(user_id);
+
+-- 创建触发器（自动更新updated_at）
+CREATE OR REPLACE FUNCTION update_ws_group_updated_at()
+    RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_ws_group_updated_at
+    BEFORE UPDATE ON ws_group
+    FOR EACH ROW
+EXECUTE FUNCTION update_ws_group_updated_at();
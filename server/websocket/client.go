@@ -10,6 +10,7 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket/protocol"
 	"go.uber.org/zap"
 )
 
@@ -148,6 +149,7 @@ func (c *Client) doConnect() error {
 	// 建立连接
 	dialer := websocket.Dialer{
 		HandshakeTimeout: 10 * time.Second,
+		Subprotocols:     protocol.Subprotocols(),
 	}
 
 	conn, _, err := dialer.Dial(u.String(), header)
@@ -0,0 +1,163 @@
+package websocket
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket/protocol"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// commandTTL 设备指令的有效期，超过后不再下发
+const commandTTL = 7 * 24 * time.Hour
+
+// commandStore 设备指令持久化（device_command 表）
+var commandStore *gorm.DB
+
+// DispatchCommand 下发设备指令，设备在线时立即发送并标记为 sent，离线时保持 pending 等待下次连接
+// 返回指令是否已送达连接
+func DispatchCommand(cmd *model.DeviceCommand) bool {
+	conn, ok := GetHub().GetConnectionByDevice(cmd.UserID, cmd.DeviceID)
+	if !ok || conn.UserID != cmd.UserID {
+		return false
+	}
+	return sendCommand(conn, cmd)
+}
+
+// sendCommand 发送指令到连接并更新状态
+func sendCommand(conn *Connection, cmd *model.DeviceCommand) bool {
+	content := protocol.DeviceCommand{
+		CommandID: cmd.ID,
+		Command:   cmd.Command,
+		CreatedAt: cmd.CreatedAt.Unix(),
+	}
+	if cmd.Params != "" {
+		content.Params = json.RawMessage(cmd.Params)
+	}
+
+	msg := NewMessage(MessageTypeCommand, content)
+	msg.Target = NewTargetDevice(cmd.DeviceID)
+	if err := conn.SendMessage(msg); err != nil {
+		logger.Warn("下发设备指令失败",
+			zap.String("conn_id", conn.ID),
+			zap.Uint("command_id", cmd.ID),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	now := time.Now()
+	cmd.Status = model.DeviceCommandStatusSent
+	cmd.SentAt = &now
+	if commandStore != nil {
+		if err := commandStore.Model(cmd).Updates(map[string]interface{}{
+			"status":  cmd.Status,
+			"sent_at": now,
+		}).Error; err != nil {
+			logger.Error("更新指令状态失败", zap.Uint("command_id", cmd.ID), zap.Error(err))
+		}
+	}
+	return true
+}
+
+// deliverPendingCommands 设备连接后下发未确认的指令
+func deliverPendingCommands(conn *Connection) {
+	if commandStore == nil {
+		return
+	}
+
+	cmds, err := model.PendingDeviceCommands(commandStore, conn.UserID, conn.Device.DeviceID)
+	if err != nil {
+		logger.Error("查询待下发指令失败",
+			zap.String("device_id", conn.Device.DeviceID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for i := range cmds {
+		cmd := &cmds[i]
+
+		// 能连上来说明设备持有的是吊销之后重新签发的token，旧的退出指令已失效
+		if cmd.Command == model.DeviceCommandSignOut {
+			expireCommand(cmd, "设备已重新登录")
+			continue
+		}
+		if time.Since(cmd.CreatedAt) > commandTTL {
+			expireCommand(cmd, "指令已过期")
+			continue
+		}
+
+		if !sendCommand(conn, cmd) {
+			return
+		}
+	}
+}
+
+// expireCommand 将指令标记为失效
+func expireCommand(cmd *model.DeviceCommand, reason string) {
+	if err := commandStore.Model(cmd).Updates(map[string]interface{}{
+		"status": model.DeviceCommandStatusExpired,
+		"result": reason,
+	}).Error; err != nil {
+		logger.Error("更新指令状态失败", zap.Uint("command_id", cmd.ID), zap.Error(err))
+	}
+}
+
+// handleCommandAck 处理设备回执
+func handleCommandAck(conn *Connection, msg *Message) {
+	// 内容已在 handleTextMessage 中按协议校验
+	var ack protocol.CommandAck
+	if err := json.Unmarshal(msg.Content, &ack); err != nil {
+		conn.sendProtocolError(protocol.NewError(protocol.ErrCodeInvalidPayload, "指令回执格式错误").WithRef(msg.ID))
+		return
+	}
+	if commandStore == nil {
+		return
+	}
+
+	var cmd model.DeviceCommand
+	err := commandStore.
+		Where("id = ? AND user_id = ? AND device_id = ?", ack.CommandID, conn.UserID, conn.Device.DeviceID).
+		First(&cmd).Error
+	if err != nil {
+		logger.Warn("未找到回执对应的指令",
+			zap.String("conn_id", conn.ID),
+			zap.Uint("command_id", ack.CommandID),
+			zap.Error(err),
+		)
+		conn.sendProtocolError(protocol.NewError(protocol.ErrCodeNotFound, "指令不存在").WithRef(msg.ID))
+		return
+	}
+	if cmd.IsFinished() {
+		return
+	}
+
+	status := model.DeviceCommandStatusCompleted
+	if !ack.Success {
+		status = model.DeviceCommandStatusFailed
+	}
+	if err := commandStore.Model(&cmd).Updates(map[string]interface{}{
+		"status":   status,
+		"result":   ack.Result,
+		"acked_at": time.Now(),
+	}).Error; err != nil {
+		logger.Error("更新指令状态失败", zap.Uint("command_id", cmd.ID), zap.Error(err))
+	}
+
+	logger.Info("设备已确认指令",
+		zap.Uint("user_id", conn.UserID),
+		zap.String("device_id", conn.Device.DeviceID),
+		zap.Uint("command_id", cmd.ID),
+		zap.String("command", cmd.Command),
+		zap.String("status", status),
+	)
+
+	// 设备确认退出后断开连接，token 已在下发时吊销，无法再重连
+	if cmd.Command == model.DeviceCommandSignOut {
+		conn.Close()
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket/protocol"
 	"go.uber.org/zap"
 )
 
@@ -24,10 +25,13 @@ type Connection struct {
 	ConnectedAt   time.Time              // 连接时间
 	LastHeartbeat time.Time              // 最后心跳时间
 	IP            string                 // 客户端IP
+	Version       int                    // 协商的协议版本
 	mu            sync.RWMutex           // 读写锁
 	closeChan     chan struct{}          // 关闭信号
 	closeOnce     sync.Once              // 确保只关闭一次
 	metadata      map[string]interface{} // 连接元数据
+
+	offlineAudience []uint // 断开时的在线状态接收者（断开后用户可能已不在任何分组）
 }
 
 // NewConnection 创建新的WebSocket连接
@@ -55,6 +59,7 @@ func NewConnection(userID uint, conn *websocket.Conn, hub *Hub, device *DeviceIn
 		IsAlive:       true,
 		ConnectedAt:   time.Now(),
 		LastHeartbeat: time.Now(),
+		Version:       protocol.CurrentVersion,
 		closeChan:     make(chan struct{}),
 		metadata:      make(map[string]interface{}),
 	}
@@ -167,13 +172,26 @@ func (c *Connection) writePump() {
 
 // handleTextMessage 处理文本消息
 func (c *Connection) handleTextMessage(data []byte) {
+	c.Hub.countReceived()
+
 	var msg Message
 	if err := json.Unmarshal(data, &msg); err != nil {
 		logger.Error("消息解析失败",
 			zap.String("conn_id", c.ID),
 			zap.Error(err),
 		)
-		c.sendError("消息格式错误")
+		c.sendProtocolError(protocol.NewError(protocol.ErrCodeInvalidJSON, "消息不是合法的JSON"))
+		return
+	}
+
+	// 按协议校验消息类型和内容
+	if perr := protocol.ValidateClientFrame(string(msg.Type), msg.Content, msg.Target != nil); perr != nil {
+		logger.Warn("消息校验失败",
+			zap.String("conn_id", c.ID),
+			zap.String("type", string(msg.Type)),
+			zap.String("code", string(perr.Code)),
+		)
+		c.sendProtocolError(perr.WithRef(msg.ID))
 		return
 	}
 
@@ -183,6 +201,7 @@ func (c *Connection) handleTextMessage(data []byte) {
 		ConnID:   c.ID,
 		DeviceID: c.Device.DeviceID,
 	}
+	msg.Version = c.Version
 	msg.Timestamp = time.Now().Unix()
 
 	// 根据消息类型处理
@@ -205,9 +224,7 @@ func (c *Connection) handleBinaryMessage(data []byte) {
 // handleHeartbeat 处理心跳
 func (c *Connection) handleHeartbeat() {
 	c.updateHeartbeat()
-	ack := NewMessage(MessageTypeAck, map[string]interface{}{
-		"type": "heartbeat_ack",
-	})
+	ack := NewMessage(MessageTypeAck, protocol.HeartbeatAck{Type: "heartbeat_ack"})
 	_ = c.SendMessage(ack)
 }
 
@@ -273,12 +290,9 @@ func (c *Connection) SendJSON(v interface{}) error {
 	return c.Send(data)
 }
 
-// sendError 发送错误消息
-func (c *Connection) sendError(errMsg string) {
-	msg := NewMessage(MessageTypeSystem, map[string]interface{}{
-		"error": errMsg,
-	})
-	_ = c.SendMessage(msg)
+// sendProtocolError 发送结构化的协议错误
+func (c *Connection) sendProtocolError(perr *protocol.Error) {
+	_ = c.SendMessage(NewMessage(MessageTypeError, perr))
 }
 
 // updateHeartbeat 更新心跳时间
@@ -286,6 +300,10 @@ func (c *Connection) updateHeartbeat() {
 	c.mu.Lock()
 	c.LastHeartbeat = time.Now()
 	c.mu.Unlock()
+
+	if c.Hub.onHeartbeat != nil {
+		c.Hub.onHeartbeat(c)
+	}
 }
 
 // SetDeviceStatus 设置设备状态
@@ -0,0 +1,107 @@
+package websocket
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// deviceTouchInterval 心跳写库的最小间隔，避免每次心跳都更新 device 表
+const deviceTouchInterval = time.Minute
+
+// deviceTracker 把 WebSocket 连接上的设备同步到 device 表
+type deviceTracker struct {
+	db        *gorm.DB
+	mu        sync.Mutex
+	lastTouch map[string]time.Time // 连接ID -> 最后写库时间
+}
+
+// newDeviceTracker 创建设备跟踪器
+func newDeviceTracker(db *gorm.DB) *deviceTracker {
+	return &deviceTracker{
+		db:        db,
+		lastTouch: make(map[string]time.Time),
+	}
+}
+
+// onHeartbeat 心跳时更新 last_active（按 deviceTouchInterval 节流）
+func (t *deviceTracker) onHeartbeat(conn *Connection) {
+	if conn.Device.RecordID == 0 {
+		return
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	if last, ok := t.lastTouch[conn.ID]; ok && now.Sub(last) < deviceTouchInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.lastTouch[conn.ID] = now
+	t.mu.Unlock()
+
+	t.touch(conn, now)
+}
+
+// onDisconnect 断开时记录最后活跃时间
+func (t *deviceTracker) onDisconnect(conn *Connection) {
+	t.mu.Lock()
+	delete(t.lastTouch, conn.ID)
+	t.mu.Unlock()
+
+	if conn.Device.RecordID == 0 {
+		return
+	}
+	t.touch(conn, time.Now())
+}
+
+// touch 写入 last_active
+func (t *deviceTracker) touch(conn *Connection, now time.Time) {
+	err := t.db.Model(&model.Device{}).
+		Where("id = ?", conn.Device.RecordID).
+		Updates(map[string]interface{}{
+			"last_active": now,
+			"updated_at":  now,
+		}).Error
+	if err != nil {
+		logger.Error("更新设备活跃时间失败",
+			zap.String("conn_id", conn.ID),
+			zap.Uint("device_record_id", conn.Device.RecordID),
+			zap.Error(err),
+		)
+	}
+}
+
+// toModelDeviceType 把连接上报的设备类型转换为 device 表的设备类型
+func toModelDeviceType(deviceType DeviceType, platform string) string {
+	switch deviceType {
+	case DeviceTypeAndroid, DeviceTypeIOS:
+		return model.DeviceTypeMobile
+	case DeviceTypeWeb:
+		return model.DeviceTypeWeb
+	}
+
+	p := strings.ToLower(platform)
+	switch {
+	case strings.Contains(p, "windows"):
+		return model.DeviceTypeWindows
+	case strings.Contains(p, "mac"), strings.Contains(p, "darwin"):
+		return model.DeviceTypeMac
+	case strings.Contains(p, "linux"):
+		return model.DeviceTypeLinux
+	}
+
+	if deviceType == DeviceTypeUnknown {
+		return string(DeviceTypeUnknown)
+	}
+	return string(deviceType)
+}
+
+// ModelDeviceType 供 HTTP 接口使用：把客户端上报的设备类型字符串转换为 device 表的设备类型
+func ModelDeviceType(deviceType, platform string) string {
+	return toModelDeviceType(parseDeviceType(deviceType), platform)
+}
@@ -0,0 +1,111 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/sunyuanling/server/internal/access"
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket/protocol"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// groupStore 分组持久化（ws_group / ws_group_member 表）
+// Hub.groups 只保存在线成员，连接建立时从数据库恢复
+var groupStore *gorm.DB
+
+// rehydrateGroups 连接建立后把用户加入其持久化的分组
+func rehydrateGroups(conn *Connection) {
+	if groupStore == nil {
+		return
+	}
+
+	names, err := model.UserGroupNames(groupStore, conn.UserID)
+	if err != nil {
+		logger.Error("恢复用户分组失败",
+			zap.Uint("user_id", conn.UserID),
+			zap.Error(err),
+		)
+		return
+	}
+	for _, name := range names {
+		GetHub().AddToGroup(name, conn.UserID)
+	}
+}
+
+// JoinGroup 成员加入分组后同步到 Hub（仅在线用户）
+func JoinGroup(groupName string, userID uint) {
+	hub := GetHub()
+	if hub.IsUserOnline(userID) {
+		hub.AddToGroup(groupName, userID)
+	}
+}
+
+// LeaveGroup 成员退出分组后同步到 Hub
+func LeaveGroup(groupName string, userID uint) {
+	GetHub().RemoveFromGroup(groupName, userID)
+}
+
+// DeleteGroup 分组删除后同步到 Hub
+func DeleteGroup(groupName string) {
+	GetHub().RemoveGroup(groupName)
+}
+
+// NotifyFolderChange 文件变化时通知共享文件夹包含该路径的分组成员和文件夹共享的接收人（只通知对该路径有读权限的用户）
+func NotifyFolderChange(userID uint, path, action string) {
+	if groupStore == nil {
+		return
+	}
+
+	readable := newReadChecker(groupStore, path)
+	notifyShareFolderChange(userID, path, action, readable)
+
+	groups, err := model.GroupsForPath(groupStore, path)
+	if err != nil {
+		logger.Error("查询共享文件夹分组失败", zap.String("path", path), zap.Error(err))
+		return
+	}
+
+	for _, g := range groups {
+		memberIDs, err := model.GroupMemberIDs(groupStore, g.ID)
+		if err != nil {
+			logger.Error("查询分组成员失败", zap.Uint("group_id", g.ID), zap.Error(err))
+			continue
+		}
+		msg := protocol.FolderChanged{
+			Event:      protocol.EventFolderChange,
+			GroupID:    g.ID,
+			GroupName:  g.Name,
+			FolderPath: g.FolderPath,
+			Path:       path,
+			Action:     action,
+			UserID:     userID,
+			Time:       time.Now().Unix(),
+		}
+		for _, id := range memberIDs {
+			if readable(id) {
+				_ = SendToUser(id, MessageTypeFileSync, msg)
+			}
+		}
+	}
+}
+
+// newReadChecker 推送前逐个确认接收人对路径有读权限（rbac 或共享），同一次推送内缓存结果
+// 分组的共享文件夹或成员的权限可能已经变化，不能只按成员关系推送
+func newReadChecker(db *gorm.DB, path string) func(userID uint) bool {
+	checked := make(map[uint]bool)
+	return func(userID uint) bool {
+		if ok, done := checked[userID]; done {
+			return ok
+		}
+		ok, err := access.UserCanAccess(db, userID, rbac.PermFilesRead, path)
+		if err != nil {
+			logger.Error("检查文件读取权限失败", zap.Uint("user_id", userID), zap.String("path", path), zap.Error(err))
+			ok = false
+		}
+		checked[userID] = ok
+		return ok
+	}
+}
@@ -4,14 +4,18 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"github.com/sunyuanling/server/internal/audit"
 	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/internal/rbac"
 	"github.com/sunyuanling/server/pkg/logger"
 	"github.com/sunyuanling/server/pkg/response"
 	token "github.com/sunyuanling/server/pkg/tokn"
+	"github.com/sunyuanling/server/websocket/protocol"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -33,6 +37,7 @@ func NewHandler(db *gorm.DB) *Handler {
 			CheckOrigin: func(r *http.Request) bool {
 				return true
 			},
+			Subprotocols: protocol.Subprotocols(),
 		},
 		hub: GetHub(),
 	}
@@ -45,6 +50,7 @@ type ConnectRequest struct {
 	DeviceName string `form:"device_name"`
 	Platform   string `form:"platform"`
 	AppVersion string `form:"app_version"`
+	Protocol   string `form:"protocol"` // 协议版本，也可通过 Sec-WebSocket-Protocol 协商
 }
 
 // Connect WebSocket连接入口
@@ -77,6 +83,50 @@ func (h *Handler) Connect(c *gin.Context) {
 	var req ConnectRequest
 	_ = c.ShouldBindQuery(&req)
 
+	// 协商协议版本
+	version, err := protocol.Negotiate(req.Protocol, websocket.Subprotocols(c.Request))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// 设备绑定的token只能以该设备身份连接
+	if payload.DeviceID != "" {
+		if req.DeviceID != "" && req.DeviceID != payload.DeviceID {
+			logger.Warn("设备token与连接设备不匹配",
+				zap.Uint("user_id", userID),
+				zap.String("token_device_id", payload.DeviceID),
+				zap.String("device_id", req.DeviceID),
+			)
+			response.Forbidden(c, "token与设备不匹配")
+			return
+		}
+		req.DeviceID = payload.DeviceID
+	}
+
+	// 没有设备ID（如网页端）时使用临时ID，只保留在内存中，不登记到 device 表，
+	// 否则每次连接都会新增一台设备
+	ephemeral := req.DeviceID == ""
+	if ephemeral {
+		req.DeviceID = generateUUID()
+	}
+
+	// 设备被远程退出登录后，之前签发的token不能再以该设备身份连接
+	revoked, err := token.GetGlobalTokenManager().IsDeviceRevoked(userID, req.DeviceID, payload.IssuedAtMilli())
+	if err != nil {
+		logger.Error("读取设备吊销记录失败", zap.Uint("user_id", userID), zap.Error(err))
+		response.Unauthorized(c, "无法确认登录状态，请稍后重试")
+		return
+	}
+	if revoked {
+		logger.Warn("已退出登录的设备尝试连接",
+			zap.Uint("user_id", userID),
+			zap.String("device_id", req.DeviceID),
+		)
+		response.Unauthorized(c, "设备已退出登录，请重新登录")
+		return
+	}
+
 	deviceInfo := &DeviceInfo{
 		DeviceID:   req.DeviceID,
 		DeviceType: parseDeviceType(req.DeviceType),
@@ -86,6 +136,30 @@ func (h *Handler) Connect(c *gin.Context) {
 		Status:     DeviceStatusOnline,
 	}
 
+	// 登记设备，已禁用的设备拒绝连接
+	if !ephemeral {
+		device, err := h.registerDevice(userID, deviceInfo, c.ClientIP())
+		if err != nil {
+			logger.Error("设备登记失败",
+				zap.Uint("user_id", userID),
+				zap.String("device_id", deviceInfo.DeviceID),
+				zap.Error(err),
+			)
+			response.InternalError(c, "设备登记失败")
+			return
+		}
+		if !device.IsActive() {
+			logger.Warn("已禁用的设备尝试连接",
+				zap.Uint("user_id", userID),
+				zap.String("device_id", deviceInfo.DeviceID),
+			)
+			response.Forbidden(c, "设备已被禁用")
+			return
+		}
+		deviceInfo.RecordID = device.ID
+		deviceInfo.DeviceName = device.DeviceName
+	}
+
 	// 升级连接
 	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -96,11 +170,43 @@ func (h *Handler) Connect(c *gin.Context) {
 	// 创建连接
 	connection := NewConnection(userID, conn, h.hub, deviceInfo)
 	connection.IP = c.ClientIP()
+	connection.Version = version
 	connection.SetMetadata("username", user.Username)
 	connection.SetMetadata("role", user.Role)
 	connection.Start()
 }
 
+// registerDevice 将设备写入 device 表
+// 已禁用的设备不做任何更新，直接返回现有记录
+func (h *Handler) registerDevice(userID uint, info *DeviceInfo, ip string) (*model.Device, error) {
+	existing, err := model.FindDeviceByIdentifier(h.db, userID, info.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !existing.IsActive() {
+		return existing, nil
+	}
+
+	name := info.DeviceName
+	if name == "" {
+		name = string(info.DeviceType)
+	}
+
+	device := &model.Device{
+		UserID:     userID,
+		DeviceName: name,
+		DeviceType: toModelDeviceType(info.DeviceType, info.Platform),
+		DeviceID:   info.DeviceID,
+		OSVersion:  info.Platform,
+		AppVersion: info.AppVersion,
+		IPAddress:  ip,
+	}
+	if err := model.RegisterDevice(h.db, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
 // parseDeviceType 解析设备类型
 func parseDeviceType(s string) DeviceType {
 	switch s {
@@ -158,14 +264,18 @@ type SendMessageRequest struct {
 
 // SendMessage 发送消息
 func (h *Handler) SendMessage(c *gin.Context) {
+	payload, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	var req SendMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Error(c, http.StatusBadRequest, "请求参数错误")
 		return
 	}
 
-	senderIDVal, _ := c.Get("user_id")
-	senderID := uint(senderIDVal.(float64))
+	senderID := uint(payload.UserID)
 
 	msg := &Message{
 		ID:   generateUUID(),
@@ -221,8 +331,11 @@ func (h *Handler) BroadcastMessage(c *gin.Context) {
 		return
 	}
 
-	senderIDVal, _ := c.Get("user_id")
-	senderID := uint(senderIDVal.(float64))
+	payload, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	senderID := uint(payload.UserID)
 
 	msg := &Message{
 		ID:   generateUUID(),
@@ -272,6 +385,12 @@ func (h *Handler) DisconnectConn(c *gin.Context) {
 	}
 
 	conn.Close()
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionWSDisconnect,
+		TargetType: audit.TargetConnection,
+		TargetID:   connID,
+		Detail:     map[string]interface{}{"user_id": conn.UserID, "device_id": conn.Device.DeviceID},
+	})
 	response.Success(c, gin.H{
 		"message": "连接已断开",
 		"conn_id": connID,
@@ -296,6 +415,12 @@ func (h *Handler) DisconnectUser(c *gin.Context) {
 	for _, conn := range conns {
 		conn.Close()
 	}
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionWSDisconnect,
+		TargetType: audit.TargetUser,
+		TargetID:   userIDStr,
+		Detail:     map[string]interface{}{"conn_count": len(conns)},
+	})
 
 	response.Success(c, gin.H{
 		"message":    "用户所有连接已断开",
@@ -308,19 +433,149 @@ func (h *Handler) DisconnectUser(c *gin.Context) {
 func (h *Handler) DisconnectDevice(c *gin.Context) {
 	deviceID := c.Param("device_id")
 
-	conn, exists := h.hub.GetConnectionByDevice(deviceID)
-	if !exists {
+	// 设备ID可能被不同用户使用，断开全部使用该ID的连接
+	conns := h.hub.ConnectionsByDeviceID(deviceID)
+	if len(conns) == 0 {
 		response.Error(c, http.StatusNotFound, "设备不在线")
 		return
 	}
 
-	conn.Close()
+	userIDs := make([]uint, 0, len(conns))
+	for _, conn := range conns {
+		conn.Close()
+		userIDs = append(userIDs, conn.UserID)
+	}
+	audit.Log(c, audit.Event{
+		Action:     audit.ActionWSDisconnect,
+		TargetType: audit.TargetDevice,
+		TargetID:   deviceID,
+		Detail:     map[string]interface{}{"user_ids": userIDs},
+	})
 	response.Success(c, gin.H{
 		"message":   "设备已断开",
 		"device_id": deviceID,
 	})
 }
 
+// DevicePresence 设备在线状态
+type DevicePresence struct {
+	DeviceID   string       `json:"device_id"`
+	DeviceName string       `json:"device_name"`
+	DeviceType string       `json:"device_type"`
+	Status     DeviceStatus `json:"status"`
+	LastSeen   *time.Time   `json:"last_seen"`
+}
+
+// UserPresence 用户在线状态（多设备聚合）
+type UserPresence struct {
+	UserID   uint             `json:"user_id"`
+	Status   DeviceStatus     `json:"status"`
+	LastSeen *time.Time       `json:"last_seen"`
+	Devices  []DevicePresence `json:"devices"`
+}
+
+// GetPresence 查询在线状态
+// 只能查询本人和与自己同组的用户，user_ids 为逗号分隔的用户ID，默认查询本人
+func (h *Handler) GetPresence(c *gin.Context) {
+	payload, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	self := uint(payload.UserID)
+
+	userIDs := []uint{self}
+	if raw := c.Query("user_ids"); raw != "" {
+		userIDs = userIDs[:0]
+		for _, part := range strings.Split(raw, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				response.BadRequest(c, "无效的用户ID")
+				return
+			}
+			userIDs = append(userIDs, uint(id))
+		}
+	}
+
+	result := make([]UserPresence, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID != self && !h.hub.SharesGroup(self, userID) {
+			response.Forbidden(c, "无权查看该用户的在线状态")
+			return
+		}
+
+		presence, err := h.userPresence(userID)
+		if err != nil {
+			logger.Error("查询在线状态失败", zap.Uint("user_id", userID), zap.Error(err))
+			response.InternalError(c, "查询在线状态失败")
+			return
+		}
+		result = append(result, presence)
+	}
+
+	response.Success(c, gin.H{
+		"list": result,
+	})
+}
+
+// userPresence 合并 device 表记录和当前连接，得到用户的在线状态
+func (h *Handler) userPresence(userID uint) (UserPresence, error) {
+	var devices []model.Device
+	if err := h.db.Where("user_id = ? AND status = ?", userID, model.DeviceStatusActive).
+		Order("last_active desc nulls last").
+		Find(&devices).Error; err != nil {
+		return UserPresence{}, err
+	}
+
+	online := make(map[string]DeviceStatus)
+	for _, conn := range h.hub.GetUserConnections(userID) {
+		online[conn.Device.DeviceID] = conn.GetDeviceStatus()
+	}
+
+	presence := UserPresence{
+		UserID:  userID,
+		Status:  DeviceStatusOffline,
+		Devices: make([]DevicePresence, 0, len(devices)),
+	}
+	for _, d := range devices {
+		status, ok := online[d.DeviceID]
+		if !ok {
+			status = DeviceStatusOffline
+		}
+		presence.Status = mergePresence(presence.Status, status)
+		if d.LastActive != nil && (presence.LastSeen == nil || d.LastActive.After(*presence.LastSeen)) {
+			presence.LastSeen = d.LastActive
+		}
+		presence.Devices = append(presence.Devices, DevicePresence{
+			DeviceID:   d.DeviceID,
+			DeviceName: d.DeviceName,
+			DeviceType: d.DeviceType,
+			Status:     status,
+			LastSeen:   d.LastActive,
+		})
+	}
+	return presence, nil
+}
+
+// currentUser 获取当前登录用户，失败时已写入响应
+func currentUser(c *gin.Context) (*token.TokenPayload, bool) {
+	userInfoAny, exists := c.Get("UserInfo")
+	if !exists || userInfoAny == nil {
+		response.Unauthorized(c, "未授权")
+		return nil, false
+	}
+	payload, ok := userInfoAny.(*token.TokenPayload)
+	if !ok {
+		response.InternalError(c, "用户信息类型错误")
+		return nil, false
+	}
+	return payload, true
+}
+
+// GetProtocolSchema 获取 WebSocket 协议的 JSON Schema，供客户端生成代码
+func (h *Handler) GetProtocolSchema(c *gin.Context) {
+	c.JSON(http.StatusOK, ProtocolSchema())
+}
+
 // GetStats 获取统计信息
 func (h *Handler) GetStats(c *gin.Context) {
 	stats := h.hub.GetStats()
@@ -329,8 +584,13 @@ func (h *Handler) GetStats(c *gin.Context) {
 	})
 }
 
-// CreateGroup 创建分组
+// CreateGroup 创建分组（持久化，当前用户为 owner）
 func (h *Handler) CreateGroup(c *gin.Context) {
+	payload, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	var req struct {
 		GroupName string `json:"group_name" binding:"required"`
 		UserIDs   []uint `json:"user_ids" binding:"required"`
@@ -341,19 +601,53 @@ func (h *Handler) CreateGroup(c *gin.Context) {
 		return
 	}
 
-	for _, userID := range req.UserIDs {
-		h.hub.AddToGroup(req.GroupName, userID)
+	group := &model.WsGroup{
+		Name:    req.GroupName,
+		OwnerID: uint(payload.UserID),
 	}
+	if err := model.CreateWsGroup(h.db, group, req.UserIDs); err != nil {
+		logger.Error("创建分组失败", zap.String("group_name", req.GroupName), zap.Error(err))
+		response.Error(c, http.StatusConflict, "分组已存在或创建失败")
+		return
+	}
+
+	// 其他用户收到邀请，接受后才加入（/group/accept）
+	JoinGroup(group.Name, group.OwnerID)
 
 	response.Success(c, gin.H{
 		"message":    "分组创建成功",
+		"group_id":   group.ID,
 		"group_name": req.GroupName,
-		"users":      req.UserIDs,
+		"invited":    req.UserIDs,
 	})
 }
 
+// groupMembership 按名称查询分组并校验当前用户是成员，失败时已写入响应
+func (h *Handler) groupMembership(c *gin.Context, groupName string, userID uint) (*model.WsGroup, bool) {
+	var group model.WsGroup
+	if err := h.db.Where("name = ?", groupName).First(&group).Error; err != nil {
+		response.NotFound(c, "分组不存在")
+		return nil, false
+	}
+	member, err := model.FindGroupMember(h.db, group.ID, userID)
+	if err != nil {
+		response.InternalError(c, "查询分组成员失败")
+		return nil, false
+	}
+	if member == nil || !member.IsActive() {
+		response.Forbidden(c, "不是该分组成员")
+		return nil, false
+	}
+	return &group, true
+}
+
 // SendToGroup 发送消息给分组
 func (h *Handler) SendToGroup(c *gin.Context) {
+	payload, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	var req struct {
 		GroupName string          `json:"group_name" binding:"required"`
 		Type      string          `json:"type" binding:"required"`
@@ -365,12 +659,15 @@ func (h *Handler) SendToGroup(c *gin.Context) {
 		return
 	}
 
-	senderIDVal, _ := c.Get("user_id")
-	senderID := uint(senderIDVal.(float64))
+	senderID := uint(payload.UserID)
+	if _, ok := h.groupMembership(c, req.GroupName, senderID); !ok {
+		return
+	}
 
 	msg := &Message{
-		ID:   generateUUID(),
-		Type: MessageType(req.Type),
+		ID:      generateUUID(),
+		Version: protocol.CurrentVersion,
+		Type:    MessageType(req.Type),
 		From: &Sender{
 			UserID: senderID,
 		},
@@ -387,41 +684,72 @@ func (h *Handler) SendToGroup(c *gin.Context) {
 	})
 }
 
-// GetGroupUsers 获取分组用户
+// GetGroupUsers 获取分组用户（全部成员及其是否在线）
 func (h *Handler) GetGroupUsers(c *gin.Context) {
+	payload, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	groupName := c.Param("name")
-	users := h.hub.GetGroupUsers(groupName)
+	group, ok := h.groupMembership(c, groupName, uint(payload.UserID))
+	if !ok {
+		return
+	}
+
+	memberIDs, err := model.GroupMemberIDs(h.db, group.ID)
+	if err != nil {
+		response.InternalError(c, "查询分组成员失败")
+		return
+	}
+
+	online := make([]uint, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		if h.hub.IsUserOnline(id) {
+			online = append(online, id)
+		}
+	}
 
 	response.Success(c, gin.H{
 		"group_name": groupName,
-		"users":      users,
-		"count":      len(users),
+		"users":      memberIDs,
+		"online":     online,
+		"count":      len(memberIDs),
 	})
 }
 
+// PermissionGuard 按全局权限拦截请求的中间件（由网关传入 middleware.RequirePermission，websocket 包不能直接引用 middleware）
+type PermissionGuard func(perms ...string) gin.HandlerFunc
+
 // RegisterRoutes 注册路由
-func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, requirePermission PermissionGuard) {
 	ws := router.Group("/ws")
 	{
 		// WebSocket连接
 		ws.GET("/connect", h.Connect)
 
-		// 在线状态
-		ws.GET("/online", h.GetOnlineUsers)
-		ws.GET("/user/:id/connections", h.GetUserConnections)
-		ws.GET("/stats", h.GetStats)
+		// 在线状态（本人和同组用户）
+		ws.GET("/presence", h.GetPresence)
+
+		// 协议定义
+		ws.GET("/protocol/schema", h.GetProtocolSchema)
 
-		// 消息发送
-		ws.POST("/send", h.SendMessage)
-		ws.POST("/broadcast", h.BroadcastMessage)
+		// 全部在线用户、连接和统计，需要查看系统监控权限
+		monitor := requirePermission(rbac.PermSystemMonitor)
+		ws.GET("/online", monitor, h.GetOnlineUsers)
+		ws.GET("/user/:id/connections", monitor, h.GetUserConnections)
+		ws.GET("/stats", monitor, h.GetStats)
 
-		// 断开连接
-		ws.DELETE("/conn/:conn_id", h.DisconnectConn)
-		ws.DELETE("/user/:id", h.DisconnectUser)
-		ws.DELETE("/device/:device_id", h.DisconnectDevice)
+		// 向任意用户/连接发消息、广播、断开连接，需要用户管理权限
+		manage := requirePermission(rbac.PermUserManage)
+		ws.POST("/send", manage, h.SendMessage)
+		ws.POST("/broadcast", manage, h.BroadcastMessage)
+		ws.DELETE("/conn/:conn_id", manage, h.DisconnectConn)
+		ws.DELETE("/user/:id", manage, h.DisconnectUser)
+		ws.DELETE("/device/:device_id", manage, h.DisconnectDevice)
 
 		// 分组管理
-		ws.POST("/group", h.CreateGroup)
+		ws.POST("/group", requirePermission(rbac.PermGroupManage), h.CreateGroup)
 		ws.POST("/group/send", h.SendToGroup)
 		ws.GET("/group/:name/users", h.GetGroupUsers)
 	}
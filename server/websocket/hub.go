@@ -2,10 +2,12 @@ package websocket
 
 import (
 	"encoding/json"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket/protocol"
 	"go.uber.org/zap"
 )
 
@@ -22,7 +24,8 @@ type Hub struct {
 	// 用户ID -> 连接ID集合 (一个用户可以有多个连接)
 	connsByUser map[uint]map[string]bool
 
-	// 设备ID -> 连接ID (一个设备同时只有一个连接)
+	// 用户ID+设备ID -> 连接ID (一个设备同时只有一个连接)
+	// 设备ID由客户端提供，不同用户可能使用相同的ID，必须带上用户ID区分
 	connByDevice map[string]string
 
 	// 分组: 分组名 -> 用户ID集合
@@ -39,9 +42,14 @@ type Hub struct {
 	// 事件回调
 	onConnect    func(*Connection)
 	onDisconnect func(*Connection)
+	onHeartbeat  func(*Connection)
+
+	// 客户端是否可以广播给全部用户，未设置时拒绝
+	canBroadcast func(*Connection) bool
 
 	// 统计
-	stats *Stats
+	stats   *Stats
+	statsMu sync.RWMutex
 
 	mu sync.RWMutex
 }
@@ -57,7 +65,6 @@ type Stats struct {
 	TotalMessagesSent  int64     `json:"total_messages_sent"`
 	TotalMessagesRecv  int64     `json:"total_messages_recv"`
 	LastConnectionTime time.Time `json:"last_connection_time"`
-	mu                 sync.RWMutex
 }
 
 // GetHub 获取全局Hub实例
@@ -113,7 +120,8 @@ func (h *Hub) handleRegister(conn *Connection) {
 	defer h.mu.Unlock()
 
 	// 如果同一设备已有连接，关闭旧连接
-	if oldConnID, exists := h.connByDevice[conn.Device.DeviceID]; exists {
+	key := deviceKey(conn.UserID, conn.Device.DeviceID)
+	if oldConnID, exists := h.connByDevice[key]; exists {
 		if oldConn, ok := h.connByID[oldConnID]; ok {
 			logger.Info("同一设备已存在连接，关闭旧连接",
 				zap.String("device_id", conn.Device.DeviceID),
@@ -126,7 +134,7 @@ func (h *Hub) handleRegister(conn *Connection) {
 
 	// 注册连接
 	h.connByID[conn.ID] = conn
-	h.connByDevice[conn.Device.DeviceID] = conn.ID
+	h.connByDevice[key] = conn.ID
 
 	// 添加到用户连接集合
 	if h.connsByUser[conn.UserID] == nil {
@@ -135,12 +143,12 @@ func (h *Hub) handleRegister(conn *Connection) {
 	h.connsByUser[conn.UserID][conn.ID] = true
 
 	// 更新统计
-	h.stats.mu.Lock()
+	h.statsMu.Lock()
 	h.stats.TotalConnections++
 	h.stats.ActiveConnections = len(h.connByID)
 	h.stats.ActiveUsers = len(h.connsByUser)
 	h.stats.LastConnectionTime = time.Now()
-	h.stats.mu.Unlock()
+	h.statsMu.Unlock()
 
 	// 触发回调
 	if h.onConnect != nil {
@@ -155,10 +163,11 @@ func (h *Hub) handleRegister(conn *Connection) {
 	)
 
 	// 发送欢迎消息
-	welcome := NewMessage(MessageTypeSystem, map[string]interface{}{
-		"event":     "connected",
-		"conn_id":   conn.ID,
-		"device_id": conn.Device.DeviceID,
+	welcome := NewMessage(MessageTypeSystem, protocol.Connected{
+		Event:    protocol.EventConnected,
+		ConnID:   conn.ID,
+		DeviceID: conn.Device.DeviceID,
+		Version:  conn.Version,
 	})
 	_ = conn.SendMessage(welcome)
 }
@@ -172,16 +181,24 @@ func (h *Hub) handleUnregister(conn *Connection) {
 		return
 	}
 
+	// 离开分组前记录在线状态接收者，供下线通知使用
+	conn.offlineAudience = h.presenceAudienceLocked(conn.UserID)
+
 	// 移除连接
 	delete(h.connByID, conn.ID)
-	delete(h.connByDevice, conn.Device.DeviceID)
+	// 被同一设备的新连接替换时，索引已指向新连接
+	key := deviceKey(conn.UserID, conn.Device.DeviceID)
+	if h.connByDevice[key] == conn.ID {
+		delete(h.connByDevice, key)
+	}
+	unsubscribeAll(conn)
 
 	// 从用户连接集合中移除
 	if userConns, exists := h.connsByUser[conn.UserID]; exists {
 		delete(userConns, conn.ID)
 		if len(userConns) == 0 {
 			delete(h.connsByUser, conn.UserID)
-			// 用户完全离线，从所有分组移除
+			// 用户完全离线，从内存分组移除（持久化的成员关系在下次连接时恢复）
 			for groupName, group := range h.groups {
 				delete(group, conn.UserID)
 				if len(group) == 0 {
@@ -192,10 +209,10 @@ func (h *Hub) handleUnregister(conn *Connection) {
 	}
 
 	// 更新统计
-	h.stats.mu.Lock()
+	h.statsMu.Lock()
 	h.stats.ActiveConnections = len(h.connByID)
 	h.stats.ActiveUsers = len(h.connsByUser)
-	h.stats.mu.Unlock()
+	h.statsMu.Unlock()
 
 	// 触发回调
 	if h.onDisconnect != nil {
@@ -244,20 +261,66 @@ func (h *Hub) RouteMessage(from *Connection, msg *Message) {
 		return
 	}
 
+	// 客户端只能给自己或同组用户发消息，只能发到自己所在的分组
 	switch msg.Target.Type {
 	case TargetTypeUser:
-		h.SendToUsers(msg.Target.UserIDs, msg)
+		h.SendToUsers(h.reachableUsers(from, msg.Target.UserIDs), msg)
 	case TargetTypeConn:
-		h.SendToConns(msg.Target.ConnIDs, msg)
+		h.SendToConns(h.reachableConns(from, msg.Target.ConnIDs), msg)
 	case TargetTypeDevice:
-		h.SendToDevices(msg.Target.DeviceIDs, msg)
+		// 客户端只能给自己的设备发消息
+		h.SendToUserDevices(from.UserID, msg.Target.DeviceIDs, msg)
 	case TargetTypeGroup:
 		for _, group := range msg.Target.Groups {
+			if !h.IsGroupMember(group, from.UserID) {
+				logger.Warn("非分组成员尝试发送分组消息",
+					zap.Uint("user_id", from.UserID),
+					zap.String("group", group),
+				)
+				continue
+			}
 			h.SendToGroup(group, msg)
 		}
 	case TargetTypeAll:
-		h.Broadcast(msg)
+		h.BroadcastFrom(from, msg)
+	}
+}
+
+// BroadcastFrom 客户端发起的广播，需要通过 SetBroadcastAuthorizer 设置的检查
+func (h *Hub) BroadcastFrom(from *Connection, msg *Message) {
+	if h.canBroadcast == nil || !h.canBroadcast(from) {
+		logger.Warn("无权广播的用户尝试广播消息", zap.Uint("user_id", from.UserID))
+		return
+	}
+	h.Broadcast(msg)
+}
+
+// reachableUsers 过滤出发送方可以发消息的用户：本人和同组用户
+func (h *Hub) reachableUsers(from *Connection, userIDs []uint) []uint {
+	allowed := make([]uint, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id == from.UserID || h.SharesGroup(from.UserID, id) {
+			allowed = append(allowed, id)
+			continue
+		}
+		logger.Warn("拒绝向非同组用户发送消息",
+			zap.Uint("user_id", from.UserID),
+			zap.Uint("target_user_id", id),
+		)
+	}
+	return allowed
+}
+
+// reachableConns 过滤出发送方可以发消息的连接：本人和同组用户的连接
+func (h *Hub) reachableConns(from *Connection, connIDs []string) []string {
+	allowed := make([]string, 0, len(connIDs))
+	for _, id := range connIDs {
+		conn, ok := h.GetConnection(id)
+		if ok && (conn.UserID == from.UserID || h.SharesGroup(from.UserID, conn.UserID)) {
+			allowed = append(allowed, id)
+		}
 	}
+	return allowed
 }
 
 // Broadcast 广播消息
@@ -331,12 +394,17 @@ func (h *Hub) SendToConn(connID string, msg *Message) error {
 	return conn.SendMessage(msg)
 }
 
-// SendToDevices 发送消息给指定设备
+// SendToDevices 发送消息给指定设备ID的全部连接，不区分用户（仅供管理接口使用）
 func (h *Hub) SendToDevices(deviceIDs []string, msg *Message) {
+	h.sendToConnections(h.ConnectionsByDeviceID(deviceIDs...), msg)
+}
+
+// SendToUserDevices 发送消息给用户的指定设备
+func (h *Hub) SendToUserDevices(userID uint, deviceIDs []string, msg *Message) {
 	h.mu.RLock()
 	var conns []*Connection
 	for _, deviceID := range deviceIDs {
-		if connID, exists := h.connByDevice[deviceID]; exists {
+		if connID, exists := h.connByDevice[deviceKey(userID, deviceID)]; exists {
 			if conn, ok := h.connByID[connID]; ok {
 				conns = append(conns, conn)
 			}
@@ -347,10 +415,10 @@ func (h *Hub) SendToDevices(deviceIDs []string, msg *Message) {
 	h.sendToConnections(conns, msg)
 }
 
-// SendToDevice 发送消息给单个设备
-func (h *Hub) SendToDevice(deviceID string, msg *Message) error {
+// SendToDevice 发送消息给用户的单个设备
+func (h *Hub) SendToDevice(userID uint, deviceID string, msg *Message) error {
 	h.mu.RLock()
-	connID, exists := h.connByDevice[deviceID]
+	connID, exists := h.connByDevice[deviceKey(userID, deviceID)]
 	if !exists {
 		h.mu.RUnlock()
 		return ErrDeviceNotFound
@@ -403,9 +471,9 @@ func (h *Hub) sendToConnections(conns []*Connection, msg *Message) {
 	}
 	wg.Wait()
 
-	h.stats.mu.Lock()
+	h.statsMu.Lock()
 	h.stats.TotalMessagesSent += int64(len(conns))
-	h.stats.mu.Unlock()
+	h.statsMu.Unlock()
 }
 
 // AddToGroup 将用户添加到分组
@@ -432,6 +500,13 @@ func (h *Hub) RemoveFromGroup(groupName string, userID uint) {
 	}
 }
 
+// RemoveGroup 删除分组
+func (h *Hub) RemoveGroup(groupName string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.groups, groupName)
+}
+
 // GetGroupUsers 获取分组中的用户
 func (h *Hub) GetGroupUsers(groupName string) []uint {
 	h.mu.RLock()
@@ -449,6 +524,51 @@ func (h *Hub) GetGroupUsers(groupName string) []uint {
 	return users
 }
 
+// PresenceAudience 在线状态的接收者：用户本人和与其同在某个分组的用户
+func (h *Hub) PresenceAudience(userID uint) []uint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.presenceAudienceLocked(userID)
+}
+
+// presenceAudienceLocked 同 PresenceAudience，调用方需持有锁
+func (h *Hub) presenceAudienceLocked(userID uint) []uint {
+	seen := map[uint]bool{userID: true}
+	audience := []uint{userID}
+	for _, group := range h.groups {
+		if !group[userID] {
+			continue
+		}
+		for member := range group {
+			if !seen[member] {
+				seen[member] = true
+				audience = append(audience, member)
+			}
+		}
+	}
+	return audience
+}
+
+// IsGroupMember 判断用户是否在分组中（分组只包含已接受邀请的在线成员）
+func (h *Hub) IsGroupMember(groupName string, userID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.groups[groupName][userID]
+}
+
+// SharesGroup 判断两个用户是否同在某个分组
+func (h *Hub) SharesGroup(a, b uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, group := range h.groups {
+		if group[a] && group[b] {
+			return true
+		}
+	}
+	return false
+}
+
 // GetConnection 通过连接ID获取连接
 func (h *Hub) GetConnection(connID string) (*Connection, bool) {
 	h.mu.RLock()
@@ -457,12 +577,12 @@ func (h *Hub) GetConnection(connID string) (*Connection, bool) {
 	return conn, exists
 }
 
-// GetConnectionByDevice 通过设备ID获取连接
-func (h *Hub) GetConnectionByDevice(deviceID string) (*Connection, bool) {
+// GetConnectionByDevice 通过用户ID和设备ID获取连接
+func (h *Hub) GetConnectionByDevice(userID uint, deviceID string) (*Connection, bool) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	connID, exists := h.connByDevice[deviceID]
+	connID, exists := h.connByDevice[deviceKey(userID, deviceID)]
 	if !exists {
 		return nil, false
 	}
@@ -470,6 +590,24 @@ func (h *Hub) GetConnectionByDevice(deviceID string) (*Connection, bool) {
 	return conn, exists
 }
 
+// ConnectionsByDeviceID 使用指定设备ID的全部连接，不区分用户（仅供管理接口使用）
+func (h *Hub) ConnectionsByDeviceID(deviceIDs ...string) []*Connection {
+	wanted := make(map[string]bool, len(deviceIDs))
+	for _, id := range deviceIDs {
+		wanted[id] = true
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var conns []*Connection
+	for _, conn := range h.connByID {
+		if wanted[conn.Device.DeviceID] {
+			conns = append(conns, conn)
+		}
+	}
+	return conns
+}
+
 // GetUserConnections 获取用户的所有连接
 func (h *Hub) GetUserConnections(userID uint) []*Connection {
 	h.mu.RLock()
@@ -516,14 +654,19 @@ func (h *Hub) IsUserOnline(userID uint) bool {
 	return exists
 }
 
-// IsDeviceOnline 检查设备是否在线
-func (h *Hub) IsDeviceOnline(deviceID string) bool {
+// IsDeviceOnline 检查用户的设备是否在线
+func (h *Hub) IsDeviceOnline(userID uint, deviceID string) bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	_, exists := h.connByDevice[deviceID]
+	_, exists := h.connByDevice[deviceKey(userID, deviceID)]
 	return exists
 }
 
+// deviceKey 设备索引的 key
+func deviceKey(userID uint, deviceID string) string {
+	return strconv.FormatUint(uint64(userID), 10) + ":" + deviceID
+}
+
 // GetOnlineUsers 获取在线用户列表
 func (h *Hub) GetOnlineUsers() []uint {
 	h.mu.RLock()
@@ -561,10 +704,27 @@ func (h *Hub) SetConnectionHandler(onConnect, onDisconnect func(*Connection)) {
 	h.onDisconnect = onDisconnect
 }
 
+// SetHeartbeatHandler 设置心跳事件处理器（客户端心跳或 pong 时触发）
+func (h *Hub) SetHeartbeatHandler(onHeartbeat func(*Connection)) {
+	h.onHeartbeat = onHeartbeat
+}
+
+// SetBroadcastAuthorizer 设置客户端广播的权限检查
+func (h *Hub) SetBroadcastAuthorizer(canBroadcast func(*Connection) bool) {
+	h.canBroadcast = canBroadcast
+}
+
+// countReceived 累计收到的客户端消息数
+func (h *Hub) countReceived() {
+	h.statsMu.Lock()
+	h.stats.TotalMessagesRecv++
+	h.statsMu.Unlock()
+}
+
 // GetStats 获取统计信息
 func (h *Hub) GetStats() Stats {
-	h.stats.mu.RLock()
-	defer h.stats.mu.RUnlock()
+	h.statsMu.RLock()
+	defer h.statsMu.RUnlock()
 	return *h.stats
 }
 
@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/sunyuanling/server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	// 拒绝发送时会写日志
+	logger.Logger = zap.NewNop()
+	logger.Sugar = logger.Logger.Sugar()
+	os.Exit(m.Run())
+}
+
+// addConn 直接登记一个在线连接（不经过 Run 主循环）
+func addConn(h *Hub, userID uint) *Connection {
+	conn := &Connection{
+		ID:       fmt.Sprintf("conn-%d", userID),
+		UserID:   userID,
+		Device:   &DeviceInfo{DeviceID: fmt.Sprintf("device-%d", userID)},
+		SendChan: make(chan []byte, 8),
+		Hub:      h,
+		IsAlive:  true,
+	}
+	h.connByID[conn.ID] = conn
+	h.connByDevice[deviceKey(userID, conn.Device.DeviceID)] = conn.ID
+	h.connsByUser[userID] = map[string]bool{conn.ID: true}
+	return conn
+}
+
+func TestRouteMessageTargets(t *testing.T) {
+	h := NewHub()
+	alice, bob, mallory := addConn(h, 1), addConn(h, 2), addConn(h, 3)
+	h.AddToGroup("team", alice.UserID)
+	h.AddToGroup("team", bob.UserID)
+	h.AddToGroup("other", mallory.UserID)
+
+	tests := []struct {
+		name   string
+		from   *Connection
+		target *Target
+		want   map[*Connection]bool // 期望收到消息的连接
+	}{
+		{"发给同组用户", alice, NewTargetUser(bob.UserID), map[*Connection]bool{bob: true}},
+		{"发给自己", mallory, NewTargetUser(mallory.UserID), map[*Connection]bool{mallory: true}},
+		{"发给非同组用户", mallory, NewTargetUser(alice.UserID, bob.UserID), nil},
+		{"混合目标只发给同组用户", alice, NewTargetUser(bob.UserID, mallory.UserID), map[*Connection]bool{bob: true}},
+		{"发给同组用户的连接", bob, NewTargetConn(alice.ID), map[*Connection]bool{alice: true}},
+		{"发给非同组用户的连接", mallory, NewTargetConn(alice.ID), nil},
+		{"发到所在分组", alice, NewTargetGroup("team"), map[*Connection]bool{alice: true, bob: true}},
+		{"发到未加入的分组", mallory, NewTargetGroup("team"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewMessage(MessageTypeText, "hi")
+			msg.Target = tt.target
+			h.RouteMessage(tt.from, msg)
+
+			for _, conn := range []*Connection{alice, bob, mallory} {
+				got := len(conn.SendChan) > 0
+				for len(conn.SendChan) > 0 {
+					<-conn.SendChan
+				}
+				if got != tt.want[conn] {
+					t.Errorf("用户 %d 收到消息 = %v, 期望 %v", conn.UserID, got, tt.want[conn])
+				}
+			}
+		})
+	}
+}
+
+func TestIsGroupMember(t *testing.T) {
+	h := NewHub()
+	h.AddToGroup("team", 1)
+	if !h.IsGroupMember("team", 1) {
+		t.Error("用户 1 应在分组中")
+	}
+	if h.IsGroupMember("team", 2) || h.IsGroupMember("missing", 1) {
+		t.Error("不在分组中的用户不应通过检查")
+	}
+	h.RemoveFromGroup("team", 1)
+	if h.IsGroupMember("team", 1) {
+		t.Error("退出后不应在分组中")
+	}
+}
+
+func TestBroadcastFrom(t *testing.T) {
+	h := NewHub()
+	admin, user := addConn(h, 1), addConn(h, 2)
+	msg := NewMessage(MessageTypeBroadcast, "hi")
+	msg.Target = NewTargetAll()
+
+	// 未设置权限检查时拒绝全部客户端广播
+	h.RouteMessage(user, msg)
+	if len(h.broadcast) != 0 {
+		t.Fatal("未设置权限检查时不应广播")
+	}
+
+	h.SetBroadcastAuthorizer(func(conn *Connection) bool { return conn.UserID == admin.UserID })
+	h.RouteMessage(user, msg)
+	if len(h.broadcast) != 0 {
+		t.Error("没有权限的用户不应广播")
+	}
+	h.BroadcastFrom(admin, msg)
+	if len(h.broadcast) != 1 {
+		t.Error("有权限的用户应能广播")
+	}
+}
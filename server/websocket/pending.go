@@ -0,0 +1,84 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sunyuanling/server/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// pendingTTL 离线消息在 Redis 中的保留时间
+const pendingTTL = 24 * time.Hour
+
+// pendingStore 离线设备消息暂存（Redis List，设备下次连接时补发）
+var pendingStore *redis.Client
+
+// pendingKey 设备离线消息队列的 Redis key
+// 设备ID由客户端提供，带上用户ID，避免其他用户以相同的设备ID连接时收到消息
+func pendingKey(userID uint, deviceID string) string {
+	return fmt.Sprintf("ws_pending:%d:%s", userID, deviceID)
+}
+
+// QueueForDevice 发送消息给用户的设备，设备不在线时暂存，待其下次连接时补发
+func QueueForDevice(userID uint, deviceID string, msgType MessageType, content interface{}) error {
+	msg := NewMessage(msgType, content)
+	msg.Target = NewTargetDevice(deviceID)
+
+	if err := GetHub().SendToDevice(userID, deviceID, msg); err == nil {
+		return nil
+	}
+
+	if pendingStore == nil {
+		return ErrDeviceNotFound
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	key := pendingKey(userID, deviceID)
+	pipe := pendingStore.TxPipeline()
+	pipe.RPush(ctx, key, data)
+	pipe.Expire(ctx, key, pendingTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("暂存离线消息失败: %w", err)
+	}
+	return nil
+}
+
+// flushPending 设备连接后补发离线消息
+func flushPending(conn *Connection) {
+	if pendingStore == nil {
+		return
+	}
+
+	ctx := context.Background()
+	key := pendingKey(conn.UserID, conn.Device.DeviceID)
+
+	pipe := pendingStore.TxPipeline()
+	rangeCmd := pipe.LRange(ctx, key, 0, -1)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("读取离线消息失败",
+			zap.String("device_id", conn.Device.DeviceID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for _, data := range rangeCmd.Val() {
+		if err := conn.Send([]byte(data)); err != nil {
+			logger.Warn("补发离线消息失败",
+				zap.String("conn_id", conn.ID),
+				zap.Error(err),
+			)
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package websocket
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket/protocol"
+	"go.uber.org/zap"
+)
+
+// presenceRank 聚合用户状态时的优先级，数值越小越“在线”
+var presenceRank = map[DeviceStatus]int{
+	DeviceStatusOnline:  0,
+	DeviceStatusBusy:    1,
+	DeviceStatusAway:    2,
+	DeviceStatusOffline: 3,
+}
+
+// mergePresence 多设备时取最“在线”的状态作为用户状态
+func mergePresence(a, b DeviceStatus) DeviceStatus {
+	if presenceRank[b] < presenceRank[a] {
+		return b
+	}
+	return a
+}
+
+// sendToPresenceAudience 把消息发给用户本人的设备和同组用户，不再全员广播
+func sendToPresenceAudience(userID uint, msg *Message) {
+	sendToAudience(GetHub().PresenceAudience(userID), msg)
+}
+
+// sendToAudience 发送给指定用户列表
+func sendToAudience(audience []uint, msg *Message) {
+	msg.Target = NewTargetUser(audience...)
+	GetHub().SendToUsers(audience, msg)
+}
+
+// publishPresence 通知设备在线状态变化
+func publishPresence(conn *Connection, status DeviceStatus) {
+	msg := NewMessage(MessageTypeSystem, protocol.PresenceChanged{
+		Event:    protocol.EventPresence,
+		UserID:   conn.UserID,
+		DeviceID: conn.Device.DeviceID,
+		Status:   string(status),
+		LastSeen: time.Now().Unix(),
+	})
+	sendToPresenceAudience(conn.UserID, msg)
+}
+
+// onPresence 处理客户端设置在线状态，状态变化时通知并记录最后活跃时间
+func (t *deviceTracker) onPresence(conn *Connection, msg *Message) {
+	// 内容已在 handleTextMessage 中按协议校验
+	var update protocol.PresenceUpdate
+	if err := json.Unmarshal(msg.Content, &update); err != nil {
+		conn.sendProtocolError(protocol.NewError(protocol.ErrCodeInvalidPayload, "在线状态格式错误").WithRef(msg.ID))
+		return
+	}
+
+	status := DeviceStatus(update.Status)
+	if conn.GetDeviceStatus() == status {
+		return
+	}
+	conn.SetDeviceStatus(status)
+
+	logger.Debug("设备在线状态变化",
+		zap.String("conn_id", conn.ID),
+		zap.Uint("user_id", conn.UserID),
+		zap.String("status", update.Status),
+	)
+
+	publishPresence(conn, status)
+	if conn.Device.RecordID != 0 {
+		t.touch(conn, time.Now())
+	}
+}
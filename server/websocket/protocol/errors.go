@@ -0,0 +1,50 @@
+package protocol
+
+// ErrorCode 协议错误码
+type ErrorCode string
+
+const (
+	ErrCodeInvalidJSON        ErrorCode = "invalid_json"        // 消息不是合法的 JSON
+	ErrCodeUnknownType        ErrorCode = "unknown_type"        // 不支持的消息类型
+	ErrCodeInvalidPayload     ErrorCode = "invalid_payload"     // content 不符合该消息类型的结构
+	ErrCodeMissingTarget      ErrorCode = "missing_target"      // 需要 target 的消息缺少 target
+	ErrCodeUnsupportedVersion ErrorCode = "unsupported_version" // 协议版本不支持
+	ErrCodeNotFound           ErrorCode = "not_found"           // 引用的对象不存在
+	ErrCodeForbidden          ErrorCode = "forbidden"           // 无权限
+	ErrCodeInternal           ErrorCode = "internal_error"      // 服务端内部错误
+)
+
+// errorCodes 全部错误码，用于生成 Schema 枚举
+var errorCodes = []ErrorCode{
+	ErrCodeInvalidJSON,
+	ErrCodeUnknownType,
+	ErrCodeInvalidPayload,
+	ErrCodeMissingTarget,
+	ErrCodeUnsupportedVersion,
+	ErrCodeNotFound,
+	ErrCodeForbidden,
+	ErrCodeInternal,
+}
+
+// Error 错误消息（type=error）的 content
+type Error struct {
+	Code    ErrorCode `json:"code"`             // 错误码
+	Message string    `json:"message"`          // 错误描述
+	RefID   string    `json:"ref_id,omitempty"` // 出错的客户端消息ID
+}
+
+// NewError 创建协议错误
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+// WithRef 关联出错的客户端消息ID
+func (e *Error) WithRef(id string) *Error {
+	e.RefID = id
+	return e
+}
@@ -0,0 +1,236 @@
+package protocol
+
+import "encoding/json"
+
+// 消息类型（Message.type）
+const (
+	TypeText         = "text"
+	TypeBroadcast    = "broadcast"
+	TypeSystem       = "system"
+	TypeHeartbeat    = "heartbeat"
+	TypeAck          = "ack"
+	TypeFileSync     = "file_sync"
+	TypeNotify       = "notification"
+	TypeCommand      = "device_command" // 服务端下发的设备指令
+	TypeCommandAck   = "command_ack"    // 设备对指令的回执
+	TypeError        = "error"          // 协议错误
+	TypeFileUpload   = "file_upload"
+	TypeFileDownload = "file_download"
+	TypePresence     = "presence"       // 客户端设置在线状态
+	TypeSubscribe    = "subscribe"      // 客户端订阅主题
+	TypeUnsubscribe  = "unsubscribe"    // 客户端退订主题
+	TypeMetrics      = "system_metrics" // 系统监控采样
+)
+
+// 可订阅的主题（type=subscribe/unsubscribe 时 content.topic 的取值）
+const (
+	TopicSystemMetrics = "system_metrics" // 系统监控实时采样，需要 system:monitor 权限
+)
+
+// Topics 全部可订阅的主题
+var Topics = []string{TopicSystemMetrics}
+
+// 系统事件（type=system 时 content.event 的取值）
+const (
+	EventConnected     = "connected"
+	EventUserOnline    = "user_online"
+	EventUserOffline   = "user_offline"
+	EventDevicePaired  = "device_paired"
+	EventPresence      = "presence_changed"
+	EventFolderChange  = "folder_changed"
+	EventFolderShare   = "folder_shared"    // 收到文件夹共享或共享权限变化
+	EventFolderUnshare = "folder_unshared"  // 共享被取消
+	EventTakeout       = "takeout_progress" // 数据导出进度
+	EventSubscription  = "subscription"     // 订阅或退订结果
+)
+
+// 在线状态
+const (
+	PresenceOnline  = "online"
+	PresenceAway    = "away"
+	PresenceBusy    = "busy"
+	PresenceOffline = "offline"
+)
+
+// 共享文件夹变化（type=file_sync 时 content.action 的取值）
+const (
+	FolderActionCreated = "created"
+	FolderActionUpdated = "updated"
+	FolderActionDeleted = "deleted"
+)
+
+// 文件传输事件（type=file_upload/file_download 时 content.event 的取值）
+const (
+	FileEventStart     = "start"
+	FileEventCompleted = "completed"
+)
+
+// ========== 服务端 -> 客户端 ==========
+
+// Connected 连接建立后的欢迎消息
+type Connected struct {
+	Event    string `json:"event" enum:"connected"`
+	ConnID   string `json:"conn_id"`
+	DeviceID string `json:"device_id"`
+	Version  int    `json:"version"` // 本连接协商出的协议版本
+}
+
+// UserOnline 用户设备上线
+type UserOnline struct {
+	Event      string `json:"event" enum:"user_online"`
+	UserID     uint   `json:"user_id"`
+	ConnID     string `json:"conn_id"`
+	DeviceID   string `json:"device_id"`
+	DeviceType string `json:"device_type"`
+}
+
+// UserOffline 用户设备下线
+type UserOffline struct {
+	Event                string `json:"event" enum:"user_offline"`
+	UserID               uint   `json:"user_id"`
+	ConnID               string `json:"conn_id"`
+	DeviceID             string `json:"device_id"`
+	RemainingConnections int    `json:"remaining_connections"`
+	LastSeen             int64  `json:"last_seen"`
+}
+
+// PresenceChanged 设备在线状态变化
+type PresenceChanged struct {
+	Event    string `json:"event" enum:"presence_changed"`
+	UserID   uint   `json:"user_id"`
+	DeviceID string `json:"device_id"`
+	Status   string `json:"status" enum:"online,away,busy,offline"`
+	LastSeen int64  `json:"last_seen"`
+}
+
+// DevicePaired 设备配对完成
+type DevicePaired struct {
+	Event          string `json:"event" enum:"device_paired"`
+	DeviceID       string `json:"device_id"`
+	DeviceName     string `json:"device_name"`
+	DeviceType     string `json:"device_type"`
+	SourceDeviceID string `json:"source_device_id,omitempty"`
+	PairedAt       int64  `json:"paired_at"`
+}
+
+// HeartbeatAck 心跳回复（type=ack）
+type HeartbeatAck struct {
+	Type string `json:"type" enum:"heartbeat_ack"`
+}
+
+// FileTransfer 文件上传/下载进度（type=file_upload/file_download）
+type FileTransfer struct {
+	Event       string `json:"event" enum:"start,completed"`
+	FileName    string `json:"file_name"`
+	FileSize    int64  `json:"file_size"`
+	HistoryID   uint   `json:"history_id"`
+	StoragePath string `json:"storage_path,omitempty"`
+}
+
+// FolderChanged 共享文件夹内的文件变化（type=file_sync）
+// 来自分组共享文件夹时带 group_id/group_name，来自文件夹共享时带 share_id
+type FolderChanged struct {
+	Event      string `json:"event" enum:"folder_changed"`
+	GroupID    uint   `json:"group_id,omitempty"`
+	GroupName  string `json:"group_name,omitempty"`
+	ShareID    uint   `json:"share_id,omitempty"`
+	FolderPath string `json:"folder_path"`
+	Path       string `json:"path"`
+	Action     string `json:"action" enum:"created,updated,deleted"`
+	UserID     uint   `json:"user_id"` // 操作人
+	Time       int64  `json:"time"`
+}
+
+// FolderShared 文件夹共享给我或共享被取消（type=system）
+type FolderShared struct {
+	Event       string `json:"event" enum:"folder_shared,folder_unshared"`
+	ShareID     uint   `json:"share_id"`
+	FolderPath  string `json:"folder_path"`
+	OwnerID     uint   `json:"owner_id"`
+	GranteeType string `json:"grantee_type" enum:"user,group"`
+	GranteeID   uint   `json:"grantee_id"`
+	Permission  string `json:"permission" enum:"read,write,manage"`
+	Time        int64  `json:"time"`
+}
+
+// TakeoutProgress 数据导出任务进度（type=system），只发给本人
+type TakeoutProgress struct {
+	Event    string `json:"event" enum:"takeout_progress"`
+	JobID    uint   `json:"job_id"`
+	Status   string `json:"status" enum:"pending,running,completed,failed,expired"`
+	Progress int    `json:"progress"` // 0-100
+	Error    string `json:"error,omitempty"`
+	Time     int64  `json:"time"`
+}
+
+// SubscriptionChanged 订阅或退订结果（type=system），只发给发起的连接
+type SubscriptionChanged struct {
+	Event      string `json:"event" enum:"subscription"`
+	Topic      string `json:"topic"`
+	Subscribed bool   `json:"subscribed"`
+}
+
+// Notification 通知（type=notification）
+type Notification struct {
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	Level   string `json:"level"`
+	Time    int64  `json:"time"`
+}
+
+// DeviceCommand 设备指令（type=device_command）
+type DeviceCommand struct {
+	CommandID uint            `json:"command_id"`
+	Command   string          `json:"command" enum:"sign_out,clear_local_cache,upload_diagnostics,pause_sync"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	CreatedAt int64           `json:"created_at"`
+}
+
+// ========== 客户端 -> 服务端 ==========
+
+// Heartbeat 心跳（type=heartbeat），content 可为空
+type Heartbeat struct{}
+
+// CommandAck 设备指令回执（type=command_ack）
+type CommandAck struct {
+	CommandID uint   `json:"command_id"`
+	Success   bool   `json:"success"`
+	Result    string `json:"result,omitempty"`
+}
+
+// PresenceUpdate 设置当前设备的在线状态（type=presence）
+type PresenceUpdate struct {
+	Status string `json:"status" enum:"online,away,busy"`
+}
+
+// Subscription 订阅或退订主题（type=subscribe/unsubscribe）
+type Subscription struct {
+	Topic string `json:"topic" enum:"system_metrics"`
+}
+
+// Validate 校验主题取值
+func (s *Subscription) Validate() error {
+	for _, t := range Topics {
+		if s.Topic == t {
+			return nil
+		}
+	}
+	return NewError(ErrCodeInvalidPayload, "不支持的主题: "+s.Topic)
+}
+
+// Validate 校验状态取值，offline 由服务端在断开时设置
+func (p *PresenceUpdate) Validate() error {
+	switch p.Status {
+	case PresenceOnline, PresenceAway, PresenceBusy:
+		return nil
+	}
+	return NewError(ErrCodeInvalidPayload, "status 只能是 online/away/busy")
+}
+
+// Validate 校验回执
+func (a *CommandAck) Validate() error {
+	if a.CommandID == 0 {
+		return NewError(ErrCodeInvalidPayload, "command_id 不能为空")
+	}
+	return nil
+}
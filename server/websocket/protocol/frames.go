@@ -0,0 +1,100 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// FrameSpec 一种消息（type + content 结构）的定义
+type FrameSpec struct {
+	Type           string      // Message.type
+	Payload        interface{} // content 的结构，nil 表示任意 JSON
+	RequiresTarget bool        // 是否必须携带 target（需要路由的消息）
+	Description    string      // 说明，写入 Schema
+}
+
+// ServerFrames 服务端下发的消息
+var ServerFrames = []FrameSpec{
+	{Type: TypeSystem, Payload: Connected{}, Description: "连接建立"},
+	{Type: TypeSystem, Payload: UserOnline{}, Description: "用户设备上线"},
+	{Type: TypeSystem, Payload: UserOffline{}, Description: "用户设备下线"},
+	{Type: TypeSystem, Payload: DevicePaired{}, Description: "设备配对完成"},
+	{Type: TypeSystem, Payload: PresenceChanged{}, Description: "设备在线状态变化（仅发给本人设备和同组用户）"},
+	{Type: TypeAck, Payload: HeartbeatAck{}, Description: "心跳回复"},
+	{Type: TypeFileUpload, Payload: FileTransfer{}, Description: "文件上传进度"},
+	{Type: TypeFileDownload, Payload: FileTransfer{}, Description: "文件下载进度"},
+	{Type: TypeSystem, Payload: FolderShared{}, Description: "文件夹共享给我或共享被取消"},
+	{Type: TypeFileSync, Payload: FolderChanged{}, Description: "共享文件夹（分组或文件夹共享）内的文件变化"},
+	{Type: TypeSystem, Payload: TakeoutProgress{}, Description: "数据导出任务进度"},
+	{Type: TypeSystem, Payload: SubscriptionChanged{}, Description: "订阅或退订结果"},
+	{Type: TypeMetrics, Payload: SystemMetrics{}, Description: "系统监控实时采样（订阅 system_metrics 后按采样间隔推送）"},
+	{Type: TypeNotify, Payload: Notification{}, Description: "通知"},
+	{Type: TypeCommand, Payload: DeviceCommand{}, Description: "设备远程指令"},
+	{Type: TypeError, Payload: Error{}, Description: "协议错误"},
+}
+
+// ClientFrames 客户端可发送的消息
+var ClientFrames = []FrameSpec{
+	{Type: TypeHeartbeat, Payload: Heartbeat{}, Description: "心跳"},
+	{Type: TypeCommandAck, Payload: CommandAck{}, Description: "设备指令回执"},
+	{Type: TypePresence, Payload: PresenceUpdate{}, Description: "设置当前设备的在线状态"},
+	{Type: TypeSubscribe, Payload: Subscription{}, Description: "订阅主题"},
+	{Type: TypeUnsubscribe, Payload: Subscription{}, Description: "退订主题"},
+	{Type: TypeText, RequiresTarget: true, Description: "文本消息，按 target 转发"},
+	{Type: TypeFileSync, RequiresTarget: true, Description: "文件同步消息，按 target 转发"},
+	{Type: TypeNotify, RequiresTarget: true, Description: "通知，按 target 转发"},
+	{Type: TypeAck, RequiresTarget: true, Description: "业务回执，按 target 转发"},
+	{Type: TypeBroadcast, Description: "广播给所有在线用户"},
+}
+
+// clientFrameIndex 按消息类型索引客户端消息定义
+var clientFrameIndex = func() map[string]FrameSpec {
+	index := make(map[string]FrameSpec, len(ClientFrames))
+	for _, spec := range ClientFrames {
+		index[spec.Type] = spec
+	}
+	return index
+}()
+
+// validator content 结构可实现的额外校验
+type validator interface {
+	Validate() error
+}
+
+// ValidateClientFrame 校验客户端发来的消息
+// content 按该类型定义的结构严格解析（不允许未知字段），并执行结构自带的 Validate
+func ValidateClientFrame(msgType string, content json.RawMessage, hasTarget bool) *Error {
+	spec, ok := clientFrameIndex[msgType]
+	if !ok {
+		return NewError(ErrCodeUnknownType, "不支持的消息类型: "+msgType)
+	}
+	if spec.RequiresTarget && !hasTarget {
+		return NewError(ErrCodeMissingTarget, "消息缺少 target")
+	}
+	if spec.Payload == nil || isEmptyContent(content) {
+		return nil
+	}
+
+	value := reflect.New(reflect.TypeOf(spec.Payload)).Interface()
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return NewError(ErrCodeInvalidPayload, "content 格式错误: "+err.Error())
+	}
+	if v, ok := value.(validator); ok {
+		if err := v.Validate(); err != nil {
+			if pe, ok := err.(*Error); ok {
+				return pe
+			}
+			return NewError(ErrCodeInvalidPayload, err.Error())
+		}
+	}
+	return nil
+}
+
+// isEmptyContent content 为空或 null
+func isEmptyContent(content json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(content)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))
+}
@@ -0,0 +1,84 @@
+package protocol
+
+// SystemMetrics 系统监控采样（type=system_metrics），只发给订阅了 system_metrics 的连接
+// REST 接口 /api/monitor/latest、/api/monitor/history 返回同样的结构
+// 速率类字段为与上一次采样的差值除以间隔
+type SystemMetrics struct {
+	Time     int64            `json:"time"`     // 采样时间（Unix 毫秒）
+	Interval int              `json:"interval"` // 采样间隔（秒）
+	CPU      CPUMetrics       `json:"cpu"`
+	Load     *LoadMetrics     `json:"load,omitempty"` // Windows 不支持
+	Memory   MemoryMetrics    `json:"memory"`
+	Network  []NetworkMetrics `json:"network"`
+	DiskIO   []DiskIOMetrics  `json:"disk_io"`
+	Process  ProcessMetrics   `json:"process"`
+	Runtime  RuntimeMetrics   `json:"runtime"`
+}
+
+// CPUMetrics CPU 使用率（百分比）
+type CPUMetrics struct {
+	Percent float64   `json:"percent"`  // 总使用率
+	PerCore []float64 `json:"per_core"` // 每个逻辑核的使用率
+}
+
+// LoadMetrics 系统负载
+type LoadMetrics struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+// MemoryMetrics 内存和交换区（字节）
+type MemoryMetrics struct {
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Available   uint64  `json:"available"`
+	UsedPercent float64 `json:"used_percent"`
+	SwapTotal   uint64  `json:"swap_total"`
+	SwapUsed    uint64  `json:"swap_used"`
+	SwapPercent float64 `json:"swap_percent"`
+}
+
+// NetworkMetrics 网卡吞吐
+type NetworkMetrics struct {
+	Name      string  `json:"name"`       // 网卡名
+	SendRate  float64 `json:"send_rate"`  // 发送速率（字节/秒）
+	RecvRate  float64 `json:"recv_rate"`  // 接收速率（字节/秒）
+	BytesSent uint64  `json:"bytes_sent"` // 累计发送字节
+	BytesRecv uint64  `json:"bytes_recv"` // 累计接收字节
+	Errors    uint64  `json:"errors"`     // 累计收发错误
+	Drops     uint64  `json:"drops"`      // 累计丢包
+}
+
+// DiskIOMetrics 磁盘读写
+type DiskIOMetrics struct {
+	Name        string  `json:"name"`         // 设备名（如 sda、nvme0n1、C:）
+	ReadRate    float64 `json:"read_rate"`    // 读取速率（字节/秒）
+	WriteRate   float64 `json:"write_rate"`   // 写入速率（字节/秒）
+	ReadIOPS    float64 `json:"read_iops"`    // 每秒读次数
+	WriteIOPS   float64 `json:"write_iops"`   // 每秒写次数
+	BusyPercent float64 `json:"busy_percent"` // 设备忙碌时间占比（不支持时为 0）
+}
+
+// ProcessMetrics 服务进程
+type ProcessMetrics struct {
+	PID        int32   `json:"pid"`
+	CPUPercent float64 `json:"cpu_percent"` // 单核满载为 100
+	RSS        uint64  `json:"rss"`         // 常驻内存（字节）
+	VMS        uint64  `json:"vms"`         // 虚拟内存（字节）
+	Threads    int32   `json:"threads"`
+	OpenFiles  int32   `json:"open_files"` // 打开的文件描述符（不支持时为 0）
+	Uptime     int64   `json:"uptime"`     // 运行时间（秒）
+}
+
+// RuntimeMetrics Go 运行时
+type RuntimeMetrics struct {
+	Goroutines   int    `json:"goroutines"`
+	GOMAXPROCS   int    `json:"gomaxprocs"`
+	HeapAlloc    uint64 `json:"heap_alloc"`     // 堆上已分配（字节）
+	HeapInuse    uint64 `json:"heap_inuse"`     // 堆占用（字节）
+	Sys          uint64 `json:"sys"`            // 从系统申请的内存（字节）
+	NumGC        uint32 `json:"num_gc"`         // 累计 GC 次数
+	PauseTotalNs uint64 `json:"pause_total_ns"` // 累计 GC 暂停（纳秒）
+	LastPauseNs  uint64 `json:"last_pause_ns"`  // 最近一次 GC 暂停（纳秒）
+}
@@ -0,0 +1,194 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SchemaDraft 生成的 JSON Schema 版本
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// GenerateSchema 生成协议的 JSON Schema 文档
+// envelope 为消息外层结构（websocket.Message），各消息的 type/content 按 ServerFrames、ClientFrames 约束
+func GenerateSchema(envelope interface{}) map[string]interface{} {
+	b := &schemaBuilder{defs: make(map[string]interface{})}
+	envelopeRef := b.typeSchema(reflect.TypeOf(envelope))
+
+	b.defs["ServerFrame"] = map[string]interface{}{
+		"description": "服务端下发的消息",
+		"oneOf":       b.frames(envelopeRef, ServerFrames, false),
+	}
+	b.defs["ClientFrame"] = map[string]interface{}{
+		"description": "客户端可发送的消息",
+		"oneOf":       b.frames(envelopeRef, ClientFrames, true),
+	}
+
+	codes := make([]string, len(errorCodes))
+	for i, c := range errorCodes {
+		codes[i] = string(c)
+	}
+	if def, ok := b.defs["Error"].(map[string]interface{}); ok {
+		props := def["properties"].(map[string]interface{})
+		props["code"] = map[string]interface{}{"type": "string", "enum": codes}
+	}
+
+	return map[string]interface{}{
+		"$schema":            SchemaDraft,
+		"$id":                "filesync-websocket-protocol-v1",
+		"title":              "File Sync WebSocket Protocol",
+		"x-protocol-version": CurrentVersion,
+		"x-subprotocols":     Subprotocols(),
+		"oneOf": []interface{}{
+			map[string]interface{}{"$ref": "#/$defs/ServerFrame"},
+			map[string]interface{}{"$ref": "#/$defs/ClientFrame"},
+		},
+		"$defs": b.defs,
+	}
+}
+
+// schemaBuilder 通过反射把 Go 结构转换为 JSON Schema
+type schemaBuilder struct {
+	defs map[string]interface{}
+}
+
+// frames 为每种消息生成 “外层结构 + type 常量 + content 结构” 的约束
+// nullableContent 为 true 时 content 可以为 null（客户端消息的 content 可省略）
+func (b *schemaBuilder) frames(envelopeRef map[string]interface{}, specs []FrameSpec, nullableContent bool) []interface{} {
+	list := make([]interface{}, 0, len(specs))
+	for _, spec := range specs {
+		content := map[string]interface{}{}
+		if spec.Payload != nil {
+			content = b.typeSchema(reflect.TypeOf(spec.Payload))
+			if nullableContent {
+				content = map[string]interface{}{"anyOf": []interface{}{content, map[string]interface{}{"type": "null"}}}
+			}
+		}
+
+		props := map[string]interface{}{
+			"type":    map[string]interface{}{"const": spec.Type},
+			"content": content,
+		}
+		required := []string{"type"}
+		if spec.RequiresTarget {
+			required = append(required, "target")
+		}
+
+		list = append(list, map[string]interface{}{
+			"description": spec.Description,
+			"allOf": []interface{}{
+				envelopeRef,
+				map[string]interface{}{
+					"properties": props,
+					"required":   required,
+				},
+			},
+		})
+	}
+	return list
+}
+
+// typeSchema 生成类型的 Schema，结构体放入 $defs 并返回引用
+func (b *schemaBuilder) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case rawMessageType:
+		return map[string]interface{}{}
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := b.defs[name]; !ok {
+			b.defs[name] = nil // 占位，防止递归
+			b.defs[name] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + name}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.typeSchema(t.Elem())}
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema 生成结构体的 Schema，没有 omitempty 的字段视为必填
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+	required := make([]string, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := b.typeSchema(field.Type)
+		if enum := field.Tag.Get("enum"); enum != "" {
+			values := strings.Split(enum, ",")
+			if len(values) == 1 {
+				schema = map[string]interface{}{"type": "string", "const": values[0]}
+			} else {
+				schema = map[string]interface{}{"type": "string", "enum": values}
+			}
+		}
+		omitempty := strings.Contains(opts, "omitempty")
+		if !omitempty && isNilable(field.Type) {
+			// 未赋值时序列化为 null
+			schema = map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+		}
+		props[name] = schema
+
+		if !omitempty && !isNilable(field.Type) {
+			required = append(required, name)
+		}
+	}
+
+	def := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		def["required"] = required
+	}
+	return def
+}
+
+// isNilable 可为 nil 的类型（指针、切片、map、interface），不作为必填字段
+func isNilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
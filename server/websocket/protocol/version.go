@@ -0,0 +1,80 @@
+// Package protocol 定义 WebSocket 协议：版本协商、消息类型、各消息 content 的结构、错误码以及 JSON Schema 生成
+// 客户端（Kotlin/ArkTS/TS）应以 docs/websocket-protocol.schema.json 为准生成代码
+package protocol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 协议版本
+const (
+	Version1       = 1
+	CurrentVersion = Version1
+)
+
+// SubprotocolPrefix Sec-WebSocket-Protocol 子协议前缀，如 filesync.v1
+const SubprotocolPrefix = "filesync.v"
+
+// supportedVersions 服务端支持的协议版本（从新到旧）
+var supportedVersions = []int{Version1}
+
+// SupportedVersions 返回服务端支持的协议版本
+func SupportedVersions() []int {
+	return append([]int(nil), supportedVersions...)
+}
+
+// Subprotocols 返回服务端支持的子协议名称，用于 websocket.Upgrader
+func Subprotocols() []string {
+	names := make([]string, len(supportedVersions))
+	for i, v := range supportedVersions {
+		names[i] = Subprotocol(v)
+	}
+	return names
+}
+
+// Subprotocol 版本对应的子协议名称
+func Subprotocol(version int) string {
+	return fmt.Sprintf("%s%d", SubprotocolPrefix, version)
+}
+
+// IsSupported 判断协议版本是否支持
+func IsSupported(version int) bool {
+	for _, v := range supportedVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate 协商协议版本
+// requested 为 query 参数 protocol 的值，offered 为客户端 Sec-WebSocket-Protocol 中的子协议列表；
+// 优先使用 query 参数，其次按客户端顺序选择第一个支持的子协议，都没有时使用当前版本
+func Negotiate(requested string, offered []string) (int, error) {
+	if requested != "" {
+		v, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(requested), "v"))
+		if err != nil || !IsSupported(v) {
+			return 0, NewError(ErrCodeUnsupportedVersion, fmt.Sprintf("不支持的协议版本: %s", requested))
+		}
+		return v, nil
+	}
+
+	sawOurs := false
+	for _, name := range offered {
+		name = strings.TrimSpace(name)
+		if !strings.HasPrefix(name, SubprotocolPrefix) {
+			continue
+		}
+		sawOurs = true
+		v, err := strconv.Atoi(strings.TrimPrefix(name, SubprotocolPrefix))
+		if err == nil && IsSupported(v) {
+			return v, nil
+		}
+	}
+	if sawOurs {
+		return 0, NewError(ErrCodeUnsupportedVersion, "客户端请求的协议版本均不受支持")
+	}
+	return CurrentVersion, nil
+}
@@ -0,0 +1,8 @@
+package websocket
+
+import "github.com/sunyuanling/server/websocket/protocol"
+
+// ProtocolSchema 生成 WebSocket 协议的 JSON Schema（外层结构为 Message）
+func ProtocolSchema() map[string]interface{} {
+	return protocol.GenerateSchema(Message{})
+}
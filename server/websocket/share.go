@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/sunyuanling/server/internal/model"
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket/protocol"
+	"go.uber.org/zap"
+)
+
+// NotifyFolderShared 共享创建/修改后通知接收人（分组共享通知全部成员）
+func NotifyFolderShared(share *model.FolderShare) {
+	notifyShareRecipients(share, protocol.EventFolderShare)
+}
+
+// NotifyFolderUnshared 共享取消后通知原接收人
+// 分组共享需要在删除共享前调用，或分组仍存在时调用
+func NotifyFolderUnshared(share *model.FolderShare) {
+	notifyShareRecipients(share, protocol.EventFolderUnshare)
+}
+
+// notifyShareRecipients 向共享的接收人发送共享事件
+func notifyShareRecipients(share *model.FolderShare, event string) {
+	if groupStore == nil {
+		return
+	}
+
+	recipients, err := model.ShareRecipientIDs(groupStore, share)
+	if err != nil {
+		logger.Error("查询共享接收人失败", zap.Uint("share_id", share.ID), zap.Error(err))
+		return
+	}
+
+	content := protocol.FolderShared{
+		Event:       event,
+		ShareID:     share.ID,
+		FolderPath:  share.FolderPath,
+		OwnerID:     share.OwnerID,
+		GranteeType: share.GranteeType,
+		GranteeID:   share.GranteeID,
+		Permission:  share.Permission,
+		Time:        time.Now().Unix(),
+	}
+	for _, userID := range recipients {
+		_ = SendToUser(userID, MessageTypeSystem, content)
+	}
+}
+
+// notifyShareFolderChange 文件变化时通知共享了包含该路径的文件夹的用户（含共享人，不含操作人）
+// 同一用户命中多个共享时只通知一次，readable 为 false 的用户不通知
+func notifyShareFolderChange(userID uint, path, action string, readable func(uint) bool) {
+	shares, err := model.SharesForPath(groupStore, path)
+	if err != nil {
+		logger.Error("查询文件夹共享失败", zap.String("path", path), zap.Error(err))
+		return
+	}
+
+	notified := map[uint]bool{userID: true}
+	now := time.Now().Unix()
+	for i := range shares {
+		share := &shares[i]
+		recipients, err := model.ShareRecipientIDs(groupStore, share)
+		if err != nil {
+			logger.Error("查询共享接收人失败", zap.Uint("share_id", share.ID), zap.Error(err))
+			continue
+		}
+		recipients = append(recipients, share.OwnerID)
+
+		for _, recipient := range recipients {
+			if notified[recipient] {
+				continue
+			}
+			notified[recipient] = true
+			if !readable(recipient) {
+				continue
+			}
+			_ = SendToUser(recipient, MessageTypeFileSync, protocol.FolderChanged{
+				Event:      protocol.EventFolderChange,
+				ShareID:    share.ID,
+				FolderPath: share.FolderPath,
+				Path:       path,
+				Action:     action,
+				UserID:     userID,
+				Time:       now,
+			})
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket/protocol"
+	"go.uber.org/zap"
+)
+
+// topic 可订阅的主题
+type topic struct {
+	authorize   func(userID uint) bool // 为 nil 时所有登录用户可订阅
+	subscribers map[string]*Connection // 连接ID -> 连接
+}
+
+var (
+	topicsMu sync.RWMutex
+	topics   = make(map[string]*topic)
+)
+
+// RegisterTopic 注册可订阅的主题，由提供数据的模块在初始化时调用
+func RegisterTopic(name string, authorize func(userID uint) bool) {
+	topicsMu.Lock()
+	defer topicsMu.Unlock()
+	if t, ok := topics[name]; ok {
+		t.authorize = authorize
+		return
+	}
+	topics[name] = &topic{authorize: authorize, subscribers: make(map[string]*Connection)}
+}
+
+// HasSubscribers 主题是否有订阅连接，没有时发布方可以跳过推送
+func HasSubscribers(name string) bool {
+	topicsMu.RLock()
+	defer topicsMu.RUnlock()
+	t, ok := topics[name]
+	return ok && len(t.subscribers) > 0
+}
+
+// PublishTopic 推送消息给主题的订阅连接
+// 每次推送重新检查权限（权限有缓存），权限被收回的连接自动退订
+func PublishTopic(name string, msgType MessageType, content interface{}) {
+	topicsMu.RLock()
+	t, ok := topics[name]
+	if !ok || len(t.subscribers) == 0 {
+		topicsMu.RUnlock()
+		return
+	}
+	authorize := t.authorize
+	candidates := make([]*Connection, 0, len(t.subscribers))
+	for _, conn := range t.subscribers {
+		candidates = append(candidates, conn)
+	}
+	topicsMu.RUnlock()
+
+	conns := candidates[:0]
+	for _, conn := range candidates {
+		if authorize != nil && !authorize(conn.UserID) {
+			topicsMu.Lock()
+			delete(t.subscribers, conn.ID)
+			topicsMu.Unlock()
+			logger.Info("权限已收回，取消主题订阅",
+				zap.String("topic", name),
+				zap.String("conn_id", conn.ID),
+				zap.Uint("user_id", conn.UserID),
+			)
+			continue
+		}
+		conns = append(conns, conn)
+	}
+
+	GetHub().sendToConnections(conns, NewMessage(msgType, content))
+}
+
+// unsubscribeAll 连接断开时退订全部主题
+func unsubscribeAll(conn *Connection) {
+	topicsMu.Lock()
+	defer topicsMu.Unlock()
+	for _, t := range topics {
+		delete(t.subscribers, conn.ID)
+	}
+}
+
+// handleSubscribe 处理客户端订阅
+func handleSubscribe(conn *Connection, msg *Message) {
+	name, ok := subscriptionTopic(conn, msg)
+	if !ok {
+		return
+	}
+
+	topicsMu.RLock()
+	t, exists := topics[name]
+	var authorize func(uint) bool
+	if exists {
+		authorize = t.authorize
+	}
+	topicsMu.RUnlock()
+	if !exists {
+		conn.sendProtocolError(protocol.NewError(protocol.ErrCodeNotFound, "主题未启用: "+name).WithRef(msg.ID))
+		return
+	}
+	if authorize != nil && !authorize(conn.UserID) {
+		conn.sendProtocolError(protocol.NewError(protocol.ErrCodeForbidden, "无权订阅该主题").WithRef(msg.ID))
+		return
+	}
+
+	topicsMu.Lock()
+	t.subscribers[conn.ID] = conn
+	topicsMu.Unlock()
+
+	logger.Debug("订阅主题",
+		zap.String("conn_id", conn.ID),
+		zap.Uint("user_id", conn.UserID),
+		zap.String("topic", name),
+	)
+	replySubscription(conn, name, true)
+}
+
+// handleUnsubscribe 处理客户端退订
+func handleUnsubscribe(conn *Connection, msg *Message) {
+	name, ok := subscriptionTopic(conn, msg)
+	if !ok {
+		return
+	}
+
+	topicsMu.Lock()
+	if t, exists := topics[name]; exists {
+		delete(t.subscribers, conn.ID)
+	}
+	topicsMu.Unlock()
+
+	replySubscription(conn, name, false)
+}
+
+// subscriptionTopic 解析订阅消息中的主题
+func subscriptionTopic(conn *Connection, msg *Message) (string, bool) {
+	// 内容已在 handleTextMessage 中按协议校验，这里只需处理 content 为空的情况
+	var sub protocol.Subscription
+	if err := json.Unmarshal(msg.Content, &sub); err != nil || sub.Topic == "" {
+		conn.sendProtocolError(protocol.NewError(protocol.ErrCodeInvalidPayload, "topic 不能为空").WithRef(msg.ID))
+		return "", false
+	}
+	return sub.Topic, true
+}
+
+// replySubscription 通知连接订阅状态
+func replySubscription(conn *Connection, name string, subscribed bool) {
+	reply := NewMessage(MessageTypeSystem, protocol.SubscriptionChanged{
+		Event:      protocol.EventSubscription,
+		Topic:      name,
+		Subscribed: subscribed,
+	})
+	_ = conn.SendMessage(reply)
+}
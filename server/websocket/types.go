@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"time"
+
+	"github.com/sunyuanling/server/websocket/protocol"
 )
 
 // 错误定义
@@ -15,17 +17,26 @@ var (
 	ErrDeviceNotFound   = errors.New("device not found")
 )
 
-// MessageType 消息类型
+// MessageType 消息类型，取值与 content 结构见 protocol 包
 type MessageType string
 
 const (
-	MessageTypeText      MessageType = "text"
-	MessageTypeBroadcast MessageType = "broadcast"
-	MessageTypeSystem    MessageType = "system"
-	MessageTypeHeartbeat MessageType = "heartbeat"
-	MessageTypeAck       MessageType = "ack"
-	MessageTypeFileSync  MessageType = "file_sync"
-	MessageTypeNotify    MessageType = "notification"
+	MessageTypeText         MessageType = protocol.TypeText
+	MessageTypeBroadcast    MessageType = protocol.TypeBroadcast
+	MessageTypeSystem       MessageType = protocol.TypeSystem
+	MessageTypeHeartbeat    MessageType = protocol.TypeHeartbeat
+	MessageTypeAck          MessageType = protocol.TypeAck
+	MessageTypeFileSync     MessageType = protocol.TypeFileSync
+	MessageTypeNotify       MessageType = protocol.TypeNotify
+	MessageTypeCommand      MessageType = protocol.TypeCommand      // 服务端下发的设备指令
+	MessageTypeCommandAck   MessageType = protocol.TypeCommandAck   // 设备对指令的回执
+	MessageTypeError        MessageType = protocol.TypeError        // 协议错误
+	MessageTypeFileUpload   MessageType = protocol.TypeFileUpload   // 文件上传进度
+	MessageTypeFileDownload MessageType = protocol.TypeFileDownload // 文件下载进度
+	MessageTypePresence     MessageType = protocol.TypePresence     // 客户端设置在线状态
+	MessageTypeSubscribe    MessageType = protocol.TypeSubscribe    // 客户端订阅主题
+	MessageTypeUnsubscribe  MessageType = protocol.TypeUnsubscribe  // 客户端退订主题
+	MessageTypeMetrics      MessageType = protocol.TypeMetrics      // 系统监控采样
 )
 
 // TargetType 消息目标类型
@@ -55,21 +66,22 @@ const (
 type DeviceStatus string
 
 const (
-	DeviceStatusOnline  DeviceStatus = "online"
-	DeviceStatusAway    DeviceStatus = "away"
-	DeviceStatusBusy    DeviceStatus = "busy"
-	DeviceStatusOffline DeviceStatus = "offline"
+	DeviceStatusOnline  DeviceStatus = protocol.PresenceOnline
+	DeviceStatusAway    DeviceStatus = protocol.PresenceAway
+	DeviceStatusBusy    DeviceStatus = protocol.PresenceBusy
+	DeviceStatusOffline DeviceStatus = protocol.PresenceOffline
 )
 
 // Message WebSocket消息结构
 type Message struct {
-	ID        string          `json:"id,omitempty"`    // 消息ID
-	Type      MessageType     `json:"type"`            // 消息类型
-	From      *Sender         `json:"from,omitempty"`  // 发送者信息
-	Target    *Target         `json:"target"`          // 目标信息
-	Content   json.RawMessage `json:"content"`         // 消息内容
-	Timestamp int64           `json:"timestamp"`       // 时间戳
-	Extra     json.RawMessage `json:"extra,omitempty"` // 扩展字段
+	ID        string          `json:"id,omitempty"`        // 消息ID
+	Version   int             `json:"v,omitempty"`         // 协议版本
+	Type      MessageType     `json:"type"`                // 消息类型
+	From      *Sender         `json:"from,omitempty"`      // 发送者信息
+	Target    *Target         `json:"target"`              // 目标信息
+	Content   json.RawMessage `json:"content"`             // 消息内容
+	Timestamp int64           `json:"timestamp,omitempty"` // 时间戳（服务端填写）
+	Extra     json.RawMessage `json:"extra,omitempty"`     // 扩展字段
 }
 
 // Sender 发送者信息
@@ -91,6 +103,7 @@ type Target struct {
 // DeviceInfo 设备信息
 type DeviceInfo struct {
 	DeviceID   string                 `json:"device_id"`       // 设备唯一标识
+	RecordID   uint                   `json:"record_id"`       // device 表主键
 	DeviceType DeviceType             `json:"device_type"`     // 设备类型
 	DeviceName string                 `json:"device_name"`     // 设备名称
 	Status     DeviceStatus           `json:"status"`          // 设备状态
@@ -132,6 +145,7 @@ func NewMessage(msgType MessageType, content interface{}) *Message {
 	data, _ := json.Marshal(content)
 	return &Message{
 		ID:        generateMessageID(),
+		Version:   protocol.CurrentVersion,
 		Type:      msgType,
 		Content:   data,
 		Timestamp: time.Now().Unix(),
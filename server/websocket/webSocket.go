@@ -4,44 +4,77 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+	"github.com/sunyuanling/server/internal/rbac"
 	"github.com/sunyuanling/server/pkg/logger"
+	"github.com/sunyuanling/server/websocket/protocol"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // InitWebSocket 初始化WebSocket系统
-func InitWebSocket(db *gorm.DB) *Handler {
+func InitWebSocket(db *gorm.DB, rdb *redis.Client) *Handler {
 	hub := GetHub()
+	devices := newDeviceTracker(db)
+	pendingStore = rdb
+	commandStore = db
+	groupStore = db
 
 	// 设置连接事件处理器
 	hub.SetConnectionHandler(
 		// 连接成功
 		func(conn *Connection) {
-			msg := NewMessage(MessageTypeSystem, map[string]interface{}{
-				"event":       "user_online",
-				"user_id":     conn.UserID,
-				"conn_id":     conn.ID,
-				"device_id":   conn.Device.DeviceID,
-				"device_type": conn.Device.DeviceType,
+			// 先恢复分组，上线通知才能送达同组用户
+			rehydrateGroups(conn)
+
+			msg := NewMessage(MessageTypeSystem, protocol.UserOnline{
+				Event:      protocol.EventUserOnline,
+				UserID:     conn.UserID,
+				ConnID:     conn.ID,
+				DeviceID:   conn.Device.DeviceID,
+				DeviceType: string(conn.Device.DeviceType),
 			})
-			hub.Broadcast(msg)
+			sendToPresenceAudience(conn.UserID, msg)
+
+			// 补发设备离线期间的消息
+			flushPending(conn)
+			deliverPendingCommands(conn)
 		},
 		// 断开连接
 		func(conn *Connection) {
+			devices.onDisconnect(conn)
+
 			// 检查用户是否还有其他连接
 			remaining := len(hub.GetUserConnections(conn.UserID))
 
-			msg := NewMessage(MessageTypeSystem, map[string]interface{}{
-				"event":                 "user_offline",
-				"user_id":               conn.UserID,
-				"conn_id":               conn.ID,
-				"device_id":             conn.Device.DeviceID,
-				"remaining_connections": remaining,
+			msg := NewMessage(MessageTypeSystem, protocol.UserOffline{
+				Event:                protocol.EventUserOffline,
+				UserID:               conn.UserID,
+				ConnID:               conn.ID,
+				DeviceID:             conn.Device.DeviceID,
+				RemainingConnections: remaining,
+				LastSeen:             time.Now().Unix(),
 			})
-			hub.Broadcast(msg)
+			sendToAudience(conn.offlineAudience, msg)
 		},
 	)
 
+	// 心跳时刷新设备活跃时间
+	hub.SetHeartbeatHandler(devices.onHeartbeat)
+
+	// 客户端设置在线状态
+	hub.RegisterHandler(MessageTypePresence, devices.onPresence)
+
+	// 客户端广播与 /ws/broadcast 接口一样需要用户管理权限
+	hub.SetBroadcastAuthorizer(func(conn *Connection) bool {
+		grants, err := rbac.Load(db, conn.UserID)
+		if err != nil {
+			logger.Error("加载用户权限失败", zap.Uint("user_id", conn.UserID), zap.Error(err))
+			return false
+		}
+		return grants.Has(rbac.PermUserManage)
+	})
+
 	// 注册默认消息处理器
 	RegisterDefaultHandlers(hub)
 
@@ -77,9 +110,14 @@ func RegisterDefaultHandlers(hub *Hub) {
 	})
 
 	// 广播消息
-	hub.RegisterHandler(MessageTypeBroadcast, func(conn *Connection, msg *Message) {
-		hub.Broadcast(msg)
-	})
+	hub.RegisterHandler(MessageTypeBroadcast, hub.BroadcastFrom)
+
+	// 设备指令回执
+	hub.RegisterHandler(MessageTypeCommandAck, handleCommandAck)
+
+	// 主题订阅
+	hub.RegisterHandler(MessageTypeSubscribe, handleSubscribe)
+	hub.RegisterHandler(MessageTypeUnsubscribe, handleUnsubscribe)
 }
 
 // ========== 便捷函数 ==========
@@ -112,11 +150,11 @@ func SendToConn(connID string, msgType MessageType, content interface{}) error {
 	return GetHub().SendToConn(connID, msg)
 }
 
-// SendToDevice 发送消息给指定设备
-func SendToDevice(deviceID string, msgType MessageType, content interface{}) error {
+// SendToDevice 发送消息给用户的指定设备
+func SendToDevice(userID uint, deviceID string, msgType MessageType, content interface{}) error {
 	msg := NewMessage(msgType, content)
 	msg.Target = NewTargetDevice(deviceID)
-	return GetHub().SendToDevice(deviceID, msg)
+	return GetHub().SendToDevice(userID, deviceID, msg)
 }
 
 // SendToGroup 发送消息给分组
@@ -128,33 +166,33 @@ func SendToGroup(groupName string, msgType MessageType, content interface{}) {
 
 // NotifyUser 发送通知给用户
 func NotifyUser(userID uint, title, message, level string) error {
-	content := map[string]interface{}{
-		"title":   title,
-		"message": message,
-		"level":   level,
-		"time":    time.Now().Unix(),
+	content := protocol.Notification{
+		Title:   title,
+		Message: message,
+		Level:   level,
+		Time:    time.Now().Unix(),
 	}
 	return SendToUser(userID, MessageTypeNotify, content)
 }
 
-// NotifyDevice 发送通知给设备
-func NotifyDevice(deviceID string, title, message, level string) error {
-	content := map[string]interface{}{
-		"title":   title,
-		"message": message,
-		"level":   level,
-		"time":    time.Now().Unix(),
+// NotifyDevice 发送通知给用户的设备
+func NotifyDevice(userID uint, deviceID string, title, message, level string) error {
+	content := protocol.Notification{
+		Title:   title,
+		Message: message,
+		Level:   level,
+		Time:    time.Now().Unix(),
 	}
-	return SendToDevice(deviceID, MessageTypeNotify, content)
+	return SendToDevice(userID, deviceID, MessageTypeNotify, content)
 }
 
 // NotifyAll 通知所有用户
 func NotifyAll(title, message, level string) {
-	content := map[string]interface{}{
-		"title":   title,
-		"message": message,
-		"level":   level,
-		"time":    time.Now().Unix(),
+	content := protocol.Notification{
+		Title:   title,
+		Message: message,
+		Level:   level,
+		Time:    time.Now().Unix(),
 	}
 	Broadcast(MessageTypeNotify, content)
 }
@@ -166,9 +204,9 @@ func IsUserOnline(userID uint) bool {
 	return GetHub().IsUserOnline(userID)
 }
 
-// IsDeviceOnline 检查设备是否在线
-func IsDeviceOnline(deviceID string) bool {
-	return GetHub().IsDeviceOnline(deviceID)
+// IsDeviceOnline 检查用户的设备是否在线
+func IsDeviceOnline(userID uint, deviceID string) bool {
+	return GetHub().IsDeviceOnline(userID, deviceID)
 }
 
 // GetOnlineUserCount 获取在线用户数量
@@ -214,9 +252,9 @@ func DisconnectUser(userID uint) {
 	}
 }
 
-// DisconnectDevice 断开指定设备
-func DisconnectDevice(deviceID string) {
-	if conn, exists := GetHub().GetConnectionByDevice(deviceID); exists {
+// DisconnectDevice 断开用户的指定设备
+func DisconnectDevice(userID uint, deviceID string) {
+	if conn, exists := GetHub().GetConnectionByDevice(userID, deviceID); exists {
 		conn.Close()
 	}
 }